@@ -5,9 +5,11 @@ import (
 	"flag"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -27,12 +29,14 @@ import (
 	"github.com/NavarchProject/navarch/pkg/provider/fake"
 	"github.com/NavarchProject/navarch/pkg/provider/gcp"
 	"github.com/NavarchProject/navarch/pkg/provider/lambda"
+	pb "github.com/NavarchProject/navarch/proto"
 	"github.com/NavarchProject/navarch/proto/protoconnect"
 )
 
 func main() {
 	configPath := flag.String("config", "", "Path to configuration file")
 	authToken := flag.String("auth-token", "", "Authentication token (or use NAVARCH_AUTH_TOKEN env)")
+	configWatchInterval := flag.Duration("config-watch-interval", 10*time.Second, "How often to check the configuration file for changes (0 disables; SIGHUP always reloads)")
 	flag.Parse()
 
 	// Get auth token from flag or environment
@@ -63,7 +67,12 @@ func main() {
 		slog.String("addr", cfg.Server.Address),
 	)
 
-	database := db.NewInMemDB()
+	database, err := buildDatabase(cfg.Server.Database)
+	if err != nil {
+		logger.Error("failed to open database", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	logger.Info("database configured", slog.String("type", databaseType(cfg.Server.Database)))
 
 	// Create the instance manager for tracking cloud instance lifecycle
 	instanceManager := controlplane.NewInstanceManager(
@@ -73,14 +82,10 @@ func main() {
 	)
 
 	// Create heartbeat monitor to detect dead nodes
-	heartbeatTimeout := cfg.Server.HeartbeatTimeout
-	if heartbeatTimeout == 0 {
-		heartbeatTimeout = 3 * cfg.Server.HeartbeatInterval // Default: 3x heartbeat interval
-	}
 	heartbeatMonitor := controlplane.NewHeartbeatMonitor(
 		database,
 		controlplane.HeartbeatMonitorConfig{
-			HeartbeatTimeout: heartbeatTimeout,
+			HeartbeatTimeout: heartbeatTimeout(cfg),
 			CheckInterval:    cfg.Server.HeartbeatInterval,
 		},
 		logger,
@@ -97,12 +102,27 @@ func main() {
 		}
 		logger.Info("loaded health policy", slog.String("path", cfg.Server.HealthPolicy), slog.Int("rules", len(healthPolicy.Rules)))
 	}
+	poolHealthPolicies, err := loadPoolHealthPolicies(cfg)
+	if err != nil {
+		logger.Error("failed to load pool health policy", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	for name, policy := range poolHealthPolicies {
+		logger.Info("loaded pool health policy", slog.String("pool", name), slog.String("path", cfg.Pools[name].HealthPolicy), slog.Int("rules", len(policy.Rules)))
+	}
 
 	srv := controlplane.NewServer(database, controlplane.Config{
 		HealthCheckIntervalSeconds: int32(cfg.Server.HealthCheckInterval.Seconds()),
 		HeartbeatIntervalSeconds:   int32(cfg.Server.HeartbeatInterval.Seconds()),
 		EnabledHealthChecks:        []string{"boot", "nvml", "xid"},
 		HealthPolicy:               healthPolicy,
+		PoolHealthPolicies:         poolHealthPolicies,
+		HealthPolicyDir:            cfg.Server.HealthPolicyDir,
+		HealthHistoryWindow:        cfg.Server.HealthHistoryWindow,
+		HealthHistoryMaxEvents:     cfg.Server.HealthHistoryMaxEvents,
+		HealthEventRetention:       cfg.Server.HealthEventRetention,
+		CommandRetention:           cfg.Server.CommandRetention,
+		FlapDetection:              flapConfig(cfg.Server.FlapDetection),
 	}, instanceManager, logger)
 
 	// Set up notifier for workload system integration
@@ -110,8 +130,10 @@ func main() {
 	srv.SetNotifier(n)
 	logger.Info("notifier configured", slog.String("type", n.Name()))
 
+	// Start the pool manager whenever providers are configured, so pools can
+	// be created at runtime even if the config file defines none.
 	var poolManager *controlplane.PoolManager
-	if len(cfg.Pools) > 0 {
+	if len(cfg.Pools) > 0 || len(cfg.Providers) > 0 {
 		poolManager, err = initPoolManager(cfg, database, instanceManager, logger)
 		if err != nil {
 			logger.Error("failed to initialize pool manager", slog.String("error", err.Error()))
@@ -119,12 +141,24 @@ func main() {
 		}
 		// Wire pool manager to receive health notifications for auto-replacement
 		srv.SetHealthObserver(poolManager)
+		srv.SetPoolManager(poolManager)
 		heartbeatMonitor.SetHealthObserver(poolManager)
+		poolManager.SetNotifier(n)
+	}
+
+	var reaper *controlplane.Reaper
+	if r := cfg.Server.Reaper; r != nil && r.Enabled && poolManager != nil {
+		reaper = controlplane.NewReaper(database, poolManager, controlplane.ReaperConfig{
+			GracePeriod: r.GracePeriod,
+			Interval:    r.Interval,
+			DryRun:      r.DryRun,
+		}, logger)
 	}
 
 	// Create Prometheus metrics collector
 	promMetrics := controlplane.NewPrometheusMetrics(database)
 	prometheus.MustRegister(promMetrics)
+	srv.SetBurnInObserver(promMetrics)
 
 	mux := http.NewServeMux()
 	path, handler := protoconnect.NewControlPlaneServiceHandler(srv)
@@ -160,14 +194,17 @@ func main() {
 
 	logger.Info("control plane ready", slog.String("addr", cfg.Server.Address))
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	httpServer := &http.Server{
 		Addr:    cfg.Server.Address,
 		Handler: h2c.NewHandler(httpHandler, &http2.Server{}),
+		// Derive request contexts from ctx so that cancelling it on shutdown
+		// ends long-lived WatchCommands streams.
+		BaseContext: func(net.Listener) context.Context { return ctx },
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
 	// Start the instance manager for background stale instance detection
 	instanceManager.Start(ctx)
 
@@ -175,9 +212,23 @@ func main() {
 	heartbeatMonitor.Start(ctx)
 
 	if poolManager != nil {
+		// Re-adopt instances that survived a restart before the autoscaler
+		// runs, so existing capacity is not provisioned twice.
+		if _, err := poolManager.Reconcile(ctx); err != nil {
+			logger.Warn("pool reconciliation incomplete", slog.String("error", err.Error()))
+		}
 		poolManager.Start(ctx)
 	}
 
+	if reaper != nil {
+		reaper.Start(ctx)
+	}
+
+	if *configPath != "" {
+		reloader := newConfigReloader(*configPath, cfg, healthPolicy, poolHealthPolicies, srv, heartbeatMonitor, poolManager, logger)
+		go reloader.Run(ctx, *configWatchInterval)
+	}
+
 	serverErrChan := make(chan error, 1)
 	go func() {
 		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -201,6 +252,10 @@ func main() {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
+	if reaper != nil {
+		reaper.Stop()
+	}
+
 	if poolManager != nil {
 		poolManager.Stop()
 	}
@@ -218,6 +273,33 @@ func main() {
 	logger.Info("control plane stopped")
 }
 
+// heartbeatTimeout returns the configured heartbeat timeout, defaulting to
+// three heartbeat intervals.
+func heartbeatTimeout(cfg *config.Config) time.Duration {
+	if cfg.Server.HeartbeatTimeout != 0 {
+		return cfg.Server.HeartbeatTimeout
+	}
+	return 3 * cfg.Server.HeartbeatInterval
+}
+
+// loadPoolHealthPolicies loads the health policy of each pool that names
+// one, keyed by pool name.
+func loadPoolHealthPolicies(cfg *config.Config) (map[string]*health.Policy, error) {
+	policies := make(map[string]*health.Policy)
+	for _, name := range sortedPoolNames(cfg.Pools) {
+		path := cfg.Pools[name].HealthPolicy
+		if path == "" {
+			continue
+		}
+		policy, err := health.LoadPolicy(path)
+		if err != nil {
+			return nil, fmt.Errorf("pools.%s.health_policy %s: %w", name, path, err)
+		}
+		policies[name] = policy
+	}
+	return policies, nil
+}
+
 func defaultConfig() *config.Config {
 	return &config.Config{
 		Server: config.ServerConfig{
@@ -250,13 +332,6 @@ func readyzHandler(database db.DB, logger *slog.Logger) http.HandlerFunc {
 }
 
 func initPoolManager(cfg *config.Config, database db.DB, instanceManager *controlplane.InstanceManager, logger *slog.Logger) (*controlplane.PoolManager, error) {
-	metricsSource := controlplane.NewDBMetricsSource(database, logger)
-	pm := controlplane.NewPoolManager(controlplane.PoolManagerConfig{
-		EvaluationInterval: cfg.Server.AutoscaleInterval,
-		DB:                 database,
-	}, metricsSource, instanceManager, logger)
-
-	providers := make(map[string]provider.Provider)
 	controlPlaneAddr := cfg.Server.ExternalAddress
 	if controlPlaneAddr == "" {
 		controlPlaneAddr = fmt.Sprintf("http://localhost%s", cfg.Server.Address)
@@ -265,13 +340,24 @@ func initPoolManager(cfg *config.Config, database db.DB, instanceManager *contro
 		}
 	}
 
-	for poolName, poolCfg := range cfg.Pools {
+	// Providers are shared between pools, including pools created at runtime.
+	var providersMu sync.Mutex
+	providers := make(map[string]provider.Provider)
+
+	buildPool := func(poolName string, poolCfg config.PoolCfg) (*pool.Pool, error) {
+		providersMu.Lock()
 		poolProviders, err := buildPoolProviders(poolName, poolCfg, cfg, providers, controlPlaneAddr, logger)
+		providersMu.Unlock()
 		if err != nil {
 			return nil, err
 		}
 
-		labels := buildPoolLabels(poolName, poolCfg.Labels)
+		labels := buildPoolLabels(poolName, cfg.Server.ClusterID, poolCfg.Labels)
+
+		var burnIn config.BurnInCfg
+		if poolCfg.BurnIn != nil {
+			burnIn = *poolCfg.BurnIn
+		}
 
 		p, err := pool.NewWithOptions(pool.NewPoolOptions{
 			Config: pool.Config{
@@ -285,6 +371,11 @@ func initPoolManager(cfg *config.Config, database db.DB, instanceManager *contro
 				CooldownPeriod:     poolCfg.Cooldown,
 				UnhealthyThreshold: config.GetUnhealthyThreshold(poolCfg.Health),
 				AutoReplace:        config.GetAutoReplace(poolCfg.Health),
+				MinHealthyGPUs:     config.GetMinHealthyGPUs(poolCfg.Health),
+				DisableReaper:      poolCfg.DisableReaper,
+				BurnIn:             burnIn.Enabled,
+				BurnInTests:        burnIn.Tests,
+				BurnInTimeout:      burnIn.Timeout,
 				Labels:            labels,
 				SetupCommands:     poolCfg.SetupCommands,
 				SSHUser:           poolCfg.SSHUser,
@@ -298,22 +389,22 @@ func initPoolManager(cfg *config.Config, database db.DB, instanceManager *contro
 		if err != nil {
 			return nil, fmt.Errorf("failed to create pool %s: %w", poolName, err)
 		}
+		return p, nil
+	}
 
-		autoscaler, err := config.BuildAutoscaler(poolCfg.Autoscaling)
-		if err != nil {
-			return nil, fmt.Errorf("pool %s: %w", poolName, err)
-		}
+	metricsSource := controlplane.NewDBMetricsSource(database, logger)
+	pm := controlplane.NewPoolManager(controlplane.PoolManagerConfig{
+		EvaluationInterval: cfg.Server.AutoscaleInterval,
+		DB:                 database,
+		Config:             cfg,
+		PoolBuilder:        buildPool,
+		ClusterID:          cfg.Server.ClusterID,
+	}, metricsSource, instanceManager, logger)
 
-		if err := pm.AddPool(p, autoscaler); err != nil {
+	for poolName, poolCfg := range cfg.Pools {
+		if err := pm.CreatePool(context.Background(), poolName, poolCfg); err != nil {
 			return nil, err
 		}
-
-		logger.Info("pool configured",
-			slog.String("pool", poolName),
-			slog.String("instance_type", poolCfg.InstanceType),
-			slog.Int("min_nodes", poolCfg.MinNodes),
-			slog.Int("max_nodes", poolCfg.MaxNodes),
-		)
 	}
 
 	return pm, nil
@@ -351,13 +442,17 @@ func buildPoolProviders(poolName string, poolCfg config.PoolCfg, cfg *config.Con
 	return poolProviders, nil
 }
 
-func buildPoolLabels(poolName string, labels map[string]string) map[string]string {
+func buildPoolLabels(poolName, clusterID string, labels map[string]string) map[string]string {
 	result := make(map[string]string)
 	for k, v := range labels {
 		result[k] = v
 	}
 	// Ensure pool name is always in labels for metrics aggregation
-	result["pool"] = poolName
+	result[pool.NameLabel] = poolName
+	// Mark the instance as ours, so the reaper never touches another
+	// deployment's instances
+	result[pool.OwnerLabel] = poolName
+	result[pool.ClusterLabel] = clusterID
 	return result
 }
 
@@ -421,6 +516,44 @@ func getOrCreateProvider(name string, cfg *config.Config, cache map[string]provi
 	return prov, nil
 }
 
+func buildDatabase(cfg *config.DatabaseCfg) (db.DB, error) {
+	switch databaseType(cfg) {
+	case "sqlite":
+		return db.NewSQLiteDB(cfg.Path)
+	case "memory":
+		return db.NewInMemDB(), nil
+	default:
+		return nil, fmt.Errorf("unknown database type: %s", cfg.Type)
+	}
+}
+
+func databaseType(cfg *config.DatabaseCfg) string {
+	if cfg == nil || cfg.Type == "" {
+		return "memory"
+	}
+	return cfg.Type
+}
+
+// flapConfig converts the flap detection configuration. Detection is off
+// unless enabled.
+func flapConfig(cfg *config.FlapDetectionCfg) controlplane.FlapConfig {
+	if cfg == nil || !cfg.Enabled {
+		return controlplane.FlapConfig{}
+	}
+	fc := controlplane.FlapConfig{
+		Transitions: cfg.Transitions,
+		Window:      cfg.Window,
+		HoldDown:    cfg.HoldDown,
+	}
+	if fc.Transitions == 0 {
+		fc.Transitions = controlplane.DefaultFlapConfig().Transitions
+	}
+	if cfg.State == "unhealthy" {
+		fc.HoldState = pb.NodeStatus_NODE_STATUS_UNHEALTHY
+	}
+	return fc
+}
+
 func buildNotifier(cfg *config.NotifierCfg, logger *slog.Logger) notifier.Notifier {
 	if cfg == nil {
 		return notifier.NewNoop(logger)
@@ -437,6 +570,7 @@ func buildNotifier(cfg *config.NotifierCfg, logger *slog.Logger) notifier.Notifi
 			UncordonURL:    cfg.Webhook.UncordonURL,
 			DrainURL:       cfg.Webhook.DrainURL,
 			DrainStatusURL: cfg.Webhook.DrainStatusURL,
+			HealthURL:      cfg.Webhook.HealthURL,
 			Timeout:        cfg.Webhook.Timeout,
 			Headers:        cfg.Webhook.Headers,
 		}, logger)
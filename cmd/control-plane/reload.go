@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/NavarchProject/navarch/pkg/config"
+	"github.com/NavarchProject/navarch/pkg/controlplane"
+	"github.com/NavarchProject/navarch/pkg/health"
+)
+
+// configReloader applies changes to the configuration file without a
+// restart. Changes that running components can absorb (heartbeat timeout,
+// notifier, health policies, pool settings, new pools) are applied in place.
+// Any other change rejects the whole reload and the running configuration
+// is kept.
+type configReloader struct {
+	path   string
+	logger *slog.Logger
+
+	server           *controlplane.Server
+	heartbeatMonitor *controlplane.HeartbeatMonitor
+	poolManager      *controlplane.PoolManager // nil if pools are not managed
+
+	mu       sync.Mutex
+	current  *config.Config
+	policy   *health.Policy // loaded from current.Server.HealthPolicy; nil for the default policy
+	checksum [sha256.Size]byte
+
+	poolPolicies map[string]*health.Policy // loaded from each pool's HealthPolicy, keyed by pool name
+}
+
+func newConfigReloader(path string, cfg *config.Config, policy *health.Policy, poolPolicies map[string]*health.Policy, srv *controlplane.Server, hm *controlplane.HeartbeatMonitor, pm *controlplane.PoolManager, logger *slog.Logger) *configReloader {
+	r := &configReloader{
+		path:             path,
+		logger:           logger,
+		server:           srv,
+		heartbeatMonitor: hm,
+		poolManager:      pm,
+		current:          cfg,
+		policy:           policy,
+		poolPolicies:     poolPolicies,
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		r.checksum = sha256.Sum256(data)
+	}
+	return r
+}
+
+// Run reloads the configuration on SIGHUP, and whenever the file's contents
+// change if watchInterval is positive, until ctx is done.
+func (r *configReloader) Run(ctx context.Context, watchInterval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var poll <-chan time.Time
+	if watchInterval > 0 {
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.logger.Info("received SIGHUP, reloading configuration", slog.String("path", r.path))
+			r.Reload(ctx)
+		case <-poll:
+			if r.fileChanged() {
+				r.logger.Info("configuration file changed, reloading", slog.String("path", r.path))
+				r.Reload(ctx)
+			}
+		}
+	}
+}
+
+func (r *configReloader) fileChanged() bool {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		// Editors often replace files by rename; try again next time.
+		return false
+	}
+	sum := sha256.Sum256(data)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return sum != r.checksum
+}
+
+// Reload reads the configuration file and applies it. The health policy file
+// is re-read too, so a reload also picks up policy edits made in place.
+func (r *configReloader) Reload(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.reloadLocked(ctx)
+	if err != nil {
+		r.logger.Error("configuration reload failed",
+			slog.String("path", r.path),
+			slog.String("error", err.Error()),
+		)
+	}
+	return err
+}
+
+func (r *configReloader) reloadLocked(ctx context.Context) error {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	// Remember the contents even if they are rejected, so the watcher does
+	// not retry until the file changes again.
+	r.checksum = sha256.Sum256(data)
+
+	next, err := config.Load(r.path)
+	if err != nil {
+		return err
+	}
+
+	var policy *health.Policy
+	if next.Server.HealthPolicy != "" {
+		policy, err = health.LoadPolicy(next.Server.HealthPolicy)
+		if err != nil {
+			return fmt.Errorf("loading health policy %s: %w", next.Server.HealthPolicy, err)
+		}
+	}
+
+	poolPolicies, err := loadPoolHealthPolicies(next)
+	if err != nil {
+		return fmt.Errorf("loading health policy: %w", err)
+	}
+
+	r.forgetDeletedPools(ctx, next)
+	changes, err := diffConfig(r.current, next)
+	if err != nil {
+		return fmt.Errorf("rejected, running configuration kept: %w", err)
+	}
+	if r.poolManager == nil && len(changes.addedPools) > 0 {
+		return fmt.Errorf("rejected, running configuration kept: pools cannot be added because pool management was not enabled at startup; restart the control plane")
+	}
+	changes.healthPolicy = !reflect.DeepEqual(r.policy, policy)
+	for _, name := range sortedPoolNames(next.Pools) {
+		if !reflect.DeepEqual(r.poolPolicies[name], poolPolicies[name]) {
+			changes.poolHealthPolicies = append(changes.poolHealthPolicies, name)
+		}
+	}
+
+	summary, err := r.apply(ctx, next, policy, poolPolicies, changes)
+	if len(summary) == 0 && err == nil {
+		r.logger.Info("configuration reloaded, no changes", slog.String("path", r.path))
+		return nil
+	}
+	r.logger.Info("configuration reloaded",
+		slog.String("path", r.path),
+		slog.Any("changes", summary),
+	)
+	return err
+}
+
+// forgetDeletedPools drops pools from the running configuration that were
+// deleted at runtime, or are being deleted, and are no longer in next. Removing
+// such a pool from the file is then accepted rather than rejected as a removal.
+func (r *configReloader) forgetDeletedPools(ctx context.Context, next *config.Config) {
+	if r.poolManager == nil {
+		return
+	}
+	var pools map[string]config.PoolCfg
+	for name := range r.current.Pools {
+		if _, ok := next.Pools[name]; ok {
+			continue
+		}
+		info, err := r.poolManager.DescribePool(ctx, name)
+		if !errors.Is(err, controlplane.ErrPoolNotFound) && !info.Deleting {
+			continue
+		}
+		if pools == nil {
+			pools = maps.Clone(r.current.Pools)
+		}
+		delete(pools, name)
+		r.logger.Info("pool deleted at runtime removed from configuration", slog.String("pool", name))
+	}
+	if pools != nil {
+		current := *r.current
+		current.Pools = pools
+		r.current = &current
+	}
+}
+
+// apply makes the changes in place and records next as the running
+// configuration. Settings that fail to apply keep their previous value, so
+// the next reload retries them.
+func (r *configReloader) apply(ctx context.Context, next *config.Config, policy *health.Policy, poolPolicies map[string]*health.Policy, changes configChanges) ([]string, error) {
+	prev := r.current
+	var summary []string
+	var errs []error
+
+	if changes.heartbeatTimeout {
+		timeout := heartbeatTimeout(next)
+		r.heartbeatMonitor.SetHeartbeatTimeout(timeout)
+		summary = append(summary, fmt.Sprintf("server.heartbeat_timeout: %s -> %s", heartbeatTimeout(prev), timeout))
+	}
+
+	if changes.notifier {
+		n := buildNotifier(next.Server.Notifier, r.logger)
+		r.server.SetNotifier(n)
+		if r.poolManager != nil {
+			r.poolManager.SetNotifier(n)
+		}
+		summary = append(summary, fmt.Sprintf("server.notifier: now %s", n.Name()))
+	}
+
+	if changes.healthPolicy {
+		p := policy
+		if p == nil {
+			p = health.DefaultPolicy()
+		}
+		if err := r.server.UpdateHealthPolicy(p); err != nil {
+			errs = append(errs, fmt.Errorf("server.health_policy: %w", err))
+			next.Server.HealthPolicy = prev.Server.HealthPolicy
+		} else {
+			r.policy = policy
+			source := next.Server.HealthPolicy
+			if source == "" {
+				source = "default policy"
+			}
+			summary = append(summary, fmt.Sprintf("server.health_policy: %d rules from %s", len(p.Rules), source))
+		}
+	}
+
+	for _, name := range changes.updatedPools {
+		if err := r.poolManager.UpdatePool(ctx, name, next.Pools[name]); err != nil {
+			errs = append(errs, fmt.Errorf("pools.%s: %w", name, err))
+			next.Pools[name] = prev.Pools[name]
+			continue
+		}
+		summary = append(summary, fmt.Sprintf("pools.%s: updated", name))
+	}
+	for _, name := range changes.addedPools {
+		if err := r.poolManager.CreatePool(ctx, name, next.Pools[name]); err != nil {
+			errs = append(errs, fmt.Errorf("pools.%s: %w", name, err))
+			delete(next.Pools, name)
+			continue
+		}
+		summary = append(summary, fmt.Sprintf("pools.%s: added", name))
+	}
+
+	for _, name := range changes.poolHealthPolicies {
+		poolCfg, ok := next.Pools[name]
+		if !ok {
+			// The pool failed to be added above.
+			continue
+		}
+		p := poolPolicies[name]
+		if err := r.server.SetPoolHealthPolicy(name, p); err != nil {
+			errs = append(errs, fmt.Errorf("pools.%s.health_policy: %w", name, err))
+			poolCfg.HealthPolicy = prev.Pools[name].HealthPolicy
+			next.Pools[name] = poolCfg
+			continue
+		}
+		if p == nil {
+			delete(r.poolPolicies, name)
+			summary = append(summary, fmt.Sprintf("pools.%s.health_policy: removed, server policy in effect", name))
+			continue
+		}
+		if r.poolPolicies == nil {
+			r.poolPolicies = make(map[string]*health.Policy)
+		}
+		r.poolPolicies[name] = p
+		summary = append(summary, fmt.Sprintf("pools.%s.health_policy: %d rules from %s", name, len(p.Rules), poolCfg.HealthPolicy))
+	}
+
+	r.current = next
+	return summary, errors.Join(errs...)
+}
+
+// configChanges lists the settings a reload changes in place.
+type configChanges struct {
+	heartbeatTimeout bool
+	notifier         bool
+	healthPolicy     bool
+	updatedPools     []string
+	addedPools       []string
+
+	// poolHealthPolicies lists pools whose health policy changed, including
+	// edits to the policy file itself.
+	poolHealthPolicies []string
+}
+
+// diffConfig compares a new configuration with the running one. It returns
+// an error naming every change that cannot be applied without a restart.
+func diffConfig(prev, next *config.Config) (configChanges, error) {
+	var changes configChanges
+	var errs []error
+
+	restart := func(field string, a, b any) {
+		if !reflect.DeepEqual(a, b) {
+			errs = append(errs, fmt.Errorf("%s changed; restart the control plane to apply it", field))
+		}
+	}
+	restart("server.address", prev.Server.Address, next.Server.Address)
+	restart("server.external_address", prev.Server.ExternalAddress, next.Server.ExternalAddress)
+	restart("server.cluster_id", prev.Server.ClusterID, next.Server.ClusterID)
+	restart("server.heartbeat_interval", prev.Server.HeartbeatInterval, next.Server.HeartbeatInterval)
+	restart("server.health_check_interval", prev.Server.HealthCheckInterval, next.Server.HealthCheckInterval)
+	restart("server.autoscale_interval", prev.Server.AutoscaleInterval, next.Server.AutoscaleInterval)
+	restart("server.health_policy_dir", prev.Server.HealthPolicyDir, next.Server.HealthPolicyDir)
+	restart("server.health_history_window", prev.Server.HealthHistoryWindow, next.Server.HealthHistoryWindow)
+	restart("server.health_history_max_events", prev.Server.HealthHistoryMaxEvents, next.Server.HealthHistoryMaxEvents)
+	restart("server.health_event_retention", prev.Server.HealthEventRetention, next.Server.HealthEventRetention)
+	restart("server.command_retention", prev.Server.CommandRetention, next.Server.CommandRetention)
+	restart("server.database", prev.Server.Database, next.Server.Database)
+	restart("server.reaper", prev.Server.Reaper, next.Server.Reaper)
+	restart("server.flap_detection", prev.Server.FlapDetection, next.Server.FlapDetection)
+	restart("providers", prev.Providers, next.Providers)
+
+	changes.heartbeatTimeout = heartbeatTimeout(prev) != heartbeatTimeout(next)
+	changes.notifier = !reflect.DeepEqual(prev.Server.Notifier, next.Server.Notifier)
+
+	for _, name := range sortedPoolNames(prev.Pools) {
+		before := prev.Pools[name]
+		after, ok := next.Pools[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("pools.%s removed; delete it with \"navarch pool delete %s\" before removing it from the file", name, name))
+			continue
+		}
+		if reflect.DeepEqual(before, after) {
+			continue
+		}
+		// Existing nodes cannot follow these; a new pool is needed.
+		immutable := func(field string, a, b any) {
+			if !reflect.DeepEqual(a, b) {
+				errs = append(errs, fmt.Errorf("pools.%s.%s cannot change for an existing pool; create a new pool instead", name, field))
+			}
+		}
+		immutable("instance_type", before.InstanceType, after.InstanceType)
+		immutable("provider", before.Provider, after.Provider)
+		immutable("providers", before.Providers, after.Providers)
+		immutable("strategy", before.Strategy, after.Strategy)
+		changes.updatedPools = append(changes.updatedPools, name)
+	}
+	for _, name := range sortedPoolNames(next.Pools) {
+		if _, ok := prev.Pools[name]; !ok {
+			changes.addedPools = append(changes.addedPools, name)
+		}
+	}
+
+	return changes, errors.Join(errs...)
+}
+
+func sortedPoolNames(pools map[string]config.PoolCfg) []string {
+	names := make([]string, 0, len(pools))
+	for name := range pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
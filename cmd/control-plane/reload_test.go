@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+
+	"github.com/NavarchProject/navarch/pkg/config"
+	"github.com/NavarchProject/navarch/pkg/controlplane"
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+const reloadBaseConfig = `
+server:
+  heartbeat_interval: 30s
+providers:
+  fake:
+    type: fake
+pools:
+  training:
+    provider: fake
+    instance_type: gpu_8x_h100
+    min_nodes: 0
+    max_nodes: 4
+`
+
+func TestDiffConfig(t *testing.T) {
+	load := func(t *testing.T, yaml string) *config.Config {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := config.Load(path)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		return cfg
+	}
+	base := load(t, reloadBaseConfig)
+
+	t.Run("safe_changes", func(t *testing.T) {
+		next := load(t, strings.NewReplacer(
+			"heartbeat_interval: 30s", "heartbeat_interval: 30s\n  heartbeat_timeout: 5m\n  notifier:\n    type: noop",
+			"max_nodes: 4", "max_nodes: 8\n  batch:\n    provider: fake\n    instance_type: gpu_1x_a100\n    max_nodes: 2",
+		).Replace(reloadBaseConfig))
+
+		changes, err := diffConfig(base, next)
+		if err != nil {
+			t.Fatalf("diffConfig failed: %v", err)
+		}
+		if !changes.heartbeatTimeout || !changes.notifier {
+			t.Errorf("changes = %+v, want heartbeat timeout and notifier", changes)
+		}
+		if len(changes.updatedPools) != 1 || changes.updatedPools[0] != "training" {
+			t.Errorf("updated pools = %v, want [training]", changes.updatedPools)
+		}
+		if len(changes.addedPools) != 1 || changes.addedPools[0] != "batch" {
+			t.Errorf("added pools = %v, want [batch]", changes.addedPools)
+		}
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		changes, err := diffConfig(base, load(t, reloadBaseConfig))
+		if err != nil {
+			t.Fatalf("diffConfig failed: %v", err)
+		}
+		if changes.heartbeatTimeout || changes.notifier || len(changes.updatedPools) > 0 || len(changes.addedPools) > 0 {
+			t.Errorf("expected no changes, got %+v", changes)
+		}
+	})
+
+	unsafe := []struct {
+		name    string
+		old     string
+		new     string
+		wantErr string
+	}{
+		{"address", "heartbeat_interval: 30s", "heartbeat_interval: 30s\n  address: :6000", "server.address"},
+		{"heartbeat_interval", "heartbeat_interval: 30s", "heartbeat_interval: 1m", "server.heartbeat_interval"},
+		{"providers", "type: fake", "type: fake\n  other:\n    type: fake", "providers"},
+		{"instance_type", "gpu_8x_h100", "gpu_4x_h100", "pools.training.instance_type"},
+		{"pool_removed", "training:", "renamed:", "pools.training removed"},
+	}
+	for _, tt := range unsafe {
+		t.Run(tt.name, func(t *testing.T) {
+			next := load(t, strings.Replace(reloadBaseConfig, tt.old, tt.new, 1))
+			_, err := diffConfig(base, next)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want mention of %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigReloader(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(t *testing.T, yaml string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(t, reloadBaseConfig)
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	database := db.NewInMemDB()
+	defer database.Close()
+	srv := controlplane.NewServer(database, controlplane.DefaultConfig(), nil, logger)
+	hm := controlplane.NewHeartbeatMonitor(database, controlplane.HeartbeatMonitorConfig{HeartbeatTimeout: heartbeatTimeout(cfg)}, logger)
+	pm, err := initPoolManager(cfg, database, nil, logger)
+	if err != nil {
+		t.Fatalf("initPoolManager failed: %v", err)
+	}
+	srv.SetPoolManager(pm)
+
+	r := newConfigReloader(path, cfg, nil, nil, srv, hm, pm, logger)
+	if r.fileChanged() {
+		t.Error("fileChanged reported a change before the file was edited")
+	}
+
+	t.Run("applies_safe_changes", func(t *testing.T) {
+		write(t, strings.Replace(reloadBaseConfig, "max_nodes: 4", "max_nodes: 8", 1))
+		if !r.fileChanged() {
+			t.Fatal("fileChanged did not detect the edit")
+		}
+		if err := r.Reload(ctx); err != nil {
+			t.Fatalf("Reload failed: %v", err)
+		}
+		info, err := pm.DescribePool(ctx, "training")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Config.MaxNodes != 8 {
+			t.Errorf("max nodes = %d, want 8", info.Config.MaxNodes)
+		}
+		if r.fileChanged() {
+			t.Error("fileChanged still reports a change after reloading")
+		}
+	})
+
+	t.Run("rejects_unsafe_changes", func(t *testing.T) {
+		write(t, strings.NewReplacer(
+			"max_nodes: 4", "max_nodes: 2",
+			"heartbeat_interval: 30s", "heartbeat_interval: 30s\n  address: :6000",
+		).Replace(reloadBaseConfig))
+
+		err := r.Reload(ctx)
+		if err == nil || !strings.Contains(err.Error(), "server.address") {
+			t.Fatalf("error = %v, want server.address rejection", err)
+		}
+		info, _ := pm.DescribePool(ctx, "training")
+		if info.Config.MaxNodes != 8 {
+			t.Errorf("max nodes = %d, want rejected reload to keep 8", info.Config.MaxNodes)
+		}
+	})
+
+	t.Run("invalid_health_policy", func(t *testing.T) {
+		write(t, strings.Replace(reloadBaseConfig, "heartbeat_interval: 30s",
+			"heartbeat_interval: 30s\n  health_policy: "+filepath.Join(t.TempDir(), "missing.yaml"), 1))
+		if err := r.Reload(ctx); err == nil {
+			t.Error("expected error for missing health policy file")
+		}
+	})
+
+	t.Run("health_policy", func(t *testing.T) {
+		policyPath := filepath.Join(t.TempDir(), "policy.yaml")
+		policy := `
+rules:
+  - name: default
+    condition: "true"
+    result: healthy
+`
+		if err := os.WriteFile(policyPath, []byte(policy), 0644); err != nil {
+			t.Fatal(err)
+		}
+		write(t, strings.Replace(reloadBaseConfig, "heartbeat_interval: 30s",
+			"heartbeat_interval: 30s\n  heartbeat_timeout: 10m\n  health_policy: "+policyPath, 1))
+		if err := r.Reload(ctx); err != nil {
+			t.Fatalf("Reload failed: %v", err)
+		}
+		if r.policy == nil || len(r.policy.Rules) != 1 {
+			t.Errorf("policy = %+v, want the reloaded single-rule policy", r.policy)
+		}
+		if got := heartbeatTimeout(r.current); got != 10*time.Minute {
+			t.Errorf("running heartbeat timeout = %v, want 10m", got)
+		}
+	})
+
+	t.Run("pool_health_policy", func(t *testing.T) {
+		policyPath := filepath.Join(t.TempDir(), "training-policy.yaml")
+		policy := `
+rules:
+  - name: fatal-xid
+    condition: event.event_type == "xid"
+    result: unhealthy
+  - name: default
+    condition: "true"
+    result: healthy
+`
+		if err := os.WriteFile(policyPath, []byte(policy), 0644); err != nil {
+			t.Fatal(err)
+		}
+		write(t, strings.Replace(reloadBaseConfig, "max_nodes: 4", "max_nodes: 8\n    health_policy: "+policyPath, 1))
+		if err := r.Reload(ctx); err != nil {
+			t.Fatalf("Reload failed: %v", err)
+		}
+		resp, err := srv.GetHealthPolicy(ctx, connect.NewRequest(&pb.GetHealthPolicyRequest{Pool: "training"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Msg.Inherited || len(resp.Msg.Policy.Rules) != 2 {
+			t.Errorf("training policy = %v, want its own two-rule policy", resp.Msg)
+		}
+
+		write(t, strings.Replace(reloadBaseConfig, "max_nodes: 4", "max_nodes: 8", 1))
+		if err := r.Reload(ctx); err != nil {
+			t.Fatalf("Reload failed: %v", err)
+		}
+		resp, err = srv.GetHealthPolicy(ctx, connect.NewRequest(&pb.GetHealthPolicyRequest{Pool: "training"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !resp.Msg.Inherited {
+			t.Error("training policy should be inherited once health_policy is removed")
+		}
+	})
+
+	t.Run("pool_deleted_then_removed_from_file", func(t *testing.T) {
+		withBatch := strings.Replace(reloadBaseConfig, "max_nodes: 4",
+			"max_nodes: 8\n  batch:\n    provider: fake\n    instance_type: gpu_1x_a100\n    max_nodes: 2", 1)
+		write(t, withBatch)
+		if err := r.Reload(ctx); err != nil {
+			t.Fatalf("Reload failed: %v", err)
+		}
+		if err := pm.DeletePool(ctx, "batch"); err != nil {
+			t.Fatalf("DeletePool failed: %v", err)
+		}
+
+		write(t, strings.Replace(reloadBaseConfig, "max_nodes: 4", "max_nodes: 8", 1))
+		if err := r.Reload(ctx); err != nil {
+			t.Fatalf("Reload after removing the deleted pool failed: %v", err)
+		}
+		if _, ok := r.current.Pools["batch"]; ok {
+			t.Error("running configuration still has the deleted pool")
+		}
+
+		write(t, strings.Replace(reloadBaseConfig, "max_nodes: 4", "max_nodes: 6", 1))
+		if err := r.Reload(ctx); err != nil {
+			t.Fatalf("later Reload failed: %v", err)
+		}
+		info, err := pm.DescribePool(ctx, "training")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Config.MaxNodes != 6 {
+			t.Errorf("max nodes = %d, want 6", info.Config.MaxNodes)
+		}
+	})
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+func commandsCmd() *cobra.Command {
+	var nodeID, cmdType, status, issuedBy string
+	var since, until string
+	var limit int32
+
+	cmd := &cobra.Command{
+		Use:   "commands",
+		Short: "List commands issued to nodes",
+		Long: `List commands issued to nodes, oldest first, including control-plane-only
+commands such as cordon and drain. Only the most recent --limit commands are
+shown. Use "navarch commands get" to see the message and output a node
+reported for a command.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+
+			req := &pb.ListCommandsRequest{
+				NodeId:   nodeID,
+				IssuedBy: issuedBy,
+				Limit:    limit,
+			}
+
+			if cmdType != "" {
+				typeEnum, err := parseCommandType(cmdType)
+				if err != nil {
+					return err
+				}
+				req.CommandType = typeEnum
+			}
+			if status != "" {
+				statusEnum, err := parseCommandStatus(status)
+				if err != nil {
+					return err
+				}
+				req.Status = statusEnum
+			}
+
+			now := time.Now()
+			if since != "" {
+				t, err := parseTimeFlag(since, now)
+				if err != nil {
+					return fmt.Errorf("invalid --since: %w", err)
+				}
+				req.IssuedAfter = timestamppb.New(t)
+			}
+			if until != "" {
+				t, err := parseTimeFlag(until, now)
+				if err != nil {
+					return fmt.Errorf("invalid --until: %w", err)
+				}
+				req.IssuedBefore = timestamppb.New(t)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+
+			resp, err := client.ListCommands(ctx, connect.NewRequest(req))
+			if err != nil {
+				return fmt.Errorf("failed to list commands: %w", err)
+			}
+
+			if len(resp.Msg.Commands) == 0 {
+				fmt.Println("No commands found")
+				return nil
+			}
+
+			switch outputFormat {
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(resp.Msg.Commands)
+			case "table":
+				return outputCommandsTable(resp.Msg.Commands)
+			default:
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&nodeID, "node", "", "Filter by node ID")
+	cmd.Flags().StringVar(&cmdType, "type", "", "Filter by type (cordon, uncordon, drain, terminate, run_diagnostic)")
+	cmd.Flags().StringVar(&status, "status", "", "Filter by status (pending, delivered, running, completed, failed)")
+	cmd.Flags().StringVar(&since, "since", "", "Only commands issued at or after this time (duration like 1h, or RFC3339)")
+	cmd.Flags().StringVar(&until, "until", "", "Only commands issued before this time (duration like 1h, or RFC3339)")
+	cmd.Flags().StringVar(&issuedBy, "issued-by", "", "Filter by issuer (e.g., user:oncall@example.com)")
+	cmd.Flags().Int32Var(&limit, "limit", 100, "Maximum number of commands to show, the most recent")
+
+	cmd.AddCommand(commandsGetCmd())
+
+	return cmd
+}
+
+func commandsGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <command-id>",
+		Short: "Get details about a specific command",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+
+			resp, err := client.GetCommand(ctx, connect.NewRequest(&pb.GetCommandRequest{
+				CommandId: args[0],
+			}))
+			if err != nil {
+				return fmt.Errorf("failed to get command: %w", err)
+			}
+
+			switch outputFormat {
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(resp.Msg.Command)
+			case "table":
+				return outputCommandDetails(resp.Msg.Command)
+			default:
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func outputCommandsTable(commands []*pb.CommandInfo) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Append([]string{"Command ID", "Node ID", "Type", "Status", "Issued", "Issued By", "Message"})
+
+	for _, c := range commands {
+		issued := "Unknown"
+		if c.IssuedAt != nil {
+			issued = formatTimestamp(c.IssuedAt.AsTime())
+		}
+
+		table.Append([]string{
+			c.CommandId,
+			c.NodeId,
+			formatCommandType(c.Type),
+			formatCommandStatus(c.Status),
+			issued,
+			formatIssuedBy(c.IssuedBy),
+			c.Message,
+		})
+	}
+
+	table.Render()
+	return nil
+}
+
+func outputCommandDetails(c *pb.CommandInfo) error {
+	fmt.Printf("Command ID:  %s\n", c.CommandId)
+	fmt.Printf("Node ID:     %s\n", c.NodeId)
+	fmt.Printf("Type:        %s\n", formatCommandType(c.Type))
+	fmt.Printf("Status:      %s\n", formatCommandStatus(c.Status))
+	fmt.Printf("Issued By:   %s\n", formatIssuedBy(c.IssuedBy))
+	if c.IssuedAt != nil {
+		fmt.Printf("Issued:      %s\n", formatTimestamp(c.IssuedAt.AsTime()))
+	}
+	if c.DeliveredAt != nil {
+		fmt.Printf("Delivered:   %s (%d attempts)\n", formatTimestamp(c.DeliveredAt.AsTime()), c.DeliveryAttempts)
+	}
+	if c.UpdatedAt != nil {
+		fmt.Printf("Updated:     %s\n", formatTimestamp(c.UpdatedAt.AsTime()))
+	}
+	if c.Message != "" {
+		fmt.Printf("Message:     %s\n", c.Message)
+	}
+
+	if len(c.Parameters) > 0 {
+		fmt.Printf("\nParameters:\n")
+		for k, v := range c.Parameters {
+			fmt.Printf("  %s: %s\n", k, v)
+		}
+	}
+
+	if c.Output != "" {
+		fmt.Printf("\nOutput:\n%s\n", strings.TrimRight(c.Output, "\n"))
+	}
+
+	return nil
+}
+
+func formatCommandType(t pb.NodeCommandType) string {
+	switch t {
+	case pb.NodeCommandType_NODE_COMMAND_TYPE_CORDON:
+		return "Cordon"
+	case pb.NodeCommandType_NODE_COMMAND_TYPE_UNCORDON:
+		return "Uncordon"
+	case pb.NodeCommandType_NODE_COMMAND_TYPE_DRAIN:
+		return "Drain"
+	case pb.NodeCommandType_NODE_COMMAND_TYPE_TERMINATE:
+		return "Terminate"
+	case pb.NodeCommandType_NODE_COMMAND_TYPE_RUN_DIAGNOSTIC:
+		return "Run Diagnostic"
+	case pb.NodeCommandType_NODE_COMMAND_TYPE_RESET_GPU:
+		return "Reset GPU"
+	case pb.NodeCommandType_NODE_COMMAND_TYPE_QUARANTINE_GPU:
+		return "Quarantine GPU"
+	default:
+		return "Unknown"
+	}
+}
+
+func formatCommandStatus(status pb.CommandStatus) string {
+	switch status {
+	case pb.CommandStatus_COMMAND_STATUS_PENDING:
+		return "Pending"
+	case pb.CommandStatus_COMMAND_STATUS_DELIVERED:
+		return "Delivered"
+	case pb.CommandStatus_COMMAND_STATUS_RUNNING:
+		return "Running"
+	case pb.CommandStatus_COMMAND_STATUS_COMPLETED:
+		return "Completed"
+	case pb.CommandStatus_COMMAND_STATUS_FAILED:
+		return "Failed"
+	default:
+		return "Unknown"
+	}
+}
+
+func formatIssuedBy(subject string) string {
+	if subject == "" {
+		return "-"
+	}
+	return subject
+}
+
+func parseCommandType(s string) (pb.NodeCommandType, error) {
+	// Accept both short form (cordon) and full form (NODE_COMMAND_TYPE_CORDON)
+	normalized := strings.ToUpper(strings.ReplaceAll(s, "-", "_"))
+	if !strings.HasPrefix(normalized, "NODE_COMMAND_TYPE_") {
+		normalized = "NODE_COMMAND_TYPE_" + normalized
+	}
+
+	typeValue, ok := pb.NodeCommandType_value[normalized]
+	if !ok || typeValue == int32(pb.NodeCommandType_NODE_COMMAND_TYPE_UNKNOWN) {
+		return 0, fmt.Errorf("invalid command type: %s (valid: cordon, uncordon, drain, terminate, run_diagnostic, reset_gpu, quarantine_gpu)", s)
+	}
+	return pb.NodeCommandType(typeValue), nil
+}
+
+func parseCommandStatus(s string) (pb.CommandStatus, error) {
+	// Accept both short form (failed) and full form (COMMAND_STATUS_FAILED)
+	normalized := strings.ToUpper(s)
+	if !strings.HasPrefix(normalized, "COMMAND_STATUS_") {
+		normalized = "COMMAND_STATUS_" + normalized
+	}
+
+	statusValue, ok := pb.CommandStatus_value[normalized]
+	if !ok || statusValue == int32(pb.CommandStatus_COMMAND_STATUS_UNKNOWN) {
+		return 0, fmt.Errorf("invalid status: %s (valid: pending, delivered, running, completed, failed)", s)
+	}
+	return pb.CommandStatus(statusValue), nil
+}
+
+// parseTimeFlag parses either a duration relative to now (e.g., "2h" means two
+// hours ago) or an RFC3339 timestamp.
+func parseTimeFlag(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a duration nor an RFC3339 timestamp", s)
+	}
+	return t, nil
+}
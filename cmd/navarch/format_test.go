@@ -2,6 +2,7 @@ package main
 
 import (
 	"testing"
+	"time"
 
 	pb "github.com/NavarchProject/navarch/proto"
 )
@@ -33,6 +34,11 @@ func TestParseNodeStatus(t *testing.T) {
 			input: "terminated",
 			want:  pb.NodeStatus_NODE_STATUS_TERMINATED,
 		},
+		{
+			name:  "short form qualifying",
+			input: "qualifying",
+			want:  pb.NodeStatus_NODE_STATUS_QUALIFYING,
+		},
 		{
 			name:  "uppercase short form",
 			input: "ACTIVE",
@@ -79,3 +85,130 @@ func TestParseNodeStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCommandType(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    pb.NodeCommandType
+		wantErr bool
+	}{
+		{name: "short form", input: "cordon", want: pb.NodeCommandType_NODE_COMMAND_TYPE_CORDON},
+		{name: "underscore", input: "run_diagnostic", want: pb.NodeCommandType_NODE_COMMAND_TYPE_RUN_DIAGNOSTIC},
+		{name: "hyphen", input: "run-diagnostic", want: pb.NodeCommandType_NODE_COMMAND_TYPE_RUN_DIAGNOSTIC},
+		{name: "full form", input: "NODE_COMMAND_TYPE_TERMINATE", want: pb.NodeCommandType_NODE_COMMAND_TYPE_TERMINATE},
+		{name: "unknown rejected", input: "unknown", wantErr: true},
+		{name: "invalid", input: "reboot", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCommandType(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseCommandType(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("parseCommandType(%q) unexpected error: %v", tt.input, err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseCommandType(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCommandStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    pb.CommandStatus
+		wantErr bool
+	}{
+		{name: "short form", input: "failed", want: pb.CommandStatus_COMMAND_STATUS_FAILED},
+		{name: "mixed case", input: "Completed", want: pb.CommandStatus_COMMAND_STATUS_COMPLETED},
+		{name: "full form", input: "COMMAND_STATUS_RUNNING", want: pb.CommandStatus_COMMAND_STATUS_RUNNING},
+		{name: "unknown rejected", input: "unknown", wantErr: true},
+		{name: "invalid", input: "done", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCommandStatus(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseCommandStatus(%q) expected error, got nil", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("parseCommandStatus(%q) unexpected error: %v", tt.input, err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseCommandStatus(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeFlag(t *testing.T) {
+	now := time.Date(2026, 1, 19, 14, 0, 0, 0, time.UTC)
+
+	got, err := parseTimeFlag("2h", now)
+	if err != nil || !got.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("parseTimeFlag(2h) = %v, %v, want %v", got, err, now.Add(-2*time.Hour))
+	}
+
+	got, err = parseTimeFlag("2026-01-18T00:00:00Z", now)
+	if want := time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC); err != nil || !got.Equal(want) {
+		t.Errorf("parseTimeFlag(RFC3339) = %v, %v, want %v", got, err, want)
+	}
+
+	if _, err := parseTimeFlag("yesterday", now); err == nil {
+		t.Error("parseTimeFlag(yesterday) expected error, got nil")
+	}
+}
+
+func TestFormatRecommendation(t *testing.T) {
+	tests := []struct {
+		name string
+		rec  *pb.ScaleRecommendation
+		want string
+	}{
+		{name: "none", rec: nil, want: "-"},
+		{name: "no reason", rec: &pb.ScaleRecommendation{TargetNodes: 3}, want: "3 nodes"},
+		{name: "with reason", rec: &pb.ScaleRecommendation{TargetNodes: 5, Reason: "utilization 90% > 80%"}, want: "5 nodes (utilization 90% > 80%)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatRecommendation(tt.rec); got != tt.want {
+				t.Errorf("formatRecommendation() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatXIDCounts(t *testing.T) {
+	tests := []struct {
+		name   string
+		counts map[int32]int32
+		want   string
+	}{
+		{name: "none", counts: nil, want: "-"},
+		{name: "most frequent first", counts: map[int32]int32{13: 1, 79: 3}, want: "79x3 13x1"},
+		{name: "ties by code", counts: map[int32]int32{94: 2, 48: 2}, want: "48x2 94x2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatXIDCounts(tt.counts); got != tt.want {
+				t.Errorf("formatXIDCounts() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
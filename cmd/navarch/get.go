@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"connectrpc.com/connect"
 	"github.com/spf13/cobra"
@@ -57,6 +58,12 @@ func outputNodeDetails(node *pb.NodeInfo) error {
 	fmt.Printf("Instance Type: %s\n", node.InstanceType)
 	fmt.Printf("Status:        %s\n", formatStatus(node.Status))
 	fmt.Printf("Health:        %s\n", formatHealthStatus(node.HealthStatus))
+	if node.StatusReason != "" {
+		fmt.Printf("Reason:        %s\n", node.StatusReason)
+	}
+	if node.HoldUntil != nil {
+		fmt.Printf("Held Until:    %s\n", node.HoldUntil.AsTime().Local().Format(time.DateTime))
+	}
 
 	if node.LastHeartbeat != nil {
 		fmt.Printf("Last Heartbeat: %s\n", formatTimestamp(node.LastHeartbeat.AsTime()))
@@ -65,12 +72,25 @@ func outputNodeDetails(node *pb.NodeInfo) error {
 	}
 
 	if len(node.Gpus) > 0 {
+		gpuHealth := make(map[int32]*pb.GPUHealth)
+		for _, h := range node.GpuHealth {
+			gpuHealth[h.Index] = h
+		}
+
 		fmt.Printf("\nGPUs:\n")
 		for _, gpu := range node.Gpus {
 			fmt.Printf("  GPU %d:\n", gpu.Index)
 			fmt.Printf("    UUID:       %s\n", gpu.Uuid)
 			fmt.Printf("    Name:       %s\n", gpu.Name)
 			fmt.Printf("    PCI Bus ID: %s\n", gpu.PciBusId)
+			if h, ok := gpuHealth[gpu.Index]; ok {
+				health := formatHealthStatus(h.Status)
+				if h.Quarantined {
+					health += " (quarantined)"
+				}
+				fmt.Printf("    Health:     %s\n", health)
+				fmt.Printf("    Reason:     %s\n", h.Reason)
+			}
 		}
 	}
 
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+func gpuCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gpu",
+		Short: "Inspect the GPU inventory",
+		Long: `Inspect every GPU the control plane has seen, by UUID. A GPU's history
+follows it across nodes, so hardware that keeps failing can be recognized
+when a provider hands it out again.`,
+	}
+
+	cmd.AddCommand(gpuListCmd())
+	cmd.AddCommand(gpuGetCmd())
+
+	return cmd
+}
+
+func gpuListCmd() *cobra.Command {
+	var nodeID, provider string
+	var minFailures int32
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List GPUs, most failures first",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+
+			resp, err := client.ListGPUs(ctx, connect.NewRequest(&pb.ListGPUsRequest{
+				NodeId:      nodeID,
+				Provider:    provider,
+				MinFailures: minFailures,
+			}))
+			if err != nil {
+				return fmt.Errorf("failed to list GPUs: %w", err)
+			}
+
+			if len(resp.Msg.Gpus) == 0 {
+				fmt.Println("No GPUs found")
+				return nil
+			}
+
+			switch outputFormat {
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(resp.Msg.Gpus)
+			case "table":
+				return outputGPUsTable(resp.Msg.Gpus)
+			default:
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&nodeID, "node", "", "Filter by the node a GPU was last seen on")
+	cmd.Flags().StringVar(&provider, "provider", "", "Filter by the provider of any node a GPU has been part of")
+	cmd.Flags().Int32Var(&minFailures, "min-failures", 0, "Only GPUs with at least this many failures")
+
+	return cmd
+}
+
+func gpuGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <gpu-uuid>",
+		Short: "Get the history of a GPU",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+
+			resp, err := client.GetGPU(ctx, connect.NewRequest(&pb.GetGPURequest{Uuid: args[0]}))
+			if err != nil {
+				return fmt.Errorf("failed to get GPU: %w", err)
+			}
+
+			switch outputFormat {
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(resp.Msg.Gpu)
+			case "table":
+				return outputGPUDetails(resp.Msg.Gpu)
+			default:
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func outputGPUsTable(gpus []*pb.GPURecord) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Append([]string{"UUID", "Name", "Node ID", "Nodes", "Events", "Failures", "Last Failure", "XIDs"})
+
+	for _, g := range gpus {
+		lastFailure := "-"
+		if g.LastFailure != nil {
+			lastFailure = formatTimestamp(g.LastFailure.AsTime())
+		}
+		table.Append([]string{
+			g.Uuid,
+			g.Name,
+			g.NodeId,
+			fmt.Sprintf("%d", len(g.Nodes)),
+			fmt.Sprintf("%d", g.EventCount),
+			fmt.Sprintf("%d", g.FailureCount),
+			lastFailure,
+			formatXIDCounts(g.XidCounts),
+		})
+	}
+
+	table.Render()
+	return nil
+}
+
+func outputGPUDetails(g *pb.GPURecord) error {
+	fmt.Printf("UUID:         %s\n", g.Uuid)
+	fmt.Printf("Name:         %s\n", g.Name)
+	fmt.Printf("Node ID:      %s\n", g.NodeId)
+	fmt.Printf("First Seen:   %s\n", formatTimestamp(g.FirstSeen.AsTime()))
+	fmt.Printf("Last Seen:    %s\n", formatTimestamp(g.LastSeen.AsTime()))
+	fmt.Printf("Events:       %d\n", g.EventCount)
+	fmt.Printf("Failures:     %d\n", g.FailureCount)
+	if g.LastFailure != nil {
+		fmt.Printf("Last Failure: %s\n", formatTimestamp(g.LastFailure.AsTime()))
+	}
+	if len(g.XidCounts) > 0 {
+		fmt.Printf("XIDs:         %s\n", formatXIDCounts(g.XidCounts))
+	}
+
+	if len(g.Nodes) > 0 {
+		fmt.Printf("\nNodes:\n")
+		for _, a := range g.Nodes {
+			fmt.Printf("  %s:\n", a.NodeId)
+			fmt.Printf("    Provider:      %s\n", a.Provider)
+			fmt.Printf("    Instance Type: %s\n", a.InstanceType)
+			fmt.Printf("    GPU Index:     %d\n", a.Index)
+			fmt.Printf("    First Seen:    %s\n", formatTimestamp(a.FirstSeen.AsTime()))
+			fmt.Printf("    Last Seen:     %s\n", formatTimestamp(a.LastSeen.AsTime()))
+		}
+	}
+
+	return nil
+}
+
+// formatXIDCounts lists XID codes and how often each was reported, most
+// frequent first, e.g. "79x3 13x1".
+func formatXIDCounts(counts map[int32]int32) string {
+	if len(counts) == 0 {
+		return "-"
+	}
+	codes := slices.SortedFunc(maps.Keys(counts), func(a, b int32) int {
+		if counts[a] != counts[b] {
+			return int(counts[b] - counts[a])
+		}
+		return int(a - b)
+	})
+	parts := make([]string, len(codes))
+	for i, code := range codes {
+		parts[i] = fmt.Sprintf("%dx%d", code, counts[code])
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/NavarchProject/navarch/pkg/gpu"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+func healthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "health",
+		Short: "Inspect recorded health events",
+	}
+
+	cmd.AddCommand(healthEventsCmd())
+
+	return cmd
+}
+
+func healthEventsCmd() *cobra.Command {
+	var gpuUUID, since, until string
+	var limit int32
+
+	cmd := &cobra.Command{
+		Use:   "events [node-id]",
+		Short: "List health events reported by nodes",
+		Long: `List the raw health events the control plane recorded, newest first, with the
+health policy rule each one matched. Events are kept after their node is
+replaced, so --gpu finds the history of a GPU for a post-mortem or RMA claim.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+
+			req := &pb.ListHealthEventsRequest{
+				GpuUuid: gpuUUID,
+				Limit:   limit,
+			}
+			if len(args) == 1 {
+				req.NodeId = args[0]
+			}
+
+			now := time.Now()
+			if since != "" {
+				t, err := parseTimeFlag(since, now)
+				if err != nil {
+					return fmt.Errorf("invalid --since: %w", err)
+				}
+				req.Since = timestamppb.New(t)
+			}
+			if until != "" {
+				t, err := parseTimeFlag(until, now)
+				if err != nil {
+					return fmt.Errorf("invalid --until: %w", err)
+				}
+				req.Until = timestamppb.New(t)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+
+			resp, err := client.ListHealthEvents(ctx, connect.NewRequest(req))
+			if err != nil {
+				return fmt.Errorf("failed to list health events: %w", err)
+			}
+
+			if len(resp.Msg.Events) == 0 {
+				fmt.Println("No health events found")
+				return nil
+			}
+
+			switch outputFormat {
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(resp.Msg.Events)
+			case "table":
+				return outputHealthEventsTable(resp.Msg.Events)
+			default:
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&gpuUUID, "gpu", "", "Filter by GPU UUID")
+	cmd.Flags().StringVar(&since, "since", "", "Only events at or after this time (duration like 1h, or RFC3339)")
+	cmd.Flags().StringVar(&until, "until", "", "Only events before this time (duration like 1h, or RFC3339)")
+	cmd.Flags().Int32Var(&limit, "limit", 100, "Maximum number of events to show")
+
+	return cmd
+}
+
+func outputHealthEventsTable(events []*pb.HealthEventRecord) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Append([]string{"Time", "Node ID", "GPU", "Type", "Details", "Rule", "Result"})
+
+	for _, r := range events {
+		e := r.Event
+		t := r.ReceivedAt.AsTime()
+		if e.GetTimestamp() != nil {
+			t = e.Timestamp.AsTime()
+		}
+
+		rule, result := "-", "-"
+		if r.MatchedRule != "" {
+			rule = r.MatchedRule
+			result = formatHealthStatus(r.Result)
+		}
+
+		table.Append([]string{
+			t.Local().Format(time.RFC3339),
+			r.NodeId,
+			formatEventGPU(e),
+			gpu.EventTypeString(e.GetEventType()),
+			formatEventDetails(e),
+			rule,
+			result,
+		})
+	}
+
+	table.Render()
+	return nil
+}
+
+func formatEventGPU(e *pb.HealthEvent) string {
+	if e.GetGpuIndex() < 0 {
+		return "-"
+	}
+	if e.GpuUuid == "" {
+		return fmt.Sprintf("%d", e.GpuIndex)
+	}
+	return fmt.Sprintf("%d (%s)", e.GpuIndex, e.GpuUuid)
+}
+
+// formatEventDetails shows an event's metrics, sorted by name, and its
+// message.
+func formatEventDetails(e *pb.HealthEvent) string {
+	var parts []string
+	for _, k := range slices.Sorted(maps.Keys(e.GetMetrics())) {
+		parts = append(parts, k+"="+e.Metrics[k])
+	}
+	if e.GetMessage() != "" {
+		parts = append(parts, e.Message)
+	}
+	return strings.Join(parts, " ")
+}
@@ -64,7 +64,7 @@ func listCmd() *cobra.Command {
 
 	cmd.Flags().StringVar(&provider, "provider", "", "Filter by provider")
 	cmd.Flags().StringVar(&region, "region", "", "Filter by region")
-	cmd.Flags().StringVar(&status, "status", "", "Filter by status (active, cordoned, draining, terminated)")
+	cmd.Flags().StringVar(&status, "status", "", "Filter by status (active, qualifying, cordoned, draining, terminated)")
 
 	return cmd
 }
@@ -116,6 +116,8 @@ func formatStatus(status pb.NodeStatus) string {
 		return "Unhealthy"
 	case pb.NodeStatus_NODE_STATUS_TERMINATED:
 		return "Terminated"
+	case pb.NodeStatus_NODE_STATUS_QUALIFYING:
+		return "Qualifying"
 	default:
 		return "Unknown"
 	}
@@ -160,7 +162,7 @@ func parseNodeStatus(s string) (pb.NodeStatus, error) {
 
 	statusValue, ok := pb.NodeStatus_value[normalized]
 	if !ok {
-		return 0, fmt.Errorf("invalid status: %s (valid: active, cordoned, draining, terminated)", s)
+		return 0, fmt.Errorf("invalid status: %s (valid: active, qualifying, cordoned, draining, terminated)", s)
 	}
 	return pb.NodeStatus(statusValue), nil
 }
@@ -37,6 +37,11 @@ func main() {
 	rootCmd.AddCommand(cordonCmd())
 	rootCmd.AddCommand(drainCmd())
 	rootCmd.AddCommand(uncordonCmd())
+	rootCmd.AddCommand(commandsCmd())
+	rootCmd.AddCommand(poolCmd())
+	rootCmd.AddCommand(policyCmd())
+	rootCmd.AddCommand(healthCmd())
+	rootCmd.AddCommand(gpuCmd())
 	rootCmd.AddCommand(versionCmd())
 
 	if err := rootCmd.Execute(); err != nil {
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/NavarchProject/navarch/pkg/health"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+func policyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Inspect and change the health policy",
+		Long: `Inspect and change the CEL health policy the control plane uses to classify
+GPU health events. Changes take effect immediately but are not written to the
+configuration file; they are lost on restart, and replaced if a configuration
+reload loads a changed health_policy file.
+
+Use --pool to work with a pool's own policy instead of the server policy. Nodes
+whose pool has no policy of its own use the server policy.`,
+	}
+
+	cmd.AddCommand(policyGetCmd())
+	cmd.AddCommand(policyApplyCmd())
+	cmd.AddCommand(policyValidateCmd())
+	cmd.AddCommand(policyRollbackCmd())
+	cmd.AddCommand(policyTestCmd())
+
+	return cmd
+}
+
+func policyGetCmd() *cobra.Command {
+	var rules bool
+	var pool string
+
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Print the health policy in effect",
+		Long: `Print the health policy in effect as YAML, in the same format as a policy
+file. Use --rules for a table of rules instead. With --pool, prints the policy
+that pool's nodes use, which is the server policy if the pool has none of its
+own.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+
+			resp, err := client.GetHealthPolicy(ctx, connect.NewRequest(&pb.GetHealthPolicyRequest{
+				Pool: pool,
+			}))
+			if err != nil {
+				return fmt.Errorf("failed to get health policy: %w", err)
+			}
+
+			switch outputFormat {
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(resp.Msg)
+			case "table":
+				if resp.Msg.Inherited {
+					fmt.Fprintf(os.Stderr, "Pool %s has no health policy of its own; showing the inherited server policy\n", pool)
+				}
+				if rules {
+					return outputPolicyRulesTable(resp.Msg.Policy)
+				}
+				fmt.Print(resp.Msg.Policy.GetYaml())
+				return nil
+			default:
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&rules, "rules", false, "Show a table of rules instead of YAML")
+	cmd.Flags().StringVar(&pool, "pool", "", "Pool whose policy to show (default: server policy)")
+
+	return cmd
+}
+
+func policyApplyCmd() *cobra.Command {
+	var file string
+	var pool string
+
+	cmd := &cobra.Command{
+		Use:   "apply -f <policy.yaml>",
+		Short: "Replace the health policy",
+		Long: `Replace the health policy with one read from a policy file. The control plane
+compiles every rule first and rejects the policy if any rule is invalid, in
+which case the current policy stays in effect. The replaced policy can be
+restored with "navarch policy rollback". With --pool, the policy applies to
+that pool's nodes only.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy, err := readPolicyFile(file)
+			if err != nil {
+				return err
+			}
+
+			client := newClient()
+
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+
+			resp, err := client.SetHealthPolicy(ctx, connect.NewRequest(&pb.SetHealthPolicyRequest{
+				Policy: policy,
+				Pool:   pool,
+			}))
+			if err != nil {
+				return fmt.Errorf("failed to apply health policy: %w", err)
+			}
+
+			if outputFormat == "json" {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(resp.Msg)
+			}
+			fmt.Printf("Health policy applied%s (%d rules)\n", poolSuffix(pool), len(resp.Msg.Policy.GetRules()))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Policy YAML file, or - for stdin")
+	cmd.Flags().StringVar(&pool, "pool", "", "Pool whose policy to replace (default: server policy)")
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func policyValidateCmd() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "validate -f <policy.yaml>",
+		Short: "Check a health policy without applying it",
+		Long: `Check that a policy file parses and that every rule compiles against the
+control plane's CEL environment. Nothing is changed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy, err := readPolicyFile(file)
+			if err != nil {
+				return err
+			}
+
+			client := newClient()
+
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+
+			resp, err := client.ValidateHealthPolicy(ctx, connect.NewRequest(&pb.ValidateHealthPolicyRequest{
+				Policy: policy,
+			}))
+			if err != nil {
+				return fmt.Errorf("failed to validate health policy: %w", err)
+			}
+
+			if outputFormat == "json" {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(resp.Msg); err != nil {
+					return err
+				}
+			} else if resp.Msg.Valid {
+				fmt.Printf("Health policy is valid (%d rules)\n", resp.Msg.RuleCount)
+			}
+			if !resp.Msg.Valid {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("invalid health policy: %s", resp.Msg.Error)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Policy YAML file, or - for stdin")
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func policyRollbackCmd() *cobra.Command {
+	var pool string
+
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Restore the previous health policy",
+		Long: `Restore the health policy that was in effect before the last change. Running
+rollback again undoes the rollback.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+
+			resp, err := client.SetHealthPolicy(ctx, connect.NewRequest(&pb.SetHealthPolicyRequest{
+				Rollback: true,
+				Pool:     pool,
+			}))
+			if err != nil {
+				return fmt.Errorf("failed to roll back health policy: %w", err)
+			}
+
+			if outputFormat == "json" {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(resp.Msg)
+			}
+			fmt.Printf("Health policy rolled back%s (%d rules)\n", poolSuffix(pool), len(resp.Msg.Policy.GetRules()))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&pool, "pool", "", "Pool whose policy to roll back (default: server policy)")
+
+	return cmd
+}
+
+func policyTestCmd() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "test <cases.yaml> [-f <policy.yaml>]",
+		Short: "Run a health policy against test cases",
+		Long: `Evaluate a health policy against a YAML or JSON file of test cases and report
+which cases pass. Each case lists one or more health events and the expected
+result and, optionally, the rule expected to match. Runs locally without a
+control plane and exits non-zero if any case fails, so it can run in CI.
+
+Without -f, the built-in default policy is tested.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy := health.DefaultPolicy()
+			if file != "" {
+				data, err := readPolicyFile(file)
+				if err != nil {
+					return err
+				}
+				policy, err = health.ParsePolicy([]byte(data))
+				if err != nil {
+					return fmt.Errorf("invalid health policy: %w", err)
+				}
+			}
+			evaluator, err := health.NewEvaluator(policy)
+			if err != nil {
+				return fmt.Errorf("invalid health policy: %w", err)
+			}
+
+			cases, err := health.LoadTestFile(args[0])
+			if err != nil {
+				return err
+			}
+			results := evaluator.RunTests(cmd.Context(), cases)
+
+			failed := 0
+			for _, r := range results {
+				if !r.Passed {
+					failed++
+				}
+			}
+
+			switch outputFormat {
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(results); err != nil {
+					return err
+				}
+			case "table":
+				outputPolicyTestTable(results)
+				fmt.Printf("\n%d passed, %d failed\n", len(results)-failed, failed)
+			default:
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+
+			if failed > 0 {
+				// A failing case is a result, not a usage error.
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d policy test cases failed", failed, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Policy YAML file, or - for stdin (default: built-in policy)")
+
+	return cmd
+}
+
+func poolSuffix(pool string) string {
+	if pool == "" {
+		return ""
+	}
+	return " for pool " + pool
+}
+
+func readPolicyFile(file string) (string, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read health policy: %w", err)
+	}
+	return string(data), nil
+}
+
+func outputPolicyRulesTable(policy *pb.HealthPolicy) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Append([]string{"Name", "Result", "Actions", "Condition"})
+
+	for _, rule := range policy.GetRules() {
+		actions := strings.Join(rule.Actions, ", ")
+		if actions == "" {
+			actions = "-"
+		}
+		result := rule.Result
+		if rule.Scope == string(health.ScopeGPU) {
+			result += " (gpu)"
+		}
+		table.Append([]string{rule.Name, result, actions, rule.Condition})
+	}
+
+	table.Render()
+	return nil
+}
+
+func outputPolicyTestTable(results []health.TestResult) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Append([]string{"Case", "Status", "Expected", "Got"})
+
+	for _, r := range results {
+		status := "PASS"
+		if !r.Passed {
+			status = "FAIL"
+		}
+		got := formatPolicyOutcome(r.Result, r.MatchedRule)
+		if r.Error != "" {
+			got = "error: " + r.Error
+		}
+		table.Append([]string{
+			r.Name,
+			status,
+			formatPolicyOutcome(r.Expected.Result, r.Expected.MatchedRule),
+			got,
+		})
+	}
+
+	table.Render()
+}
+
+func formatPolicyOutcome(result health.Result, rule string) string {
+	if rule == "" {
+		return string(result)
+	}
+	return fmt.Sprintf("%s (%s)", result, rule)
+}
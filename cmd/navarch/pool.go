@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+func poolCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pool",
+		Short: "Inspect, scale, and manage node pools",
+	}
+
+	cmd.AddCommand(poolListCmd())
+	cmd.AddCommand(poolGetCmd())
+	cmd.AddCommand(poolScaleCmd())
+	cmd.AddCommand(poolCreateCmd())
+	cmd.AddCommand(poolUpdateCmd())
+	cmd.AddCommand(poolDeleteCmd())
+
+	return cmd
+}
+
+func poolListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all pools",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+
+			resp, err := client.ListPools(ctx, connect.NewRequest(&pb.ListPoolsRequest{}))
+			if err != nil {
+				return fmt.Errorf("failed to list pools: %w", err)
+			}
+
+			if len(resp.Msg.Pools) == 0 {
+				fmt.Println("No pools found")
+				return nil
+			}
+
+			switch outputFormat {
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(resp.Msg.Pools)
+			case "table":
+				return outputPoolsTable(resp.Msg.Pools)
+			default:
+				return fmt.Errorf("unsupported output format: %s", outputFormat)
+			}
+		},
+	}
+
+	return cmd
+}
+
+func poolGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <pool>",
+		Short: "Get details about a specific pool",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+
+			resp, err := client.GetPool(ctx, connect.NewRequest(&pb.GetPoolRequest{
+				Name: args[0],
+			}))
+			if err != nil {
+				return fmt.Errorf("failed to get pool: %w", err)
+			}
+
+			return outputPool(resp.Msg.Pool)
+		},
+	}
+
+	return cmd
+}
+
+func poolScaleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scale <pool> <target-nodes>",
+		Short: "Scale a pool to a target node count",
+		Long: `Scale a pool to a target node count within its min and max limits.
+If the pool has an autoscaler, it may change the count again on its next
+evaluation.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			target, err := strconv.Atoi(args[1])
+			if err != nil || target < 0 {
+				return fmt.Errorf("invalid target node count: %s", args[1])
+			}
+
+			client := newClient()
+
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+
+			resp, err := client.ScalePool(ctx, connect.NewRequest(&pb.ScalePoolRequest{
+				Name:        name,
+				TargetNodes: int32(target),
+			}))
+			if err != nil {
+				return fmt.Errorf("failed to scale pool: %w", err)
+			}
+
+			if outputFormat == "json" {
+				return outputPool(resp.Msg.Pool)
+			}
+			fmt.Printf("Pool %s scaled to %d nodes\n", name, resp.Msg.Pool.GetStatus().GetTotalNodes())
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func poolCreateCmd() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "create <pool> -f <spec.yaml>",
+		Short: "Create a pool without restarting the control plane",
+		Long: `Create a pool from a YAML spec in the same form as an entry under "pools"
+in the control plane configuration file. The pool is not written back to the
+configuration file; add it there to keep it across restarts.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spec, err := readPoolSpec(file)
+			if err != nil {
+				return err
+			}
+
+			client := newClient()
+
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+
+			resp, err := client.CreatePool(ctx, connect.NewRequest(&pb.CreatePoolRequest{
+				Name: args[0],
+				Spec: spec,
+			}))
+			if err != nil {
+				return fmt.Errorf("failed to create pool: %w", err)
+			}
+
+			if outputFormat == "json" {
+				return outputPool(resp.Msg.Pool)
+			}
+			fmt.Printf("Pool %s created\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Pool spec YAML file, or - for stdin")
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func poolUpdateCmd() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "update <pool> -f <spec.yaml>",
+		Short: "Replace a pool's configuration",
+		Long: `Replace a pool's configuration with a complete YAML spec. Limits, labels,
+health settings, and the autoscaler can change; instance type, providers, and
+strategy cannot. Existing nodes are kept.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spec, err := readPoolSpec(file)
+			if err != nil {
+				return err
+			}
+
+			client := newClient()
+
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+
+			resp, err := client.UpdatePool(ctx, connect.NewRequest(&pb.UpdatePoolRequest{
+				Name: args[0],
+				Spec: spec,
+			}))
+			if err != nil {
+				return fmt.Errorf("failed to update pool: %w", err)
+			}
+
+			if outputFormat == "json" {
+				return outputPool(resp.Msg.Pool)
+			}
+			fmt.Printf("Pool %s updated\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Pool spec YAML file, or - for stdin")
+	cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func poolDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <pool>",
+		Short: "Drain and delete a pool",
+		Long: `Delete a pool. Its nodes are drained through the configured notifier and
+terminated once drained or when the drain timeout expires, after which the
+pool is removed. The command returns once deletion has started.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := newClient()
+
+			ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+
+			resp, err := client.DeletePool(ctx, connect.NewRequest(&pb.DeletePoolRequest{
+				Name: args[0],
+			}))
+			if err != nil {
+				return fmt.Errorf("failed to delete pool: %w", err)
+			}
+
+			if resp.Msg.Pool == nil {
+				fmt.Printf("Pool %s deleted\n", args[0])
+				return nil
+			}
+			if outputFormat == "json" {
+				return outputPool(resp.Msg.Pool)
+			}
+			fmt.Printf("Pool %s is being deleted, draining %d nodes\n", args[0], resp.Msg.Pool.GetStatus().GetTotalNodes())
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func readPoolSpec(file string) (string, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read pool spec: %w", err)
+	}
+	return string(data), nil
+}
+
+func outputPool(p *pb.PoolInfo) error {
+	switch outputFormat {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(p)
+	case "table":
+		return outputPoolDetails(p)
+	default:
+		return fmt.Errorf("unsupported output format: %s", outputFormat)
+	}
+}
+
+func outputPoolsTable(pools []*pb.PoolInfo) error {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Append([]string{"Name", "Instance Type", "Nodes", "Healthy", "Min", "Max", "Autoscaler", "Last Recommendation"})
+
+	for _, p := range pools {
+		status := p.GetStatus()
+		table.Append([]string{
+			p.Name,
+			p.InstanceType,
+			strconv.Itoa(int(status.GetTotalNodes())),
+			strconv.Itoa(int(status.GetHealthyNodes())),
+			strconv.Itoa(int(p.MinNodes)),
+			strconv.Itoa(int(p.MaxNodes)),
+			p.AutoscalerType,
+			formatRecommendation(p.LastRecommendation),
+		})
+	}
+
+	table.Render()
+	return nil
+}
+
+func outputPoolDetails(p *pb.PoolInfo) error {
+	status := p.GetStatus()
+
+	fmt.Printf("Name:          %s\n", p.Name)
+	fmt.Printf("Instance Type: %s\n", p.InstanceType)
+	fmt.Printf("Region:        %s\n", p.Region)
+	if len(p.Zones) > 0 {
+		fmt.Printf("Zones:         %s\n", strings.Join(p.Zones, ", "))
+	}
+	if len(p.Providers) > 0 {
+		fmt.Printf("Providers:     %s\n", strings.Join(p.Providers, ", "))
+	}
+	fmt.Printf("Auto Replace:  %t\n", p.AutoReplace)
+	if p.Deleting {
+		fmt.Printf("Deleting:      true\n")
+	}
+
+	fmt.Printf("\nLimits:\n")
+	fmt.Printf("  Min Nodes:   %d\n", p.MinNodes)
+	fmt.Printf("  Max Nodes:   %d\n", p.MaxNodes)
+	fmt.Printf("  Cooldown:    %s\n", time.Duration(p.CooldownSeconds)*time.Second)
+	fmt.Printf("  Min GPUs:    %d healthy per node\n", p.MinHealthyGpus)
+
+	fmt.Printf("\nStatus:\n")
+	fmt.Printf("  Total:       %d\n", status.GetTotalNodes())
+	fmt.Printf("  Healthy:     %d\n", status.GetHealthyNodes())
+	fmt.Printf("  Unhealthy:   %d\n", status.GetUnhealthyNodes())
+	fmt.Printf("  Cordoned:    %d\n", status.GetCordonedNodes())
+	fmt.Printf("  Utilization: %.1f%%\n", status.GetUtilization())
+	if status.GetBootstrapPending() > 0 || status.GetBootstrapFailed() > 0 {
+		fmt.Printf("  Bootstrap:   %d pending, %d failed\n", status.GetBootstrapPending(), status.GetBootstrapFailed())
+	}
+
+	fmt.Printf("\nAutoscaler:\n")
+	fmt.Printf("  Type:        %s\n", p.AutoscalerType)
+	fmt.Printf("  Last Recommendation: %s\n", formatRecommendation(p.LastRecommendation))
+	if p.LastEvaluatedAt != nil {
+		fmt.Printf("  Last Evaluated:      %s\n", formatTimestamp(p.LastEvaluatedAt.AsTime()))
+	}
+
+	if len(p.Labels) > 0 {
+		keys := make([]string, 0, len(p.Labels))
+		for k := range p.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		fmt.Printf("\nLabels:\n")
+		for _, k := range keys {
+			fmt.Printf("  %s: %s\n", k, p.Labels[k])
+		}
+	}
+
+	return nil
+}
+
+func formatRecommendation(rec *pb.ScaleRecommendation) string {
+	if rec == nil {
+		return "-"
+	}
+	if rec.Reason == "" {
+		return fmt.Sprintf("%d nodes", rec.TargetNodes)
+	}
+	return fmt.Sprintf("%d nodes (%s)", rec.TargetNodes, rec.Reason)
+}
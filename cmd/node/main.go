@@ -20,6 +20,7 @@ func main() {
 	instanceType := flag.String("instance-type", "", "Instance type")
 	poolName := flag.String("pool", "", "Pool name (for autoscaler node counting)")
 	authToken := flag.String("auth-token", "", "Authentication token (or use NAVARCH_AUTH_TOKEN env)")
+	gpuExclusionFile := flag.String("gpu-exclusion-file", "", "File to list quarantined GPUs in, for the workload system")
 	flag.Parse()
 
 	// Get auth token from flag or environment
@@ -69,6 +70,7 @@ func main() {
 		InstanceType:     *instanceType,
 		Pool:             *poolName,
 		AuthToken:        token,
+		GPUExclusionFile: *gpuExclusionFile,
 	}
 
 	n, err := node.New(cfg, logger)
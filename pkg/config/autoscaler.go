@@ -196,3 +196,11 @@ func GetAutoReplace(h *HealthCfg) bool {
 	}
 	return h.AutoReplace
 }
+
+// GetMinHealthyGPUs returns the minimum healthy GPU count from health config, or default.
+func GetMinHealthyGPUs(h *HealthCfg) int {
+	if h == nil || h.MinHealthyGPUs == 0 {
+		return 1
+	}
+	return h.MinHealthyGPUs
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -20,12 +21,47 @@ type Config struct {
 type ServerConfig struct {
 	Address              string        `yaml:"address,omitempty"`              // Default: ":50051"
 	ExternalAddress      string        `yaml:"external_address,omitempty"`     // Public URL for nodes to reach the control plane (e.g., tunnel URL)
+	ClusterID            string        `yaml:"cluster_id,omitempty"`           // Labels this control plane's instances. Default: "default"
 	HeartbeatInterval    time.Duration `yaml:"heartbeat_interval,omitempty"`
 	HeartbeatTimeout     time.Duration `yaml:"heartbeat_timeout,omitempty"`
 	HealthCheckInterval  time.Duration `yaml:"health_check_interval,omitempty"`
 	AutoscaleInterval    time.Duration `yaml:"autoscale_interval,omitempty"`
 	HealthPolicy         string        `yaml:"health_policy,omitempty"`
+	HealthPolicyDir      string        `yaml:"health_policy_dir,omitempty"` // Where pool specs created over RPC may name health policies
+	HealthHistoryWindow  time.Duration `yaml:"health_history_window,omitempty"`     // Default: 1h
+	HealthHistoryMaxEvents int         `yaml:"health_history_max_events,omitempty"` // Per node. Default: 1000
+	HealthEventRetention time.Duration `yaml:"health_event_retention,omitempty"`    // Default: 720h (30 days)
+	CommandRetention     time.Duration `yaml:"command_retention,omitempty"`         // Default: 720h (30 days)
 	Notifier             *NotifierCfg `yaml:"notifier,omitempty"`
+	Database             *DatabaseCfg `yaml:"database,omitempty"`
+	Reaper               *ReaperCfg   `yaml:"reaper,omitempty"`
+	FlapDetection        *FlapDetectionCfg `yaml:"flap_detection,omitempty"`
+}
+
+// FlapDetectionCfg configures holding down nodes whose health flaps between
+// healthy and unhealthy.
+type FlapDetectionCfg struct {
+	Enabled     bool          `yaml:"enabled"`
+	Transitions int           `yaml:"transitions,omitempty"` // Transitions within window that mean flapping. Default: 4
+	Window      time.Duration `yaml:"window,omitempty"`      // Default: 30m
+	HoldDown    time.Duration `yaml:"hold_down,omitempty"`   // Default: 1h
+	State       string        `yaml:"state,omitempty"`       // cordoned (default), unhealthy
+}
+
+// ReaperCfg configures termination of failed and orphaned instances.
+type ReaperCfg struct {
+	Enabled     bool          `yaml:"enabled"`
+	GracePeriod time.Duration `yaml:"grace_period,omitempty"` // Default: 30m
+	Interval    time.Duration `yaml:"interval,omitempty"`     // Default: 5m
+	DryRun      bool          `yaml:"dry_run,omitempty"`      // Record actions without terminating
+}
+
+// DatabaseCfg configures control plane state storage.
+type DatabaseCfg struct {
+	Type string `yaml:"type"` // memory, sqlite
+
+	// SQLite configuration
+	Path string `yaml:"path,omitempty"` // Database file path
 }
 
 // NotifierCfg configures integration with workload systems.
@@ -42,6 +78,7 @@ type WebhookNotifierCfg struct {
 	UncordonURL    string            `yaml:"uncordon_url,omitempty"`
 	DrainURL       string            `yaml:"drain_url,omitempty"`
 	DrainStatusURL string            `yaml:"drain_status_url,omitempty"`
+	HealthURL      string            `yaml:"health_url,omitempty"`
 	Timeout        time.Duration     `yaml:"timeout,omitempty"`
 	Headers        map[string]string `yaml:"headers,omitempty"`
 }
@@ -83,8 +120,17 @@ type PoolCfg struct {
 	Autoscaling *AutoscalingCfg `yaml:"autoscaling,omitempty"`
 	Health      *HealthCfg      `yaml:"health,omitempty"`
 
+	// HealthPolicy is a health policy file for this pool's nodes. If empty,
+	// they use the server's health policy.
+	HealthPolicy string `yaml:"health_policy,omitempty"`
+
+	// BurnIn holds new nodes back from workloads until they pass diagnostics.
+	BurnIn *BurnInCfg `yaml:"burn_in,omitempty"`
+
 	Labels map[string]string `yaml:"labels,omitempty"`
 
+	DisableReaper bool `yaml:"disable_reaper,omitempty"` // Exclude this pool's instances from the reaper
+
 	SetupCommands     []string `yaml:"setup_commands,omitempty"`
 	SSHUser           string   `yaml:"ssh_user,omitempty"`
 	SSHPrivateKeyPath string   `yaml:"ssh_private_key_path,omitempty"`
@@ -136,6 +182,18 @@ type ScheduleWindow struct {
 type HealthCfg struct {
 	UnhealthyAfter int  `yaml:"unhealthy_after,omitempty"` // Consecutive failures
 	AutoReplace    bool `yaml:"auto_replace,omitempty"`
+
+	// MinHealthyGPUs is the fewest healthy, unquarantined GPUs a node may
+	// have before it is marked unhealthy and replaced.
+	MinHealthyGPUs int `yaml:"min_healthy_gpus,omitempty"`
+}
+
+// BurnInCfg configures the diagnostics a pool's new nodes must pass before
+// they accept workloads.
+type BurnInCfg struct {
+	Enabled bool          `yaml:"enabled"`
+	Tests   []string      `yaml:"tests,omitempty"`   // Diagnostics to run. Default: all
+	Timeout time.Duration `yaml:"timeout,omitempty"` // Default: 30m
 }
 
 // DefaultsCfg holds default values applied to all pools.
@@ -166,6 +224,21 @@ func Load(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// ParsePool parses a single pool's configuration, in the same form as an
+// entry under "pools" in the configuration file. The result is neither
+// validated nor defaulted; see ValidatePool and ApplyPoolDefaults.
+func ParsePool(data []byte) (PoolCfg, error) {
+	var pool PoolCfg
+	if err := yaml.Unmarshal(data, &pool); err != nil {
+		return PoolCfg{}, fmt.Errorf("parsing pool: %w", err)
+	}
+	return pool, nil
+}
+
+// clusterIDPattern matches cluster IDs that are valid label values on every
+// provider.
+var clusterIDPattern = regexp.MustCompile(`^[a-z0-9_-]{1,63}$`)
+
 // Validate checks the configuration for errors.
 func (c *Config) Validate() error {
 	if len(c.Pools) == 0 {
@@ -173,51 +246,122 @@ func (c *Config) Validate() error {
 	}
 
 	for name, pool := range c.Pools {
-		if pool.Provider == "" && len(pool.Providers) == 0 {
-			return fmt.Errorf("pool %q: must specify provider or providers", name)
+		if err := c.ValidatePool(name, pool); err != nil {
+			return err
 		}
-		if pool.Provider != "" && len(pool.Providers) > 0 {
-			return fmt.Errorf("pool %q: cannot specify both provider and providers", name)
-		}
-		if pool.InstanceType == "" {
-			return fmt.Errorf("pool %q: instance_type is required", name)
+	}
+
+	for name, prov := range c.Providers {
+		if prov.Type == "" {
+			return fmt.Errorf("provider %q: type is required", name)
 		}
-		if pool.MaxNodes <= 0 {
-			return fmt.Errorf("pool %q: max_nodes must be > 0", name)
+	}
+
+	if db := c.Server.Database; db != nil {
+		switch db.Type {
+		case "", "memory":
+		case "sqlite":
+			if db.Path == "" {
+				return fmt.Errorf("server.database: path is required for sqlite")
+			}
+		default:
+			return fmt.Errorf("server.database: unknown type %q", db.Type)
 		}
-		if pool.MinNodes < 0 {
-			return fmt.Errorf("pool %q: min_nodes must be >= 0", name)
+	}
+
+	if c.Server.HealthHistoryWindow < 0 {
+		return fmt.Errorf("server.health_history_window must be >= 0")
+	}
+	if c.Server.HealthHistoryMaxEvents < 0 {
+		return fmt.Errorf("server.health_history_max_events must be >= 0")
+	}
+	if c.Server.HealthEventRetention < 0 {
+		return fmt.Errorf("server.health_event_retention must be >= 0")
+	}
+	if c.Server.CommandRetention < 0 {
+		return fmt.Errorf("server.command_retention must be >= 0")
+	}
+	if c.Server.ClusterID != "" && !clusterIDPattern.MatchString(c.Server.ClusterID) {
+		return fmt.Errorf("server.cluster_id must be 1-63 lowercase letters, digits, '-' or '_', got %q", c.Server.ClusterID)
+	}
+
+	if r := c.Server.Reaper; r != nil {
+		if r.GracePeriod < 0 {
+			return fmt.Errorf("server.reaper: grace_period must be >= 0")
 		}
-		if pool.MinNodes > pool.MaxNodes {
-			return fmt.Errorf("pool %q: min_nodes cannot exceed max_nodes", name)
+		if r.Interval < 0 {
+			return fmt.Errorf("server.reaper: interval must be >= 0")
 		}
+	}
 
-		// Validate provider references
-		if pool.Provider != "" {
-			if _, ok := c.Providers[pool.Provider]; !ok {
-				return fmt.Errorf("pool %q: unknown provider %q", name, pool.Provider)
-			}
+	if f := c.Server.FlapDetection; f != nil {
+		if f.Transitions < 0 {
+			return fmt.Errorf("server.flap_detection: transitions must be >= 0")
 		}
-		for _, p := range pool.Providers {
-			if _, ok := c.Providers[p.Name]; !ok {
-				return fmt.Errorf("pool %q: unknown provider %q", name, p.Name)
-			}
+		if f.Window < 0 {
+			return fmt.Errorf("server.flap_detection: window must be >= 0")
 		}
+		if f.HoldDown < 0 {
+			return fmt.Errorf("server.flap_detection: hold_down must be >= 0")
+		}
+		switch f.State {
+		case "", "cordoned", "unhealthy":
+		default:
+			return fmt.Errorf("server.flap_detection: state must be cordoned or unhealthy, got %q", f.State)
+		}
+	}
 
-		if len(pool.SetupCommands) > 0 {
-			keyPath := pool.SSHPrivateKeyPath
-			if keyPath == "" {
-				keyPath = c.Defaults.SSHPrivateKeyPath
-			}
-			if keyPath == "" {
-				return fmt.Errorf("pool %q: ssh_private_key_path is required when setup_commands are configured", name)
-			}
+	return nil
+}
+
+// ValidatePool checks a single pool's configuration, including its provider
+// references against c.Providers. Validate applies it to every pool; the
+// control plane also uses it for pools created or updated at runtime.
+func (c *Config) ValidatePool(name string, pool PoolCfg) error {
+	if pool.Provider == "" && len(pool.Providers) == 0 {
+		return fmt.Errorf("pool %q: must specify provider or providers", name)
+	}
+	if pool.Provider != "" && len(pool.Providers) > 0 {
+		return fmt.Errorf("pool %q: cannot specify both provider and providers", name)
+	}
+	if pool.InstanceType == "" {
+		return fmt.Errorf("pool %q: instance_type is required", name)
+	}
+	if pool.MaxNodes <= 0 {
+		return fmt.Errorf("pool %q: max_nodes must be > 0", name)
+	}
+	if pool.MinNodes < 0 {
+		return fmt.Errorf("pool %q: min_nodes must be >= 0", name)
+	}
+	if pool.MinNodes > pool.MaxNodes {
+		return fmt.Errorf("pool %q: min_nodes cannot exceed max_nodes", name)
+	}
+	if pool.Health != nil && pool.Health.MinHealthyGPUs < 0 {
+		return fmt.Errorf("pool %q: health.min_healthy_gpus must be >= 0", name)
+	}
+	if pool.BurnIn != nil && pool.BurnIn.Timeout < 0 {
+		return fmt.Errorf("pool %q: burn_in.timeout must be >= 0", name)
+	}
+
+	// Validate provider references
+	if pool.Provider != "" {
+		if _, ok := c.Providers[pool.Provider]; !ok {
+			return fmt.Errorf("pool %q: unknown provider %q", name, pool.Provider)
+		}
+	}
+	for _, p := range pool.Providers {
+		if _, ok := c.Providers[p.Name]; !ok {
+			return fmt.Errorf("pool %q: unknown provider %q", name, p.Name)
 		}
 	}
 
-	for name, prov := range c.Providers {
-		if prov.Type == "" {
-			return fmt.Errorf("provider %q: type is required", name)
+	if len(pool.SetupCommands) > 0 {
+		keyPath := pool.SSHPrivateKeyPath
+		if keyPath == "" {
+			keyPath = c.Defaults.SSHPrivateKeyPath
+		}
+		if keyPath == "" {
+			return fmt.Errorf("pool %q: ssh_private_key_path is required when setup_commands are configured", name)
 		}
 	}
 
@@ -237,27 +381,36 @@ func (c *Config) applyDefaults() {
 	if c.Server.AutoscaleInterval == 0 {
 		c.Server.AutoscaleInterval = 30 * time.Second
 	}
+	if c.Server.ClusterID == "" {
+		c.Server.ClusterID = "default"
+	}
 
 	for name, pool := range c.Pools {
-		if pool.Cooldown == 0 {
-			pool.Cooldown = 5 * time.Minute
-		}
-		if len(pool.SSHKeys) == 0 && len(c.Defaults.SSHKeys) > 0 {
-			pool.SSHKeys = c.Defaults.SSHKeys
-		}
-		if pool.Health == nil && c.Defaults.Health != nil {
-			pool.Health = c.Defaults.Health
-		}
-		if pool.SSHUser == "" {
-			if c.Defaults.SSHUser != "" {
-				pool.SSHUser = c.Defaults.SSHUser
-			} else {
-				pool.SSHUser = "ubuntu"
-			}
-		}
-		if pool.SSHPrivateKeyPath == "" && c.Defaults.SSHPrivateKeyPath != "" {
-			pool.SSHPrivateKeyPath = c.Defaults.SSHPrivateKeyPath
+		c.Pools[name] = c.ApplyPoolDefaults(pool)
+	}
+}
+
+// ApplyPoolDefaults returns pool with unset fields filled in from c.Defaults
+// and the built-in defaults.
+func (c *Config) ApplyPoolDefaults(pool PoolCfg) PoolCfg {
+	if pool.Cooldown == 0 {
+		pool.Cooldown = 5 * time.Minute
+	}
+	if len(pool.SSHKeys) == 0 && len(c.Defaults.SSHKeys) > 0 {
+		pool.SSHKeys = c.Defaults.SSHKeys
+	}
+	if pool.Health == nil && c.Defaults.Health != nil {
+		pool.Health = c.Defaults.Health
+	}
+	if pool.SSHUser == "" {
+		if c.Defaults.SSHUser != "" {
+			pool.SSHUser = c.Defaults.SSHUser
+		} else {
+			pool.SSHUser = "ubuntu"
 		}
-		c.Pools[name] = pool
 	}
+	if pool.SSHPrivateKeyPath == "" && c.Defaults.SSHPrivateKeyPath != "" {
+		pool.SSHPrivateKeyPath = c.Defaults.SSHPrivateKeyPath
+	}
+	return pool
 }
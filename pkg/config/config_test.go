@@ -171,6 +171,9 @@ defaults:
 	if pool.Health == nil || pool.Health.UnhealthyAfter != 3 {
 		t.Errorf("expected health from defaults")
 	}
+	if cfg.Server.ClusterID != "default" {
+		t.Errorf("expected default cluster_id, got %q", cfg.Server.ClusterID)
+	}
 }
 
 func TestValidate_MissingProvider(t *testing.T) {
@@ -341,3 +344,330 @@ pools:
 		t.Fatalf("unexpected validation error: %v", err)
 	}
 }
+
+func TestLoad_Database(t *testing.T) {
+	yaml := `
+server:
+  database:
+    type: sqlite
+    path: /var/lib/navarch/state.db
+providers:
+  fake:
+    type: fake
+pools:
+  test:
+    provider: fake
+    instance_type: gpu_8x
+    min_nodes: 1
+    max_nodes: 5
+`
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Server.Database == nil {
+		t.Fatal("expected database config")
+	}
+	if cfg.Server.Database.Type != "sqlite" {
+		t.Errorf("expected type sqlite, got %s", cfg.Server.Database.Type)
+	}
+	if cfg.Server.Database.Path != "/var/lib/navarch/state.db" {
+		t.Errorf("expected path /var/lib/navarch/state.db, got %s", cfg.Server.Database.Path)
+	}
+}
+
+func TestValidate_Database(t *testing.T) {
+	tests := []struct {
+		name    string
+		db      *DatabaseCfg
+		wantErr string
+	}{
+		{name: "unset", db: nil},
+		{name: "memory", db: &DatabaseCfg{Type: "memory"}},
+		{name: "sqlite", db: &DatabaseCfg{Type: "sqlite", Path: "state.db"}},
+		{name: "sqlite_without_path", db: &DatabaseCfg{Type: "sqlite"}, wantErr: "path is required"},
+		{name: "unknown_type", db: &DatabaseCfg{Type: "postgres"}, wantErr: "unknown type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:    ServerConfig{Database: tt.db},
+				Providers: map[string]ProviderCfg{"fake": {Type: "fake"}},
+				Pools: map[string]PoolCfg{
+					"test": {Provider: "fake", InstanceType: "gpu_8x", MaxNodes: 1},
+				},
+			}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoad_Reaper(t *testing.T) {
+	yaml := `
+server:
+  reaper:
+    enabled: true
+    grace_period: 1h
+    dry_run: true
+providers:
+  fake:
+    type: fake
+pools:
+  test:
+    provider: fake
+    instance_type: gpu_8x
+    max_nodes: 5
+    disable_reaper: true
+`
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	r := cfg.Server.Reaper
+	if r == nil {
+		t.Fatal("expected reaper config")
+	}
+	if !r.Enabled || !r.DryRun {
+		t.Errorf("expected enabled dry-run reaper, got %+v", r)
+	}
+	if r.GracePeriod != time.Hour {
+		t.Errorf("expected grace_period 1h, got %v", r.GracePeriod)
+	}
+	if !cfg.Pools["test"].DisableReaper {
+		t.Error("expected disable_reaper to be set on pool")
+	}
+}
+
+func TestValidate_Reaper(t *testing.T) {
+	tests := []struct {
+		name    string
+		reaper  *ReaperCfg
+		wantErr string
+	}{
+		{name: "unset", reaper: nil},
+		{name: "defaults", reaper: &ReaperCfg{Enabled: true}},
+		{name: "negative_grace_period", reaper: &ReaperCfg{GracePeriod: -time.Minute}, wantErr: "grace_period"},
+		{name: "negative_interval", reaper: &ReaperCfg{Interval: -time.Minute}, wantErr: "interval"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:    ServerConfig{Reaper: tt.reaper},
+				Providers: map[string]ProviderCfg{"fake": {Type: "fake"}},
+				Pools: map[string]PoolCfg{
+					"test": {Provider: "fake", InstanceType: "gpu_8x", MaxNodes: 1},
+				},
+			}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_FlapDetection(t *testing.T) {
+	tests := []struct {
+		name    string
+		flap    *FlapDetectionCfg
+		wantErr string
+	}{
+		{name: "unset", flap: nil},
+		{name: "defaults", flap: &FlapDetectionCfg{Enabled: true}},
+		{name: "unhealthy", flap: &FlapDetectionCfg{Enabled: true, Transitions: 6, Window: time.Hour, State: "unhealthy"}},
+		{name: "negative_transitions", flap: &FlapDetectionCfg{Transitions: -1}, wantErr: "transitions"},
+		{name: "negative_hold_down", flap: &FlapDetectionCfg{HoldDown: -time.Minute}, wantErr: "hold_down"},
+		{name: "bad_state", flap: &FlapDetectionCfg{State: "draining"}, wantErr: "state"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:    ServerConfig{FlapDetection: tt.flap},
+				Providers: map[string]ProviderCfg{"fake": {Type: "fake"}},
+				Pools: map[string]PoolCfg{
+					"test": {Provider: "fake", InstanceType: "gpu_8x", MaxNodes: 1},
+				},
+			}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoad_BurnIn(t *testing.T) {
+	yaml := `
+providers:
+  fake:
+    type: fake
+pools:
+  test:
+    provider: fake
+    instance_type: gpu_8x
+    max_nodes: 5
+    burn_in:
+      enabled: true
+      tests: [dcgm, nvbandwidth]
+      timeout: 45m
+`
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	b := cfg.Pools["test"].BurnIn
+	if b == nil {
+		t.Fatal("expected burn_in config")
+	}
+	if !b.Enabled || len(b.Tests) != 2 || b.Tests[1] != "nvbandwidth" || b.Timeout != 45*time.Minute {
+		t.Errorf("unexpected burn_in config: %+v", b)
+	}
+
+	cfg.Pools["test"] = PoolCfg{
+		Provider:     "fake",
+		InstanceType: "gpu_8x",
+		MaxNodes:     5,
+		BurnIn:       &BurnInCfg{Enabled: true, Timeout: -time.Minute},
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "burn_in.timeout") {
+		t.Errorf("expected burn_in.timeout error, got %v", err)
+	}
+}
+
+func TestValidate_HealthHistory(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  ServerConfig
+		wantErr string
+	}{
+		{name: "unset"},
+		{name: "set", server: ServerConfig{HealthHistoryWindow: 10 * time.Minute, HealthHistoryMaxEvents: 50}},
+		{name: "negative_window", server: ServerConfig{HealthHistoryWindow: -time.Minute}, wantErr: "health_history_window"},
+		{name: "negative_max_events", server: ServerConfig{HealthHistoryMaxEvents: -1}, wantErr: "health_history_max_events"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:    tt.server,
+				Providers: map[string]ProviderCfg{"fake": {Type: "fake"}},
+				Pools: map[string]PoolCfg{
+					"test": {Provider: "fake", InstanceType: "gpu_8x", MaxNodes: 1},
+				},
+			}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidate_ClusterID(t *testing.T) {
+	tests := []struct {
+		name      string
+		clusterID string
+		wantErr   bool
+	}{
+		{name: "unset"},
+		{name: "valid", clusterID: "prod-us_east1"},
+		{name: "uppercase", clusterID: "Prod", wantErr: true},
+		{name: "slash", clusterID: "navarch.dev/prod", wantErr: true},
+		{name: "too_long", clusterID: strings.Repeat("a", 64), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:    ServerConfig{ClusterID: tt.clusterID},
+				Providers: map[string]ProviderCfg{"fake": {Type: "fake"}},
+				Pools: map[string]PoolCfg{
+					"test": {Provider: "fake", InstanceType: "gpu_8x", MaxNodes: 1},
+				},
+			}
+			err := cfg.Validate()
+			if tt.wantErr && (err == nil || !strings.Contains(err.Error(), "cluster_id")) {
+				t.Errorf("expected cluster_id error, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestParsePool(t *testing.T) {
+	pool, err := ParsePool([]byte(`
+provider: lambda
+instance_type: gpu_8x_h100
+max_nodes: 4
+cooldown: 1m
+`))
+	if err != nil {
+		t.Fatalf("ParsePool failed: %v", err)
+	}
+	if pool.Provider != "lambda" || pool.MaxNodes != 4 || pool.Cooldown != time.Minute {
+		t.Errorf("unexpected pool: %+v", pool)
+	}
+
+	cfg := &Config{
+		Providers: map[string]ProviderCfg{"lambda": {Type: "lambda"}},
+		Defaults:  DefaultsCfg{SSHUser: "admin"},
+	}
+	if err := cfg.ValidatePool("train", pool); err != nil {
+		t.Errorf("ValidatePool failed: %v", err)
+	}
+	pool = cfg.ApplyPoolDefaults(pool)
+	if pool.SSHUser != "admin" || pool.Cooldown != time.Minute {
+		t.Errorf("defaults not applied: %+v", pool)
+	}
+
+	if _, err := ParsePool([]byte("max_nodes: [1")); err == nil {
+		t.Error("expected error for malformed YAML")
+	}
+}
@@ -0,0 +1,228 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	"github.com/NavarchProject/navarch/pkg/gpu"
+	"github.com/NavarchProject/navarch/pkg/health"
+	"github.com/NavarchProject/navarch/pkg/notifier"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+// healthPolicyIssuer is the issued_by prefix of commands issued by health
+// policy rule actions. The rule name follows it.
+const healthPolicyIssuer = "health-policy/"
+
+// actionKey identifies one target of a rule action: a node, and for
+// reset_gpu a GPU on it.
+type actionKey struct {
+	nodeID string
+	action health.ActionType
+	gpu    string
+}
+
+// plannedAction is a rule action to run for one health report.
+type plannedAction struct {
+	key    actionKey
+	rule   string
+	action health.Action
+
+	// event is the health event that matched the rule.
+	event gpu.HealthEvent
+}
+
+// actionLimiter enforces health.ActionLimit: each action type runs at most
+// Max times across the fleet in any Window, and at most once per target in
+// that window.
+type actionLimiter struct {
+	mu      sync.Mutex
+	recent  map[health.ActionType][]time.Time
+	lastRun map[actionKey]time.Time
+}
+
+func newActionLimiter() *actionLimiter {
+	return &actionLimiter{
+		recent:  make(map[health.ActionType][]time.Time),
+		lastRun: make(map[actionKey]time.Time),
+	}
+}
+
+// allow reports whether an action may run at now and, if so, counts it
+// against the limit. When it may not, the returned reason says why.
+func (l *actionLimiter) allow(key actionKey, limit health.ActionLimit, now time.Time) (bool, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := now.Add(-limit.Window)
+	if last, ok := l.lastRun[key]; ok && last.After(cutoff) {
+		return false, fmt.Sprintf("already ran on this target in the last %s", limit.Window)
+	}
+
+	recent := l.recent[key.action]
+	for len(recent) > 0 && !recent[0].After(cutoff) {
+		recent = recent[1:]
+	}
+	if len(recent) >= limit.Max {
+		l.recent[key.action] = recent
+		return false, fmt.Sprintf("fleet limit of %d per %s reached", limit.Max, limit.Window)
+	}
+	l.recent[key.action] = append(recent, now)
+
+	for k, last := range l.lastRun {
+		if k.action == key.action && !last.After(cutoff) {
+			delete(l.lastRun, k)
+		}
+	}
+	l.lastRun[key] = now
+	return true, ""
+}
+
+// planActions collects the actions of matched rules, once per target.
+func planActions(nodeID string, matches []health.RuleMatch) []plannedAction {
+	var planned []plannedAction
+	seen := make(map[actionKey]bool)
+	for i := range matches {
+		m := &matches[i]
+		for _, action := range m.Actions {
+			key := actionKey{nodeID: nodeID, action: action.Type}
+			if action.Type == health.ActionResetGPU {
+				key.gpu = m.Event.GPUUUID
+				if key.gpu == "" {
+					key.gpu = strconv.Itoa(m.Event.GPUIndex)
+				}
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			planned = append(planned, plannedAction{
+				key:    key,
+				rule:   m.Rule,
+				action: action,
+				event:  m.Event,
+			})
+		}
+	}
+	return planned
+}
+
+// runRuleActions carries out the actions of the rules that matched a node's
+// health report, within the action limits of the policy they came from.
+// Failures are logged and do not affect the report.
+func (s *Server) runRuleActions(ctx context.Context, policy *health.Policy, node *db.NodeRecord, matches []health.RuleMatch) {
+	planned := planActions(node.NodeID, matches)
+	if len(planned) == 0 {
+		return
+	}
+
+	for _, p := range planned {
+		logAttrs := []any{
+			slog.String("node_id", node.NodeID),
+			slog.String("rule", p.rule),
+			slog.String("action", string(p.action.Type)),
+		}
+
+		if skip := actionSkipReason(p, node); skip != "" {
+			s.logger.DebugContext(ctx, "health action skipped", append(logAttrs, slog.String("reason", skip))...)
+			continue
+		}
+
+		limit := policy.ActionLimit(p.action.Type)
+		if ok, reason := s.actionLimiter.allow(p.key, limit, s.clock.Now()); !ok {
+			s.logger.WarnContext(ctx, "health action rate limited", append(logAttrs, slog.String("reason", reason))...)
+			continue
+		}
+
+		if err := s.runRuleAction(ctx, node.NodeID, p); err != nil {
+			s.logger.ErrorContext(ctx, "health action failed", append(logAttrs, slog.String("error", err.Error()))...)
+			continue
+		}
+		s.logger.InfoContext(ctx, "health action taken", logAttrs...)
+	}
+}
+
+// actionSkipReason returns why an action is not needed for the node, or ""
+// if it should run. Unhealthy nodes are already out of service and are left
+// to replacement, so they are not cordoned or drained.
+func actionSkipReason(p plannedAction, node *db.NodeRecord) string {
+	switch p.action.Type {
+	case health.ActionCordon, health.ActionDrain:
+		switch node.Status {
+		case pb.NodeStatus_NODE_STATUS_UNHEALTHY, pb.NodeStatus_NODE_STATUS_TERMINATED:
+			return "node is " + node.Status.String()
+		case pb.NodeStatus_NODE_STATUS_DRAINING:
+			return "node is already draining"
+		case pb.NodeStatus_NODE_STATUS_CORDONED:
+			if p.action.Type == health.ActionCordon {
+				return "node is already cordoned"
+			}
+		}
+	case health.ActionResetGPU:
+		if p.event.GPUIndex < 0 {
+			return "event is not for a single GPU"
+		}
+	}
+	return ""
+}
+
+func (s *Server) runRuleAction(ctx context.Context, nodeID string, p plannedAction) error {
+	reason := fmt.Sprintf("health policy rule %s matched", p.rule)
+
+	var cmdType pb.NodeCommandType
+	switch p.action.Type {
+	case health.ActionCordon:
+		cmdType = pb.NodeCommandType_NODE_COMMAND_TYPE_CORDON
+	case health.ActionDrain:
+		cmdType = pb.NodeCommandType_NODE_COMMAND_TYPE_DRAIN
+	case health.ActionRunDiagnostic:
+		cmdType = pb.NodeCommandType_NODE_COMMAND_TYPE_RUN_DIAGNOSTIC
+	case health.ActionResetGPU:
+		cmdType = pb.NodeCommandType_NODE_COMMAND_TYPE_RESET_GPU
+	case health.ActionNotifyOnly:
+		hn, ok := s.currentNotifier().(notifier.HealthNotifier)
+		if !ok {
+			// Without a notifier that takes health notifications, the log
+			// line written for the action is the notification.
+			return nil
+		}
+		return hn.NotifyHealth(ctx, nodeID, reason)
+	default:
+		return fmt.Errorf("unknown action %q", p.action.Type)
+	}
+
+	params := maps.Clone(p.action.Parameters)
+	if params == nil {
+		params = make(map[string]string)
+	}
+	if _, ok := params["reason"]; !ok {
+		params["reason"] = reason
+	}
+	if cmdType == pb.NodeCommandType_NODE_COMMAND_TYPE_RESET_GPU {
+		params["gpu_index"] = strconv.Itoa(p.event.GPUIndex)
+		if p.event.GPUUUID != "" {
+			params["gpu_uuid"] = p.event.GPUUUID
+		}
+	}
+
+	_, err := s.issueCommand(ctx, &pb.IssueCommandRequest{
+		NodeId:      nodeID,
+		CommandType: cmdType,
+		Parameters:  params,
+	}, healthPolicyIssuer+p.rule)
+	return err
+}
+
+func actionTypes(actions []health.Action) []string {
+	var types []string
+	for _, a := range actions {
+		types = append(types, string(a.Type))
+	}
+	return types
+}
@@ -0,0 +1,202 @@
+package controlplane
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/NavarchProject/navarch/pkg/clock"
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	"github.com/NavarchProject/navarch/pkg/health"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+type healthNotifier struct {
+	drainNotifier
+	mu       sync.Mutex
+	notified []string
+}
+
+func (n *healthNotifier) NotifyHealth(ctx context.Context, nodeID, reason string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notified = append(n.notified, nodeID)
+	return nil
+}
+
+func TestActionLimiter(t *testing.T) {
+	l := newActionLimiter()
+	limit := health.ActionLimit{Max: 2, Window: time.Hour}
+	start := time.Now()
+	key := func(node string) actionKey { return actionKey{nodeID: node, action: health.ActionCordon} }
+
+	if ok, _ := l.allow(key("node-1"), limit, start); !ok {
+		t.Fatal("first cordon should be allowed")
+	}
+	if ok, _ := l.allow(key("node-1"), limit, start.Add(time.Minute)); ok {
+		t.Error("second cordon of the same node within the window should be refused")
+	}
+	if ok, _ := l.allow(key("node-2"), limit, start.Add(time.Minute)); !ok {
+		t.Error("cordon of another node should be allowed")
+	}
+	if ok, _ := l.allow(key("node-3"), limit, start.Add(2*time.Minute)); ok {
+		t.Error("third cordon within the window should hit the fleet limit")
+	}
+	drain := actionKey{nodeID: "node-3", action: health.ActionDrain}
+	if ok, _ := l.allow(drain, limit, start.Add(2*time.Minute)); !ok {
+		t.Error("limits are per action type; drain should be allowed")
+	}
+	if ok, _ := l.allow(key("node-3"), limit, start.Add(61*time.Minute)); !ok {
+		t.Error("cordon should be allowed once the first one leaves the window")
+	}
+}
+
+func TestRuleActions(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewInMemDB()
+	defer database.Close()
+	clk := clock.NewFakeClock(time.Now())
+
+	policy, err := health.ParsePolicy([]byte(`
+action_limits:
+  cordon: {max: 2, window: 1h}
+rules:
+  - name: fatal-xid
+    condition: event.event_type == "xid" && event.metrics.xid_code == 79
+    result: unhealthy
+    actions: [cordon]
+  - name: hot
+    condition: event.event_type == "thermal" && event.metrics.temperature >= 90
+    result: degraded
+    actions: [cordon, notify_only]
+  - name: stuck-gpu
+    condition: event.event_type == "xid" && event.metrics.xid_code == 43
+    result: degraded
+    actions:
+      - reset_gpu
+      - type: run_diagnostic
+        parameters: {test: memory}
+  - name: default
+    condition: "true"
+    result: healthy
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.HealthPolicy = policy
+	cfg.Clock = clk
+	srv := NewServer(database, cfg, nil, nil)
+	n := &healthNotifier{}
+	srv.SetNotifier(n)
+
+	for _, id := range []string{"node-1", "node-2", "node-3", "node-4"} {
+		srv.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{NodeId: id}))
+	}
+
+	report := func(nodeID string, event *pb.HealthEvent) {
+		t.Helper()
+		event.Timestamp = timestamppb.New(clk.Now())
+		if _, err := srv.ReportHealth(ctx, connect.NewRequest(&pb.ReportHealthRequest{
+			NodeId: nodeID,
+			Events: []*pb.HealthEvent{event},
+		})); err != nil {
+			t.Fatalf("ReportHealth failed: %v", err)
+		}
+	}
+	hot := func() *pb.HealthEvent {
+		return &pb.HealthEvent{EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_THERMAL, Metrics: map[string]string{"temperature": "95"}}
+	}
+	xid := func(code string, gpuIndex int32) *pb.HealthEvent {
+		return &pb.HealthEvent{EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_XID, GpuIndex: gpuIndex, Metrics: map[string]string{"xid_code": code}}
+	}
+	status := func(nodeID string) pb.NodeStatus {
+		node, err := database.GetNode(ctx, nodeID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return node.Status
+	}
+	commands := func(nodeID string) []*db.CommandRecord {
+		cmds, err := database.ListCommands(ctx, db.CommandFilter{NodeID: nodeID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cmds
+	}
+
+	t.Run("cordon_within_fleet_limit", func(t *testing.T) {
+		report("node-1", hot())
+		report("node-2", hot())
+		report("node-3", hot())
+
+		for _, id := range []string{"node-1", "node-2"} {
+			if got := status(id); got != pb.NodeStatus_NODE_STATUS_CORDONED {
+				t.Errorf("%s status = %v, want CORDONED", id, got)
+			}
+			cmds := commands(id)
+			if len(cmds) != 1 || cmds[0].Type != pb.NodeCommandType_NODE_COMMAND_TYPE_CORDON || cmds[0].IssuedBy != "health-policy/hot" {
+				t.Errorf("%s commands = %+v, want one cordon issued by health-policy/hot", id, cmds)
+			}
+		}
+		if got := status("node-3"); got != pb.NodeStatus_NODE_STATUS_ACTIVE {
+			t.Errorf("node-3 status = %v, want ACTIVE once the cordon limit is reached", got)
+		}
+
+		n.mu.Lock()
+		notified := len(n.notified)
+		n.mu.Unlock()
+		if notified != 3 {
+			t.Errorf("notify_only sent %d notifications, want 3", notified)
+		}
+	})
+
+	t.Run("node_commands", func(t *testing.T) {
+		report("node-4", xid("43", 2))
+
+		var reset, diag *db.CommandRecord
+		for _, cmd := range commands("node-4") {
+			switch cmd.Type {
+			case pb.NodeCommandType_NODE_COMMAND_TYPE_RESET_GPU:
+				reset = cmd
+			case pb.NodeCommandType_NODE_COMMAND_TYPE_RUN_DIAGNOSTIC:
+				diag = cmd
+			}
+		}
+		if reset == nil || reset.Parameters["gpu_index"] != "2" || reset.Status != db.CommandStatusPending {
+			t.Errorf("reset command = %+v, want pending reset of GPU 2", reset)
+		}
+		if diag == nil || diag.Parameters["test"] != "memory" {
+			t.Errorf("diagnostic command = %+v, want test=memory", diag)
+		}
+
+		report("node-4", xid("43", 2))
+		if got := len(commands("node-4")); got != 2 {
+			t.Errorf("repeat report issued more commands: %d, want 2", got)
+		}
+		report("node-4", xid("43", 3))
+		if got := len(commands("node-4")); got != 3 {
+			t.Errorf("reset of another GPU: %d commands, want 3", got)
+		}
+
+		clk.Advance(2 * time.Hour)
+		report("node-4", xid("43", 2))
+		if got := len(commands("node-4")); got != 5 {
+			t.Errorf("after the window: %d commands, want 5", got)
+		}
+	})
+
+	t.Run("unhealthy_not_cordoned", func(t *testing.T) {
+		report("node-3", xid("79", 0))
+		if got := status("node-3"); got != pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+			t.Errorf("node-3 status = %v, want UNHEALTHY", got)
+		}
+		if got := len(commands("node-3")); got != 0 {
+			t.Errorf("unhealthy node got %d commands, want 0", got)
+		}
+	})
+}
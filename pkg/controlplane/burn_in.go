@@ -0,0 +1,180 @@
+package controlplane
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	"github.com/NavarchProject/navarch/pkg/notifier"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+// burnInIssuer is the issuer recorded on the diagnostics run by burn-in.
+const burnInIssuer = "burn-in"
+
+// defaultBurnInTimeout is how long a node may qualify if its pool does not
+// say.
+const defaultBurnInTimeout = 30 * time.Minute
+
+// Burn-in results, as reported to a BurnInObserver.
+const (
+	BurnInPassed   = "passed"
+	BurnInFailed   = "failed"
+	BurnInTimedOut = "timeout"
+)
+
+// BurnInObserver is notified when a node finishes burn-in.
+type BurnInObserver interface {
+	OnBurnInComplete(ctx context.Context, node *db.NodeRecord, result string)
+}
+
+// SetBurnInObserver sets the observer to be notified of burn-in results.
+func (s *Server) SetBurnInObserver(observer BurnInObserver) {
+	s.burnInObserver = observer
+}
+
+// burnIn is the burn-in configuration of a pool.
+type burnIn struct {
+	tests   []string
+	timeout time.Duration
+}
+
+// burnInFor returns the burn-in configuration of the pool named by a node's
+// "pool" label, and whether that pool runs burn-in at all.
+func (s *Server) burnInFor(metadata *pb.NodeMetadata) (burnIn, bool) {
+	b := burnIn{timeout: defaultBurnInTimeout}
+	if s.poolManager == nil || metadata == nil {
+		return b, false
+	}
+	p, ok := s.poolManager.GetPool(metadata.Labels["pool"])
+	if !ok {
+		return b, false
+	}
+	cfg := p.Config()
+	b.tests = cfg.BurnInTests
+	if cfg.BurnInTimeout > 0 {
+		b.timeout = cfg.BurnInTimeout
+	}
+	return b, cfg.BurnIn
+}
+
+// startBurnIn tells the workload system a newly registered qualifying node is
+// not schedulable yet and sends it its pool's diagnostics. Failures are
+// logged; a node whose diagnostics never report fails when its burn-in times
+// out.
+func (s *Server) startBurnIn(ctx context.Context, node *db.NodeRecord, b burnIn) {
+	if n := s.currentNotifier(); n != nil {
+		if err := n.Cordon(ctx, node.NodeID, burnInIssuer); err != nil {
+			s.logger.ErrorContext(ctx, "failed to cordon qualifying node",
+				slog.String("node_id", node.NodeID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	params := map[string]string{
+		"timeout": strconv.Itoa(int(b.timeout.Seconds())),
+		"reason":  "burn-in of new node",
+	}
+	if len(b.tests) > 0 {
+		params["test"] = strings.Join(b.tests, ",")
+	}
+	if _, err := s.issueCommand(ctx, &pb.IssueCommandRequest{
+		NodeId:      node.NodeID,
+		CommandType: pb.NodeCommandType_NODE_COMMAND_TYPE_RUN_DIAGNOSTIC,
+		Parameters:  params,
+	}, burnInIssuer); err != nil {
+		s.logger.ErrorContext(ctx, "failed to issue burn-in diagnostics",
+			slog.String("node_id", node.NodeID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	s.logger.InfoContext(ctx, "node qualifying, burn-in started",
+		slog.String("node_id", node.NodeID),
+		slog.String("tests", params["test"]),
+		slog.Duration("timeout", b.timeout),
+	)
+}
+
+// burnInDiagnosticsFinished ends the burn-in of the node that ran a burn-in
+// diagnostic command. It passes only if the command completed with a passing
+// report.
+func (s *Server) burnInDiagnosticsFinished(ctx context.Context, cmd *db.CommandRecord, status, output string) {
+	var report struct {
+		Passed bool `json:"passed"`
+	}
+	result := BurnInFailed
+	if status == db.CommandStatusCompleted && json.Unmarshal([]byte(output), &report) == nil && report.Passed {
+		result = BurnInPassed
+	}
+	s.finishBurnIn(ctx, cmd.NodeID, result)
+}
+
+// checkBurnInTimeout fails the burn-in of a node that has been qualifying
+// for longer than its pool allows.
+func (s *Server) checkBurnInTimeout(ctx context.Context, nodeID string) {
+	node, err := s.db.GetNode(ctx, nodeID)
+	if err != nil || node.Status != pb.NodeStatus_NODE_STATUS_QUALIFYING {
+		return
+	}
+	b, _ := s.burnInFor(node.Metadata)
+	if s.clock.Now().Sub(node.RegisteredAt) < b.timeout {
+		return
+	}
+	s.finishBurnIn(ctx, nodeID, BurnInTimedOut)
+}
+
+// finishBurnIn moves a qualifying node out of burn-in. A node that passed
+// becomes active and schedulable; any other result marks it unhealthy so
+// that it is replaced. Nodes that are no longer qualifying are left alone.
+func (s *Server) finishBurnIn(ctx context.Context, nodeID, result string) {
+	s.burnInMu.Lock()
+	defer s.burnInMu.Unlock()
+
+	node, err := s.db.GetNode(ctx, nodeID)
+	if err != nil || node.Status != pb.NodeStatus_NODE_STATUS_QUALIFYING {
+		return
+	}
+
+	if result == BurnInPassed {
+		err = s.updateStatusAndNotify(ctx, nodeID, pb.NodeStatus_NODE_STATUS_ACTIVE, node.Status,
+			func(n notifier.Notifier) error { return n.Uncordon(ctx, nodeID) })
+	} else {
+		err = s.db.UpdateNodeStatus(ctx, nodeID, pb.NodeStatus_NODE_STATUS_UNHEALTHY)
+		s.nodeEvents.syncNode(ctx, s.db, nodeID)
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to end node burn-in",
+			slog.String("node_id", nodeID),
+			slog.String("result", result),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	if result == BurnInPassed {
+		s.logger.InfoContext(ctx, "node passed burn-in", slog.String("node_id", nodeID))
+	} else {
+		s.logger.WarnContext(ctx, "node failed burn-in",
+			slog.String("node_id", nodeID),
+			slog.String("provider", node.Provider),
+			slog.String("result", result),
+		)
+		if s.healthObserver != nil {
+			go s.healthObserver.OnNodeUnhealthy(context.Background(), nodeID)
+		}
+	}
+	s.observeBurnIn(ctx, node, result)
+}
+
+// observeBurnIn reports a node's burn-in result to the observer, if any.
+func (s *Server) observeBurnIn(ctx context.Context, node *db.NodeRecord, result string) {
+	if s.burnInObserver != nil {
+		s.burnInObserver.OnBurnInComplete(ctx, node, result)
+	}
+}
@@ -0,0 +1,256 @@
+package controlplane
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+
+	"github.com/NavarchProject/navarch/pkg/clock"
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	"github.com/NavarchProject/navarch/pkg/pool"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+type burnInNotifier struct {
+	drainNotifier
+	cordoned   []string
+	uncordoned []string
+}
+
+func (n *burnInNotifier) Cordon(ctx context.Context, nodeID, reason string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.cordoned = append(n.cordoned, nodeID)
+	return nil
+}
+
+func (n *burnInNotifier) Uncordon(ctx context.Context, nodeID string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.uncordoned = append(n.uncordoned, nodeID)
+	return nil
+}
+
+type burnInRecorder struct {
+	mu      sync.Mutex
+	results map[string]string // node ID -> result
+}
+
+func (r *burnInRecorder) OnBurnInComplete(ctx context.Context, node *db.NodeRecord, result string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.results[node.NodeID]; ok {
+		result = "counted twice"
+	}
+	r.results[node.NodeID] = result
+}
+
+func (r *burnInRecorder) result(nodeID string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.results[nodeID]
+}
+
+func TestBurnIn(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	database := db.NewInMemDBWithClock(clk)
+	defer database.Close()
+
+	cfg := DefaultConfig()
+	cfg.Clock = clk
+	srv := NewServer(database, cfg, nil, nil)
+	n := &burnInNotifier{}
+	srv.SetNotifier(n)
+	observer := &testHealthObserver{}
+	srv.SetHealthObserver(observer)
+	recorder := &burnInRecorder{results: make(map[string]string)}
+	srv.SetBurnInObserver(recorder)
+
+	pm := NewPoolManager(PoolManagerConfig{}, nil, nil, nil)
+	p, _ := pool.NewSimple(pool.Config{
+		Name:          "training",
+		InstanceType:  "h100-8x",
+		MaxNodes:      4,
+		BurnIn:        true,
+		BurnInTests:   []string{"memory", "pcie"},
+		BurnInTimeout: 10 * time.Minute,
+	}, &mockProvider{}, "mock")
+	pm.AddPool(p, pool.NewQueueBasedAutoscaler(4))
+	srv.SetPoolManager(pm)
+
+	register := func(nodeID, poolName string) {
+		t.Helper()
+		if _, err := srv.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{
+			NodeId:   nodeID,
+			Provider: "lambda",
+			Metadata: &pb.NodeMetadata{Labels: map[string]string{"pool": poolName}},
+		})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	status := func(nodeID string) pb.NodeStatus {
+		t.Helper()
+		node, err := database.GetNode(ctx, nodeID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return node.Status
+	}
+	burnInCommands := func(nodeID string) []*db.CommandRecord {
+		t.Helper()
+		commands, err := database.ListCommands(ctx, db.CommandFilter{NodeID: nodeID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return slices.DeleteFunc(commands, func(c *db.CommandRecord) bool { return c.IssuedBy != burnInIssuer })
+	}
+	ack := func(nodeID string, status pb.CommandStatus, output string) {
+		t.Helper()
+		commands := burnInCommands(nodeID)
+		if len(commands) != 1 {
+			t.Fatalf("%s has %d burn-in commands, want 1", nodeID, len(commands))
+		}
+		if _, err := srv.AcknowledgeCommand(ctx, connect.NewRequest(&pb.AcknowledgeCommandRequest{
+			NodeId:    nodeID,
+			CommandId: commands[0].CommandID,
+			Status:    status,
+			Output:    output,
+		})); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("new_node_qualifies", func(t *testing.T) {
+		register("node-1", "training")
+		if got := status("node-1"); got != pb.NodeStatus_NODE_STATUS_QUALIFYING {
+			t.Fatalf("status = %v, want qualifying", got)
+		}
+		if !slices.Contains(n.cordoned, "node-1") {
+			t.Error("qualifying node was not cordoned with the notifier")
+		}
+		commands := burnInCommands("node-1")
+		if len(commands) != 1 {
+			t.Fatalf("got %d burn-in commands, want 1", len(commands))
+		}
+		cmd := commands[0]
+		if cmd.Type != pb.NodeCommandType_NODE_COMMAND_TYPE_RUN_DIAGNOSTIC ||
+			cmd.Parameters["test"] != "memory,pcie" || cmd.Parameters["timeout"] != "600" {
+			t.Errorf("unexpected burn-in command %v %v", cmd.Type, cmd.Parameters)
+		}
+	})
+
+	t.Run("re_registration_keeps_qualifying", func(t *testing.T) {
+		register("node-1", "training")
+		if got := status("node-1"); got != pb.NodeStatus_NODE_STATUS_QUALIFYING {
+			t.Errorf("status = %v, want qualifying", got)
+		}
+		if got := len(burnInCommands("node-1")); got != 1 {
+			t.Errorf("got %d burn-in commands, want 1", got)
+		}
+	})
+
+	t.Run("pass", func(t *testing.T) {
+		ack("node-1", pb.CommandStatus_COMMAND_STATUS_COMPLETED, `{"passed":true,"results":[]}`)
+		if got := status("node-1"); got != pb.NodeStatus_NODE_STATUS_ACTIVE {
+			t.Errorf("status = %v, want active", got)
+		}
+		if !slices.Contains(n.uncordoned, "node-1") {
+			t.Error("node that passed was not uncordoned with the notifier")
+		}
+		if got := recorder.result("node-1"); got != BurnInPassed {
+			t.Errorf("result = %q, want %q", got, BurnInPassed)
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		register("node-2", "training")
+		ack("node-2", pb.CommandStatus_COMMAND_STATUS_COMPLETED, `{"passed":false,"results":[]}`)
+		if got := status("node-2"); got != pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+			t.Errorf("status = %v, want unhealthy", got)
+		}
+		if slices.Contains(n.uncordoned, "node-2") {
+			t.Error("node that failed was uncordoned")
+		}
+		if got := recorder.result("node-2"); got != BurnInFailed {
+			t.Errorf("result = %q, want %q", got, BurnInFailed)
+		}
+	})
+
+	t.Run("command_failed", func(t *testing.T) {
+		register("node-3", "training")
+		ack("node-3", pb.CommandStatus_COMMAND_STATUS_FAILED, "")
+		if got := status("node-3"); got != pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+			t.Errorf("status = %v, want unhealthy", got)
+		}
+		if got := recorder.result("node-3"); got != BurnInFailed {
+			t.Errorf("result = %q, want %q", got, BurnInFailed)
+		}
+	})
+
+	t.Run("unhealthy_report", func(t *testing.T) {
+		register("node-4", "training")
+		if _, err := srv.ReportHealth(ctx, connect.NewRequest(&pb.ReportHealthRequest{
+			NodeId:  "node-4",
+			Results: []*pb.HealthCheckResult{{CheckName: "boot", Status: pb.HealthStatus_HEALTH_STATUS_UNHEALTHY}},
+		})); err != nil {
+			t.Fatal(err)
+		}
+		ack("node-4", pb.CommandStatus_COMMAND_STATUS_COMPLETED, `{"passed":false,"results":[]}`)
+		if got := status("node-4"); got != pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+			t.Errorf("status = %v, want unhealthy", got)
+		}
+		if got := recorder.result("node-4"); got != BurnInFailed {
+			t.Errorf("result = %q, want %q", got, BurnInFailed)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		register("node-5", "training")
+		heartbeat := func() {
+			t.Helper()
+			if _, err := srv.SendHeartbeat(ctx, connect.NewRequest(&pb.HeartbeatRequest{NodeId: "node-5"})); err != nil {
+				t.Fatal(err)
+			}
+		}
+		clk.Advance(9 * time.Minute)
+		heartbeat()
+		if got := status("node-5"); got != pb.NodeStatus_NODE_STATUS_QUALIFYING {
+			t.Errorf("status before timeout = %v, want qualifying", got)
+		}
+		clk.Advance(time.Minute)
+		heartbeat()
+		if got := status("node-5"); got != pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+			t.Errorf("status after timeout = %v, want unhealthy", got)
+		}
+		if got := recorder.result("node-5"); got != BurnInTimedOut {
+			t.Errorf("result = %q, want %q", got, BurnInTimedOut)
+		}
+	})
+
+	t.Run("failures_replaced", func(t *testing.T) {
+		deadline := time.Now().Add(time.Second)
+		for len(observer.getCalls()) < 4 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		calls := observer.getCalls()
+		slices.Sort(calls)
+		if want := []string{"node-2", "node-3", "node-4", "node-5"}; !slices.Equal(calls, want) {
+			t.Errorf("replacement triggered for %v, want %v", calls, want)
+		}
+	})
+
+	t.Run("pool_without_burn_in", func(t *testing.T) {
+		register("node-6", "inference")
+		if got := status("node-6"); got != pb.NodeStatus_NODE_STATUS_ACTIVE {
+			t.Errorf("status = %v, want active", got)
+		}
+		if got := len(burnInCommands("node-6")); got != 0 {
+			t.Errorf("got %d burn-in commands, want 0", got)
+		}
+	})
+}
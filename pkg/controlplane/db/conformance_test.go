@@ -0,0 +1,26 @@
+package db_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/NavarchProject/navarch/pkg/clock"
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	"github.com/NavarchProject/navarch/pkg/controlplane/db/dbtest"
+)
+
+func TestInMemDB_Conformance(t *testing.T) {
+	dbtest.RunConformance(t, func(t *testing.T, clk clock.Clock) db.DB {
+		return db.NewInMemDBWithClock(clk)
+	})
+}
+
+func TestSQLiteDB_Conformance(t *testing.T) {
+	dbtest.RunConformance(t, func(t *testing.T, clk clock.Clock) db.DB {
+		d, err := db.NewSQLiteDBWithClock(filepath.Join(t.TempDir(), "navarch.db"), clk)
+		if err != nil {
+			t.Fatalf("NewSQLiteDBWithClock failed: %v", err)
+		}
+		return d
+	})
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	pb "github.com/NavarchProject/navarch/proto"
@@ -24,10 +25,34 @@ type NodeRecord struct {
 	HealthStatus        pb.HealthStatus
 	RegisteredAt        time.Time
 	
+	// GPUHealth is the health of individual GPUs, set by health policy rules
+	// with GPU scope. GPUs no such rule has matched are not listed.
+	GPUHealth []*GPUHealthRecord
+	
+	// StatusReason explains why the control plane is holding the node in its
+	// status, such as a flapping hold-down, until HoldUntil. Both are kept
+	// when the node re-registers.
+	StatusReason string
+	HoldUntil    time.Time
+	
 	// Configuration
 	Config *pb.NodeConfig
 }
 
+// GPUHealthRecord is the health of one GPU on a node.
+type GPUHealthRecord struct {
+	Index  int
+	UUID   string
+	Status pb.HealthStatus
+
+	// Quarantined GPUs are hidden from workloads by the node agent and no
+	// longer count as healthy. Quarantine lasts until the node is replaced.
+	Quarantined bool
+
+	Reason    string    // Rule that last changed the GPU's status
+	UpdatedAt time.Time // When the GPU's status last changed
+}
+
 // HealthCheckRecord represents a historical health check result.
 type HealthCheckRecord struct {
 	NodeID    string
@@ -35,6 +60,88 @@ type HealthCheckRecord struct {
 	Results   []*pb.HealthCheckResult
 }
 
+// HealthEventRecord is a raw health event reported by a node. Events are
+// kept after their node is deleted, so that a failed GPU can still be traced
+// once the node has been replaced.
+type HealthEventRecord struct {
+	NodeID      string
+	ReceivedAt  time.Time
+	Event       *pb.HealthEvent
+	MatchedRule string          // Health policy rule the event matched, if any
+	Result      pb.HealthStatus // Result of the matched rule
+}
+
+// Time returns when the event occurred, or when it was received if the node
+// did not say.
+func (r *HealthEventRecord) Time() time.Time {
+	if ts := r.Event.GetTimestamp(); ts != nil {
+		return ts.AsTime()
+	}
+	return r.ReceivedAt
+}
+
+// HealthEventFilter selects health events. Zero fields match all events.
+type HealthEventFilter struct {
+	NodeID  string
+	GPUUUID string
+	Since   time.Time // Inclusive, on the event time
+	Until   time.Time // Exclusive, on the event time
+	Limit   int       // Most recent events only; zero means no limit
+}
+
+// CommandFilter selects commands. Zero fields match all commands.
+type CommandFilter struct {
+	NodeID       string
+	Type         pb.NodeCommandType
+	Status       string
+	IssuedBy     string
+	IssuedAfter  time.Time // Inclusive
+	IssuedBefore time.Time // Exclusive
+	Limit        int       // Most recent commands only; zero means no limit
+}
+
+// GPURecord is the history of one physical GPU, identified by its UUID,
+// across every node it has been part of. GPU records are kept after their
+// nodes are deleted, so hardware that keeps failing can be recognized when a
+// provider hands it out again.
+type GPURecord struct {
+	UUID   string
+	Name   string           // GPU model name, as last registered
+	NodeID string           // Node the GPU was last seen on
+	Nodes  []*GPUAssignment // Every node the GPU has been part of, oldest first
+
+	FirstSeen time.Time
+	LastSeen  time.Time
+
+	EventCount   int         // Health events reported for the GPU
+	FailureCount int         // Events that a health policy rule found unhealthy
+	LastFailure  time.Time   // When the last such event occurred
+	XIDCounts    map[int]int // XID code -> times reported
+}
+
+// GPUFilter selects GPUs. Zero fields match all GPUs.
+type GPUFilter struct {
+	NodeID      string // Node the GPU was last seen on
+	MinFailures int
+}
+
+// ErrNodeNotFound is returned by GetNode for a node that is not registered.
+var ErrNodeNotFound = errors.New("node not found")
+
+// ErrGPUNotFound is returned by GetGPU for a GPU that has never been
+// recorded.
+var ErrGPUNotFound = errors.New("gpu not found")
+
+// GPUAssignment is a node a GPU has been part of.
+type GPUAssignment struct {
+	NodeID       string
+	Provider     string
+	InstanceType string
+	Index        int
+	FirstSeen    time.Time
+	LastSeen     time.Time
+}
+
 // CommandRecord represents a command issued to a node.
 type CommandRecord struct {
 	CommandID  string
@@ -42,9 +149,39 @@ type CommandRecord struct {
 	Type       pb.NodeCommandType
 	Parameters map[string]string
 	IssuedAt   time.Time
-	Status     string // "pending", "acknowledged", "completed", "failed"
+	IssuedBy   string // Authenticated subject that issued the command, if known
+	Status     string // One of the CommandStatus* constants
+
+	// Delivery tracking
+	DeliveredAt      time.Time // When the command was last returned to the node
+	DeliveryAttempts int       // How many times the command has been delivered
+
+	// Result reported by the node
+	Message   string    // Human-readable status message (e.g., error details)
+	Output    string    // Output produced by the command
+	UpdatedAt time.Time // When the command status last changed
 }
 
+// Command statuses, in lifecycle order. A command is pending until a node
+// fetches it, delivered until the node acknowledges it, and running until the
+// node reports a final result.
+const (
+	CommandStatusPending   = "pending"
+	CommandStatusDelivered = "delivered"
+	CommandStatusRunning   = "running"
+	CommandStatusCompleted = "completed"
+	CommandStatusFailed    = "failed"
+)
+
+// commandFinished reports whether a command status is final.
+func commandFinished(status string) bool {
+	return status == CommandStatusCompleted || status == CommandStatusFailed
+}
+
+// commandNodeDeletedMessage is the message of unfinished commands that
+// failed because their node was deleted.
+const commandNodeDeletedMessage = "node deleted before the command finished"
+
 // MetricsRecord represents metrics collected from a node at a point in time.
 type MetricsRecord struct {
 	NodeID    string
@@ -128,17 +265,34 @@ type DB interface {
 	UpdateNodeStatus(ctx context.Context, nodeID string, status pb.NodeStatus) error
 	UpdateNodeHealthStatus(ctx context.Context, nodeID string, health pb.HealthStatus) error
 	UpdateNodeHeartbeat(ctx context.Context, nodeID string, timestamp time.Time) error
+	UpdateNodeGPUHealth(ctx context.Context, nodeID string, gpus []*GPUHealthRecord) error // Replaces the node's GPU health
+	UpdateNodeHold(ctx context.Context, nodeID, reason string, until time.Time) error       // A zero until clears the hold
 	ListNodes(ctx context.Context) ([]*NodeRecord, error)
-	DeleteNode(ctx context.Context, nodeID string) error
+	DeleteNode(ctx context.Context, nodeID string) error // Keeps the node's commands, failing unfinished ones
 	
 	// Health check operations
 	RecordHealthCheck(ctx context.Context, record *HealthCheckRecord) error
 	GetLatestHealthCheck(ctx context.Context, nodeID string) (*HealthCheckRecord, error)
 	
+	// Health event operations
+	RecordHealthEvents(ctx context.Context, records []*HealthEventRecord) error
+	ListHealthEvents(ctx context.Context, filter HealthEventFilter) ([]*HealthEventRecord, error) // Newest first
+	DeleteHealthEventsBefore(ctx context.Context, cutoff time.Time) (int, error)                  // By receive time; returns the number deleted
+	
+	// GPU inventory operations
+	PutGPU(ctx context.Context, record *GPURecord) error // Creates or replaces the GPU
+	GetGPU(ctx context.Context, uuid string) (*GPURecord, error)
+	ListGPUs(ctx context.Context, filter GPUFilter) ([]*GPURecord, error) // Ordered by UUID
+	
 	// Command operations
 	CreateCommand(ctx context.Context, record *CommandRecord) error
 	GetPendingCommands(ctx context.Context, nodeID string) ([]*CommandRecord, error)
 	UpdateCommandStatus(ctx context.Context, commandID, status string) error
+	GetCommand(ctx context.Context, commandID string) (*CommandRecord, error)
+	ListCommands(ctx context.Context, filter CommandFilter) ([]*CommandRecord, error) // Oldest first
+	MarkCommandDelivered(ctx context.Context, commandID string) error
+	UpdateCommandResult(ctx context.Context, commandID, status, message, output string) error
+	DeleteCommandsBefore(ctx context.Context, cutoff time.Time) (int, error) // Finished commands, by issue time; returns the number deleted
 	
 	// Metrics operations
 	RecordMetrics(ctx context.Context, record *MetricsRecord) error
@@ -0,0 +1,1272 @@
+// Package dbtest provides a conformance suite for db.DB implementations.
+//
+// Every backend should run the suite from its tests so that all backends are
+// held to identical behavior:
+//
+//	func TestMyDB_Conformance(t *testing.T) {
+//		dbtest.RunConformance(t, func(t *testing.T, clk clock.Clock) db.DB {
+//			return mydb.New(clk)
+//		})
+//	}
+package dbtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/NavarchProject/navarch/pkg/clock"
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+// Factory returns a new, empty database that reads the current time from clk.
+// The suite closes the database when each test finishes.
+type Factory func(t *testing.T, clk clock.Clock) db.DB
+
+// epoch is the fixed start time for the fake clock passed to each factory.
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// RunConformance runs the full conformance suite against the databases
+// returned by newDB.
+func RunConformance(t *testing.T, newDB Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock)
+	}{
+		{"RegisterAndGetNode", testRegisterAndGetNode},
+		{"RegisterNodeDefaultsToActive", testRegisterNodeDefaultsToActive},
+		{"ReRegisterPreservesTimestamps", testReRegisterPreservesTimestamps},
+		{"ListNodes", testListNodes},
+		{"UpdateNode", testUpdateNode},
+		{"GPUHealth", testGPUHealth},
+		{"NodeHold", testNodeHold},
+		{"DeleteNode", testDeleteNode},
+		{"UnknownNodeErrors", testUnknownNodeErrors},
+		{"HealthCheck", testHealthCheck},
+		{"HealthStatusTransitions", testHealthStatusTransitions},
+		{"HealthCheckUnknownNode", testHealthCheckUnknownNode},
+		{"HealthEvents", testHealthEvents},
+		{"GPUs", testGPUs},
+		{"Commands", testCommands},
+		{"CommandOrdering", testCommandOrdering},
+		{"CommandLifecycle", testCommandLifecycle},
+		{"ListCommands", testListCommands},
+		{"DeleteCommandsBefore", testDeleteCommandsBefore},
+		{"Metrics", testMetrics},
+		{"MetricsUnknownNode", testMetricsUnknownNode},
+		{"Instances", testInstances},
+		{"InstanceStateTransitions", testInstanceStateTransitions},
+		{"InstanceFilters", testInstanceFilters},
+		{"UnknownInstanceErrors", testUnknownInstanceErrors},
+		{"BootstrapLogs", testBootstrapLogs},
+		{"BootstrapLogsByPoolLimit", testBootstrapLogsByPoolLimit},
+		{"ReturnedRecordsAreCopies", testReturnedRecordsAreCopies},
+		{"ConcurrentWriters", testConcurrentWriters},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewFakeClock(epoch)
+			d := newDB(t, clk)
+			t.Cleanup(func() { d.Close() })
+			tt.fn(t, context.Background(), d, clk)
+		})
+	}
+}
+
+func mustRegister(t *testing.T, ctx context.Context, d db.DB, record *db.NodeRecord) {
+	t.Helper()
+	if err := d.RegisterNode(ctx, record); err != nil {
+		t.Fatalf("RegisterNode(%s) failed: %v", record.NodeID, err)
+	}
+}
+
+func mustGetNode(t *testing.T, ctx context.Context, d db.DB, nodeID string) *db.NodeRecord {
+	t.Helper()
+	node, err := d.GetNode(ctx, nodeID)
+	if err != nil {
+		t.Fatalf("GetNode(%s) failed: %v", nodeID, err)
+	}
+	return node
+}
+
+func testRegisterAndGetNode(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	record := &db.NodeRecord{
+		NodeID:       "node-1",
+		Provider:     "gcp",
+		Region:       "us-central1",
+		Zone:         "us-central1-a",
+		InstanceType: "a3-highgpu-8g",
+		GPUs: []*pb.GPUInfo{
+			{Index: 0, Uuid: "GPU-0", Name: "NVIDIA H100 80GB HBM3"},
+			{Index: 1, Uuid: "GPU-1", Name: "NVIDIA H100 80GB HBM3"},
+		},
+		Metadata: &pb.NodeMetadata{Hostname: "host-1", Labels: map[string]string{"pool": "training"}},
+		Config:   &pb.NodeConfig{HeartbeatIntervalSeconds: 30},
+		Status:   pb.NodeStatus_NODE_STATUS_ACTIVE,
+	}
+	mustRegister(t, ctx, d, record)
+
+	if !record.RegisteredAt.Equal(epoch) {
+		t.Errorf("Expected RegisteredAt to be set on the record to %v, got %v", epoch, record.RegisteredAt)
+	}
+
+	node := mustGetNode(t, ctx, d, "node-1")
+	if node.Provider != "gcp" || node.Region != "us-central1" || node.Zone != "us-central1-a" || node.InstanceType != "a3-highgpu-8g" {
+		t.Errorf("Node fields did not round-trip: %+v", node)
+	}
+	if node.Status != pb.NodeStatus_NODE_STATUS_ACTIVE {
+		t.Errorf("Expected Status ACTIVE, got %v", node.Status)
+	}
+	if !node.RegisteredAt.Equal(epoch) {
+		t.Errorf("Expected RegisteredAt %v, got %v", epoch, node.RegisteredAt)
+	}
+	if len(node.GPUs) != 2 || node.GPUs[1].GetUuid() != "GPU-1" || node.GPUs[1].GetIndex() != 1 {
+		t.Errorf("GPUs did not round-trip: %v", node.GPUs)
+	}
+	if node.Metadata.GetHostname() != "host-1" || node.Metadata.GetLabels()["pool"] != "training" {
+		t.Errorf("Metadata did not round-trip: %v", node.Metadata)
+	}
+	if node.Config.GetHeartbeatIntervalSeconds() != 30 {
+		t.Errorf("Config did not round-trip: %v", node.Config)
+	}
+	if !node.LastHeartbeat.IsZero() || !node.LastHealthCheck.IsZero() {
+		t.Errorf("Expected zero heartbeat and health check times, got %v and %v", node.LastHeartbeat, node.LastHealthCheck)
+	}
+}
+
+func testRegisterNodeDefaultsToActive(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	record := &db.NodeRecord{NodeID: "node-1"}
+	mustRegister(t, ctx, d, record)
+
+	if record.Status != pb.NodeStatus_NODE_STATUS_ACTIVE {
+		t.Errorf("Expected record Status to default to ACTIVE, got %v", record.Status)
+	}
+	if node := mustGetNode(t, ctx, d, "node-1"); node.Status != pb.NodeStatus_NODE_STATUS_ACTIVE {
+		t.Errorf("Expected stored Status ACTIVE, got %v", node.Status)
+	}
+}
+
+func testReRegisterPreservesTimestamps(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	mustRegister(t, ctx, d, &db.NodeRecord{NodeID: "node-1", Provider: "gcp"})
+
+	heartbeat := epoch.Add(time.Minute)
+	if err := d.UpdateNodeHeartbeat(ctx, "node-1", heartbeat); err != nil {
+		t.Fatalf("UpdateNodeHeartbeat failed: %v", err)
+	}
+	healthCheck := epoch.Add(2 * time.Minute)
+	d.RecordHealthCheck(ctx, &db.HealthCheckRecord{
+		NodeID:    "node-1",
+		Timestamp: healthCheck,
+		Results:   []*pb.HealthCheckResult{{CheckName: "nvml", Status: pb.HealthStatus_HEALTH_STATUS_HEALTHY}},
+	})
+
+	clk.Advance(time.Hour)
+	mustRegister(t, ctx, d, &db.NodeRecord{NodeID: "node-1", Provider: "aws"})
+
+	node := mustGetNode(t, ctx, d, "node-1")
+	if node.Provider != "aws" {
+		t.Errorf("Expected re-registration to update Provider, got %q", node.Provider)
+	}
+	if !node.RegisteredAt.Equal(epoch) {
+		t.Errorf("Expected RegisteredAt %v to be preserved, got %v", epoch, node.RegisteredAt)
+	}
+	if !node.LastHeartbeat.Equal(heartbeat) {
+		t.Errorf("Expected LastHeartbeat %v to be preserved, got %v", heartbeat, node.LastHeartbeat)
+	}
+	if !node.LastHealthCheck.Equal(healthCheck) {
+		t.Errorf("Expected LastHealthCheck %v to be preserved, got %v", healthCheck, node.LastHealthCheck)
+	}
+}
+
+func testListNodes(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	nodes, err := d.ListNodes(ctx)
+	if err != nil {
+		t.Fatalf("ListNodes failed: %v", err)
+	}
+	if nodes == nil || len(nodes) != 0 {
+		t.Errorf("Expected empty non-nil list, got %v", nodes)
+	}
+
+	for i := 1; i <= 3; i++ {
+		mustRegister(t, ctx, d, &db.NodeRecord{NodeID: fmt.Sprintf("node-%d", i), Provider: "gcp"})
+	}
+
+	nodes, err = d.ListNodes(ctx)
+	if err != nil {
+		t.Fatalf("ListNodes failed: %v", err)
+	}
+	var ids []string
+	for _, n := range nodes {
+		ids = append(ids, n.NodeID)
+	}
+	sort.Strings(ids)
+	if fmt.Sprint(ids) != "[node-1 node-2 node-3]" {
+		t.Errorf("Expected nodes [node-1 node-2 node-3], got %v", ids)
+	}
+}
+
+func testUpdateNode(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	mustRegister(t, ctx, d, &db.NodeRecord{NodeID: "node-1"})
+
+	if err := d.UpdateNodeStatus(ctx, "node-1", pb.NodeStatus_NODE_STATUS_CORDONED); err != nil {
+		t.Fatalf("UpdateNodeStatus failed: %v", err)
+	}
+	if err := d.UpdateNodeHealthStatus(ctx, "node-1", pb.HealthStatus_HEALTH_STATUS_DEGRADED); err != nil {
+		t.Fatalf("UpdateNodeHealthStatus failed: %v", err)
+	}
+	heartbeat := epoch.Add(30 * time.Second)
+	if err := d.UpdateNodeHeartbeat(ctx, "node-1", heartbeat); err != nil {
+		t.Fatalf("UpdateNodeHeartbeat failed: %v", err)
+	}
+
+	node := mustGetNode(t, ctx, d, "node-1")
+	if node.Status != pb.NodeStatus_NODE_STATUS_CORDONED {
+		t.Errorf("Expected Status CORDONED, got %v", node.Status)
+	}
+	if node.HealthStatus != pb.HealthStatus_HEALTH_STATUS_DEGRADED {
+		t.Errorf("Expected HealthStatus DEGRADED, got %v", node.HealthStatus)
+	}
+	if !node.LastHeartbeat.Equal(heartbeat) {
+		t.Errorf("Expected LastHeartbeat %v, got %v", heartbeat, node.LastHeartbeat)
+	}
+}
+
+func testGPUHealth(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	mustRegister(t, ctx, d, &db.NodeRecord{NodeID: "node-1"})
+	if node := mustGetNode(t, ctx, d, "node-1"); len(node.GPUHealth) != 0 {
+		t.Errorf("Expected no GPU health for a new node, got %+v", node.GPUHealth)
+	}
+
+	gpus := []*db.GPUHealthRecord{
+		{Index: 0, UUID: "GPU-0", Status: pb.HealthStatus_HEALTH_STATUS_DEGRADED, Reason: "hot", UpdatedAt: epoch},
+		{Index: 3, UUID: "GPU-3", Status: pb.HealthStatus_HEALTH_STATUS_UNHEALTHY, Quarantined: true, Reason: "fatal-xid", UpdatedAt: epoch.Add(time.Minute)},
+	}
+	if err := d.UpdateNodeGPUHealth(ctx, "node-1", gpus); err != nil {
+		t.Fatalf("UpdateNodeGPUHealth failed: %v", err)
+	}
+	gpus[0].Status = pb.HealthStatus_HEALTH_STATUS_HEALTHY
+
+	node := mustGetNode(t, ctx, d, "node-1")
+	if len(node.GPUHealth) != 2 {
+		t.Fatalf("Expected 2 GPU health records, got %d", len(node.GPUHealth))
+	}
+	if got := node.GPUHealth[0]; got.Status != pb.HealthStatus_HEALTH_STATUS_DEGRADED || got.Reason != "hot" {
+		t.Errorf("Expected stored GPU 0 to be unaffected by caller changes, got %+v", got)
+	}
+	if got := node.GPUHealth[1]; got.Index != 3 || got.UUID != "GPU-3" || !got.Quarantined ||
+		!got.UpdatedAt.Equal(epoch.Add(time.Minute)) {
+		t.Errorf("Unexpected GPU 3 record: %+v", got)
+	}
+
+	// Re-registration, e.g. after an agent restart, keeps GPU health.
+	mustRegister(t, ctx, d, &db.NodeRecord{NodeID: "node-1"})
+	if node := mustGetNode(t, ctx, d, "node-1"); len(node.GPUHealth) != 2 {
+		t.Errorf("Expected GPU health to survive re-registration, got %+v", node.GPUHealth)
+	}
+
+	if err := d.UpdateNodeGPUHealth(ctx, "node-1", nil); err != nil {
+		t.Fatalf("UpdateNodeGPUHealth failed: %v", err)
+	}
+	if node := mustGetNode(t, ctx, d, "node-1"); len(node.GPUHealth) != 0 {
+		t.Errorf("Expected GPU health to be cleared, got %+v", node.GPUHealth)
+	}
+}
+
+func testNodeHold(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	mustRegister(t, ctx, d, &db.NodeRecord{NodeID: "node-1"})
+	if err := d.UpdateNodeHold(ctx, "node-1", "flapping", epoch.Add(time.Hour)); err != nil {
+		t.Fatalf("UpdateNodeHold failed: %v", err)
+	}
+	node := mustGetNode(t, ctx, d, "node-1")
+	if node.StatusReason != "flapping" || !node.HoldUntil.Equal(epoch.Add(time.Hour)) {
+		t.Errorf("Expected hold until %v, got %q until %v", epoch.Add(time.Hour), node.StatusReason, node.HoldUntil)
+	}
+
+	// Re-registration keeps the hold.
+	mustRegister(t, ctx, d, &db.NodeRecord{NodeID: "node-1"})
+	if node := mustGetNode(t, ctx, d, "node-1"); node.StatusReason != "flapping" {
+		t.Errorf("Expected hold to survive re-registration, got %+v", node)
+	}
+
+	if err := d.UpdateNodeHold(ctx, "node-1", "", time.Time{}); err != nil {
+		t.Fatalf("UpdateNodeHold failed: %v", err)
+	}
+	if node := mustGetNode(t, ctx, d, "node-1"); node.StatusReason != "" || !node.HoldUntil.IsZero() {
+		t.Errorf("Expected hold to be cleared, got %q until %v", node.StatusReason, node.HoldUntil)
+	}
+}
+
+func testDeleteNode(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	mustRegister(t, ctx, d, &db.NodeRecord{NodeID: "node-1"})
+	mustRegister(t, ctx, d, &db.NodeRecord{NodeID: "node-2"})
+	d.RecordHealthCheck(ctx, &db.HealthCheckRecord{NodeID: "node-1", Timestamp: epoch})
+	d.CreateCommand(ctx, &db.CommandRecord{CommandID: "cmd-1", NodeID: "node-1", Status: db.CommandStatusRunning})
+	d.CreateCommand(ctx, &db.CommandRecord{CommandID: "cmd-2", NodeID: "node-1", Status: db.CommandStatusCompleted})
+
+	clk.Advance(time.Minute)
+	if err := d.DeleteNode(ctx, "node-1"); err != nil {
+		t.Fatalf("DeleteNode failed: %v", err)
+	}
+
+	if _, err := d.GetNode(ctx, "node-1"); err == nil {
+		t.Error("Expected GetNode to fail after delete")
+	}
+	if _, err := d.GetLatestHealthCheck(ctx, "node-1"); err == nil {
+		t.Error("Expected health checks to be removed with the node")
+	}
+
+	// Commands outlive their node for the audit trail; unfinished ones fail.
+	if cmds, _ := d.ListCommands(ctx, db.CommandFilter{NodeID: "node-1"}); len(cmds) != 2 {
+		t.Errorf("Expected the node's 2 commands to be kept, got %d", len(cmds))
+	}
+	if cmd, err := d.GetCommand(ctx, "cmd-1"); err != nil {
+		t.Errorf("GetCommand failed after the node was deleted: %v", err)
+	} else if cmd.Status != db.CommandStatusFailed || cmd.Message == "" || !cmd.UpdatedAt.Equal(epoch.Add(time.Minute)) {
+		t.Errorf("Expected the running command to fail with a message at delete time, got %q %q %v", cmd.Status, cmd.Message, cmd.UpdatedAt)
+	}
+	if cmd, _ := d.GetCommand(ctx, "cmd-2"); cmd == nil || cmd.Status != db.CommandStatusCompleted || cmd.Message != "" {
+		t.Errorf("Expected the completed command to be unchanged, got %+v", cmd)
+	}
+	if nodes, _ := d.ListNodes(ctx); len(nodes) != 1 || nodes[0].NodeID != "node-2" {
+		t.Errorf("Expected only node-2 to remain, got %v", nodes)
+	}
+
+	// Deleting an unknown node is not an error.
+	if err := d.DeleteNode(ctx, "missing"); err != nil {
+		t.Errorf("Expected no error deleting unknown node, got %v", err)
+	}
+}
+
+func testUnknownNodeErrors(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	if node, err := d.GetNode(ctx, "missing"); !errors.Is(err, db.ErrNodeNotFound) || node != nil {
+		t.Errorf("GetNode: expected nil record and ErrNodeNotFound, got %v, %v", node, err)
+	}
+	if err := d.UpdateNodeStatus(ctx, "missing", pb.NodeStatus_NODE_STATUS_CORDONED); err == nil {
+		t.Error("UpdateNodeStatus: expected error for unknown node")
+	}
+	if err := d.UpdateNodeHealthStatus(ctx, "missing", pb.HealthStatus_HEALTH_STATUS_HEALTHY); err == nil {
+		t.Error("UpdateNodeHealthStatus: expected error for unknown node")
+	}
+	if err := d.UpdateNodeHeartbeat(ctx, "missing", epoch); err == nil {
+		t.Error("UpdateNodeHeartbeat: expected error for unknown node")
+	}
+	if err := d.UpdateNodeGPUHealth(ctx, "missing", nil); err == nil {
+		t.Error("UpdateNodeGPUHealth: expected error for unknown node")
+	}
+	if err := d.UpdateNodeHold(ctx, "missing", "flapping", epoch); err == nil {
+		t.Error("UpdateNodeHold: expected error for unknown node")
+	}
+	if _, err := d.GetLatestHealthCheck(ctx, "missing"); err == nil {
+		t.Error("GetLatestHealthCheck: expected error for node without health checks")
+	}
+	if err := d.UpdateCommandStatus(ctx, "missing", "completed"); err == nil {
+		t.Error("UpdateCommandStatus: expected error for unknown command")
+	}
+	if pending, err := d.GetPendingCommands(ctx, "missing"); err != nil || len(pending) != 0 {
+		t.Errorf("GetPendingCommands: expected no commands and no error, got %v, %v", pending, err)
+	}
+	if cmd, err := d.GetCommand(ctx, "missing"); err == nil || cmd != nil {
+		t.Errorf("GetCommand: expected nil record and error, got %v, %v", cmd, err)
+	}
+	if err := d.MarkCommandDelivered(ctx, "missing"); err == nil {
+		t.Error("MarkCommandDelivered: expected error for unknown command")
+	}
+	if err := d.UpdateCommandResult(ctx, "missing", db.CommandStatusCompleted, "", ""); err == nil {
+		t.Error("UpdateCommandResult: expected error for unknown command")
+	}
+	if commands, err := d.ListCommands(ctx, db.CommandFilter{NodeID: "missing"}); err != nil || len(commands) != 0 {
+		t.Errorf("ListCommands: expected no commands and no error, got %v, %v", commands, err)
+	}
+}
+
+func testHealthCheck(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	mustRegister(t, ctx, d, &db.NodeRecord{NodeID: "node-1"})
+
+	for i, name := range []string{"boot", "nvml"} {
+		ts := epoch.Add(time.Duration(i) * time.Minute)
+		err := d.RecordHealthCheck(ctx, &db.HealthCheckRecord{
+			NodeID:    "node-1",
+			Timestamp: ts,
+			Results: []*pb.HealthCheckResult{{
+				CheckName: name,
+				Status:    pb.HealthStatus_HEALTH_STATUS_HEALTHY,
+				Message:   "All GPUs healthy",
+				Details:   map[string]string{"gpus": "8"},
+			}},
+		})
+		if err != nil {
+			t.Fatalf("RecordHealthCheck failed: %v", err)
+		}
+		if node := mustGetNode(t, ctx, d, "node-1"); !node.LastHealthCheck.Equal(ts) {
+			t.Errorf("Expected LastHealthCheck %v, got %v", ts, node.LastHealthCheck)
+		}
+	}
+
+	latest, err := d.GetLatestHealthCheck(ctx, "node-1")
+	if err != nil {
+		t.Fatalf("GetLatestHealthCheck failed: %v", err)
+	}
+	if latest.NodeID != "node-1" || !latest.Timestamp.Equal(epoch.Add(time.Minute)) {
+		t.Errorf("Expected latest check for node-1 at %v, got %s at %v", epoch.Add(time.Minute), latest.NodeID, latest.Timestamp)
+	}
+	if len(latest.Results) != 1 || latest.Results[0].CheckName != "nvml" {
+		t.Fatalf("Expected latest result 'nvml', got %v", latest.Results)
+	}
+	if latest.Results[0].Message != "All GPUs healthy" || latest.Results[0].Details["gpus"] != "8" {
+		t.Errorf("Result fields did not round-trip: %v", latest.Results[0])
+	}
+}
+
+func testHealthStatusTransitions(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	tests := []struct {
+		initialNodeStatus  pb.NodeStatus
+		results            []pb.HealthStatus
+		expectedNodeStatus pb.NodeStatus
+		expectedHealth     pb.HealthStatus
+	}{
+		{pb.NodeStatus_NODE_STATUS_ACTIVE, []pb.HealthStatus{pb.HealthStatus_HEALTH_STATUS_HEALTHY}, pb.NodeStatus_NODE_STATUS_ACTIVE, pb.HealthStatus_HEALTH_STATUS_HEALTHY},
+		{pb.NodeStatus_NODE_STATUS_ACTIVE, []pb.HealthStatus{pb.HealthStatus_HEALTH_STATUS_DEGRADED}, pb.NodeStatus_NODE_STATUS_ACTIVE, pb.HealthStatus_HEALTH_STATUS_DEGRADED},
+		{pb.NodeStatus_NODE_STATUS_ACTIVE, []pb.HealthStatus{pb.HealthStatus_HEALTH_STATUS_UNHEALTHY}, pb.NodeStatus_NODE_STATUS_UNHEALTHY, pb.HealthStatus_HEALTH_STATUS_UNHEALTHY},
+		{pb.NodeStatus_NODE_STATUS_UNHEALTHY, []pb.HealthStatus{pb.HealthStatus_HEALTH_STATUS_HEALTHY}, pb.NodeStatus_NODE_STATUS_UNHEALTHY, pb.HealthStatus_HEALTH_STATUS_HEALTHY},
+		{pb.NodeStatus_NODE_STATUS_CORDONED, []pb.HealthStatus{pb.HealthStatus_HEALTH_STATUS_HEALTHY}, pb.NodeStatus_NODE_STATUS_CORDONED, pb.HealthStatus_HEALTH_STATUS_HEALTHY},
+		{pb.NodeStatus_NODE_STATUS_CORDONED, []pb.HealthStatus{pb.HealthStatus_HEALTH_STATUS_UNHEALTHY}, pb.NodeStatus_NODE_STATUS_UNHEALTHY, pb.HealthStatus_HEALTH_STATUS_UNHEALTHY},
+		{pb.NodeStatus_NODE_STATUS_DRAINING, []pb.HealthStatus{pb.HealthStatus_HEALTH_STATUS_UNHEALTHY}, pb.NodeStatus_NODE_STATUS_UNHEALTHY, pb.HealthStatus_HEALTH_STATUS_UNHEALTHY},
+		{pb.NodeStatus_NODE_STATUS_ACTIVE, []pb.HealthStatus{pb.HealthStatus_HEALTH_STATUS_HEALTHY, pb.HealthStatus_HEALTH_STATUS_DEGRADED}, pb.NodeStatus_NODE_STATUS_ACTIVE, pb.HealthStatus_HEALTH_STATUS_DEGRADED},
+		{pb.NodeStatus_NODE_STATUS_ACTIVE, []pb.HealthStatus{pb.HealthStatus_HEALTH_STATUS_DEGRADED, pb.HealthStatus_HEALTH_STATUS_UNHEALTHY}, pb.NodeStatus_NODE_STATUS_UNHEALTHY, pb.HealthStatus_HEALTH_STATUS_UNHEALTHY},
+	}
+
+	for i, tt := range tests {
+		nodeID := fmt.Sprintf("node-%d", i)
+		mustRegister(t, ctx, d, &db.NodeRecord{NodeID: nodeID, Status: tt.initialNodeStatus})
+
+		var results []*pb.HealthCheckResult
+		for j, status := range tt.results {
+			results = append(results, &pb.HealthCheckResult{CheckName: fmt.Sprintf("check-%d", j), Status: status})
+		}
+		d.RecordHealthCheck(ctx, &db.HealthCheckRecord{NodeID: nodeID, Timestamp: epoch, Results: results})
+
+		node := mustGetNode(t, ctx, d, nodeID)
+		if node.Status != tt.expectedNodeStatus || node.HealthStatus != tt.expectedHealth {
+			t.Errorf("%v + %v: expected %v/%v, got %v/%v",
+				tt.initialNodeStatus, tt.results, tt.expectedNodeStatus, tt.expectedHealth, node.Status, node.HealthStatus)
+		}
+	}
+}
+
+func testHealthCheckUnknownNode(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	// Health checks for unknown nodes are stored without error.
+	err := d.RecordHealthCheck(ctx, &db.HealthCheckRecord{
+		NodeID:    "unknown-node",
+		Timestamp: epoch,
+		Results:   []*pb.HealthCheckResult{{CheckName: "nvml", Status: pb.HealthStatus_HEALTH_STATUS_HEALTHY}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error for unknown node, got %v", err)
+	}
+	if latest, err := d.GetLatestHealthCheck(ctx, "unknown-node"); err != nil || latest == nil {
+		t.Errorf("Expected health check to be stored, got %v, %v", latest, err)
+	}
+	if _, err := d.GetNode(ctx, "unknown-node"); err == nil {
+		t.Error("Expected health check not to create a node")
+	}
+}
+
+func testCommands(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	record := &db.CommandRecord{
+		CommandID:  "cmd-1",
+		NodeID:     "node-1",
+		Type:       pb.NodeCommandType_NODE_COMMAND_TYPE_CORDON,
+		Parameters: map[string]string{"reason": "maintenance"},
+		IssuedAt:   epoch,
+		IssuedBy:   "user:oncall@example.com",
+	}
+	if err := d.CreateCommand(ctx, record); err != nil {
+		t.Fatalf("CreateCommand failed: %v", err)
+	}
+	if record.Status != "pending" {
+		t.Errorf("Expected Status to default to pending, got %q", record.Status)
+	}
+
+	pending, err := d.GetPendingCommands(ctx, "node-1")
+	if err != nil {
+		t.Fatalf("GetPendingCommands failed: %v", err)
+	}
+	if len(pending) != 1 {
+		t.Fatalf("Expected 1 pending command, got %d", len(pending))
+	}
+	got := pending[0]
+	if got.CommandID != "cmd-1" || got.NodeID != "node-1" || got.Type != pb.NodeCommandType_NODE_COMMAND_TYPE_CORDON {
+		t.Errorf("Command fields did not round-trip: %+v", got)
+	}
+	if got.Parameters["reason"] != "maintenance" || !got.IssuedAt.Equal(epoch) || got.IssuedBy != "user:oncall@example.com" || got.Status != "pending" {
+		t.Errorf("Command fields did not round-trip: %+v", got)
+	}
+
+	clk.Advance(time.Minute)
+	if err := d.UpdateCommandStatus(ctx, "cmd-1", "completed"); err != nil {
+		t.Fatalf("UpdateCommandStatus failed: %v", err)
+	}
+	if pending, _ := d.GetPendingCommands(ctx, "node-1"); len(pending) != 0 {
+		t.Errorf("Expected 0 pending commands, got %d", len(pending))
+	}
+	if cmd, _ := d.GetCommand(ctx, "cmd-1"); !cmd.UpdatedAt.Equal(epoch.Add(time.Minute)) {
+		t.Errorf("Expected UpdatedAt %v, got %v", epoch.Add(time.Minute), cmd.UpdatedAt)
+	}
+}
+
+func testCommandOrdering(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	for i := 0; i < 5; i++ {
+		d.CreateCommand(ctx, &db.CommandRecord{
+			CommandID: fmt.Sprintf("cmd-%d", i),
+			NodeID:    "node-1",
+			IssuedAt:  epoch.Add(time.Duration(i) * time.Second),
+		})
+	}
+	d.CreateCommand(ctx, &db.CommandRecord{CommandID: "other", NodeID: "node-2"})
+	d.UpdateCommandStatus(ctx, "cmd-2", "acknowledged")
+
+	pending, err := d.GetPendingCommands(ctx, "node-1")
+	if err != nil {
+		t.Fatalf("GetPendingCommands failed: %v", err)
+	}
+	var ids []string
+	for _, cmd := range pending {
+		ids = append(ids, cmd.CommandID)
+	}
+	if fmt.Sprint(ids) != "[cmd-0 cmd-1 cmd-3 cmd-4]" {
+		t.Errorf("Expected pending commands in creation order [cmd-0 cmd-1 cmd-3 cmd-4], got %v", ids)
+	}
+}
+
+func testCommandLifecycle(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	d.CreateCommand(ctx, &db.CommandRecord{
+		CommandID: "cmd-1",
+		NodeID:    "node-1",
+		Type:      pb.NodeCommandType_NODE_COMMAND_TYPE_RUN_DIAGNOSTIC,
+		IssuedAt:  epoch,
+	})
+
+	clk.Advance(time.Second)
+	if err := d.MarkCommandDelivered(ctx, "cmd-1"); err != nil {
+		t.Fatalf("MarkCommandDelivered failed: %v", err)
+	}
+	cmd, err := d.GetCommand(ctx, "cmd-1")
+	if err != nil {
+		t.Fatalf("GetCommand failed: %v", err)
+	}
+	if cmd.Status != db.CommandStatusDelivered || cmd.DeliveryAttempts != 1 || !cmd.DeliveredAt.Equal(epoch.Add(time.Second)) {
+		t.Errorf("Unexpected delivery state: status=%s attempts=%d delivered_at=%v", cmd.Status, cmd.DeliveryAttempts, cmd.DeliveredAt)
+	}
+	if pending, _ := d.GetPendingCommands(ctx, "node-1"); len(pending) != 0 {
+		t.Errorf("Expected delivered command not to be pending, got %d", len(pending))
+	}
+
+	// Redelivery increments the attempt count.
+	d.UpdateCommandStatus(ctx, "cmd-1", db.CommandStatusPending)
+	clk.Advance(time.Second)
+	d.MarkCommandDelivered(ctx, "cmd-1")
+	if cmd, _ := d.GetCommand(ctx, "cmd-1"); cmd.DeliveryAttempts != 2 || !cmd.DeliveredAt.Equal(epoch.Add(2*time.Second)) {
+		t.Errorf("Expected 2 delivery attempts at %v, got %d at %v", epoch.Add(2*time.Second), cmd.DeliveryAttempts, cmd.DeliveredAt)
+	}
+
+	clk.Advance(time.Second)
+	if err := d.UpdateCommandResult(ctx, "cmd-1", db.CommandStatusFailed, "dcgm exited 1", "level 3 failed"); err != nil {
+		t.Fatalf("UpdateCommandResult failed: %v", err)
+	}
+	cmd, _ = d.GetCommand(ctx, "cmd-1")
+	if cmd.Status != db.CommandStatusFailed || cmd.Message != "dcgm exited 1" || cmd.Output != "level 3 failed" {
+		t.Errorf("Result did not round-trip: %+v", cmd)
+	}
+	if !cmd.UpdatedAt.Equal(epoch.Add(3 * time.Second)) {
+		t.Errorf("Expected UpdatedAt %v, got %v", epoch.Add(3*time.Second), cmd.UpdatedAt)
+	}
+	if cmd.Type != pb.NodeCommandType_NODE_COMMAND_TYPE_RUN_DIAGNOSTIC || !cmd.IssuedAt.Equal(epoch) {
+		t.Errorf("Command fields changed by updates: %+v", cmd)
+	}
+}
+
+func testListCommands(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	d.CreateCommand(ctx, &db.CommandRecord{CommandID: "b-late", NodeID: "node-b", IssuedAt: epoch.Add(2 * time.Second),
+		Type: pb.NodeCommandType_NODE_COMMAND_TYPE_TERMINATE})
+	d.CreateCommand(ctx, &db.CommandRecord{CommandID: "a-1", NodeID: "node-a", IssuedAt: epoch,
+		Type: pb.NodeCommandType_NODE_COMMAND_TYPE_CORDON, IssuedBy: "user:oncall@example.com"})
+	d.CreateCommand(ctx, &db.CommandRecord{CommandID: "a-2", NodeID: "node-a", IssuedAt: epoch.Add(time.Second),
+		Type: pb.NodeCommandType_NODE_COMMAND_TYPE_TERMINATE})
+	d.UpdateCommandStatus(ctx, "a-1", db.CommandStatusCompleted)
+
+	ids := func(commands []*db.CommandRecord) string {
+		var ids []string
+		for _, cmd := range commands {
+			ids = append(ids, cmd.CommandID)
+		}
+		return fmt.Sprint(ids)
+	}
+
+	all, err := d.ListCommands(ctx, db.CommandFilter{})
+	if err != nil {
+		t.Fatalf("ListCommands failed: %v", err)
+	}
+	if got := ids(all); got != "[a-1 a-2 b-late]" {
+		t.Errorf("Expected all commands oldest first [a-1 a-2 b-late], got %s", got)
+	}
+
+	nodeA, _ := d.ListCommands(ctx, db.CommandFilter{NodeID: "node-a"})
+	if got := ids(nodeA); got != "[a-1 a-2]" {
+		t.Errorf("Expected node-a commands [a-1 a-2] regardless of status, got %s", got)
+	}
+
+	tests := []struct {
+		name   string
+		filter db.CommandFilter
+		want   string
+	}{
+		{"by_type", db.CommandFilter{Type: pb.NodeCommandType_NODE_COMMAND_TYPE_TERMINATE}, "[a-2 b-late]"},
+		{"by_status", db.CommandFilter{Status: db.CommandStatusPending}, "[a-2 b-late]"},
+		{"by_issuer", db.CommandFilter{IssuedBy: "user:oncall@example.com"}, "[a-1]"},
+		{"issued_after", db.CommandFilter{IssuedAfter: epoch.Add(time.Second)}, "[a-2 b-late]"},
+		{"issued_before", db.CommandFilter{IssuedBefore: epoch.Add(time.Second)}, "[a-1]"},
+		{"limit_keeps_most_recent", db.CommandFilter{Limit: 2}, "[a-2 b-late]"},
+		{"combined", db.CommandFilter{NodeID: "node-a", Status: db.CommandStatusPending, Limit: 5}, "[a-2]"},
+	}
+	for _, tt := range tests {
+		got, err := d.ListCommands(ctx, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: ListCommands failed: %v", tt.name, err)
+		}
+		if ids(got) != tt.want {
+			t.Errorf("%s: Expected %s, got %s", tt.name, tt.want, ids(got))
+		}
+	}
+}
+
+func testMetrics(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	mustRegister(t, ctx, d, &db.NodeRecord{NodeID: "node-1"})
+
+	for i := 0; i < 5; i++ {
+		err := d.RecordMetrics(ctx, &db.MetricsRecord{
+			NodeID:    "node-1",
+			Timestamp: clk.Now(),
+			Metrics: &pb.NodeMetrics{
+				CpuUsagePercent: float64(i),
+				GpuMetrics:      []*pb.GPUMetrics{{GpuIndex: 0, UtilizationPercent: float64(10 * i)}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("RecordMetrics failed: %v", err)
+		}
+		clk.Advance(time.Minute)
+	}
+
+	// Now is epoch+5m; a 3m window covers samples recorded at 3m and 4m.
+	recent, err := d.GetRecentMetrics(ctx, "node-1", 3*time.Minute)
+	if err != nil {
+		t.Fatalf("GetRecentMetrics failed: %v", err)
+	}
+	if len(recent) != 2 {
+		t.Fatalf("Expected 2 metrics within window, got %d", len(recent))
+	}
+	for i, want := range []float64{3, 4} {
+		if recent[i].NodeID != "node-1" || recent[i].Metrics.GetCpuUsagePercent() != want {
+			t.Errorf("Expected metrics ordered oldest first; index %d got %v", i, recent[i].Metrics)
+		}
+	}
+	if !recent[0].Timestamp.Equal(epoch.Add(3 * time.Minute)) {
+		t.Errorf("Expected Timestamp %v, got %v", epoch.Add(3*time.Minute), recent[0].Timestamp)
+	}
+	if got := recent[1].Metrics.GetGpuMetrics(); len(got) != 1 || got[0].UtilizationPercent != 40 {
+		t.Errorf("GPU metrics did not round-trip: %v", got)
+	}
+
+	all, _ := d.GetRecentMetrics(ctx, "node-1", time.Hour)
+	if len(all) != 5 {
+		t.Errorf("Expected 5 metrics within an hour, got %d", len(all))
+	}
+}
+
+func testHealthEvents(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	event := func(minute int, gpuUUID string, xid string) *pb.HealthEvent {
+		e := &pb.HealthEvent{
+			GpuUuid:   gpuUUID,
+			EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_XID,
+			Metrics:   map[string]string{"xid_code": xid},
+		}
+		if minute >= 0 {
+			e.Timestamp = timestamppb.New(epoch.Add(time.Duration(minute) * time.Minute))
+		}
+		return e
+	}
+	received := epoch.Add(time.Hour)
+	records := []*db.HealthEventRecord{
+		{NodeID: "node-1", ReceivedAt: epoch.Add(5 * time.Minute), Event: event(1, "GPU-a", "79"), MatchedRule: "fatal-xid", Result: pb.HealthStatus_HEALTH_STATUS_UNHEALTHY},
+		{NodeID: "node-1", ReceivedAt: epoch.Add(5 * time.Minute), Event: event(3, "GPU-b", "13")},
+		{NodeID: "node-2", ReceivedAt: epoch.Add(5 * time.Minute), Event: event(2, "GPU-a", "48")},
+		// Without a timestamp the event time is the receive time.
+		{NodeID: "node-2", ReceivedAt: received, Event: event(-1, "", "31")},
+	}
+	if err := d.RecordHealthEvents(ctx, records); err != nil {
+		t.Fatalf("RecordHealthEvents failed: %v", err)
+	}
+	records[0].Event.Metrics["xid_code"] = "0"
+
+	xids := func(events []*db.HealthEventRecord) []string {
+		var codes []string
+		for _, e := range events {
+			codes = append(codes, e.Event.Metrics["xid_code"])
+		}
+		return codes
+	}
+	list := func(filter db.HealthEventFilter) []*db.HealthEventRecord {
+		t.Helper()
+		events, err := d.ListHealthEvents(ctx, filter)
+		if err != nil {
+			t.Fatalf("ListHealthEvents(%+v) failed: %v", filter, err)
+		}
+		return events
+	}
+
+	all := list(db.HealthEventFilter{})
+	if got := fmt.Sprint(xids(all)); got != "[31 13 48 79]" {
+		t.Errorf("Expected all events newest first, got %s", got)
+	}
+	if got := all[3]; got.NodeID != "node-1" || got.MatchedRule != "fatal-xid" ||
+		got.Result != pb.HealthStatus_HEALTH_STATUS_UNHEALTHY || !got.ReceivedAt.Equal(epoch.Add(5*time.Minute)) {
+		t.Errorf("Unexpected stored record: %+v", got)
+	}
+	if got := all[0].Time(); !got.Equal(received) {
+		t.Errorf("Expected event without a timestamp to use its receive time, got %v", got)
+	}
+
+	filters := []struct {
+		filter db.HealthEventFilter
+		want   string
+	}{
+		{db.HealthEventFilter{NodeID: "node-1"}, "[13 79]"},
+		{db.HealthEventFilter{GPUUUID: "GPU-a"}, "[48 79]"},
+		{db.HealthEventFilter{Since: epoch.Add(2 * time.Minute), Until: epoch.Add(30 * time.Minute)}, "[13 48]"},
+		{db.HealthEventFilter{Limit: 2}, "[31 13]"},
+		{db.HealthEventFilter{NodeID: "missing"}, "[]"},
+	}
+	for _, f := range filters {
+		if got := fmt.Sprint(xids(list(f.filter))); got != f.want {
+			t.Errorf("ListHealthEvents(%+v) = %s, want %s", f.filter, got, f.want)
+		}
+	}
+
+	n, err := d.DeleteHealthEventsBefore(ctx, epoch.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("DeleteHealthEventsBefore failed: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("Expected 3 events deleted, got %d", n)
+	}
+	if got := fmt.Sprint(xids(list(db.HealthEventFilter{}))); got != "[31]" {
+		t.Errorf("Expected only the recent event to remain, got %s", got)
+	}
+}
+
+func testDeleteCommandsBefore(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	mustRegister(t, ctx, d, &db.NodeRecord{NodeID: "node-1"})
+	for _, cmd := range []*db.CommandRecord{
+		{CommandID: "old-done", IssuedAt: epoch, Status: db.CommandStatusCompleted},
+		{CommandID: "old-failed", IssuedAt: epoch, Status: db.CommandStatusFailed},
+		{CommandID: "old-pending", IssuedAt: epoch, Status: db.CommandStatusPending},
+		{CommandID: "new-done", IssuedAt: epoch.Add(time.Hour), Status: db.CommandStatusCompleted},
+	} {
+		cmd.NodeID = "node-1"
+		if err := d.CreateCommand(ctx, cmd); err != nil {
+			t.Fatalf("CreateCommand failed: %v", err)
+		}
+	}
+
+	n, err := d.DeleteCommandsBefore(ctx, epoch.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("DeleteCommandsBefore failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Expected 2 commands deleted, got %d", n)
+	}
+	cmds, _ := d.ListCommands(ctx, db.CommandFilter{NodeID: "node-1"})
+	var ids []string
+	for _, cmd := range cmds {
+		ids = append(ids, cmd.CommandID)
+	}
+	if got := fmt.Sprint(ids); got != "[old-pending new-done]" {
+		t.Errorf("Expected the unfinished and recent commands to remain, got %s", got)
+	}
+	if _, err := d.GetCommand(ctx, "old-done"); err == nil {
+		t.Error("Expected GetCommand to fail for a deleted command")
+	}
+}
+
+func testGPUs(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	record := &db.GPURecord{
+		UUID:   "GPU-b",
+		Name:   "NVIDIA H100 80GB HBM3",
+		NodeID: "node-2",
+		Nodes: []*db.GPUAssignment{
+			{NodeID: "node-1", Provider: "gcp", InstanceType: "a3-highgpu-8g", Index: 3, FirstSeen: epoch, LastSeen: epoch.Add(time.Hour)},
+			{NodeID: "node-2", Provider: "gcp", InstanceType: "a3-highgpu-8g", Index: 5, FirstSeen: epoch.Add(2 * time.Hour), LastSeen: epoch.Add(2 * time.Hour)},
+		},
+		FirstSeen:    epoch,
+		LastSeen:     epoch.Add(2 * time.Hour),
+		EventCount:   4,
+		FailureCount: 2,
+		LastFailure:  epoch.Add(time.Hour),
+		XIDCounts:    map[int]int{79: 2, 13: 1},
+	}
+	if err := d.PutGPU(ctx, record); err != nil {
+		t.Fatalf("PutGPU failed: %v", err)
+	}
+	if err := d.PutGPU(ctx, &db.GPURecord{UUID: "GPU-a", FirstSeen: epoch}); err != nil {
+		t.Fatalf("PutGPU failed: %v", err)
+	}
+	record.Nodes[0].NodeID = "changed"
+	record.XIDCounts[79] = 0
+
+	got, err := d.GetGPU(ctx, "GPU-b")
+	if err != nil {
+		t.Fatalf("GetGPU failed: %v", err)
+	}
+	if got.Name != "NVIDIA H100 80GB HBM3" || got.NodeID != "node-2" || got.EventCount != 4 || got.FailureCount != 2 ||
+		!got.FirstSeen.Equal(epoch) || !got.LastFailure.Equal(epoch.Add(time.Hour)) {
+		t.Errorf("Unexpected stored GPU: %+v", got)
+	}
+	if len(got.Nodes) != 2 || got.Nodes[0].NodeID != "node-1" || got.Nodes[1].Index != 5 ||
+		!got.Nodes[0].LastSeen.Equal(epoch.Add(time.Hour)) {
+		t.Errorf("Unexpected stored GPU nodes: %+v", got.Nodes)
+	}
+	if got.XIDCounts[79] != 2 || got.XIDCounts[13] != 1 {
+		t.Errorf("Unexpected stored XID counts: %v", got.XIDCounts)
+	}
+
+	got.FailureCount = 3
+	if err := d.PutGPU(ctx, got); err != nil {
+		t.Fatalf("PutGPU failed: %v", err)
+	}
+	gpus, err := d.ListGPUs(ctx, db.GPUFilter{})
+	if err != nil {
+		t.Fatalf("ListGPUs failed: %v", err)
+	}
+	if len(gpus) != 2 || gpus[0].UUID != "GPU-a" || gpus[1].FailureCount != 3 {
+		t.Errorf("Expected both GPUs by UUID with the update applied, got %+v", gpus)
+	}
+	if gpus[0].Nodes != nil || gpus[0].XIDCounts != nil {
+		t.Errorf("Expected an empty GPU to round-trip empty, got %+v", gpus[0])
+	}
+
+	filters := []struct {
+		name   string
+		filter db.GPUFilter
+		want   string
+	}{
+		{"by_node", db.GPUFilter{NodeID: "node-2"}, "[GPU-b]"},
+		{"earlier_node", db.GPUFilter{NodeID: "node-1"}, "[]"},
+		{"min_failures", db.GPUFilter{MinFailures: 3}, "[GPU-b]"},
+		{"above_max_failures", db.GPUFilter{MinFailures: 4}, "[]"},
+		{"combined", db.GPUFilter{NodeID: "node-2", MinFailures: 1}, "[GPU-b]"},
+	}
+	for _, tt := range filters {
+		gpus, err := d.ListGPUs(ctx, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: ListGPUs failed: %v", tt.name, err)
+		}
+		uuids := make([]string, len(gpus))
+		for i, g := range gpus {
+			uuids[i] = g.UUID
+		}
+		if got := fmt.Sprint(uuids); got != tt.want {
+			t.Errorf("%s: Expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+
+	if _, err := d.GetGPU(ctx, "missing"); !errors.Is(err, db.ErrGPUNotFound) {
+		t.Errorf("GetGPU: expected ErrGPUNotFound for unknown GPU, got %v", err)
+	}
+}
+
+func testMetricsUnknownNode(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	if err := d.RecordMetrics(ctx, &db.MetricsRecord{NodeID: "missing", Timestamp: epoch}); err == nil {
+		t.Error("RecordMetrics: expected error for unknown node")
+	}
+	if _, err := d.GetRecentMetrics(ctx, "missing", time.Hour); err == nil {
+		t.Error("GetRecentMetrics: expected error for unknown node")
+	}
+}
+
+func testInstances(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	record := &db.InstanceRecord{
+		InstanceID:   "i-1",
+		Provider:     "gcp",
+		Region:       "us-central1",
+		Zone:         "us-central1-a",
+		InstanceType: "a3-highgpu-8g",
+		State:        pb.InstanceState_INSTANCE_STATE_PROVISIONING,
+		PoolName:     "training",
+		CreatedAt:    epoch,
+		Labels:       map[string]string{"team": "ml"},
+	}
+	if err := d.CreateInstance(ctx, record); err != nil {
+		t.Fatalf("CreateInstance failed: %v", err)
+	}
+	if err := d.CreateInstance(ctx, record); err == nil {
+		t.Error("Expected error creating duplicate instance")
+	}
+	if err := d.CreateInstance(ctx, &db.InstanceRecord{}); err == nil {
+		t.Error("Expected error creating instance without ID")
+	}
+
+	instance, err := d.GetInstance(ctx, "i-1")
+	if err != nil {
+		t.Fatalf("GetInstance failed: %v", err)
+	}
+	if instance.Provider != "gcp" || instance.Region != "us-central1" || instance.Zone != "us-central1-a" ||
+		instance.InstanceType != "a3-highgpu-8g" || instance.PoolName != "training" {
+		t.Errorf("Instance fields did not round-trip: %+v", instance)
+	}
+	if instance.State != pb.InstanceState_INSTANCE_STATE_PROVISIONING || !instance.CreatedAt.Equal(epoch) {
+		t.Errorf("Instance fields did not round-trip: %+v", instance)
+	}
+	if instance.Labels["team"] != "ml" {
+		t.Errorf("Labels did not round-trip: %v", instance.Labels)
+	}
+
+	if err := d.UpdateInstanceNodeID(ctx, "i-1", "node-1"); err != nil {
+		t.Fatalf("UpdateInstanceNodeID failed: %v", err)
+	}
+	if instance, _ := d.GetInstance(ctx, "i-1"); instance.NodeID != "node-1" {
+		t.Errorf("Expected NodeID node-1, got %q", instance.NodeID)
+	}
+
+	if err := d.DeleteInstance(ctx, "i-1"); err != nil {
+		t.Fatalf("DeleteInstance failed: %v", err)
+	}
+	if _, err := d.GetInstance(ctx, "i-1"); err == nil {
+		t.Error("Expected GetInstance to fail after delete")
+	}
+	if err := d.DeleteInstance(ctx, "i-1"); err != nil {
+		t.Errorf("Expected no error deleting unknown instance, got %v", err)
+	}
+}
+
+func testInstanceStateTransitions(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	d.CreateInstance(ctx, &db.InstanceRecord{
+		InstanceID: "i-1",
+		State:      pb.InstanceState_INSTANCE_STATE_PROVISIONING,
+		CreatedAt:  epoch,
+	})
+
+	steps := []struct {
+		state            pb.InstanceState
+		message          string
+		wantReadyAt      time.Time
+		wantTerminatedAt time.Time
+	}{
+		{pb.InstanceState_INSTANCE_STATE_PENDING_REGISTRATION, "provisioned", time.Time{}, time.Time{}},
+		{pb.InstanceState_INSTANCE_STATE_RUNNING, "registered", epoch.Add(2 * time.Minute), time.Time{}},
+		// ReadyAt is only set on the first transition to RUNNING.
+		{pb.InstanceState_INSTANCE_STATE_RUNNING, "re-registered", epoch.Add(2 * time.Minute), time.Time{}},
+		{pb.InstanceState_INSTANCE_STATE_TERMINATING, "scale down", epoch.Add(2 * time.Minute), time.Time{}},
+		{pb.InstanceState_INSTANCE_STATE_TERMINATED, "", epoch.Add(2 * time.Minute), epoch.Add(5 * time.Minute)},
+		// TerminatedAt is only set on the first transition to TERMINATED.
+		{pb.InstanceState_INSTANCE_STATE_TERMINATED, "again", epoch.Add(2 * time.Minute), epoch.Add(5 * time.Minute)},
+	}
+
+	for i, step := range steps {
+		clk.Advance(time.Minute)
+		if err := d.UpdateInstanceState(ctx, "i-1", step.state, step.message); err != nil {
+			t.Fatalf("step %d: UpdateInstanceState failed: %v", i, err)
+		}
+		instance, err := d.GetInstance(ctx, "i-1")
+		if err != nil {
+			t.Fatalf("step %d: GetInstance failed: %v", i, err)
+		}
+		if instance.State != step.state || instance.StatusMessage != step.message {
+			t.Errorf("step %d: expected %v %q, got %v %q", i, step.state, step.message, instance.State, instance.StatusMessage)
+		}
+		if !instance.ReadyAt.Equal(step.wantReadyAt) {
+			t.Errorf("step %d: expected ReadyAt %v, got %v", i, step.wantReadyAt, instance.ReadyAt)
+		}
+		if !instance.TerminatedAt.Equal(step.wantTerminatedAt) {
+			t.Errorf("step %d: expected TerminatedAt %v, got %v", i, step.wantTerminatedAt, instance.TerminatedAt)
+		}
+	}
+}
+
+func testInstanceFilters(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	all, err := d.ListInstances(ctx)
+	if err != nil {
+		t.Fatalf("ListInstances failed: %v", err)
+	}
+	if all == nil || len(all) != 0 {
+		t.Errorf("Expected empty non-nil list, got %v", all)
+	}
+
+	instances := []struct {
+		id    string
+		pool  string
+		state pb.InstanceState
+	}{
+		{"i-1", "training", pb.InstanceState_INSTANCE_STATE_RUNNING},
+		{"i-2", "training", pb.InstanceState_INSTANCE_STATE_PENDING_REGISTRATION},
+		{"i-3", "inference", pb.InstanceState_INSTANCE_STATE_RUNNING},
+		{"i-4", "", pb.InstanceState_INSTANCE_STATE_FAILED},
+	}
+	for _, inst := range instances {
+		if err := d.CreateInstance(ctx, &db.InstanceRecord{InstanceID: inst.id, PoolName: inst.pool, State: inst.state, CreatedAt: epoch}); err != nil {
+			t.Fatalf("CreateInstance(%s) failed: %v", inst.id, err)
+		}
+	}
+
+	ids := func(records []*db.InstanceRecord) string {
+		var ids []string
+		for _, r := range records {
+			ids = append(ids, r.InstanceID)
+		}
+		sort.Strings(ids)
+		return fmt.Sprint(ids)
+	}
+
+	all, _ = d.ListInstances(ctx)
+	if got := ids(all); got != "[i-1 i-2 i-3 i-4]" {
+		t.Errorf("ListInstances: got %s", got)
+	}
+	running, _ := d.ListInstancesByState(ctx, pb.InstanceState_INSTANCE_STATE_RUNNING)
+	if got := ids(running); got != "[i-1 i-3]" {
+		t.Errorf("ListInstancesByState(RUNNING): got %s", got)
+	}
+	training, _ := d.ListInstancesByPool(ctx, "training")
+	if got := ids(training); got != "[i-1 i-2]" {
+		t.Errorf("ListInstancesByPool(training): got %s", got)
+	}
+	standalone, _ := d.ListInstancesByPool(ctx, "")
+	if got := ids(standalone); got != "[i-4]" {
+		t.Errorf("ListInstancesByPool(\"\"): got %s", got)
+	}
+	none, err := d.ListInstancesByState(ctx, pb.InstanceState_INSTANCE_STATE_TERMINATED)
+	if err != nil || len(none) != 0 {
+		t.Errorf("ListInstancesByState(TERMINATED): expected none, got %v, %v", none, err)
+	}
+}
+
+func testUnknownInstanceErrors(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	if instance, err := d.GetInstance(ctx, "missing"); err == nil || instance != nil {
+		t.Errorf("GetInstance: expected nil record and error, got %v, %v", instance, err)
+	}
+	if err := d.UpdateInstanceState(ctx, "missing", pb.InstanceState_INSTANCE_STATE_RUNNING, ""); err == nil {
+		t.Error("UpdateInstanceState: expected error for unknown instance")
+	}
+	if err := d.UpdateInstanceNodeID(ctx, "missing", "node-1"); err == nil {
+		t.Error("UpdateInstanceNodeID: expected error for unknown instance")
+	}
+}
+
+func testBootstrapLogs(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	log1 := &db.BootstrapLogRecord{
+		ID:          "log-1",
+		NodeID:      "node-1",
+		InstanceID:  "instance-1",
+		Pool:        "gpu-pool",
+		StartedAt:   epoch,
+		Duration:    5 * time.Second,
+		SSHWaitTime: 2 * time.Second,
+		Success:     true,
+		Commands: []db.BootstrapCommandLog{
+			{Command: "echo hello", Stdout: "hello", Stderr: "warn", ExitCode: 0, Duration: time.Second},
+		},
+	}
+	log2 := &db.BootstrapLogRecord{
+		ID:         "log-2",
+		NodeID:     "node-1",
+		InstanceID: "instance-1",
+		Pool:       "gpu-pool",
+		StartedAt:  epoch.Add(time.Minute),
+		Duration:   10 * time.Second,
+		Success:    false,
+		Error:      "connection refused",
+	}
+	for _, log := range []*db.BootstrapLogRecord{log1, log2} {
+		if err := d.RecordBootstrapLog(ctx, log); err != nil {
+			t.Fatalf("RecordBootstrapLog failed: %v", err)
+		}
+	}
+
+	logs, err := d.GetBootstrapLogs(ctx, "node-1")
+	if err != nil {
+		t.Fatalf("GetBootstrapLogs failed: %v", err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("Expected 2 logs, got %d", len(logs))
+	}
+	got := logs[0]
+	if got.ID != "log-1" || got.InstanceID != "instance-1" || got.Pool != "gpu-pool" || !got.StartedAt.Equal(epoch) {
+		t.Errorf("Log fields did not round-trip: %+v", got)
+	}
+	if got.Duration != 5*time.Second || got.SSHWaitTime != 2*time.Second || !got.Success {
+		t.Errorf("Log fields did not round-trip: %+v", got)
+	}
+	if len(got.Commands) != 1 || got.Commands[0] != log1.Commands[0] {
+		t.Errorf("Commands did not round-trip: %+v", got.Commands)
+	}
+	if logs[1].ID != "log-2" || logs[1].Success || logs[1].Error != "connection refused" {
+		t.Errorf("Expected second log to be the failure, got %+v", logs[1])
+	}
+
+	unknown, err := d.GetBootstrapLogs(ctx, "unknown")
+	if err != nil {
+		t.Fatalf("GetBootstrapLogs for unknown failed: %v", err)
+	}
+	if unknown == nil || len(unknown) != 0 {
+		t.Errorf("Expected empty non-nil list for unknown node, got %v", unknown)
+	}
+}
+
+func testBootstrapLogsByPoolLimit(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	// Interleave logs across nodes so ordering cannot rely on per-node storage.
+	for i := 0; i < 6; i++ {
+		d.RecordBootstrapLog(ctx, &db.BootstrapLogRecord{
+			ID:        fmt.Sprintf("log-%d", i),
+			NodeID:    fmt.Sprintf("node-%d", i%3),
+			Pool:      "gpu-pool",
+			StartedAt: epoch.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	d.RecordBootstrapLog(ctx, &db.BootstrapLogRecord{ID: "other", NodeID: "node-9", Pool: "other-pool", StartedAt: epoch.Add(time.Hour)})
+
+	ids := func(logs []*db.BootstrapLogRecord) string {
+		var ids []string
+		for _, l := range logs {
+			ids = append(ids, l.ID)
+		}
+		return fmt.Sprint(ids)
+	}
+
+	logs, err := d.ListBootstrapLogsByPool(ctx, "gpu-pool", 3)
+	if err != nil {
+		t.Fatalf("ListBootstrapLogsByPool failed: %v", err)
+	}
+	if got := ids(logs); got != "[log-5 log-4 log-3]" {
+		t.Errorf("Expected the 3 most recent logs newest first, got %s", got)
+	}
+
+	logs, _ = d.ListBootstrapLogsByPool(ctx, "gpu-pool", 0)
+	if got := ids(logs); got != "[log-5 log-4 log-3 log-2 log-1 log-0]" {
+		t.Errorf("Expected all logs newest first with limit 0, got %s", got)
+	}
+
+	logs, _ = d.ListBootstrapLogsByPool(ctx, "gpu-pool", 100)
+	if len(logs) != 6 {
+		t.Errorf("Expected 6 logs with a limit larger than the pool, got %d", len(logs))
+	}
+
+	logs, err = d.ListBootstrapLogsByPool(ctx, "missing-pool", 10)
+	if err != nil || len(logs) != 0 {
+		t.Errorf("Expected no logs for unknown pool, got %v, %v", logs, err)
+	}
+}
+
+func testReturnedRecordsAreCopies(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	mustRegister(t, ctx, d, &db.NodeRecord{
+		NodeID:   "node-1",
+		GPUs:     []*pb.GPUInfo{{Index: 0, Uuid: "GPU-0"}},
+		Metadata: &pb.NodeMetadata{Hostname: "host-1"},
+	})
+	d.CreateCommand(ctx, &db.CommandRecord{CommandID: "cmd-1", NodeID: "node-1", Parameters: map[string]string{"k": "v"}})
+	d.CreateInstance(ctx, &db.InstanceRecord{InstanceID: "i-1", Labels: map[string]string{"k": "v"}})
+
+	node := mustGetNode(t, ctx, d, "node-1")
+	node.Status = pb.NodeStatus_NODE_STATUS_TERMINATED
+	node.GPUs[0].Uuid = "mutated"
+	node.Metadata.Hostname = "mutated"
+
+	pending, _ := d.GetPendingCommands(ctx, "node-1")
+	pending[0].Parameters["k"] = "mutated"
+
+	instance, _ := d.GetInstance(ctx, "i-1")
+	instance.Labels["k"] = "mutated"
+	instance.State = pb.InstanceState_INSTANCE_STATE_FAILED
+
+	node = mustGetNode(t, ctx, d, "node-1")
+	if node.Status == pb.NodeStatus_NODE_STATUS_TERMINATED || node.GPUs[0].Uuid != "GPU-0" || node.Metadata.Hostname != "host-1" {
+		t.Errorf("Mutating a returned node changed stored state: %+v", node)
+	}
+	pending, _ = d.GetPendingCommands(ctx, "node-1")
+	if pending[0].Parameters["k"] != "v" {
+		t.Errorf("Mutating returned command parameters changed stored state: %v", pending[0].Parameters)
+	}
+	instance, _ = d.GetInstance(ctx, "i-1")
+	if instance.Labels["k"] != "v" || instance.State == pb.InstanceState_INSTANCE_STATE_FAILED {
+		t.Errorf("Mutating a returned instance changed stored state: %+v", instance)
+	}
+}
+
+func testConcurrentWriters(t *testing.T, ctx context.Context, d db.DB, clk *clock.FakeClock) {
+	const (
+		writers   = 8
+		perWriter = 10
+	)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter*4)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			nodeID := fmt.Sprintf("node-%d", w)
+			if err := d.RegisterNode(ctx, &db.NodeRecord{NodeID: nodeID}); err != nil {
+				errs <- err
+				return
+			}
+			for i := 0; i < perWriter; i++ {
+				if err := d.UpdateNodeHeartbeat(ctx, nodeID, epoch.Add(time.Duration(i)*time.Second)); err != nil {
+					errs <- err
+				}
+				if err := d.RecordMetrics(ctx, &db.MetricsRecord{NodeID: nodeID, Timestamp: epoch, Metrics: &pb.NodeMetrics{}}); err != nil {
+					errs <- err
+				}
+				if err := d.CreateCommand(ctx, &db.CommandRecord{CommandID: fmt.Sprintf("cmd-%d-%d", w, i), NodeID: nodeID}); err != nil {
+					errs <- err
+				}
+				if err := d.CreateInstance(ctx, &db.InstanceRecord{InstanceID: fmt.Sprintf("i-%d-%d", w, i), CreatedAt: epoch}); err != nil {
+					errs <- err
+				}
+				if _, err := d.ListNodes(ctx); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Concurrent write failed: %v", err)
+	}
+
+	nodes, _ := d.ListNodes(ctx)
+	if len(nodes) != writers {
+		t.Errorf("Expected %d nodes, got %d", writers, len(nodes))
+	}
+	instances, _ := d.ListInstances(ctx)
+	if len(instances) != writers*perWriter {
+		t.Errorf("Expected %d instances, got %d", writers*perWriter, len(instances))
+	}
+	for w := 0; w < writers; w++ {
+		nodeID := fmt.Sprintf("node-%d", w)
+		if pending, _ := d.GetPendingCommands(ctx, nodeID); len(pending) != perWriter {
+			t.Errorf("%s: expected %d pending commands, got %d", nodeID, perWriter, len(pending))
+		}
+		if metrics, _ := d.GetRecentMetrics(ctx, nodeID, time.Hour); len(metrics) != perWriter {
+			t.Errorf("%s: expected %d metrics, got %d", nodeID, perWriter, len(metrics))
+		}
+		if node := mustGetNode(t, ctx, d, nodeID); !node.LastHeartbeat.Equal(epoch.Add((perWriter - 1) * time.Second)) {
+			t.Errorf("%s: expected final heartbeat to win, got %v", nodeID, node.LastHeartbeat)
+		}
+	}
+}
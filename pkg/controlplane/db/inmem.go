@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -18,6 +19,8 @@ type InMemDB struct {
 	clock         clock.Clock
 	nodes         map[string]*NodeRecord
 	healthChecks  map[string][]*HealthCheckRecord  // nodeID -> list of health checks
+	healthEvents  []*HealthEventRecord             // in the order received
+	gpus          map[string]*GPURecord            // GPU UUID -> GPU record
 	commands      map[string]*CommandRecord        // commandID -> command
 	nodeCommands  map[string][]*CommandRecord      // nodeID -> list of commands
 	metrics       map[string][]*MetricsRecord      // nodeID -> list of metrics (max 100 per node)
@@ -39,6 +42,7 @@ func NewInMemDBWithClock(clk clock.Clock) *InMemDB {
 		clock:         clk,
 		nodes:         make(map[string]*NodeRecord),
 		healthChecks:  make(map[string][]*HealthCheckRecord),
+		gpus:          make(map[string]*GPURecord),
 		commands:      make(map[string]*CommandRecord),
 		nodeCommands:  make(map[string][]*CommandRecord),
 		metrics:       make(map[string][]*MetricsRecord),
@@ -55,6 +59,9 @@ func (db *InMemDB) RegisterNode(ctx context.Context, record *NodeRecord) error {
 		record.RegisteredAt = existing.RegisteredAt
 		record.LastHeartbeat = existing.LastHeartbeat
 		record.LastHealthCheck = existing.LastHealthCheck
+		record.GPUHealth = existing.GPUHealth
+		record.StatusReason = existing.StatusReason
+		record.HoldUntil = existing.HoldUntil
 	} else {
 		record.RegisteredAt = db.clock.Now()
 	}
@@ -71,7 +78,7 @@ func (db *InMemDB) GetNode(ctx context.Context, nodeID string) (*NodeRecord, err
 	defer db.mu.RUnlock()
 	node, ok := db.nodes[nodeID]
 	if !ok {
-		return nil, fmt.Errorf("node not found: %s", nodeID)
+		return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, nodeID)
 	}
 	return db.copyNodeRecord(node), nil
 }
@@ -112,6 +119,31 @@ func (db *InMemDB) UpdateNodeHeartbeat(ctx context.Context, nodeID string, times
 	return nil
 }
 
+// UpdateNodeGPUHealth replaces the health of a node's GPUs.
+func (db *InMemDB) UpdateNodeGPUHealth(ctx context.Context, nodeID string, gpus []*GPUHealthRecord) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	node, ok := db.nodes[nodeID]
+	if !ok {
+		return fmt.Errorf("node not found: %s", nodeID)
+	}
+	node.GPUHealth = copyGPUHealth(gpus)
+	return nil
+}
+
+// UpdateNodeHold sets why and until when a node is held in its status.
+func (db *InMemDB) UpdateNodeHold(ctx context.Context, nodeID, reason string, until time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	node, ok := db.nodes[nodeID]
+	if !ok {
+		return fmt.Errorf("node not found: %s", nodeID)
+	}
+	node.StatusReason = reason
+	node.HoldUntil = until
+	return nil
+}
+
 // ListNodes returns all registered nodes.
 func (db *InMemDB) ListNodes(ctx context.Context) ([]*NodeRecord, error) {
 	db.mu.RLock()
@@ -125,13 +157,21 @@ func (db *InMemDB) ListNodes(ctx context.Context) ([]*NodeRecord, error) {
 	return nodes, nil
 }
 
-// DeleteNode removes a node from the database.
+// DeleteNode removes a node from the database. Its commands are kept for
+// the audit trail, and those not yet finished are marked failed.
 func (db *InMemDB) DeleteNode(ctx context.Context, nodeID string) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 	delete(db.nodes, nodeID)
 	delete(db.healthChecks, nodeID)
-	delete(db.nodeCommands, nodeID)
+	now := db.clock.Now()
+	for _, cmd := range db.nodeCommands[nodeID] {
+		if !commandFinished(cmd.Status) {
+			cmd.Status = CommandStatusFailed
+			cmd.Message = commandNodeDeletedMessage
+			cmd.UpdatedAt = now
+		}
+	}
 	return nil
 }
 
@@ -177,13 +217,139 @@ func (db *InMemDB) GetLatestHealthCheck(ctx context.Context, nodeID string) (*He
 	return checks[len(checks)-1], nil
 }
 
+// RecordHealthEvents stores raw health events.
+func (db *InMemDB) RecordHealthEvents(ctx context.Context, records []*HealthEventRecord) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, record := range records {
+		db.healthEvents = append(db.healthEvents, db.copyHealthEventRecord(record))
+	}
+	return nil
+}
+
+// ListHealthEvents returns the health events that match filter, newest first.
+func (db *InMemDB) ListHealthEvents(ctx context.Context, filter HealthEventFilter) ([]*HealthEventRecord, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var events []*HealthEventRecord
+	for i := len(db.healthEvents) - 1; i >= 0; i-- {
+		record := db.healthEvents[i]
+		if filter.NodeID != "" && record.NodeID != filter.NodeID {
+			continue
+		}
+		if filter.GPUUUID != "" && record.Event.GetGpuUuid() != filter.GPUUUID {
+			continue
+		}
+		t := record.Time()
+		if !filter.Since.IsZero() && t.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && !t.Before(filter.Until) {
+			continue
+		}
+		events = append(events, db.copyHealthEventRecord(record))
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time().After(events[j].Time())
+	})
+	if filter.Limit > 0 && len(events) > filter.Limit {
+		events = events[:filter.Limit]
+	}
+	return events, nil
+}
+
+// DeleteHealthEventsBefore removes health events received before cutoff.
+func (db *InMemDB) DeleteHealthEventsBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	kept := db.healthEvents[:0]
+	for _, record := range db.healthEvents {
+		if record.ReceivedAt.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, record)
+	}
+	deleted := len(db.healthEvents) - len(kept)
+	clear(db.healthEvents[len(kept):])
+	db.healthEvents = kept
+	return deleted, nil
+}
+
+func (db *InMemDB) copyHealthEventRecord(src *HealthEventRecord) *HealthEventRecord {
+	dst := *src
+	if src.Event != nil {
+		dst.Event = proto.Clone(src.Event).(*pb.HealthEvent)
+	}
+	return &dst
+}
+
+// PutGPU creates or replaces a GPU record.
+func (db *InMemDB) PutGPU(ctx context.Context, record *GPURecord) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.gpus[record.UUID] = copyGPURecord(record)
+	return nil
+}
+
+// GetGPU retrieves a GPU by UUID.
+func (db *InMemDB) GetGPU(ctx context.Context, uuid string) (*GPURecord, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	gpu, ok := db.gpus[uuid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrGPUNotFound, uuid)
+	}
+	return copyGPURecord(gpu), nil
+}
+
+// ListGPUs returns the GPUs matching filter, ordered by UUID.
+func (db *InMemDB) ListGPUs(ctx context.Context, filter GPUFilter) ([]*GPURecord, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	gpus := make([]*GPURecord, 0)
+	for _, gpu := range db.gpus {
+		if filter.NodeID != "" && gpu.NodeID != filter.NodeID {
+			continue
+		}
+		if gpu.FailureCount < filter.MinFailures {
+			continue
+		}
+		gpus = append(gpus, copyGPURecord(gpu))
+	}
+	sort.Slice(gpus, func(i, j int) bool {
+		return gpus[i].UUID < gpus[j].UUID
+	})
+	return gpus, nil
+}
+
+func copyGPURecord(src *GPURecord) *GPURecord {
+	dst := *src
+	if src.Nodes != nil {
+		dst.Nodes = make([]*GPUAssignment, len(src.Nodes))
+		for i, a := range src.Nodes {
+			c := *a
+			dst.Nodes[i] = &c
+		}
+	}
+	if src.XIDCounts != nil {
+		dst.XIDCounts = make(map[int]int, len(src.XIDCounts))
+		for k, v := range src.XIDCounts {
+			dst.XIDCounts[k] = v
+		}
+	}
+	return &dst
+}
+
 // CreateCommand creates a new command for a node.
 func (db *InMemDB) CreateCommand(ctx context.Context, record *CommandRecord) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 	
 	if record.Status == "" {
-		record.Status = "pending"
+		record.Status = CommandStatusPending
 	}
 	
 	db.commands[record.CommandID] = record
@@ -201,7 +367,7 @@ func (db *InMemDB) GetPendingCommands(ctx context.Context, nodeID string) ([]*Co
 	pending := make([]*CommandRecord, 0)
 
 	for _, cmd := range commands {
-		if cmd.Status == "pending" {
+		if cmd.Status == CommandStatusPending {
 			pending = append(pending, db.copyCommandRecord(cmd))
 		}
 	}
@@ -220,9 +386,124 @@ func (db *InMemDB) UpdateCommandStatus(ctx context.Context, commandID, status st
 	}
 	
 	cmd.Status = status
+	cmd.UpdatedAt = db.clock.Now()
+	return nil
+}
+
+// GetCommand retrieves a command by ID.
+func (db *InMemDB) GetCommand(ctx context.Context, commandID string) (*CommandRecord, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	cmd, ok := db.commands[commandID]
+	if !ok {
+		return nil, fmt.Errorf("command not found: %s", commandID)
+	}
+	return db.copyCommandRecord(cmd), nil
+}
+
+// ListCommands returns the commands matching filter, oldest first.
+func (db *InMemDB) ListCommands(ctx context.Context, filter CommandFilter) ([]*CommandRecord, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var nodeIDs []string
+	if filter.NodeID != "" {
+		nodeIDs = []string{filter.NodeID}
+	} else {
+		for id := range db.nodeCommands {
+			nodeIDs = append(nodeIDs, id)
+		}
+		sort.Strings(nodeIDs)
+	}
+
+	commands := make([]*CommandRecord, 0)
+	for _, id := range nodeIDs {
+		for _, cmd := range db.nodeCommands[id] {
+			if filter.Type != pb.NodeCommandType_NODE_COMMAND_TYPE_UNKNOWN && cmd.Type != filter.Type {
+				continue
+			}
+			if filter.Status != "" && cmd.Status != filter.Status {
+				continue
+			}
+			if filter.IssuedBy != "" && cmd.IssuedBy != filter.IssuedBy {
+				continue
+			}
+			if !filter.IssuedAfter.IsZero() && cmd.IssuedAt.Before(filter.IssuedAfter) {
+				continue
+			}
+			if !filter.IssuedBefore.IsZero() && !cmd.IssuedAt.Before(filter.IssuedBefore) {
+				continue
+			}
+			commands = append(commands, db.copyCommandRecord(cmd))
+		}
+	}
+	sort.SliceStable(commands, func(i, j int) bool {
+		return commands[i].IssuedAt.Before(commands[j].IssuedAt)
+	})
+	if filter.Limit > 0 && len(commands) > filter.Limit {
+		commands = commands[len(commands)-filter.Limit:]
+	}
+	return commands, nil
+}
+
+// MarkCommandDelivered records that a command was returned to its node.
+func (db *InMemDB) MarkCommandDelivered(ctx context.Context, commandID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	cmd, ok := db.commands[commandID]
+	if !ok {
+		return fmt.Errorf("command not found: %s", commandID)
+	}
+	cmd.Status = CommandStatusDelivered
+	cmd.DeliveredAt = db.clock.Now()
+	cmd.DeliveryAttempts++
+	return nil
+}
+
+// UpdateCommandResult records a status update reported for a command.
+func (db *InMemDB) UpdateCommandResult(ctx context.Context, commandID, status, message, output string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	cmd, ok := db.commands[commandID]
+	if !ok {
+		return fmt.Errorf("command not found: %s", commandID)
+	}
+	cmd.Status = status
+	cmd.Message = message
+	cmd.Output = output
+	cmd.UpdatedAt = db.clock.Now()
 	return nil
 }
 
+// DeleteCommandsBefore removes finished commands issued before cutoff.
+func (db *InMemDB) DeleteCommandsBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	deleted := 0
+	for nodeID, cmds := range db.nodeCommands {
+		kept := cmds[:0]
+		for _, cmd := range cmds {
+			if commandFinished(cmd.Status) && cmd.IssuedAt.Before(cutoff) {
+				delete(db.commands, cmd.CommandID)
+				deleted++
+				continue
+			}
+			kept = append(kept, cmd)
+		}
+		clear(cmds[len(kept):])
+		if len(kept) == 0 {
+			delete(db.nodeCommands, nodeID)
+		} else {
+			db.nodeCommands[nodeID] = kept
+		}
+	}
+	return deleted, nil
+}
+
 // copyNodeRecord creates a deep copy of a NodeRecord to prevent data races.
 // Pass-by-value won't work here because NodeRecord contains pointer fields
 // (Metadata, Config, GPUs) that would share underlying protobuf data.
@@ -242,6 +523,8 @@ func (db *InMemDB) copyNodeRecord(src *NodeRecord) *NodeRecord {
 		LastHealthCheck: src.LastHealthCheck,
 		HealthStatus:    src.HealthStatus,
 		RegisteredAt:    src.RegisteredAt,
+		StatusReason:    src.StatusReason,
+		HoldUntil:       src.HoldUntil,
 	}
 
 	if src.Metadata != nil {
@@ -261,6 +544,20 @@ func (db *InMemDB) copyNodeRecord(src *NodeRecord) *NodeRecord {
 		}
 	}
 
+	dst.GPUHealth = copyGPUHealth(src.GPUHealth)
+
+	return dst
+}
+
+func copyGPUHealth(src []*GPUHealthRecord) []*GPUHealthRecord {
+	if len(src) == 0 {
+		return nil
+	}
+	dst := make([]*GPUHealthRecord, len(src))
+	for i, g := range src {
+		c := *g
+		dst[i] = &c
+	}
 	return dst
 }
 
@@ -273,11 +570,17 @@ func (db *InMemDB) copyCommandRecord(src *CommandRecord) *CommandRecord {
 	}
 
 	dst := &CommandRecord{
-		CommandID: src.CommandID,
-		NodeID:    src.NodeID,
-		Type:      src.Type,
-		IssuedAt:  src.IssuedAt,
-		Status:    src.Status,
+		CommandID:        src.CommandID,
+		NodeID:           src.NodeID,
+		Type:             src.Type,
+		IssuedAt:         src.IssuedAt,
+		IssuedBy:         src.IssuedBy,
+		Status:           src.Status,
+		DeliveredAt:      src.DeliveredAt,
+		DeliveryAttempts: src.DeliveryAttempts,
+		Message:          src.Message,
+		Output:           src.Output,
+		UpdatedAt:        src.UpdatedAt,
 	}
 
 	if src.Parameters != nil {
@@ -537,7 +840,8 @@ func (db *InMemDB) GetBootstrapLogs(ctx context.Context, nodeID string) ([]*Boot
 	return result, nil
 }
 
-// ListBootstrapLogsByPool returns recent bootstrap logs for a pool.
+// ListBootstrapLogsByPool returns up to limit of the most recent bootstrap
+// logs for a pool, newest first. A limit of 0 returns all logs.
 func (db *InMemDB) ListBootstrapLogsByPool(ctx context.Context, pool string, limit int) ([]*BootstrapLogRecord, error) {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
@@ -552,9 +856,11 @@ func (db *InMemDB) ListBootstrapLogsByPool(ctx context.Context, pool string, lim
 	}
 
 	// Sort by start time descending and limit
-	// For simplicity, just return up to limit (already in order per node)
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].StartedAt.After(all[j].StartedAt)
+	})
 	if limit > 0 && len(all) > limit {
-		all = all[len(all)-limit:]
+		all = all[:limit]
 	}
 
 	return all, nil
@@ -0,0 +1,1464 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	_ "modernc.org/sqlite"
+
+	"github.com/NavarchProject/navarch/pkg/clock"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+const (
+	// maxMetricsPerNode matches the retention of InMemDB.
+	maxMetricsPerNode = 100
+
+	// maxBootstrapLogsPerNode matches the retention of InMemDB.
+	maxBootstrapLogsPerNode = 50
+)
+
+// migrations are applied in order. The index of each entry plus one is the
+// schema version it produces, tracked in SQLite's user_version pragma.
+// Never edit an existing entry; append a new one instead.
+var migrations = []string{
+	`
+	CREATE TABLE nodes (
+		node_id           TEXT PRIMARY KEY,
+		provider          TEXT NOT NULL DEFAULT '',
+		region            TEXT NOT NULL DEFAULT '',
+		zone              TEXT NOT NULL DEFAULT '',
+		instance_type     TEXT NOT NULL DEFAULT '',
+		gpus              BLOB,
+		metadata          BLOB,
+		config            BLOB,
+		status            INTEGER NOT NULL DEFAULT 0,
+		health_status     INTEGER NOT NULL DEFAULT 0,
+		last_heartbeat    INTEGER NOT NULL DEFAULT 0,
+		last_health_check INTEGER NOT NULL DEFAULT 0,
+		registered_at     INTEGER NOT NULL DEFAULT 0
+	);
+
+	CREATE TABLE health_checks (
+		seq       INTEGER PRIMARY KEY AUTOINCREMENT,
+		node_id   TEXT NOT NULL,
+		timestamp INTEGER NOT NULL DEFAULT 0,
+		results   BLOB
+	);
+	CREATE INDEX idx_health_checks_node ON health_checks(node_id, seq);
+
+	CREATE TABLE commands (
+		seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+		command_id TEXT NOT NULL UNIQUE,
+		node_id    TEXT NOT NULL,
+		type       INTEGER NOT NULL DEFAULT 0,
+		parameters TEXT,
+		issued_at  INTEGER NOT NULL DEFAULT 0,
+		status     TEXT NOT NULL
+	);
+	CREATE INDEX idx_commands_node ON commands(node_id, status, seq);
+
+	CREATE TABLE metrics (
+		seq       INTEGER PRIMARY KEY AUTOINCREMENT,
+		node_id   TEXT NOT NULL,
+		timestamp INTEGER NOT NULL DEFAULT 0,
+		metrics   BLOB
+	);
+	CREATE INDEX idx_metrics_node ON metrics(node_id, seq);
+
+	CREATE TABLE instances (
+		instance_id    TEXT PRIMARY KEY,
+		provider       TEXT NOT NULL DEFAULT '',
+		region         TEXT NOT NULL DEFAULT '',
+		zone           TEXT NOT NULL DEFAULT '',
+		instance_type  TEXT NOT NULL DEFAULT '',
+		state          INTEGER NOT NULL DEFAULT 0,
+		pool_name      TEXT NOT NULL DEFAULT '',
+		created_at     INTEGER NOT NULL DEFAULT 0,
+		ready_at       INTEGER NOT NULL DEFAULT 0,
+		terminated_at  INTEGER NOT NULL DEFAULT 0,
+		node_id        TEXT NOT NULL DEFAULT '',
+		status_message TEXT NOT NULL DEFAULT '',
+		labels         TEXT
+	);
+	CREATE INDEX idx_instances_state ON instances(state);
+	CREATE INDEX idx_instances_pool ON instances(pool_name);
+
+	CREATE TABLE bootstrap_logs (
+		seq           INTEGER PRIMARY KEY AUTOINCREMENT,
+		id            TEXT NOT NULL DEFAULT '',
+		node_id       TEXT NOT NULL,
+		instance_id   TEXT NOT NULL DEFAULT '',
+		pool          TEXT NOT NULL DEFAULT '',
+		started_at    INTEGER NOT NULL DEFAULT 0,
+		duration      INTEGER NOT NULL DEFAULT 0,
+		ssh_wait_time INTEGER NOT NULL DEFAULT 0,
+		success       INTEGER NOT NULL DEFAULT 0,
+		error         TEXT NOT NULL DEFAULT '',
+		commands      TEXT
+	);
+	CREATE INDEX idx_bootstrap_logs_node ON bootstrap_logs(node_id, seq);
+	CREATE INDEX idx_bootstrap_logs_pool ON bootstrap_logs(pool, seq);
+	`,
+	`
+	ALTER TABLE commands ADD COLUMN delivered_at INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE commands ADD COLUMN delivery_attempts INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE commands ADD COLUMN message TEXT NOT NULL DEFAULT '';
+	ALTER TABLE commands ADD COLUMN output TEXT NOT NULL DEFAULT '';
+	ALTER TABLE commands ADD COLUMN updated_at INTEGER NOT NULL DEFAULT 0;
+	`,
+	`
+	ALTER TABLE commands ADD COLUMN issued_by TEXT NOT NULL DEFAULT '';
+	`,
+	`
+	ALTER TABLE nodes ADD COLUMN gpu_health TEXT;
+	`,
+	`
+	CREATE TABLE health_events (
+		seq          INTEGER PRIMARY KEY AUTOINCREMENT,
+		node_id      TEXT NOT NULL,
+		gpu_uuid     TEXT NOT NULL DEFAULT '',
+		timestamp    INTEGER NOT NULL DEFAULT 0,
+		received_at  INTEGER NOT NULL DEFAULT 0,
+		event        BLOB,
+		matched_rule TEXT NOT NULL DEFAULT '',
+		result       INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX idx_health_events_node ON health_events(node_id, timestamp);
+	CREATE INDEX idx_health_events_gpu ON health_events(gpu_uuid, timestamp);
+	CREATE INDEX idx_health_events_received ON health_events(received_at);
+	`,
+	`
+	CREATE TABLE gpus (
+		uuid          TEXT PRIMARY KEY,
+		name          TEXT NOT NULL DEFAULT '',
+		node_id       TEXT NOT NULL DEFAULT '',
+		nodes         TEXT,
+		first_seen    INTEGER NOT NULL DEFAULT 0,
+		last_seen     INTEGER NOT NULL DEFAULT 0,
+		event_count   INTEGER NOT NULL DEFAULT 0,
+		failure_count INTEGER NOT NULL DEFAULT 0,
+		last_failure  INTEGER NOT NULL DEFAULT 0,
+		xid_counts    TEXT
+	);
+	`,
+	`
+	ALTER TABLE nodes ADD COLUMN status_reason TEXT NOT NULL DEFAULT '';
+	ALTER TABLE nodes ADD COLUMN hold_until INTEGER NOT NULL DEFAULT 0;
+	`,
+	`
+	CREATE INDEX idx_commands_issued ON commands(issued_at);
+	`,
+	`
+	CREATE INDEX idx_gpus_node ON gpus(node_id);
+	CREATE INDEX idx_gpus_failures ON gpus(failure_count);
+	`,
+}
+
+// SQLiteDB is a durable implementation of the DB interface backed by SQLite.
+// State survives control plane restarts, so instances provisioned before a
+// restart are still tracked afterwards.
+type SQLiteDB struct {
+	db    *sql.DB
+	clock clock.Clock
+}
+
+// NewSQLiteDB opens (or creates) a SQLite database at path and applies any
+// pending schema migrations.
+func NewSQLiteDB(path string) (*SQLiteDB, error) {
+	return NewSQLiteDBWithClock(path, clock.Real())
+}
+
+// NewSQLiteDBWithClock opens a SQLite database with a custom clock.
+func NewSQLiteDBWithClock(path string, clk clock.Clock) (*SQLiteDB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sqlite path is required")
+	}
+	if clk == nil {
+		clk = clock.Real()
+	}
+
+	// SQLite decodes the path of a file URI, so it is escaped here so that
+	// "?", "#" and "%" in it are not read as URI syntax.
+	dsn := url.URL{
+		Scheme:   "file",
+		Opaque:   (&url.URL{Path: path}).EscapedPath(),
+		RawQuery: "_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)",
+	}
+	sqlDB, err := sql.Open("sqlite", dsn.String())
+	if err != nil {
+		return nil, fmt.Errorf("opening sqlite database: %w", err)
+	}
+	// SQLite allows a single writer at a time. Serializing through one
+	// connection avoids SQLITE_BUSY errors and keeps ":memory:" databases
+	// from being split across connections.
+	sqlDB.SetMaxOpenConns(1)
+
+	s := &SQLiteDB{db: sqlDB, clock: clk}
+	if err := s.migrate(context.Background()); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// migrate applies all migrations newer than the stored schema version.
+func (s *SQLiteDB) migrate(ctx context.Context) error {
+	var version int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("reading schema version: %w", err)
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than supported version %d", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("beginning migration %d: %w", i+1, err)
+		}
+		if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("applying migration %d: %w", i+1, err)
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("recording migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("committing migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// SchemaVersion returns the currently applied schema version.
+func (s *SQLiteDB) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// RegisterNode registers a new node or updates an existing one.
+func (s *SQLiteDB) RegisterNode(ctx context.Context, record *NodeRecord) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var registeredAt, lastHeartbeat, lastHealthCheck, holdUntil int64
+	var gpuHealth sql.NullString
+	var statusReason string
+	err = tx.QueryRowContext(ctx,
+		`SELECT registered_at, last_heartbeat, last_health_check, gpu_health, status_reason, hold_until FROM nodes WHERE node_id = ?`,
+		record.NodeID,
+	).Scan(&registeredAt, &lastHeartbeat, &lastHealthCheck, &gpuHealth, &statusReason, &holdUntil)
+	switch {
+	case err == nil:
+		record.RegisteredAt = fromUnixNano(registeredAt)
+		record.LastHeartbeat = fromUnixNano(lastHeartbeat)
+		record.LastHealthCheck = fromUnixNano(lastHealthCheck)
+		record.StatusReason = statusReason
+		record.HoldUntil = fromUnixNano(holdUntil)
+		if record.GPUHealth, err = unmarshalGPUHealth(gpuHealth); err != nil {
+			return err
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		record.RegisteredAt = s.clock.Now()
+	default:
+		return err
+	}
+	if record.Status == pb.NodeStatus_NODE_STATUS_UNKNOWN {
+		record.Status = pb.NodeStatus_NODE_STATUS_ACTIVE
+	}
+
+	gpus, err := marshalGPUs(record.GPUs)
+	if err != nil {
+		return err
+	}
+	metadata, err := marshalProto(record.Metadata)
+	if err != nil {
+		return err
+	}
+	cfg, err := marshalProto(record.Config)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT OR REPLACE INTO nodes (
+			node_id, provider, region, zone, instance_type, gpus, metadata, config,
+			status, health_status, last_heartbeat, last_health_check, registered_at, gpu_health,
+			status_reason, hold_until
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.NodeID, record.Provider, record.Region, record.Zone, record.InstanceType,
+		gpus, metadata, cfg,
+		int32(record.Status), int32(record.HealthStatus),
+		toUnixNano(record.LastHeartbeat), toUnixNano(record.LastHealthCheck), toUnixNano(record.RegisteredAt),
+		gpuHealth, record.StatusReason, toUnixNano(record.HoldUntil),
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetNode retrieves a node by ID.
+func (s *SQLiteDB) GetNode(ctx context.Context, nodeID string) (*NodeRecord, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT node_id, provider, region, zone, instance_type, gpus, metadata, config,
+			status, health_status, last_heartbeat, last_health_check, registered_at, gpu_health,
+			status_reason, hold_until
+		FROM nodes WHERE node_id = ?`, nodeID)
+	node, err := scanNode(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrNodeNotFound, nodeID)
+	}
+	return node, err
+}
+
+// UpdateNodeStatus updates the status of a node.
+func (s *SQLiteDB) UpdateNodeStatus(ctx context.Context, nodeID string, status pb.NodeStatus) error {
+	return s.updateNode(ctx, nodeID, `UPDATE nodes SET status = ? WHERE node_id = ?`, int32(status))
+}
+
+// UpdateNodeHealthStatus updates the health status of a node.
+func (s *SQLiteDB) UpdateNodeHealthStatus(ctx context.Context, nodeID string, health pb.HealthStatus) error {
+	return s.updateNode(ctx, nodeID, `UPDATE nodes SET health_status = ? WHERE node_id = ?`, int32(health))
+}
+
+// UpdateNodeHeartbeat updates the last heartbeat time for a node.
+func (s *SQLiteDB) UpdateNodeHeartbeat(ctx context.Context, nodeID string, timestamp time.Time) error {
+	return s.updateNode(ctx, nodeID, `UPDATE nodes SET last_heartbeat = ? WHERE node_id = ?`, toUnixNano(timestamp))
+}
+
+// UpdateNodeGPUHealth replaces the health of a node's GPUs.
+func (s *SQLiteDB) UpdateNodeGPUHealth(ctx context.Context, nodeID string, gpus []*GPUHealthRecord) error {
+	gpuHealth, err := marshalGPUHealth(gpus)
+	if err != nil {
+		return err
+	}
+	return s.updateNode(ctx, nodeID, `UPDATE nodes SET gpu_health = ? WHERE node_id = ?`, gpuHealth)
+}
+
+// UpdateNodeHold sets why and until when a node is held in its status.
+func (s *SQLiteDB) UpdateNodeHold(ctx context.Context, nodeID, reason string, until time.Time) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE nodes SET status_reason = ?, hold_until = ? WHERE node_id = ?`,
+		reason, toUnixNano(until), nodeID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("node not found: %s", nodeID)
+	}
+	return nil
+}
+
+func (s *SQLiteDB) updateNode(ctx context.Context, nodeID, query string, value any) error {
+	res, err := s.db.ExecContext(ctx, query, value, nodeID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("node not found: %s", nodeID)
+	}
+	return nil
+}
+
+// ListNodes returns all registered nodes.
+func (s *SQLiteDB) ListNodes(ctx context.Context) ([]*NodeRecord, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT node_id, provider, region, zone, instance_type, gpus, metadata, config,
+			status, health_status, last_heartbeat, last_health_check, registered_at, gpu_health,
+			status_reason, hold_until
+		FROM nodes ORDER BY node_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := make([]*NodeRecord, 0)
+	for rows.Next() {
+		node, err := scanNode(rows)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, rows.Err()
+}
+
+// DeleteNode removes a node and its health check and metrics history. Its
+// commands are kept for the audit trail, and those not yet finished are
+// marked failed.
+func (s *SQLiteDB) DeleteNode(ctx context.Context, nodeID string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"nodes", "health_checks", "metrics"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE node_id = ?", nodeID); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE commands SET status = ?, message = ?, updated_at = ?
+		WHERE node_id = ? AND status NOT IN (?, ?)`,
+		CommandStatusFailed, commandNodeDeletedMessage, toUnixNano(s.clock.Now()),
+		nodeID, CommandStatusCompleted, CommandStatusFailed,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RecordHealthCheck stores a health check result and updates the node's
+// health status. Like InMemDB, unhealthy nodes do not auto-recover.
+func (s *SQLiteDB) RecordHealthCheck(ctx context.Context, record *HealthCheckRecord) error {
+	results, err := marshalHealthResults(record.Results)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO health_checks (node_id, timestamp, results) VALUES (?, ?, ?)`,
+		record.NodeID, toUnixNano(record.Timestamp), results,
+	); err != nil {
+		return err
+	}
+
+	overallStatus := pb.HealthStatus_HEALTH_STATUS_HEALTHY
+	for _, result := range record.Results {
+		if result.Status == pb.HealthStatus_HEALTH_STATUS_UNHEALTHY {
+			overallStatus = pb.HealthStatus_HEALTH_STATUS_UNHEALTHY
+			break
+		}
+		if result.Status == pb.HealthStatus_HEALTH_STATUS_DEGRADED &&
+			overallStatus == pb.HealthStatus_HEALTH_STATUS_HEALTHY {
+			overallStatus = pb.HealthStatus_HEALTH_STATUS_DEGRADED
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE nodes SET last_health_check = ?, health_status = ? WHERE node_id = ?`,
+		toUnixNano(record.Timestamp), int32(overallStatus), record.NodeID,
+	); err != nil {
+		return err
+	}
+	if overallStatus == pb.HealthStatus_HEALTH_STATUS_UNHEALTHY {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE nodes SET status = ? WHERE node_id = ?`,
+			int32(pb.NodeStatus_NODE_STATUS_UNHEALTHY), record.NodeID,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetLatestHealthCheck retrieves the most recent health check for a node.
+func (s *SQLiteDB) GetLatestHealthCheck(ctx context.Context, nodeID string) (*HealthCheckRecord, error) {
+	var ts int64
+	var results []byte
+	err := s.db.QueryRowContext(ctx,
+		`SELECT timestamp, results FROM health_checks WHERE node_id = ? ORDER BY seq DESC LIMIT 1`,
+		nodeID,
+	).Scan(&ts, &results)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("no health checks found for node: %s", nodeID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record := &HealthCheckRecord{NodeID: nodeID, Timestamp: fromUnixNano(ts)}
+	if record.Results, err = unmarshalHealthResults(results); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// RecordHealthEvents stores raw health events.
+func (s *SQLiteDB) RecordHealthEvents(ctx context.Context, records []*HealthEventRecord) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, record := range records {
+		event, err := marshalProto(record.Event)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO health_events (node_id, gpu_uuid, timestamp, received_at, event, matched_rule, result)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			record.NodeID, record.Event.GetGpuUuid(), toUnixNano(record.Time()), toUnixNano(record.ReceivedAt),
+			event, record.MatchedRule, int32(record.Result),
+		); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListHealthEvents returns the health events that match filter, newest first.
+func (s *SQLiteDB) ListHealthEvents(ctx context.Context, filter HealthEventFilter) ([]*HealthEventRecord, error) {
+	query := `SELECT node_id, received_at, event, matched_rule, result FROM health_events WHERE 1 = 1`
+	var args []any
+	if filter.NodeID != "" {
+		query += ` AND node_id = ?`
+		args = append(args, filter.NodeID)
+	}
+	if filter.GPUUUID != "" {
+		query += ` AND gpu_uuid = ?`
+		args = append(args, filter.GPUUUID)
+	}
+	if !filter.Since.IsZero() {
+		query += ` AND timestamp >= ?`
+		args = append(args, filter.Since.UnixNano())
+	}
+	if !filter.Until.IsZero() {
+		query += ` AND timestamp < ?`
+		args = append(args, filter.Until.UnixNano())
+	}
+	query += ` ORDER BY timestamp DESC, seq DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*HealthEventRecord
+	for rows.Next() {
+		var (
+			record     HealthEventRecord
+			receivedAt int64
+			data       []byte
+			result     int32
+		)
+		if err := rows.Scan(&record.NodeID, &receivedAt, &data, &record.MatchedRule, &result); err != nil {
+			return nil, err
+		}
+		record.ReceivedAt = fromUnixNano(receivedAt)
+		record.Result = pb.HealthStatus(result)
+		if data != nil {
+			record.Event = &pb.HealthEvent{}
+			if err := proto.Unmarshal(data, record.Event); err != nil {
+				return nil, fmt.Errorf("decoding health event: %w", err)
+			}
+		}
+		events = append(events, &record)
+	}
+	return events, rows.Err()
+}
+
+// DeleteHealthEventsBefore removes health events received before cutoff.
+func (s *SQLiteDB) DeleteHealthEventsBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM health_events WHERE received_at < ?`, cutoff.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// PutGPU creates or replaces a GPU record.
+func (s *SQLiteDB) PutGPU(ctx context.Context, record *GPURecord) error {
+	nodes, err := marshalGPUAssignments(record.Nodes)
+	if err != nil {
+		return err
+	}
+	xidCounts, err := marshalXIDCounts(record.XIDCounts)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO gpus (
+			uuid, name, node_id, nodes, first_seen, last_seen,
+			event_count, failure_count, last_failure, xid_counts
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.UUID, record.Name, record.NodeID, nodes,
+		toUnixNano(record.FirstSeen), toUnixNano(record.LastSeen),
+		record.EventCount, record.FailureCount, toUnixNano(record.LastFailure), xidCounts,
+	)
+	return err
+}
+
+// GetGPU retrieves a GPU by UUID.
+func (s *SQLiteDB) GetGPU(ctx context.Context, uuid string) (*GPURecord, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT uuid, name, node_id, nodes, first_seen, last_seen,
+			event_count, failure_count, last_failure, xid_counts
+		FROM gpus WHERE uuid = ?`, uuid)
+	gpu, err := scanGPU(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrGPUNotFound, uuid)
+	}
+	return gpu, err
+}
+
+// ListGPUs returns the GPUs matching filter, ordered by UUID.
+func (s *SQLiteDB) ListGPUs(ctx context.Context, filter GPUFilter) ([]*GPURecord, error) {
+	query := `
+		SELECT uuid, name, node_id, nodes, first_seen, last_seen,
+			event_count, failure_count, last_failure, xid_counts
+		FROM gpus WHERE 1 = 1`
+	var args []any
+	if filter.NodeID != "" {
+		query += ` AND node_id = ?`
+		args = append(args, filter.NodeID)
+	}
+	if filter.MinFailures > 0 {
+		query += ` AND failure_count >= ?`
+		args = append(args, filter.MinFailures)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY uuid`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	gpus := make([]*GPURecord, 0)
+	for rows.Next() {
+		gpu, err := scanGPU(rows)
+		if err != nil {
+			return nil, err
+		}
+		gpus = append(gpus, gpu)
+	}
+	return gpus, rows.Err()
+}
+
+// CreateCommand creates a new command for a node.
+func (s *SQLiteDB) CreateCommand(ctx context.Context, record *CommandRecord) error {
+	if record.Status == "" {
+		record.Status = CommandStatusPending
+	}
+
+	params, err := marshalStringMap(record.Parameters)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO commands (
+			command_id, node_id, type, parameters, issued_at, status,
+			delivered_at, delivery_attempts, message, output, updated_at, issued_by
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.CommandID, record.NodeID, int32(record.Type), params, toUnixNano(record.IssuedAt), record.Status,
+		toUnixNano(record.DeliveredAt), record.DeliveryAttempts, record.Message, record.Output, toUnixNano(record.UpdatedAt),
+		record.IssuedBy,
+	)
+	return err
+}
+
+const commandColumns = `command_id, node_id, type, parameters, issued_at, status,
+	delivered_at, delivery_attempts, message, output, updated_at, issued_by`
+
+// GetPendingCommands retrieves all pending commands for a node.
+func (s *SQLiteDB) GetPendingCommands(ctx context.Context, nodeID string) ([]*CommandRecord, error) {
+	return s.queryCommands(ctx, `SELECT `+commandColumns+`
+		FROM commands WHERE node_id = ? AND status = ? ORDER BY seq`, nodeID, CommandStatusPending)
+}
+
+// UpdateCommandStatus updates the status of a command.
+func (s *SQLiteDB) UpdateCommandStatus(ctx context.Context, commandID, status string) error {
+	return s.updateCommand(ctx, commandID, `UPDATE commands SET status = ?, updated_at = ? WHERE command_id = ?`,
+		status, toUnixNano(s.clock.Now()), commandID)
+}
+
+// GetCommand retrieves a command by ID.
+func (s *SQLiteDB) GetCommand(ctx context.Context, commandID string) (*CommandRecord, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+commandColumns+` FROM commands WHERE command_id = ?`, commandID)
+	cmd, err := scanCommand(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("command not found: %s", commandID)
+	}
+	return cmd, err
+}
+
+// ListCommands returns the commands matching filter, oldest first.
+func (s *SQLiteDB) ListCommands(ctx context.Context, filter CommandFilter) ([]*CommandRecord, error) {
+	query := `SELECT ` + commandColumns + ` FROM commands WHERE 1 = 1`
+	var args []any
+	if filter.NodeID != "" {
+		query += ` AND node_id = ?`
+		args = append(args, filter.NodeID)
+	}
+	if filter.Type != pb.NodeCommandType_NODE_COMMAND_TYPE_UNKNOWN {
+		query += ` AND type = ?`
+		args = append(args, int32(filter.Type))
+	}
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+	if filter.IssuedBy != "" {
+		query += ` AND issued_by = ?`
+		args = append(args, filter.IssuedBy)
+	}
+	if !filter.IssuedAfter.IsZero() {
+		query += ` AND issued_at >= ?`
+		args = append(args, toUnixNano(filter.IssuedAfter))
+	}
+	if !filter.IssuedBefore.IsZero() {
+		query += ` AND issued_at < ?`
+		args = append(args, toUnixNano(filter.IssuedBefore))
+	}
+	// Select the most recent commands, then return them oldest first.
+	query += ` ORDER BY issued_at DESC, node_id DESC, seq DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	commands, err := s.queryCommands(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(commands)
+	return commands, nil
+}
+
+// MarkCommandDelivered records that a command was returned to its node.
+func (s *SQLiteDB) MarkCommandDelivered(ctx context.Context, commandID string) error {
+	return s.updateCommand(ctx, commandID, `
+		UPDATE commands SET status = ?, delivered_at = ?, delivery_attempts = delivery_attempts + 1
+		WHERE command_id = ?`,
+		CommandStatusDelivered, toUnixNano(s.clock.Now()), commandID,
+	)
+}
+
+// UpdateCommandResult records a status update reported for a command.
+func (s *SQLiteDB) UpdateCommandResult(ctx context.Context, commandID, status, message, output string) error {
+	return s.updateCommand(ctx, commandID, `
+		UPDATE commands SET status = ?, message = ?, output = ?, updated_at = ?
+		WHERE command_id = ?`,
+		status, message, output, toUnixNano(s.clock.Now()), commandID,
+	)
+}
+
+// DeleteCommandsBefore removes finished commands issued before cutoff.
+func (s *SQLiteDB) DeleteCommandsBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM commands WHERE issued_at < ? AND status IN (?, ?)`,
+		toUnixNano(cutoff), CommandStatusCompleted, CommandStatusFailed)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (s *SQLiteDB) updateCommand(ctx context.Context, commandID, query string, args ...any) error {
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("command not found: %s", commandID)
+	}
+	return nil
+}
+
+func (s *SQLiteDB) queryCommands(ctx context.Context, query string, args ...any) ([]*CommandRecord, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	commands := make([]*CommandRecord, 0)
+	for rows.Next() {
+		cmd, err := scanCommand(rows)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, cmd)
+	}
+	return commands, rows.Err()
+}
+
+func scanCommand(row rowScanner) (*CommandRecord, error) {
+	var (
+		cmd         CommandRecord
+		cmdType     int32
+		params      sql.NullString
+		issuedAt    int64
+		deliveredAt int64
+		updatedAt   int64
+	)
+	if err := row.Scan(&cmd.CommandID, &cmd.NodeID, &cmdType, &params, &issuedAt, &cmd.Status,
+		&deliveredAt, &cmd.DeliveryAttempts, &cmd.Message, &cmd.Output, &updatedAt, &cmd.IssuedBy); err != nil {
+		return nil, err
+	}
+	cmd.Type = pb.NodeCommandType(cmdType)
+	cmd.IssuedAt = fromUnixNano(issuedAt)
+	cmd.DeliveredAt = fromUnixNano(deliveredAt)
+	cmd.UpdatedAt = fromUnixNano(updatedAt)
+
+	var err error
+	if cmd.Parameters, err = unmarshalStringMap(params); err != nil {
+		return nil, err
+	}
+	return &cmd, nil
+}
+
+// RecordMetrics stores metrics from a node heartbeat.
+func (s *SQLiteDB) RecordMetrics(ctx context.Context, record *MetricsRecord) error {
+	metrics, err := marshalProto(record.Metrics)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if exists, err := nodeExists(ctx, tx, record.NodeID); err != nil {
+		return err
+	} else if !exists {
+		return fmt.Errorf("node %s not found", record.NodeID)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO metrics (node_id, timestamp, metrics) VALUES (?, ?, ?)`,
+		record.NodeID, toUnixNano(record.Timestamp), metrics,
+	); err != nil {
+		return err
+	}
+
+	// Keep only the most recent metrics per node
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM metrics WHERE node_id = ? AND seq NOT IN (
+			SELECT seq FROM metrics WHERE node_id = ? ORDER BY seq DESC LIMIT ?
+		)`, record.NodeID, record.NodeID, maxMetricsPerNode,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetRecentMetrics retrieves metrics for a node within the specified duration.
+func (s *SQLiteDB) GetRecentMetrics(ctx context.Context, nodeID string, duration time.Duration) ([]*MetricsRecord, error) {
+	if exists, err := nodeExists(ctx, s.db, nodeID); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("node %s not found", nodeID)
+	}
+
+	cutoff := s.clock.Now().Add(-duration)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT timestamp, metrics FROM metrics WHERE node_id = ? AND timestamp > ? ORDER BY seq`,
+		nodeID, cutoff.UnixNano(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recent []*MetricsRecord
+	for rows.Next() {
+		var ts int64
+		var data []byte
+		if err := rows.Scan(&ts, &data); err != nil {
+			return nil, err
+		}
+		record := &MetricsRecord{NodeID: nodeID, Timestamp: fromUnixNano(ts)}
+		if data != nil {
+			record.Metrics = &pb.NodeMetrics{}
+			if err := proto.Unmarshal(data, record.Metrics); err != nil {
+				return nil, fmt.Errorf("decoding metrics: %w", err)
+			}
+		}
+		recent = append(recent, record)
+	}
+	return recent, rows.Err()
+}
+
+// CreateInstance creates a new instance record.
+func (s *SQLiteDB) CreateInstance(ctx context.Context, record *InstanceRecord) error {
+	if record.InstanceID == "" {
+		return fmt.Errorf("instance_id is required")
+	}
+
+	labels, err := marshalStringMap(record.Labels)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	err = tx.QueryRowContext(ctx, `SELECT 1 FROM instances WHERE instance_id = ?`, record.InstanceID).Scan(&exists)
+	if err == nil {
+		return fmt.Errorf("instance already exists: %s", record.InstanceID)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO instances (
+			instance_id, provider, region, zone, instance_type, state, pool_name,
+			created_at, ready_at, terminated_at, node_id, status_message, labels
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.InstanceID, record.Provider, record.Region, record.Zone, record.InstanceType,
+		int32(record.State), record.PoolName,
+		toUnixNano(record.CreatedAt), toUnixNano(record.ReadyAt), toUnixNano(record.TerminatedAt),
+		record.NodeID, record.StatusMessage, labels,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetInstance retrieves an instance by ID.
+func (s *SQLiteDB) GetInstance(ctx context.Context, instanceID string) (*InstanceRecord, error) {
+	row := s.db.QueryRowContext(ctx, selectInstances+` WHERE instance_id = ?`, instanceID)
+	instance, err := scanInstance(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("instance not found: %s", instanceID)
+	}
+	return instance, err
+}
+
+// UpdateInstanceState updates the state and status message of an instance.
+func (s *SQLiteDB) UpdateInstanceState(ctx context.Context, instanceID string, state pb.InstanceState, message string) error {
+	// Set timestamps based on state transitions. A zero value leaves the
+	// column untouched.
+	var readyAt, terminatedAt int64
+	switch state {
+	case pb.InstanceState_INSTANCE_STATE_RUNNING:
+		readyAt = toUnixNano(s.clock.Now())
+	case pb.InstanceState_INSTANCE_STATE_TERMINATED:
+		terminatedAt = toUnixNano(s.clock.Now())
+	}
+
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE instances SET
+			state = ?,
+			status_message = ?,
+			ready_at = CASE WHEN ready_at = 0 THEN ? ELSE ready_at END,
+			terminated_at = CASE WHEN terminated_at = 0 THEN ? ELSE terminated_at END
+		WHERE instance_id = ?`,
+		int32(state), message, readyAt, terminatedAt, instanceID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("instance not found: %s", instanceID)
+	}
+	return nil
+}
+
+// UpdateInstanceNodeID links an instance to a registered node.
+func (s *SQLiteDB) UpdateInstanceNodeID(ctx context.Context, instanceID string, nodeID string) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE instances SET node_id = ? WHERE instance_id = ?`, nodeID, instanceID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("instance not found: %s", instanceID)
+	}
+	return nil
+}
+
+// ListInstances returns all instance records.
+func (s *SQLiteDB) ListInstances(ctx context.Context) ([]*InstanceRecord, error) {
+	instances, err := s.queryInstances(ctx, selectInstances+` ORDER BY created_at, instance_id`)
+	if err != nil {
+		return nil, err
+	}
+	if instances == nil {
+		instances = make([]*InstanceRecord, 0)
+	}
+	return instances, nil
+}
+
+// ListInstancesByState returns all instances in a specific state.
+func (s *SQLiteDB) ListInstancesByState(ctx context.Context, state pb.InstanceState) ([]*InstanceRecord, error) {
+	return s.queryInstances(ctx, selectInstances+` WHERE state = ? ORDER BY created_at, instance_id`, int32(state))
+}
+
+// ListInstancesByPool returns all instances belonging to a specific pool.
+func (s *SQLiteDB) ListInstancesByPool(ctx context.Context, poolName string) ([]*InstanceRecord, error) {
+	return s.queryInstances(ctx, selectInstances+` WHERE pool_name = ? ORDER BY created_at, instance_id`, poolName)
+}
+
+// DeleteInstance removes an instance record.
+func (s *SQLiteDB) DeleteInstance(ctx context.Context, instanceID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM instances WHERE instance_id = ?`, instanceID)
+	return err
+}
+
+const selectInstances = `
+	SELECT instance_id, provider, region, zone, instance_type, state, pool_name,
+		created_at, ready_at, terminated_at, node_id, status_message, labels
+	FROM instances`
+
+func (s *SQLiteDB) queryInstances(ctx context.Context, query string, args ...any) ([]*InstanceRecord, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var instances []*InstanceRecord
+	for rows.Next() {
+		instance, err := scanInstance(rows)
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, instance)
+	}
+	return instances, rows.Err()
+}
+
+// RecordBootstrapLog stores a bootstrap execution log.
+func (s *SQLiteDB) RecordBootstrapLog(ctx context.Context, record *BootstrapLogRecord) error {
+	var commands []byte
+	if len(record.Commands) > 0 {
+		var err error
+		if commands, err = json.Marshal(record.Commands); err != nil {
+			return fmt.Errorf("encoding bootstrap commands: %w", err)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO bootstrap_logs (
+			id, node_id, instance_id, pool, started_at, duration, ssh_wait_time, success, error, commands
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		record.ID, record.NodeID, record.InstanceID, record.Pool,
+		toUnixNano(record.StartedAt), int64(record.Duration), int64(record.SSHWaitTime),
+		record.Success, record.Error, nullableString(commands),
+	); err != nil {
+		return err
+	}
+
+	// Keep only the most recent bootstrap logs per node
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM bootstrap_logs WHERE node_id = ? AND seq NOT IN (
+			SELECT seq FROM bootstrap_logs WHERE node_id = ? ORDER BY seq DESC LIMIT ?
+		)`, record.NodeID, record.NodeID, maxBootstrapLogsPerNode,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetBootstrapLogs retrieves all bootstrap logs for a node.
+func (s *SQLiteDB) GetBootstrapLogs(ctx context.Context, nodeID string) ([]*BootstrapLogRecord, error) {
+	logs, err := s.queryBootstrapLogs(ctx, selectBootstrapLogs+` WHERE node_id = ? ORDER BY seq`, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if logs == nil {
+		logs = make([]*BootstrapLogRecord, 0)
+	}
+	return logs, nil
+}
+
+// ListBootstrapLogsByPool returns up to limit of the most recent bootstrap
+// logs for a pool, newest first. A limit of 0 returns all logs.
+func (s *SQLiteDB) ListBootstrapLogsByPool(ctx context.Context, pool string, limit int) ([]*BootstrapLogRecord, error) {
+	query := selectBootstrapLogs + ` WHERE pool = ? ORDER BY started_at DESC, seq DESC`
+	if limit > 0 {
+		return s.queryBootstrapLogs(ctx, query+` LIMIT ?`, pool, limit)
+	}
+	return s.queryBootstrapLogs(ctx, query, pool)
+}
+
+const selectBootstrapLogs = `
+	SELECT id, node_id, instance_id, pool, started_at, duration, ssh_wait_time, success, error, commands
+	FROM bootstrap_logs`
+
+func (s *SQLiteDB) queryBootstrapLogs(ctx context.Context, query string, args ...any) ([]*BootstrapLogRecord, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []*BootstrapLogRecord
+	for rows.Next() {
+		var (
+			log               BootstrapLogRecord
+			startedAt         int64
+			duration, sshWait int64
+			commands          sql.NullString
+		)
+		if err := rows.Scan(&log.ID, &log.NodeID, &log.InstanceID, &log.Pool,
+			&startedAt, &duration, &sshWait, &log.Success, &log.Error, &commands); err != nil {
+			return nil, err
+		}
+		log.StartedAt = fromUnixNano(startedAt)
+		log.Duration = time.Duration(duration)
+		log.SSHWaitTime = time.Duration(sshWait)
+		if commands.Valid {
+			if err := json.Unmarshal([]byte(commands.String), &log.Commands); err != nil {
+				return nil, fmt.Errorf("decoding bootstrap commands: %w", err)
+			}
+		}
+		logs = append(logs, &log)
+	}
+	return logs, rows.Err()
+}
+
+// Close closes the underlying database.
+func (s *SQLiteDB) Close() error {
+	return s.db.Close()
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func nodeExists(ctx context.Context, q queryer, nodeID string) (bool, error) {
+	var exists int
+	err := q.QueryRowContext(ctx, `SELECT 1 FROM nodes WHERE node_id = ?`, nodeID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func scanNode(row rowScanner) (*NodeRecord, error) {
+	var (
+		node                                                    NodeRecord
+		gpus, metadata, cfg                                     []byte
+		status, healthStatus                                    int32
+		lastHeartbeat, lastHealthCheck, registeredAt, holdUntil int64
+		gpuHealth                                               sql.NullString
+	)
+	if err := row.Scan(&node.NodeID, &node.Provider, &node.Region, &node.Zone, &node.InstanceType,
+		&gpus, &metadata, &cfg, &status, &healthStatus,
+		&lastHeartbeat, &lastHealthCheck, &registeredAt, &gpuHealth,
+		&node.StatusReason, &holdUntil); err != nil {
+		return nil, err
+	}
+	node.HoldUntil = fromUnixNano(holdUntil)
+
+	node.Status = pb.NodeStatus(status)
+	node.HealthStatus = pb.HealthStatus(healthStatus)
+	node.LastHeartbeat = fromUnixNano(lastHeartbeat)
+	node.LastHealthCheck = fromUnixNano(lastHealthCheck)
+	node.RegisteredAt = fromUnixNano(registeredAt)
+
+	var err error
+	if node.GPUs, err = unmarshalGPUs(gpus); err != nil {
+		return nil, err
+	}
+	if node.GPUHealth, err = unmarshalGPUHealth(gpuHealth); err != nil {
+		return nil, err
+	}
+	if metadata != nil {
+		node.Metadata = &pb.NodeMetadata{}
+		if err := proto.Unmarshal(metadata, node.Metadata); err != nil {
+			return nil, fmt.Errorf("decoding node metadata: %w", err)
+		}
+	}
+	if cfg != nil {
+		node.Config = &pb.NodeConfig{}
+		if err := proto.Unmarshal(cfg, node.Config); err != nil {
+			return nil, fmt.Errorf("decoding node config: %w", err)
+		}
+	}
+	return &node, nil
+}
+
+func scanGPU(row rowScanner) (*GPURecord, error) {
+	var (
+		gpu                              GPURecord
+		nodes, xidCounts                 sql.NullString
+		firstSeen, lastSeen, lastFailure int64
+	)
+	if err := row.Scan(&gpu.UUID, &gpu.Name, &gpu.NodeID, &nodes, &firstSeen, &lastSeen,
+		&gpu.EventCount, &gpu.FailureCount, &lastFailure, &xidCounts); err != nil {
+		return nil, err
+	}
+
+	gpu.FirstSeen = fromUnixNano(firstSeen)
+	gpu.LastSeen = fromUnixNano(lastSeen)
+	gpu.LastFailure = fromUnixNano(lastFailure)
+
+	var err error
+	if gpu.Nodes, err = unmarshalGPUAssignments(nodes); err != nil {
+		return nil, err
+	}
+	if gpu.XIDCounts, err = unmarshalXIDCounts(xidCounts); err != nil {
+		return nil, err
+	}
+	return &gpu, nil
+}
+
+func scanInstance(row rowScanner) (*InstanceRecord, error) {
+	var (
+		instance                         InstanceRecord
+		state                            int32
+		createdAt, readyAt, terminatedAt int64
+		labels                           sql.NullString
+	)
+	if err := row.Scan(&instance.InstanceID, &instance.Provider, &instance.Region, &instance.Zone,
+		&instance.InstanceType, &state, &instance.PoolName,
+		&createdAt, &readyAt, &terminatedAt,
+		&instance.NodeID, &instance.StatusMessage, &labels); err != nil {
+		return nil, err
+	}
+
+	instance.State = pb.InstanceState(state)
+	instance.CreatedAt = fromUnixNano(createdAt)
+	instance.ReadyAt = fromUnixNano(readyAt)
+	instance.TerminatedAt = fromUnixNano(terminatedAt)
+
+	var err error
+	if instance.Labels, err = unmarshalStringMap(labels); err != nil {
+		return nil, err
+	}
+	return &instance, nil
+}
+
+// toUnixNano stores zero times as 0 so they round-trip back to time.Time{}.
+func toUnixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+func marshalProto(m proto.Message) ([]byte, error) {
+	if m == nil || !m.ProtoReflect().IsValid() {
+		return nil, nil
+	}
+	data, err := proto.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s: %w", m.ProtoReflect().Descriptor().Name(), err)
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return data, nil
+}
+
+// marshalGPUs encodes GPU info as a JSON array of protojson objects.
+func marshalGPUs(gpus []*pb.GPUInfo) ([]byte, error) {
+	if gpus == nil {
+		return nil, nil
+	}
+	raw := make([]json.RawMessage, len(gpus))
+	for i, gpu := range gpus {
+		data, err := protojson.Marshal(gpu)
+		if err != nil {
+			return nil, fmt.Errorf("encoding gpu info: %w", err)
+		}
+		raw[i] = data
+	}
+	return json.Marshal(raw)
+}
+
+func unmarshalGPUs(data []byte) ([]*pb.GPUInfo, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decoding gpu info: %w", err)
+	}
+	gpus := make([]*pb.GPUInfo, len(raw))
+	for i, r := range raw {
+		gpus[i] = &pb.GPUInfo{}
+		if err := protojson.Unmarshal(r, gpus[i]); err != nil {
+			return nil, fmt.Errorf("decoding gpu info: %w", err)
+		}
+	}
+	return gpus, nil
+}
+
+func marshalGPUHealth(gpus []*GPUHealthRecord) (sql.NullString, error) {
+	if len(gpus) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(gpus)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("encoding gpu health: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func unmarshalGPUHealth(s sql.NullString) ([]*GPUHealthRecord, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	var gpus []*GPUHealthRecord
+	if err := json.Unmarshal([]byte(s.String), &gpus); err != nil {
+		return nil, fmt.Errorf("decoding gpu health: %w", err)
+	}
+	return gpus, nil
+}
+
+func marshalGPUAssignments(nodes []*GPUAssignment) (sql.NullString, error) {
+	if len(nodes) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(nodes)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("encoding gpu nodes: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func unmarshalGPUAssignments(s sql.NullString) ([]*GPUAssignment, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	var nodes []*GPUAssignment
+	if err := json.Unmarshal([]byte(s.String), &nodes); err != nil {
+		return nil, fmt.Errorf("decoding gpu nodes: %w", err)
+	}
+	return nodes, nil
+}
+
+func marshalXIDCounts(counts map[int]int) (sql.NullString, error) {
+	if len(counts) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(counts)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("encoding xid counts: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func unmarshalXIDCounts(s sql.NullString) (map[int]int, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	var counts map[int]int
+	if err := json.Unmarshal([]byte(s.String), &counts); err != nil {
+		return nil, fmt.Errorf("decoding xid counts: %w", err)
+	}
+	return counts, nil
+}
+
+// marshalHealthResults encodes health check results as a JSON array of protojson objects.
+func marshalHealthResults(results []*pb.HealthCheckResult) ([]byte, error) {
+	raw := make([]json.RawMessage, len(results))
+	for i, result := range results {
+		data, err := protojson.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("encoding health check result: %w", err)
+		}
+		raw[i] = data
+	}
+	return json.Marshal(raw)
+}
+
+func unmarshalHealthResults(data []byte) ([]*pb.HealthCheckResult, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("decoding health check results: %w", err)
+	}
+	results := make([]*pb.HealthCheckResult, len(raw))
+	for i, r := range raw {
+		results[i] = &pb.HealthCheckResult{}
+		if err := protojson.Unmarshal(r, results[i]); err != nil {
+			return nil, fmt.Errorf("decoding health check result: %w", err)
+		}
+	}
+	return results, nil
+}
+
+func marshalStringMap(m map[string]string) (sql.NullString, error) {
+	if m == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+func unmarshalStringMap(s sql.NullString) (map[string]string, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	var m map[string]string
+	if err := json.Unmarshal([]byte(s.String), &m); err != nil {
+		return nil, fmt.Errorf("decoding string map: %w", err)
+	}
+	return m, nil
+}
+
+func nullableString(data []byte) sql.NullString {
+	if data == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(data), Valid: true}
+}
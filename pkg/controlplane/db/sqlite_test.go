@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NavarchProject/navarch/pkg/clock"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+func newTestSQLiteDB(t *testing.T, clk clock.Clock) (*SQLiteDB, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "navarch.db")
+	db, err := NewSQLiteDBWithClock(path, clk)
+	if err != nil {
+		t.Fatalf("NewSQLiteDB failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, path
+}
+
+func TestSQLiteDB_RequiresPath(t *testing.T) {
+	if _, err := NewSQLiteDB(""); err == nil {
+		t.Fatal("Expected error for empty path")
+	}
+}
+
+func TestSQLiteDB_PathWithURICharacters(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data?x=1#frag 100%")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "navarch.db")
+	db, err := NewSQLiteDB(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteDB failed: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.RegisterNode(context.Background(), &NodeRecord{NodeID: "node-1"}); err != nil {
+		t.Fatalf("RegisterNode failed: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected database at %q: %v", path, err)
+	}
+}
+
+func TestSQLiteDB_Migrations(t *testing.T) {
+	db, path := newTestSQLiteDB(t, nil)
+	ctx := context.Background()
+
+	version, err := db.SchemaVersion(ctx)
+	if err != nil {
+		t.Fatalf("SchemaVersion failed: %v", err)
+	}
+	if version != len(migrations) {
+		t.Errorf("Expected schema version %d, got %d", len(migrations), version)
+	}
+	db.Close()
+
+	// Reopening an up-to-date database must not re-apply migrations.
+	reopened, err := NewSQLiteDB(path)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	if version, _ := reopened.SchemaVersion(ctx); version != len(migrations) {
+		t.Errorf("Expected schema version %d after reopen, got %d", len(migrations), version)
+	}
+}
+
+func TestSQLiteDB_RejectsNewerSchema(t *testing.T) {
+	db, path := newTestSQLiteDB(t, nil)
+	if _, err := db.db.Exec("PRAGMA user_version = 9999"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	_, err := NewSQLiteDB(path)
+	if err == nil {
+		t.Fatal("Expected error opening database with newer schema")
+	}
+	if !strings.Contains(err.Error(), "newer than supported") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestSQLiteDB_MetricsRetention(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	db, _ := newTestSQLiteDB(t, fakeClock)
+	ctx := context.Background()
+
+	if err := db.RecordMetrics(ctx, &MetricsRecord{NodeID: "node-1"}); err == nil {
+		t.Error("Expected error recording metrics for unknown node")
+	}
+
+	db.RegisterNode(ctx, &NodeRecord{NodeID: "node-1"})
+	for i := 0; i < maxMetricsPerNode+20; i++ {
+		db.RecordMetrics(ctx, &MetricsRecord{
+			NodeID:    "node-1",
+			Timestamp: fakeClock.Now(),
+			Metrics:   &pb.NodeMetrics{CpuUsagePercent: float64(i)},
+		})
+	}
+
+	recent, err := db.GetRecentMetrics(ctx, "node-1", time.Minute)
+	if err != nil {
+		t.Fatalf("GetRecentMetrics failed: %v", err)
+	}
+	if len(recent) != maxMetricsPerNode {
+		t.Fatalf("Expected %d metrics, got %d", maxMetricsPerNode, len(recent))
+	}
+	if recent[0].Metrics.CpuUsagePercent != 20 {
+		t.Errorf("Expected oldest retained metric to be 20, got %v", recent[0].Metrics.CpuUsagePercent)
+	}
+
+	fakeClock.Advance(2 * time.Minute)
+	recent, _ = db.GetRecentMetrics(ctx, "node-1", time.Minute)
+	if len(recent) != 0 {
+		t.Errorf("Expected no metrics within window, got %d", len(recent))
+	}
+}
+
+func TestSQLiteDB_PersistsAcrossRestart(t *testing.T) {
+	db, path := newTestSQLiteDB(t, nil)
+	ctx := context.Background()
+
+	db.RegisterNode(ctx, &NodeRecord{NodeID: "node-1", Provider: "lambda"})
+	db.CreateCommand(ctx, &CommandRecord{CommandID: "cmd-1", NodeID: "node-1", Type: pb.NodeCommandType_NODE_COMMAND_TYPE_CORDON})
+	db.CreateInstance(ctx, &InstanceRecord{
+		InstanceID: "i-1",
+		State:      pb.InstanceState_INSTANCE_STATE_PENDING_REGISTRATION,
+		PoolName:   "training",
+	})
+	db.Close()
+
+	reopened, err := NewSQLiteDB(path)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer reopened.Close()
+
+	node, err := reopened.GetNode(ctx, "node-1")
+	if err != nil || node.Provider != "lambda" {
+		t.Errorf("Expected node to survive restart, got %v (%v)", node, err)
+	}
+	pending, _ := reopened.GetPendingCommands(ctx, "node-1")
+	if len(pending) != 1 {
+		t.Errorf("Expected 1 pending command after restart, got %d", len(pending))
+	}
+	instance, err := reopened.GetInstance(ctx, "i-1")
+	if err != nil || instance.State != pb.InstanceState_INSTANCE_STATE_PENDING_REGISTRATION {
+		t.Errorf("Expected instance to survive restart, got %v (%v)", instance, err)
+	}
+}
@@ -0,0 +1,225 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	"github.com/NavarchProject/navarch/pkg/notifier"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+// FlapConfig configures detection of nodes whose health flaps between
+// healthy and unhealthy. A flapping node is held in HoldState for HoldDown,
+// so that it stops toggling status and re-triggering replacement.
+type FlapConfig struct {
+	// Transitions is how many health transitions into or out of unhealthy
+	// within Window mark a node as flapping. Zero disables detection.
+	Transitions int
+
+	// Window is the period transitions are counted over. Default: 30 minutes.
+	Window time.Duration
+
+	// HoldDown is how long a flapping node is held. Default: 1 hour.
+	HoldDown time.Duration
+
+	// HoldState is the status a flapping node is held in: CORDONED (the
+	// default) or UNHEALTHY. An unhealthy hold triggers replacement once.
+	HoldState pb.NodeStatus
+}
+
+// DefaultFlapConfig returns sensible defaults for flap detection.
+func DefaultFlapConfig() FlapConfig {
+	return FlapConfig{
+		Transitions: 4,
+		Window:      30 * time.Minute,
+		HoldDown:    time.Hour,
+		HoldState:   pb.NodeStatus_NODE_STATUS_CORDONED,
+	}
+}
+
+// withDefaults fills in unset fields of an enabled configuration.
+func (c FlapConfig) withDefaults() FlapConfig {
+	if c.Transitions == 0 {
+		return c
+	}
+	defaults := DefaultFlapConfig()
+	if c.Window == 0 {
+		c.Window = defaults.Window
+	}
+	if c.HoldDown == 0 {
+		c.HoldDown = defaults.HoldDown
+	}
+	if c.HoldState == pb.NodeStatus_NODE_STATUS_UNKNOWN {
+		c.HoldState = defaults.HoldState
+	}
+	return c
+}
+
+// flapDetector counts each node's health transitions into and out of
+// unhealthy.
+type flapDetector struct {
+	mu          sync.Mutex
+	unhealthy   map[string]bool        // Whether the node's last report was unhealthy
+	transitions map[string][]time.Time // Recent transition times, oldest first
+}
+
+func newFlapDetector() *flapDetector {
+	return &flapDetector{
+		unhealthy:   make(map[string]bool),
+		transitions: make(map[string][]time.Time),
+	}
+}
+
+// observe records a node's reported health at now and returns how many
+// transitions it has made within window.
+func (d *flapDetector) observe(nodeID string, status pb.HealthStatus, now time.Time, window time.Duration) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	unhealthy := status == pb.HealthStatus_HEALTH_STATUS_UNHEALTHY
+	transitions := d.transitions[nodeID]
+	if prev, ok := d.unhealthy[nodeID]; ok && prev != unhealthy {
+		transitions = append(transitions, now)
+	}
+	d.unhealthy[nodeID] = unhealthy
+
+	cutoff := now.Add(-window)
+	for len(transitions) > 0 && !transitions[0].After(cutoff) {
+		transitions = transitions[1:]
+	}
+	if len(transitions) == 0 {
+		delete(d.transitions, nodeID)
+	} else {
+		d.transitions[nodeID] = transitions
+	}
+	return len(transitions)
+}
+
+// reset forgets a node's transitions.
+func (d *flapDetector) reset(nodeID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.transitions, nodeID)
+}
+
+// checkFlapping counts a node's health transitions after a health report.
+// A node that flaps is held in the configured state until its hold-down
+// ends. The node is updated in place. Failures are logged and do not affect
+// the report.
+func (s *Server) checkFlapping(ctx context.Context, node *db.NodeRecord) {
+	cfg := s.config.FlapDetection
+	if cfg.Transitions == 0 {
+		return
+	}
+	now := s.clock.Now()
+	n := s.flaps.observe(node.NodeID, node.HealthStatus, now, cfg.Window)
+
+	switch {
+	case node.HoldUntil.IsZero():
+		if n < cfg.Transitions {
+			return
+		}
+		reason := fmt.Sprintf("flapping: %d health transitions in %s", n, cfg.Window)
+		until := now.Add(cfg.HoldDown)
+		if err := s.db.UpdateNodeHold(ctx, node.NodeID, reason, until); err != nil {
+			s.logger.ErrorContext(ctx, "failed to hold down flapping node",
+				slog.String("node_id", node.NodeID),
+				slog.String("error", err.Error()),
+			)
+			return
+		}
+		s.logger.WarnContext(ctx, "node is flapping, holding it down",
+			slog.String("node_id", node.NodeID),
+			slog.Int("transitions", n),
+			slog.String("state", nodeStatusString(cfg.HoldState)),
+			slog.Time("until", until),
+		)
+		node.StatusReason = reason
+		node.HoldUntil = until
+
+	case !now.Before(node.HoldUntil):
+		s.releaseHold(ctx, node)
+		return
+	}
+
+	s.holdStatus(ctx, node)
+}
+
+// holdStatus puts a held node back in the hold state if something else,
+// such as a health report or re-registration, changed its status. Nodes
+// being drained or terminated are left alone.
+func (s *Server) holdStatus(ctx context.Context, node *db.NodeRecord) {
+	state := s.config.FlapDetection.HoldState
+	switch node.Status {
+	case state, pb.NodeStatus_NODE_STATUS_DRAINING, pb.NodeStatus_NODE_STATUS_TERMINATED:
+		return
+	}
+
+	var err error
+	if state == pb.NodeStatus_NODE_STATUS_CORDONED {
+		err = s.updateStatusAndNotify(ctx, node.NodeID, state, node.Status,
+			func(n notifier.Notifier) error { return n.Cordon(ctx, node.NodeID, node.StatusReason) })
+	} else {
+		err = s.db.UpdateNodeStatus(ctx, node.NodeID, state)
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to hold node status",
+			slog.String("node_id", node.NodeID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	node.Status = state
+}
+
+// releaseHold ends a node's hold-down. A node still cordoned by the hold
+// becomes active again, or unhealthy if its last report was unhealthy. An
+// unhealthy node stays unhealthy.
+func (s *Server) releaseHold(ctx context.Context, node *db.NodeRecord) {
+	if err := s.clearHold(ctx, node.NodeID); err != nil {
+		s.logger.ErrorContext(ctx, "failed to release node hold-down",
+			slog.String("node_id", node.NodeID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	s.logger.InfoContext(ctx, "node hold-down ended", slog.String("node_id", node.NodeID))
+	node.StatusReason = ""
+	node.HoldUntil = time.Time{}
+
+	if s.config.FlapDetection.HoldState != pb.NodeStatus_NODE_STATUS_CORDONED ||
+		node.Status != pb.NodeStatus_NODE_STATUS_CORDONED {
+		return
+	}
+
+	var err error
+	status := pb.NodeStatus_NODE_STATUS_ACTIVE
+	if node.HealthStatus == pb.HealthStatus_HEALTH_STATUS_UNHEALTHY {
+		status = pb.NodeStatus_NODE_STATUS_UNHEALTHY
+		err = s.db.UpdateNodeStatus(ctx, node.NodeID, status)
+	} else {
+		err = s.updateStatusAndNotify(ctx, node.NodeID, status, node.Status,
+			func(n notifier.Notifier) error { return n.Uncordon(ctx, node.NodeID) })
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to restore node status after hold-down",
+			slog.String("node_id", node.NodeID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	node.Status = status
+}
+
+// clearHold removes a node's hold and forgets its transitions.
+func (s *Server) clearHold(ctx context.Context, nodeID string) error {
+	if err := s.db.UpdateNodeHold(ctx, nodeID, "", time.Time{}); err != nil {
+		return err
+	}
+	s.flaps.reset(nodeID)
+	return nil
+}
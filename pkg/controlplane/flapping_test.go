@@ -0,0 +1,160 @@
+package controlplane
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+
+	"github.com/NavarchProject/navarch/pkg/clock"
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+func TestFlapDetection(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewInMemDB()
+	defer database.Close()
+	clk := clock.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	cfg := DefaultConfig()
+	cfg.Clock = clk
+	cfg.FlapDetection = FlapConfig{Transitions: 4}
+	srv := NewServer(database, cfg, nil, nil)
+	observer := &testHealthObserver{}
+	srv.SetHealthObserver(observer)
+
+	register := func() {
+		t.Helper()
+		if _, err := srv.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{NodeId: "node-1"})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report := func(status pb.HealthStatus) pb.NodeStatus {
+		t.Helper()
+		clk.Advance(time.Minute)
+		resp, err := srv.ReportHealth(ctx, connect.NewRequest(&pb.ReportHealthRequest{
+			NodeId:  "node-1",
+			Results: []*pb.HealthCheckResult{{CheckName: "boot", Status: status}},
+		}))
+		if err != nil {
+			t.Fatalf("ReportHealth failed: %v", err)
+		}
+		return resp.Msg.NodeStatus
+	}
+	getNode := func() *db.NodeRecord {
+		t.Helper()
+		node, err := database.GetNode(ctx, "node-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return node
+	}
+	waitForCalls := func(want int) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for len(observer.getCalls()) < want && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if got := len(observer.getCalls()); got != want {
+			t.Fatalf("observer called %d times, want %d", got, want)
+		}
+	}
+
+	healthy := pb.HealthStatus_HEALTH_STATUS_HEALTHY
+	unhealthy := pb.HealthStatus_HEALTH_STATUS_UNHEALTHY
+
+	// Each unhealthy report marks the node unhealthy until it re-registers.
+	register()
+	report(healthy)
+	report(unhealthy)
+	waitForCalls(1)
+	register()
+	report(healthy)
+	report(unhealthy)
+	waitForCalls(2)
+	register()
+
+	t.Run("held_after_transitions", func(t *testing.T) {
+		if got := report(healthy); got != pb.NodeStatus_NODE_STATUS_CORDONED {
+			t.Fatalf("status after 4 transitions = %v, want cordoned", got)
+		}
+		node := getNode()
+		if !strings.HasPrefix(node.StatusReason, "flapping:") || !node.HoldUntil.Equal(clk.Now().Add(time.Hour)) {
+			t.Errorf("reason %q, hold until %v, want flapping for an hour", node.StatusReason, node.HoldUntil)
+		}
+	})
+
+	t.Run("no_replacement_while_held", func(t *testing.T) {
+		if got := report(unhealthy); got != pb.NodeStatus_NODE_STATUS_CORDONED {
+			t.Errorf("status after unhealthy report = %v, want cordoned", got)
+		}
+		time.Sleep(50 * time.Millisecond)
+		waitForCalls(2)
+	})
+
+	t.Run("held_across_registration", func(t *testing.T) {
+		register()
+		if got := getNode().Status; got != pb.NodeStatus_NODE_STATUS_CORDONED {
+			t.Errorf("status after re-registration = %v, want cordoned", got)
+		}
+	})
+
+	t.Run("released_after_hold_down", func(t *testing.T) {
+		clk.Advance(time.Hour)
+		if got := report(healthy); got != pb.NodeStatus_NODE_STATUS_ACTIVE {
+			t.Errorf("status after hold-down = %v, want active", got)
+		}
+		if node := getNode(); node.StatusReason != "" || !node.HoldUntil.IsZero() {
+			t.Errorf("reason %q, hold until %v, want hold cleared", node.StatusReason, node.HoldUntil)
+		}
+	})
+
+	t.Run("uncordon_clears_hold", func(t *testing.T) {
+		if err := database.UpdateNodeHold(ctx, "node-1", "flapping: test", clk.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		if err := database.UpdateNodeStatus(ctx, "node-1", pb.NodeStatus_NODE_STATUS_CORDONED); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := srv.IssueCommand(ctx, connect.NewRequest(&pb.IssueCommandRequest{
+			NodeId:      "node-1",
+			CommandType: pb.NodeCommandType_NODE_COMMAND_TYPE_UNCORDON,
+		})); err != nil {
+			t.Fatal(err)
+		}
+		if node := getNode(); node.Status != pb.NodeStatus_NODE_STATUS_ACTIVE || !node.HoldUntil.IsZero() {
+			t.Errorf("status %v, hold until %v, want active with no hold", node.Status, node.HoldUntil)
+		}
+	})
+}
+
+func TestFlapDetector(t *testing.T) {
+	d := newFlapDetector()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	window := 10 * time.Minute
+
+	statuses := []pb.HealthStatus{
+		pb.HealthStatus_HEALTH_STATUS_HEALTHY,
+		pb.HealthStatus_HEALTH_STATUS_UNHEALTHY,
+		pb.HealthStatus_HEALTH_STATUS_DEGRADED,
+		pb.HealthStatus_HEALTH_STATUS_HEALTHY,
+		pb.HealthStatus_HEALTH_STATUS_UNHEALTHY,
+	}
+	want := []int{0, 1, 2, 2, 3}
+	for i, status := range statuses {
+		if got := d.observe("node-1", status, start.Add(time.Duration(i)*time.Minute), window); got != want[i] {
+			t.Errorf("report %d (%v): %d transitions, want %d", i, status, got, want[i])
+		}
+	}
+
+	if got := d.observe("node-1", pb.HealthStatus_HEALTH_STATUS_UNHEALTHY, start.Add(12*time.Minute), window); got != 1 {
+		t.Errorf("after window: %d transitions, want 1", got)
+	}
+	d.reset("node-1")
+	if got := d.observe("node-1", pb.HealthStatus_HEALTH_STATUS_HEALTHY, start.Add(13*time.Minute), window); got != 1 {
+		t.Errorf("after reset: %d transitions, want 1", got)
+	}
+}
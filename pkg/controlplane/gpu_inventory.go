@@ -0,0 +1,227 @@
+package controlplane
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+// recordGPUsSeen adds a registering node's GPUs to the GPU inventory.
+// Failures are logged and do not affect registration.
+func (s *Server) recordGPUsSeen(ctx context.Context, node *db.NodeRecord) {
+	now := s.clock.Now()
+	gpus := make(map[string]*pb.GPUInfo, len(node.GPUs))
+	var uuids []string
+	for _, g := range node.GPUs {
+		if g.Uuid == "" {
+			continue
+		}
+		if _, ok := gpus[g.Uuid]; !ok {
+			uuids = append(uuids, g.Uuid)
+		}
+		gpus[g.Uuid] = g
+	}
+	s.updateGPUs(ctx, node.NodeID, uuids, func(rec *db.GPURecord) {
+		g := gpus[rec.UUID]
+		if g.Name != "" {
+			rec.Name = g.Name
+		}
+		a := assignGPU(rec, node.NodeID, now)
+		a.Provider = node.Provider
+		a.InstanceType = node.InstanceType
+		a.Index = int(g.Index)
+	})
+}
+
+// recordGPUEvents counts recorded health events against the GPUs they came
+// from. Events that a rule found unhealthy count as failures. The records
+// come from one node's report, so each GPU's events are applied together.
+// Failures are logged and do not affect the report.
+func (s *Server) recordGPUEvents(ctx context.Context, records []*db.HealthEventRecord) {
+	if len(records) == 0 {
+		return
+	}
+	now := s.clock.Now()
+	nodeID := records[0].NodeID
+	events := make(map[string][]*db.HealthEventRecord)
+	var uuids []string
+	for _, r := range records {
+		uuid := r.Event.GetGpuUuid()
+		if uuid == "" {
+			continue
+		}
+		if _, ok := events[uuid]; !ok {
+			uuids = append(uuids, uuid)
+		}
+		events[uuid] = append(events[uuid], r)
+	}
+	s.updateGPUs(ctx, nodeID, uuids, func(rec *db.GPURecord) {
+		assignGPU(rec, nodeID, now)
+		for _, r := range events[rec.UUID] {
+			rec.EventCount++
+			if r.Result == pb.HealthStatus_HEALTH_STATUS_UNHEALTHY {
+				rec.FailureCount++
+				if t := r.Time(); t.After(rec.LastFailure) {
+					rec.LastFailure = t
+				}
+			}
+			if r.Event.EventType == pb.HealthEventType_HEALTH_EVENT_TYPE_XID {
+				if code, err := strconv.Atoi(r.Event.Metrics["xid_code"]); err == nil {
+					if rec.XIDCounts == nil {
+						rec.XIDCounts = make(map[int]int)
+					}
+					rec.XIDCounts[code]++
+				}
+			}
+		}
+	})
+}
+
+// updateGPUs applies update once to the inventory record of each GPU in
+// uuids, creating records for GPUs that have not been seen before. The
+// inventory stays locked for the whole batch. A GPU that fails to update is
+// logged and does not stop the others.
+func (s *Server) updateGPUs(ctx context.Context, nodeID string, uuids []string, update func(*db.GPURecord)) {
+	if len(uuids) == 0 {
+		return
+	}
+	s.gpuInventoryMu.Lock()
+	defer s.gpuInventoryMu.Unlock()
+
+	for _, uuid := range uuids {
+		if err := s.updateGPU(ctx, uuid, update); err != nil {
+			s.logger.ErrorContext(ctx, "failed to update GPU inventory",
+				slog.String("node_id", nodeID),
+				slog.String("gpu_uuid", uuid),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// updateGPU applies update to a GPU's inventory record. The caller must hold
+// gpuInventoryMu.
+func (s *Server) updateGPU(ctx context.Context, uuid string, update func(*db.GPURecord)) error {
+	rec, err := s.db.GetGPU(ctx, uuid)
+	if errors.Is(err, db.ErrGPUNotFound) {
+		rec = &db.GPURecord{UUID: uuid, FirstSeen: s.clock.Now()}
+	} else if err != nil {
+		return err
+	}
+	update(rec)
+	return s.db.PutGPU(ctx, rec)
+}
+
+// assignGPU marks a GPU as seen on a node at now and returns the node's
+// assignment, adding one if the GPU is new to the node.
+func assignGPU(rec *db.GPURecord, nodeID string, now time.Time) *db.GPUAssignment {
+	rec.NodeID = nodeID
+	rec.LastSeen = now
+
+	var a *db.GPUAssignment
+	if n := len(rec.Nodes); n > 0 && rec.Nodes[n-1].NodeID == nodeID {
+		a = rec.Nodes[n-1]
+	} else {
+		a = &db.GPUAssignment{NodeID: nodeID, FirstSeen: now}
+		rec.Nodes = append(rec.Nodes, a)
+	}
+	a.LastSeen = now
+	return a
+}
+
+// ListGPUs returns the GPU inventory, most failures first.
+func (s *Server) ListGPUs(ctx context.Context, req *connect.Request[pb.ListGPUsRequest]) (*connect.Response[pb.ListGPUsResponse], error) {
+	records, err := s.db.ListGPUs(ctx, db.GPUFilter{
+		NodeID:      req.Msg.NodeId,
+		MinFailures: int(req.Msg.MinFailures),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list GPUs", slog.String("error", err.Error()))
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list GPUs: %w", err))
+	}
+
+	// Providers are recorded per assignment, so they are matched here.
+	var gpus []*db.GPURecord
+	for _, rec := range records {
+		if req.Msg.Provider != "" && !slices.ContainsFunc(rec.Nodes, func(a *db.GPUAssignment) bool {
+			return a.Provider == req.Msg.Provider
+		}) {
+			continue
+		}
+		gpus = append(gpus, rec)
+	}
+	// ListGPUs orders by UUID, which breaks ties.
+	slices.SortStableFunc(gpus, func(a, b *db.GPURecord) int {
+		return cmp.Compare(b.FailureCount, a.FailureCount)
+	})
+
+	resp := &pb.ListGPUsResponse{Gpus: make([]*pb.GPURecord, len(gpus))}
+	for i, rec := range gpus {
+		resp.Gpus[i] = gpuRecordToProto(rec)
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// GetGPU returns one GPU from the inventory.
+func (s *Server) GetGPU(ctx context.Context, req *connect.Request[pb.GetGPURequest]) (*connect.Response[pb.GetGPUResponse], error) {
+	if req.Msg.Uuid == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("uuid is required"))
+	}
+
+	rec, err := s.db.GetGPU(ctx, req.Msg.Uuid)
+	if errors.Is(err, db.ErrGPUNotFound) {
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to get GPU",
+			slog.String("gpu_uuid", req.Msg.Uuid),
+			slog.String("error", err.Error()),
+		)
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to get GPU: %w", err))
+	}
+
+	return connect.NewResponse(&pb.GetGPUResponse{Gpu: gpuRecordToProto(rec)}), nil
+}
+
+func gpuRecordToProto(rec *db.GPURecord) *pb.GPURecord {
+	g := &pb.GPURecord{
+		Uuid:         rec.UUID,
+		Name:         rec.Name,
+		NodeId:       rec.NodeID,
+		FirstSeen:    timestamppb.New(rec.FirstSeen),
+		LastSeen:     timestamppb.New(rec.LastSeen),
+		EventCount:   int32(rec.EventCount),
+		FailureCount: int32(rec.FailureCount),
+	}
+	if !rec.LastFailure.IsZero() {
+		g.LastFailure = timestamppb.New(rec.LastFailure)
+	}
+	for _, a := range rec.Nodes {
+		g.Nodes = append(g.Nodes, &pb.GPUAssignment{
+			NodeId:       a.NodeID,
+			Provider:     a.Provider,
+			InstanceType: a.InstanceType,
+			Index:        int32(a.Index),
+			FirstSeen:    timestamppb.New(a.FirstSeen),
+			LastSeen:     timestamppb.New(a.LastSeen),
+		})
+	}
+	if len(rec.XIDCounts) > 0 {
+		g.XidCounts = make(map[int32]int32, len(rec.XIDCounts))
+		for code, n := range rec.XIDCounts {
+			g.XidCounts[int32(code)] = int32(n)
+		}
+	}
+	return g
+}
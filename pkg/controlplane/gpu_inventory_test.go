@@ -0,0 +1,167 @@
+package controlplane
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+
+	"github.com/NavarchProject/navarch/pkg/clock"
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+func TestGPUInventory(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewInMemDB()
+	defer database.Close()
+	clk := clock.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	cfg := DefaultConfig()
+	cfg.Clock = clk
+	srv := NewServer(database, cfg, nil, nil)
+
+	register := func(nodeID, provider string, gpus ...*pb.GPUInfo) {
+		t.Helper()
+		if _, err := srv.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{
+			NodeId:   nodeID,
+			Provider: provider,
+			Gpus:     gpus,
+		})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	xid := func(nodeID string, gpuIndex int32, code string) {
+		t.Helper()
+		if _, err := srv.ReportHealth(ctx, connect.NewRequest(&pb.ReportHealthRequest{
+			NodeId: nodeID,
+			Events: []*pb.HealthEvent{{
+				EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_XID,
+				GpuIndex:  gpuIndex,
+				Metrics:   map[string]string{"xid_code": code},
+			}},
+		})); err != nil {
+			t.Fatalf("ReportHealth failed: %v", err)
+		}
+	}
+	getGPU := func(uuid string) *pb.GPURecord {
+		t.Helper()
+		resp, err := srv.GetGPU(ctx, connect.NewRequest(&pb.GetGPURequest{Uuid: uuid}))
+		if err != nil {
+			t.Fatalf("GetGPU(%s) failed: %v", uuid, err)
+		}
+		return resp.Msg.Gpu
+	}
+
+	h100 := "NVIDIA H100 80GB HBM3"
+	register("node-1", "gcp",
+		&pb.GPUInfo{Index: 0, Uuid: "GPU-good", Name: h100},
+		&pb.GPUInfo{Index: 3, Uuid: "GPU-bad", Name: h100},
+	)
+	xid("node-1", 3, "79")
+
+	// The node is replaced and the provider hands the bad GPU out again.
+	clk.Advance(time.Hour)
+	if err := database.DeleteNode(ctx, "node-1"); err != nil {
+		t.Fatal(err)
+	}
+	register("node-2", "lambda", &pb.GPUInfo{Index: 0, Uuid: "GPU-bad", Name: h100})
+	xid("node-2", 0, "79")
+	xid("node-2", 0, "8")
+
+	t.Run("history_across_nodes", func(t *testing.T) {
+		g := getGPU("GPU-bad")
+		if g.Name != h100 || g.NodeId != "node-2" || g.EventCount != 3 || g.FailureCount != 2 {
+			t.Errorf("GPU-bad = %v, want 3 events and 2 failures, last on node-2", g)
+		}
+		if len(g.Nodes) != 2 || g.Nodes[0].NodeId != "node-1" || g.Nodes[0].Index != 3 || g.Nodes[0].Provider != "gcp" ||
+			g.Nodes[1].NodeId != "node-2" || g.Nodes[1].Index != 0 || g.Nodes[1].Provider != "lambda" {
+			t.Errorf("GPU-bad nodes = %v, want node-1 then node-2", g.Nodes)
+		}
+		if g.XidCounts[79] != 2 || g.XidCounts[8] != 1 {
+			t.Errorf("XID counts = %v, want 79 twice and 8 once", g.XidCounts)
+		}
+		if !g.FirstSeen.AsTime().Equal(clk.Now().Add(-time.Hour)) || !g.LastFailure.AsTime().Equal(clk.Now()) {
+			t.Errorf("first seen %v, last failure %v", g.FirstSeen.AsTime(), g.LastFailure.AsTime())
+		}
+	})
+
+	t.Run("events_by_uuid", func(t *testing.T) {
+		resp, err := srv.ListHealthEvents(ctx, connect.NewRequest(&pb.ListHealthEventsRequest{GpuUuid: "GPU-bad"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(resp.Msg.Events); got != 3 {
+			t.Errorf("got %d events for GPU-bad, want 3 with the UUID filled in", got)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		list := func(req *pb.ListGPUsRequest) []string {
+			t.Helper()
+			resp, err := srv.ListGPUs(ctx, connect.NewRequest(req))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var uuids []string
+			for _, g := range resp.Msg.Gpus {
+				uuids = append(uuids, g.Uuid)
+			}
+			return uuids
+		}
+		tests := []struct {
+			name string
+			req  *pb.ListGPUsRequest
+			want []string
+		}{
+			{"all, most failures first", &pb.ListGPUsRequest{}, []string{"GPU-bad", "GPU-good"}},
+			{"by node", &pb.ListGPUsRequest{NodeId: "node-1"}, []string{"GPU-good"}},
+			{"by provider", &pb.ListGPUsRequest{Provider: "lambda"}, []string{"GPU-bad"}},
+			{"min failures", &pb.ListGPUsRequest{MinFailures: 1}, []string{"GPU-bad"}},
+		}
+		for _, tt := range tests {
+			got := list(tt.req)
+			if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("one_update_per_gpu", func(t *testing.T) {
+		counting := &gpuWriteCounter{DB: database}
+		srv := NewServer(counting, cfg, nil, nil)
+		events := []*pb.HealthEvent{
+			{EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_XID, GpuIndex: 0, Metrics: map[string]string{"xid_code": "79"}},
+			{EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_XID, GpuIndex: 0, Metrics: map[string]string{"xid_code": "79"}},
+			{EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_XID, GpuIndex: 0, Metrics: map[string]string{"xid_code": "13"}},
+		}
+		if _, err := srv.ReportHealth(ctx, connect.NewRequest(&pb.ReportHealthRequest{NodeId: "node-2", Events: events})); err != nil {
+			t.Fatal(err)
+		}
+		if counting.puts != 1 {
+			t.Errorf("PutGPU called %d times for one GPU, want 1", counting.puts)
+		}
+		if g := getGPU("GPU-bad"); g.EventCount != 6 || g.XidCounts[79] != 4 || g.XidCounts[13] != 1 {
+			t.Errorf("GPU-bad = %v, want every event in the report counted", g)
+		}
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		_, err := srv.GetGPU(ctx, connect.NewRequest(&pb.GetGPURequest{Uuid: "GPU-missing"}))
+		if connect.CodeOf(err) != connect.CodeNotFound {
+			t.Errorf("err = %v, want NotFound", err)
+		}
+	})
+}
+
+// gpuWriteCounter counts GPU inventory writes.
+type gpuWriteCounter struct {
+	db.DB
+	puts int
+}
+
+func (c *gpuWriteCounter) PutGPU(ctx context.Context, record *db.GPURecord) error {
+	c.puts++
+	return c.DB.PutGPU(ctx, record)
+}
@@ -0,0 +1,146 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	"github.com/NavarchProject/navarch/pkg/health"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+const (
+	// healthEventPruneInterval is how often health events older than the
+	// retention period are deleted.
+	healthEventPruneInterval = time.Hour
+
+	// defaultHealthEventLimit is how many events ListHealthEvents returns
+	// when the request sets no limit.
+	defaultHealthEventLimit = 1000
+)
+
+// recordHealthEvents stores the raw events of a health report, each with the
+// rule it matched, and counts them in the GPU inventory. evaluated holds the
+// events that were evaluated, which the matches index into. Failures are
+// logged and do not affect the report.
+func (s *Server) recordHealthEvents(ctx context.Context, node *db.NodeRecord, events, evaluated []*pb.HealthEvent, matches []health.RuleMatch) {
+	if len(events) == 0 {
+		return
+	}
+
+	matched := make(map[*pb.HealthEvent]*health.RuleMatch, len(matches))
+	for i := range matches {
+		if idx := matches[i].EventIndex; idx < len(evaluated) {
+			matched[evaluated[idx]] = &matches[i]
+		}
+	}
+
+	now := s.clock.Now()
+	records := make([]*db.HealthEventRecord, 0, len(events))
+	for _, event := range events {
+		record := &db.HealthEventRecord{
+			NodeID:     node.NodeID,
+			ReceivedAt: now,
+			Event:      withGPUUUID(node, event),
+		}
+		if m, ok := matched[event]; ok {
+			record.MatchedRule = m.Rule
+			record.Result = healthStatusFromResult(m.Result)
+		}
+		records = append(records, record)
+	}
+
+	if err := s.db.RecordHealthEvents(ctx, records); err != nil {
+		s.logger.ErrorContext(ctx, "failed to record health events",
+			slog.String("node_id", node.NodeID),
+			slog.String("error", err.Error()),
+		)
+	}
+	s.recordGPUEvents(ctx, records)
+	s.pruneHealthEvents(ctx, now)
+}
+
+// withGPUUUID returns event with the UUID of its GPU filled in from the
+// node's registered GPUs, so that the GPU's history can be found by UUID.
+// The event is cloned rather than changed.
+func withGPUUUID(node *db.NodeRecord, event *pb.HealthEvent) *pb.HealthEvent {
+	if event.GpuUuid != "" || event.GpuIndex < 0 {
+		return event
+	}
+	for _, g := range node.GPUs {
+		if g.Index == event.GpuIndex && g.Uuid != "" {
+			event = proto.Clone(event).(*pb.HealthEvent)
+			event.GpuUuid = g.Uuid
+			return event
+		}
+	}
+	return event
+}
+
+// pruneHealthEvents deletes events older than the retention period, at most
+// once per healthEventPruneInterval.
+func (s *Server) pruneHealthEvents(ctx context.Context, now time.Time) {
+	s.eventPruneMu.Lock()
+	if now.Sub(s.lastEventPrune) < healthEventPruneInterval {
+		s.eventPruneMu.Unlock()
+		return
+	}
+	s.lastEventPrune = now
+	s.eventPruneMu.Unlock()
+
+	n, err := s.db.DeleteHealthEventsBefore(ctx, now.Add(-s.config.HealthEventRetention))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to delete expired health events", slog.String("error", err.Error()))
+		return
+	}
+	if n > 0 {
+		s.logger.DebugContext(ctx, "deleted expired health events", slog.Int("count", n))
+	}
+}
+
+// ListHealthEvents returns recorded health events, newest first.
+func (s *Server) ListHealthEvents(ctx context.Context, req *connect.Request[pb.ListHealthEventsRequest]) (*connect.Response[pb.ListHealthEventsResponse], error) {
+	if req.Msg.Limit < 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("limit must be >= 0"))
+	}
+
+	filter := db.HealthEventFilter{
+		NodeID:  req.Msg.NodeId,
+		GPUUUID: req.Msg.GpuUuid,
+		Limit:   int(req.Msg.Limit),
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultHealthEventLimit
+	}
+	if req.Msg.Since != nil {
+		filter.Since = req.Msg.Since.AsTime()
+	}
+	if req.Msg.Until != nil {
+		filter.Until = req.Msg.Until.AsTime()
+	}
+
+	records, err := s.db.ListHealthEvents(ctx, filter)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list health events", slog.String("error", err.Error()))
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list health events: %w", err))
+	}
+
+	events := make([]*pb.HealthEventRecord, len(records))
+	for i, r := range records {
+		events[i] = &pb.HealthEventRecord{
+			NodeId:      r.NodeID,
+			Event:       r.Event,
+			MatchedRule: r.MatchedRule,
+			Result:      r.Result,
+			ReceivedAt:  timestamppb.New(r.ReceivedAt),
+		}
+	}
+
+	return connect.NewResponse(&pb.ListHealthEventsResponse{Events: events}), nil
+}
@@ -0,0 +1,111 @@
+package controlplane
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/NavarchProject/navarch/pkg/clock"
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+func TestListHealthEvents(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewInMemDB()
+	defer database.Close()
+	clk := clock.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	cfg := DefaultConfig()
+	cfg.Clock = clk
+	cfg.HealthEventRetention = 24 * time.Hour
+	srv := NewServer(database, cfg, nil, nil)
+
+	for _, id := range []string{"node-1", "node-2"} {
+		srv.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{NodeId: id}))
+	}
+	report := func(nodeID string, events ...*pb.HealthEvent) {
+		t.Helper()
+		for _, e := range events {
+			e.Timestamp = timestamppb.New(clk.Now())
+		}
+		if _, err := srv.ReportHealth(ctx, connect.NewRequest(&pb.ReportHealthRequest{NodeId: nodeID, Events: events})); err != nil {
+			t.Fatalf("ReportHealth failed: %v", err)
+		}
+	}
+	xid := func(code, gpuUUID string) *pb.HealthEvent {
+		return &pb.HealthEvent{
+			EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_XID,
+			GpuUuid:   gpuUUID,
+			Metrics:   map[string]string{"xid_code": code},
+		}
+	}
+	list := func(req *pb.ListHealthEventsRequest) []*pb.HealthEventRecord {
+		t.Helper()
+		resp, err := srv.ListHealthEvents(ctx, connect.NewRequest(req))
+		if err != nil {
+			t.Fatalf("ListHealthEvents failed: %v", err)
+		}
+		return resp.Msg.Events
+	}
+
+	report("node-1", xid("79", "GPU-a"), &pb.HealthEvent{EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_THERMAL, GpuUuid: "GPU-b"})
+	clk.Advance(time.Minute)
+	report("node-2", xid("13", "GPU-c"))
+
+	t.Run("matched_rule", func(t *testing.T) {
+		events := list(&pb.ListHealthEventsRequest{NodeId: "node-1"})
+		if len(events) != 2 {
+			t.Fatalf("got %d events for node-1, want 2", len(events))
+		}
+		var fatal *pb.HealthEventRecord
+		for _, e := range events {
+			if e.Event.GpuUuid == "GPU-a" {
+				fatal = e
+			}
+		}
+		if fatal == nil || fatal.MatchedRule != "fatal-xid" || fatal.Result != pb.HealthStatus_HEALTH_STATUS_UNHEALTHY ||
+			fatal.Event.Metrics["xid_code"] != "79" || fatal.NodeId != "node-1" {
+			t.Errorf("XID 79 record = %v, want fatal-xid match", fatal)
+		}
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		all := list(&pb.ListHealthEventsRequest{})
+		if len(all) != 3 || all[0].NodeId != "node-2" {
+			t.Errorf("got %d events starting with %v, want 3 newest first", len(all), all)
+		}
+		if got := list(&pb.ListHealthEventsRequest{GpuUuid: "GPU-c"}); len(got) != 1 || got[0].NodeId != "node-2" {
+			t.Errorf("GPU-c events = %v, want the node-2 event", got)
+		}
+		if got := list(&pb.ListHealthEventsRequest{Limit: 1}); len(got) != 1 {
+			t.Errorf("got %d events with limit 1", len(got))
+		}
+		_, err := srv.ListHealthEvents(ctx, connect.NewRequest(&pb.ListHealthEventsRequest{Limit: -1}))
+		if connect.CodeOf(err) != connect.CodeInvalidArgument {
+			t.Errorf("negative limit: err = %v, want InvalidArgument", err)
+		}
+	})
+
+	t.Run("kept_after_node_deleted", func(t *testing.T) {
+		if err := database.DeleteNode(ctx, "node-2"); err != nil {
+			t.Fatal(err)
+		}
+		if got := list(&pb.ListHealthEventsRequest{NodeId: "node-2"}); len(got) != 1 {
+			t.Errorf("got %d events for deleted node-2, want 1", len(got))
+		}
+	})
+
+	t.Run("retention", func(t *testing.T) {
+		clk.Advance(25 * time.Hour)
+		report("node-1", xid("48", "GPU-a"))
+
+		events := list(&pb.ListHealthEventsRequest{})
+		if len(events) != 1 || events[0].Event.Metrics["xid_code"] != "48" {
+			t.Errorf("events after retention = %v, want only the new XID 48", events)
+		}
+	})
+}
@@ -20,6 +20,8 @@ type HeartbeatMonitor struct {
 	logger *slog.Logger
 	config HeartbeatMonitorConfig
 
+	timeoutMu sync.RWMutex // guards config.HeartbeatTimeout, which can change while running
+
 	mu       sync.Mutex
 	started  bool
 	cancel   context.CancelFunc
@@ -80,6 +82,20 @@ func (m *HeartbeatMonitor) SetHealthObserver(observer NodeHealthObserver) {
 	m.observer = observer
 }
 
+// SetHeartbeatTimeout changes the heartbeat timeout. It takes effect on the
+// next check and may be called while the monitor is running.
+func (m *HeartbeatMonitor) SetHeartbeatTimeout(timeout time.Duration) {
+	m.timeoutMu.Lock()
+	defer m.timeoutMu.Unlock()
+	m.config.HeartbeatTimeout = timeout
+}
+
+func (m *HeartbeatMonitor) heartbeatTimeout() time.Duration {
+	m.timeoutMu.RLock()
+	defer m.timeoutMu.RUnlock()
+	return m.config.HeartbeatTimeout
+}
+
 // Start begins monitoring heartbeats in the background.
 func (m *HeartbeatMonitor) Start(ctx context.Context) {
 	m.mu.Lock()
@@ -96,7 +112,7 @@ func (m *HeartbeatMonitor) Start(ctx context.Context) {
 	go m.monitorLoop(ctx)
 
 	m.logger.Info("heartbeat monitor started",
-		slog.Duration("timeout", m.config.HeartbeatTimeout),
+		slog.Duration("timeout", m.heartbeatTimeout()),
 		slog.Duration("check_interval", m.config.CheckInterval),
 	)
 }
@@ -143,7 +159,7 @@ func (m *HeartbeatMonitor) checkHeartbeats(ctx context.Context) {
 	}
 
 	now := m.clock.Now()
-	timeout := m.config.HeartbeatTimeout
+	timeout := m.heartbeatTimeout()
 
 	for _, node := range nodes {
 		// Skip nodes that are already unhealthy or terminated
@@ -168,7 +184,7 @@ func (m *HeartbeatMonitor) markNodeUnhealthy(ctx context.Context, nodeID string,
 	m.logger.Warn("node heartbeat timeout",
 		slog.String("node_id", nodeID),
 		slog.Duration("last_heartbeat_age", age),
-		slog.Duration("timeout", m.config.HeartbeatTimeout),
+		slog.Duration("timeout", m.heartbeatTimeout()),
 	)
 
 	err := m.db.UpdateNodeStatus(ctx, nodeID, pb.NodeStatus_NODE_STATUS_UNHEALTHY)
@@ -249,3 +249,35 @@ func (o *testHealthObserver) getCalls() []string {
 	copy(result, o.calls)
 	return result
 }
+
+func TestHeartbeatMonitor_SetHeartbeatTimeout(t *testing.T) {
+	database := db.NewInMemDB()
+	fakeClock := clock.NewFakeClock(time.Now())
+
+	ctx := context.Background()
+	database.RegisterNode(ctx, &db.NodeRecord{
+		NodeID:        "node-1",
+		Status:        pb.NodeStatus_NODE_STATUS_ACTIVE,
+		LastHeartbeat: fakeClock.Now(),
+	})
+
+	monitor := NewHeartbeatMonitor(database, HeartbeatMonitorConfig{
+		HeartbeatTimeout: 1 * time.Minute,
+		CheckInterval:    10 * time.Second,
+		Clock:            fakeClock,
+	}, nil)
+	monitor.Start(ctx)
+	defer monitor.Stop()
+
+	// Raise the timeout while running; a two minute gap is now fine.
+	monitor.SetHeartbeatTimeout(5 * time.Minute)
+
+	time.Sleep(10 * time.Millisecond)
+	fakeClock.Advance(2 * time.Minute)
+	time.Sleep(100 * time.Millisecond)
+
+	node, _ := database.GetNode(ctx, "node-1")
+	if node.Status != pb.NodeStatus_NODE_STATUS_ACTIVE {
+		t.Errorf("Expected node status ACTIVE under the raised timeout, got %v", node.Status)
+	}
+}
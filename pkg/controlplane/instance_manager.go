@@ -239,6 +239,49 @@ func (t *InstanceManager) TrackNodeRegistered(ctx context.Context, instanceID, n
 	return nil
 }
 
+// TrackAdopted records an instance that was found running at the provider
+// during reconciliation. If a live record already exists (for example, from a
+// persistent database) it is left untouched; otherwise a record is created in
+// the running state.
+func (t *InstanceManager) TrackAdopted(ctx context.Context, instanceID, provider, region, zone, instanceType, poolName string, labels map[string]string) error {
+	existing, err := t.db.GetInstance(ctx, instanceID)
+	if err == nil {
+		switch existing.State {
+		case pb.InstanceState_INSTANCE_STATE_TERMINATED, pb.InstanceState_INSTANCE_STATE_FAILED:
+			// The provider says it is still running; bring the record back.
+			if err := t.db.UpdateInstanceState(ctx, instanceID, pb.InstanceState_INSTANCE_STATE_RUNNING, "adopted from provider inventory"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	record := &db.InstanceRecord{
+		InstanceID:    instanceID,
+		Provider:      provider,
+		Region:        region,
+		Zone:          zone,
+		InstanceType:  instanceType,
+		State:         pb.InstanceState_INSTANCE_STATE_RUNNING,
+		StatusMessage: "adopted from provider inventory",
+		PoolName:      poolName,
+		CreatedAt:     t.clock.Now(),
+		ReadyAt:       t.clock.Now(),
+		Labels:        labels,
+	}
+
+	if err := t.db.CreateInstance(ctx, record); err != nil {
+		return err
+	}
+
+	t.logger.Info("adopted existing instance",
+		slog.String("instance_id", instanceID),
+		slog.String("provider", provider),
+		slog.String("pool", poolName),
+	)
+	return nil
+}
+
 // TrackTerminating marks an instance as terminating.
 // Call this before calling provider.Terminate().
 func (t *InstanceManager) TrackTerminating(ctx context.Context, instanceID string) error {
@@ -168,6 +168,69 @@ func TestInstanceManager_NodeRegistered_UnknownInstance(t *testing.T) {
 	}
 }
 
+// TestInstanceManager_TrackAdopted tests recording instances found during reconciliation
+func TestInstanceManager_TrackAdopted(t *testing.T) {
+	t.Run("creates_running_record", func(t *testing.T) {
+		database := db.NewInMemDB()
+		defer database.Close()
+
+		im := NewInstanceManager(database, DefaultInstanceManagerConfig(), nil)
+		ctx := context.Background()
+
+		if err := im.TrackAdopted(ctx, "i-12345", "gcp", "us-central1", "us-central1-a", "a3-highgpu-8g", "gpu-pool", nil); err != nil {
+			t.Fatalf("TrackAdopted failed: %v", err)
+		}
+
+		instance, _ := im.GetInstance(ctx, "i-12345")
+		if instance.State != pb.InstanceState_INSTANCE_STATE_RUNNING {
+			t.Errorf("Expected state RUNNING, got %v", instance.State)
+		}
+		if instance.PoolName != "gpu-pool" {
+			t.Errorf("Expected pool_name gpu-pool, got %s", instance.PoolName)
+		}
+	})
+
+	t.Run("keeps_existing_record", func(t *testing.T) {
+		database := db.NewInMemDB()
+		defer database.Close()
+
+		im := NewInstanceManager(database, DefaultInstanceManagerConfig(), nil)
+		ctx := context.Background()
+
+		im.TrackProvisioning(ctx, "i-12345", "gcp", "us-central1", "us-central1-a", "a3-highgpu-8g", "gpu-pool", nil)
+		im.TrackProvisioningComplete(ctx, "i-12345")
+
+		if err := im.TrackAdopted(ctx, "i-12345", "gcp", "us-central1", "us-central1-a", "a3-highgpu-8g", "gpu-pool", nil); err != nil {
+			t.Fatalf("TrackAdopted failed: %v", err)
+		}
+
+		instance, _ := im.GetInstance(ctx, "i-12345")
+		if instance.State != pb.InstanceState_INSTANCE_STATE_PENDING_REGISTRATION {
+			t.Errorf("Expected state PENDING_REGISTRATION to be preserved, got %v", instance.State)
+		}
+	})
+
+	t.Run("revives_failed_record", func(t *testing.T) {
+		database := db.NewInMemDB()
+		defer database.Close()
+
+		im := NewInstanceManager(database, DefaultInstanceManagerConfig(), nil)
+		ctx := context.Background()
+
+		im.TrackProvisioning(ctx, "i-12345", "gcp", "us-central1", "us-central1-a", "a3-highgpu-8g", "gpu-pool", nil)
+		im.TrackProvisioningFailed(ctx, "i-12345", "registration timeout exceeded")
+
+		if err := im.TrackAdopted(ctx, "i-12345", "gcp", "us-central1", "us-central1-a", "a3-highgpu-8g", "gpu-pool", nil); err != nil {
+			t.Fatalf("TrackAdopted failed: %v", err)
+		}
+
+		instance, _ := im.GetInstance(ctx, "i-12345")
+		if instance.State != pb.InstanceState_INSTANCE_STATE_RUNNING {
+			t.Errorf("Expected state RUNNING, got %v", instance.State)
+		}
+	})
+}
+
 // TestInstanceManager_Termination tests the termination flow
 func TestInstanceManager_Termination(t *testing.T) {
 	database := db.NewInMemDB()
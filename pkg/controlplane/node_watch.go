@@ -0,0 +1,320 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+// nodeEvents turns node records into a versioned stream of change events.
+//
+// Nodes are written by several components (the server, the heartbeat monitor,
+// the database itself when recording health checks), so changes are detected
+// by comparing the database against the last snapshot rather than by hooking
+// every writer. The server syncs a node right after changing it, and ListNodes
+// and a single resync loop, run while any watch is open, sync the whole fleet
+// to catch everything else.
+type nodeEvents struct {
+	syncMu sync.Mutex // serializes syncs so a stale read never overwrites a newer one
+
+	mu         sync.Mutex
+	version    uint64                  // version of the most recent event
+	nodes      map[string]*pb.NodeInfo // last seen state of each node
+	history    []*pb.NodeEvent         // recent events, oldest first
+	maxHistory int                     // events retained for resumption
+	watchers   map[chan struct{}]struct{}
+	resync     func(ctx context.Context) // run from the first watch opening until the last closes
+	stopResync context.CancelFunc
+}
+
+// newNodeEvents creates an event log whose versions start after start. The
+// server passes the current time so that versions handed out before a restart
+// are always older than the log and are rejected on resume, instead of being
+// confused with unrelated events.
+func newNodeEvents(start uint64, maxHistory int) *nodeEvents {
+	return &nodeEvents{
+		version:    start,
+		nodes:      make(map[string]*pb.NodeInfo),
+		maxHistory: maxHistory,
+		watchers:   make(map[chan struct{}]struct{}),
+	}
+}
+
+// subscribe registers a watcher. The returned channel receives a value
+// whenever new events are recorded; notifications coalesce if the watcher is
+// busy. The first watcher starts the resync loop and the last one stops it.
+func (e *nodeEvents) subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	e.mu.Lock()
+	if len(e.watchers) == 0 && e.resync != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		e.stopResync = cancel
+		go e.resync(ctx)
+	}
+	e.watchers[ch] = struct{}{}
+	e.mu.Unlock()
+
+	return ch, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		delete(e.watchers, ch)
+		if len(e.watchers) == 0 && e.stopResync != nil {
+			e.stopResync()
+			e.stopResync = nil
+		}
+	}
+}
+
+// sync compares every node in the database with the last snapshot and records
+// an event for each node that was added, changed, or removed. It returns the
+// nodes read and the version that reflects them.
+func (e *nodeEvents) sync(ctx context.Context, database db.DB) ([]*db.NodeRecord, uint64, error) {
+	e.syncMu.Lock()
+	defer e.syncMu.Unlock()
+
+	records, err := database.ListNodes(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	seen := make(map[string]bool, len(records))
+	for _, record := range records {
+		seen[record.NodeID] = true
+		e.observeLocked(nodeRecordToProto(record))
+	}
+	for nodeID, node := range e.nodes {
+		if !seen[nodeID] {
+			delete(e.nodes, nodeID)
+			e.recordLocked(pb.NodeEventType_NODE_EVENT_TYPE_DELETED, node)
+		}
+	}
+	e.notifyLocked()
+	return records, e.version, nil
+}
+
+// syncNode compares a single node with the last snapshot. It runs whether or
+// not anyone is watching, so versions handed out by ListNodes stay current.
+func (e *nodeEvents) syncNode(ctx context.Context, database db.DB, nodeID string) {
+	e.syncMu.Lock()
+	defer e.syncMu.Unlock()
+
+	// A lookup failure means the node is gone; a full sync reports the
+	// deletion, so there is nothing to record here.
+	record, err := database.GetNode(ctx, nodeID)
+	if err != nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.observeLocked(nodeRecordToProto(record))
+	e.notifyLocked()
+}
+
+func (e *nodeEvents) observeLocked(node *pb.NodeInfo) {
+	prev, ok := e.nodes[node.NodeId]
+	e.nodes[node.NodeId] = node
+	switch {
+	case !ok:
+		e.recordLocked(pb.NodeEventType_NODE_EVENT_TYPE_ADDED, node)
+	case nodeChanged(prev, node):
+		e.recordLocked(pb.NodeEventType_NODE_EVENT_TYPE_MODIFIED, node)
+	}
+}
+
+func (e *nodeEvents) recordLocked(eventType pb.NodeEventType, node *pb.NodeInfo) {
+	e.version++
+	e.history = append(e.history, &pb.NodeEvent{
+		Type:            eventType,
+		Node:            node,
+		ResourceVersion: e.version,
+	})
+	if len(e.history) > e.maxHistory {
+		e.history = e.history[len(e.history)-e.maxHistory:]
+	}
+}
+
+func (e *nodeEvents) notifyLocked() {
+	for ch := range e.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// snapshot returns the current state of every node and the version it
+// reflects.
+func (e *nodeEvents) snapshot() ([]*pb.NodeInfo, uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	nodes := make([]*pb.NodeInfo, 0, len(e.nodes))
+	for _, node := range e.nodes {
+		nodes = append(nodes, node)
+	}
+	return nodes, e.version
+}
+
+// currentVersion returns the version of the most recent event.
+func (e *nodeEvents) currentVersion() uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.version
+}
+
+// since returns the events recorded after the given version. It fails if
+// events after the version have already been discarded, or if the version is
+// ahead of this control plane.
+func (e *nodeEvents) since(version uint64) ([]*pb.NodeEvent, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if version > e.version {
+		return nil, fmt.Errorf("resource version %d is newer than current version %d", version, e.version)
+	}
+	if version == e.version {
+		return nil, nil
+	}
+	oldest := e.version - uint64(len(e.history)) + 1
+	if version+1 < oldest {
+		return nil, fmt.Errorf("resource version %d is too old, oldest retained is %d", version, oldest-1)
+	}
+	start := len(e.history) - int(e.version-version)
+	events := make([]*pb.NodeEvent, len(e.history)-start)
+	copy(events, e.history[start:])
+	return events, nil
+}
+
+// nodeChanged reports whether a node changed in a way watchers care about.
+// Heartbeat timestamps change constantly and are ignored.
+func nodeChanged(prev, cur *pb.NodeInfo) bool {
+	return prev.Status != cur.Status ||
+		prev.HealthStatus != cur.HealthStatus ||
+		prev.StatusReason != cur.StatusReason ||
+		!proto.Equal(prev.Metadata, cur.Metadata) ||
+		!slices.EqualFunc(prev.GpuHealth, cur.GpuHealth, func(a, b *pb.GPUHealth) bool { return proto.Equal(a, b) })
+}
+
+func nodeRecordToProto(node *db.NodeRecord) *pb.NodeInfo {
+	return &pb.NodeInfo{
+		NodeId:        node.NodeID,
+		Provider:      node.Provider,
+		Region:        node.Region,
+		Zone:          node.Zone,
+		InstanceType:  node.InstanceType,
+		Status:        node.Status,
+		HealthStatus:  node.HealthStatus,
+		LastHeartbeat: timestamppb.New(node.LastHeartbeat),
+		Gpus:          node.GPUs,
+		Metadata:      node.Metadata,
+		GpuHealth:     gpuHealthToProto(node.GPUHealth),
+		StatusReason:  node.StatusReason,
+		HoldUntil:     holdUntilToProto(node.HoldUntil),
+	}
+}
+
+func holdUntilToProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func gpuHealthToProto(records []*db.GPUHealthRecord) []*pb.GPUHealth {
+	var gpus []*pb.GPUHealth
+	for _, g := range records {
+		gpus = append(gpus, &pb.GPUHealth{
+			Index:       int32(g.Index),
+			Uuid:        g.UUID,
+			Status:      g.Status,
+			Quarantined: g.Quarantined,
+			Reason:      g.Reason,
+			UpdatedAt:   timestamppb.New(g.UpdatedAt),
+		})
+	}
+	return gpus
+}
+
+// WatchNodes streams node change events. With a zero resource version the
+// stream starts with an ADDED event for every current node; otherwise it
+// replays retained events after that version before streaming new ones.
+func (s *Server) WatchNodes(ctx context.Context, req *connect.Request[pb.WatchNodesRequest], stream *connect.ServerStream[pb.NodeEvent]) error {
+	// Subscribe before syncing so no change made in between is missed.
+	notify, unsubscribe := s.nodeEvents.subscribe()
+	defer unsubscribe()
+
+	if _, _, err := s.nodeEvents.sync(ctx, s.db); err != nil {
+		s.logger.ErrorContext(ctx, "failed to sync nodes", slog.String("error", err.Error()))
+		return connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list nodes: %w", err))
+	}
+
+	last := req.Msg.ResourceVersion
+	if last == 0 {
+		nodes, version := s.nodeEvents.snapshot()
+		for _, node := range nodes {
+			if err := stream.Send(&pb.NodeEvent{
+				Type:            pb.NodeEventType_NODE_EVENT_TYPE_ADDED,
+				Node:            node,
+				ResourceVersion: version,
+			}); err != nil {
+				return err
+			}
+		}
+		last = version
+	}
+
+	s.logger.DebugContext(ctx, "node watch opened", slog.Uint64("resource_version", last))
+	defer s.logger.DebugContext(ctx, "node watch closed")
+
+	for {
+		events, err := s.nodeEvents.since(last)
+		if err != nil {
+			return connect.NewError(connect.CodeOutOfRange, err)
+		}
+		for _, event := range events {
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+			last = event.ResourceVersion
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-notify:
+		}
+	}
+}
+
+// resyncNodes syncs every node periodically, to catch changes made outside
+// the server such as heartbeat timeouts. The open watches share one loop.
+func (s *Server) resyncNodes(ctx context.Context) {
+	ticker := s.clock.NewTicker(s.config.NodeWatchResyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			if _, _, err := s.nodeEvents.sync(ctx, s.db); err != nil {
+				s.logger.WarnContext(ctx, "failed to resync nodes", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package controlplane
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	pb "github.com/NavarchProject/navarch/proto"
+	"github.com/NavarchProject/navarch/proto/protoconnect"
+)
+
+func TestNodeEvents(t *testing.T) {
+	ctx := context.Background()
+
+	register := func(t *testing.T, database db.DB, nodeID string) {
+		t.Helper()
+		if err := database.RegisterNode(ctx, &db.NodeRecord{
+			NodeID: nodeID,
+			Status: pb.NodeStatus_NODE_STATUS_ACTIVE,
+		}); err != nil {
+			t.Fatalf("RegisterNode failed: %v", err)
+		}
+	}
+
+	t.Run("records_changes", func(t *testing.T) {
+		database := db.NewInMemDB()
+		defer database.Close()
+		events := newNodeEvents(100, 10)
+
+		register(t, database, "node-1")
+		if _, _, err := events.sync(ctx, database); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+
+		// Heartbeats alone are not a change.
+		database.UpdateNodeHeartbeat(ctx, "node-1", time.Now())
+		database.UpdateNodeStatus(ctx, "node-1", pb.NodeStatus_NODE_STATUS_UNHEALTHY)
+		if _, _, err := events.sync(ctx, database); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+
+		database.DeleteNode(ctx, "node-1")
+		if _, _, err := events.sync(ctx, database); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+
+		got, err := events.since(100)
+		if err != nil {
+			t.Fatalf("since failed: %v", err)
+		}
+		want := []pb.NodeEventType{
+			pb.NodeEventType_NODE_EVENT_TYPE_ADDED,
+			pb.NodeEventType_NODE_EVENT_TYPE_MODIFIED,
+			pb.NodeEventType_NODE_EVENT_TYPE_DELETED,
+		}
+		if len(got) != len(want) {
+			t.Fatalf("got %d events, want %d", len(got), len(want))
+		}
+		for i, event := range got {
+			if event.Type != want[i] {
+				t.Errorf("event %d: type = %v, want %v", i, event.Type, want[i])
+			}
+			if event.ResourceVersion != uint64(101+i) {
+				t.Errorf("event %d: resource version = %d, want %d", i, event.ResourceVersion, 101+i)
+			}
+		}
+		if got[1].Node.Status != pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+			t.Errorf("MODIFIED status = %v, want UNHEALTHY", got[1].Node.Status)
+		}
+
+		got, err = events.since(102)
+		if err != nil {
+			t.Fatalf("since failed: %v", err)
+		}
+		if len(got) != 1 || got[0].ResourceVersion != 103 {
+			t.Errorf("since(102) = %v, want only version 103", got)
+		}
+	})
+
+	t.Run("rejects_unretained_versions", func(t *testing.T) {
+		database := db.NewInMemDB()
+		defer database.Close()
+		events := newNodeEvents(100, 2)
+
+		for _, id := range []string{"node-1", "node-2", "node-3"} {
+			register(t, database, id)
+		}
+		if _, _, err := events.sync(ctx, database); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+
+		if _, err := events.since(100); err == nil {
+			t.Error("expected error for version older than retained history")
+		}
+		if _, err := events.since(101); err != nil {
+			t.Errorf("since(101) failed: %v", err)
+		}
+		if _, err := events.since(200); err == nil {
+			t.Error("expected error for version newer than current")
+		}
+	})
+
+	t.Run("sync_node_without_watchers", func(t *testing.T) {
+		database := db.NewInMemDB()
+		defer database.Close()
+		events := newNodeEvents(0, 10)
+
+		register(t, database, "node-1")
+		events.syncNode(ctx, database, "node-1")
+		if v := events.currentVersion(); v != 1 {
+			t.Errorf("version = %d, want 1 with no watchers", v)
+		}
+	})
+
+	t.Run("one_resync_loop", func(t *testing.T) {
+		events := newNodeEvents(0, 10)
+		started := make(chan struct{}, 10)
+		stopped := make(chan struct{}, 10)
+		events.resync = func(ctx context.Context) {
+			started <- struct{}{}
+			<-ctx.Done()
+			stopped <- struct{}{}
+		}
+
+		_, unsubscribe1 := events.subscribe()
+		_, unsubscribe2 := events.subscribe()
+		<-started
+		unsubscribe1()
+		select {
+		case <-stopped:
+			t.Fatal("resync stopped while a watch is still open")
+		case <-time.After(10 * time.Millisecond):
+		}
+		unsubscribe2()
+		<-stopped
+		if len(started) != 0 {
+			t.Error("each watch started its own resync loop")
+		}
+	})
+}
+
+func TestWatchNodes(t *testing.T) {
+	database := db.NewInMemDB()
+	defer database.Close()
+	srv := NewServer(database, DefaultConfig(), nil, nil)
+
+	_, handler := protoconnect.NewControlPlaneServiceHandler(srv)
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+	client := protoconnect.NewControlPlaneServiceClient(http.DefaultClient, httpServer.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := srv.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{NodeId: "node-1"})); err != nil {
+		t.Fatalf("RegisterNode failed: %v", err)
+	}
+
+	stream, err := client.WatchNodes(ctx, connect.NewRequest(&pb.WatchNodesRequest{}))
+	if err != nil {
+		t.Fatalf("WatchNodes failed: %v", err)
+	}
+	defer stream.Close()
+
+	receive := func(t *testing.T) *pb.NodeEvent {
+		t.Helper()
+		if !stream.Receive() {
+			t.Fatalf("stream ended: %v", stream.Err())
+		}
+		return stream.Msg()
+	}
+
+	event := receive(t)
+	if event.Type != pb.NodeEventType_NODE_EVENT_TYPE_ADDED || event.Node.NodeId != "node-1" {
+		t.Fatalf("first event = %v %s, want ADDED node-1", event.Type, event.Node.NodeId)
+	}
+
+	// Cordoning must be pushed without waiting for a resync.
+	if _, err := srv.IssueCommand(ctx, connect.NewRequest(&pb.IssueCommandRequest{
+		NodeId:      "node-1",
+		CommandType: pb.NodeCommandType_NODE_COMMAND_TYPE_CORDON,
+	})); err != nil {
+		t.Fatalf("IssueCommand failed: %v", err)
+	}
+	event = receive(t)
+	if event.Type != pb.NodeEventType_NODE_EVENT_TYPE_MODIFIED || event.Node.Status != pb.NodeStatus_NODE_STATUS_CORDONED {
+		t.Fatalf("event = %v %v, want MODIFIED CORDONED", event.Type, event.Node.Status)
+	}
+	cordonedVersion := event.ResourceVersion
+
+	if _, err := srv.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{NodeId: "node-2"})); err != nil {
+		t.Fatalf("RegisterNode failed: %v", err)
+	}
+	event = receive(t)
+	if event.Type != pb.NodeEventType_NODE_EVENT_TYPE_ADDED || event.Node.NodeId != "node-2" {
+		t.Fatalf("event = %v %s, want ADDED node-2", event.Type, event.Node.NodeId)
+	}
+	if event.ResourceVersion != cordonedVersion+1 {
+		t.Errorf("resource version = %d, want %d", event.ResourceVersion, cordonedVersion+1)
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		resumed, err := client.WatchNodes(ctx, connect.NewRequest(&pb.WatchNodesRequest{
+			ResourceVersion: cordonedVersion,
+		}))
+		if err != nil {
+			t.Fatalf("WatchNodes failed: %v", err)
+		}
+		defer resumed.Close()
+
+		if !resumed.Receive() {
+			t.Fatalf("stream ended: %v", resumed.Err())
+		}
+		if got := resumed.Msg(); got.Node.NodeId != "node-2" || got.ResourceVersion != cordonedVersion+1 {
+			t.Errorf("resumed event = %s@%d, want node-2@%d", got.Node.NodeId, got.ResourceVersion, cordonedVersion+1)
+		}
+	})
+
+	t.Run("list_version", func(t *testing.T) {
+		resp, err := srv.ListNodes(ctx, connect.NewRequest(&pb.ListNodesRequest{}))
+		if err != nil {
+			t.Fatalf("ListNodes failed: %v", err)
+		}
+		if resp.Msg.ResourceVersion != cordonedVersion+1 {
+			t.Errorf("list resource version = %d, want %d", resp.Msg.ResourceVersion, cordonedVersion+1)
+		}
+	})
+
+	t.Run("list_version_without_watch", func(t *testing.T) {
+		quiet := NewServer(database, DefaultConfig(), nil, nil)
+		if _, err := quiet.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{NodeId: "node-3"})); err != nil {
+			t.Fatalf("RegisterNode failed: %v", err)
+		}
+		listed, err := quiet.ListNodes(ctx, connect.NewRequest(&pb.ListNodesRequest{}))
+		if err != nil {
+			t.Fatalf("ListNodes failed: %v", err)
+		}
+		if _, err := quiet.IssueCommand(ctx, connect.NewRequest(&pb.IssueCommandRequest{
+			NodeId:      "node-3",
+			CommandType: pb.NodeCommandType_NODE_COMMAND_TYPE_CORDON,
+		})); err != nil {
+			t.Fatalf("IssueCommand failed: %v", err)
+		}
+
+		// Resuming from the listed version replays only the cordon, not an
+		// ADDED event for every node.
+		events, err := quiet.nodeEvents.since(listed.Msg.ResourceVersion)
+		if err != nil {
+			t.Fatalf("since failed: %v", err)
+		}
+		if len(events) != 1 || events[0].Type != pb.NodeEventType_NODE_EVENT_TYPE_MODIFIED || events[0].Node.NodeId != "node-3" {
+			t.Errorf("events after list = %v, want only MODIFIED node-3", events)
+		}
+	})
+
+	t.Run("stale_version", func(t *testing.T) {
+		stale, err := client.WatchNodes(ctx, connect.NewRequest(&pb.WatchNodesRequest{ResourceVersion: 1}))
+		if err != nil {
+			t.Fatalf("WatchNodes failed: %v", err)
+		}
+		defer stale.Close()
+
+		if stale.Receive() {
+			t.Fatalf("expected stream to fail, got event %v", stale.Msg())
+		}
+		var connectErr *connect.Error
+		if !errors.As(stale.Err(), &connectErr) || connectErr.Code() != connect.CodeOutOfRange {
+			t.Errorf("error = %v, want OutOfRange", stale.Err())
+		}
+	})
+}
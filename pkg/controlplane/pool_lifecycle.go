@@ -0,0 +1,269 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/NavarchProject/navarch/pkg/config"
+	"github.com/NavarchProject/navarch/pkg/notifier"
+	"github.com/NavarchProject/navarch/pkg/pool"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+// drainPollInterval is how often a deleted pool's nodes are checked for
+// drain completion.
+const drainPollInterval = 10 * time.Second
+
+// CreatePool validates cfg with the same rules as the configuration file,
+// builds the pool and its autoscaler, and starts managing it. If the pool
+// manager is running, the autoscaler loop starts immediately.
+//
+// Pools created at runtime are not written back to the configuration file.
+func (pm *PoolManager) CreatePool(ctx context.Context, name string, cfg config.PoolCfg) error {
+	p, autoscaler, spec, err := pm.build(name, cfg)
+	if err != nil {
+		return err
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	mp, err := pm.addPoolLocked(p, autoscaler)
+	if err != nil {
+		return err
+	}
+	mp.spec = &spec
+
+	pm.logger.InfoContext(ctx, "pool created",
+		slog.String("pool", name),
+		slog.String("instance_type", spec.InstanceType),
+		slog.Int("min_nodes", spec.MinNodes),
+		slog.Int("max_nodes", spec.MaxNodes),
+	)
+	return nil
+}
+
+// UpdatePool replaces the configuration of an existing pool. Limits,
+// labels, health settings, and the autoscaler take effect on the next
+// evaluation; existing nodes are left in place. Changing the instance type,
+// providers, or provider strategy is rejected because existing nodes could
+// not follow; create a new pool instead.
+func (pm *PoolManager) UpdatePool(ctx context.Context, name string, cfg config.PoolCfg) error {
+	pm.mu.RLock()
+	mp, ok := pm.pools[name]
+	deleting := ok && mp.deleting
+	pm.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrPoolNotFound, name)
+	}
+	if deleting {
+		return fmt.Errorf("%w: %s", ErrPoolDeleting, name)
+	}
+
+	next, autoscaler, spec, err := pm.build(name, cfg)
+	if err != nil {
+		return err
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if mp.deleting {
+		return fmt.Errorf("%w: %s", ErrPoolDeleting, name)
+	}
+	if !sameProviders(mp.pool.Providers(), next.Providers()) {
+		return fmt.Errorf("%w: pool %q: providers cannot be changed", ErrInvalidPoolConfig, name)
+	}
+	if mp.spec != nil && mp.spec.Strategy != spec.Strategy {
+		return fmt.Errorf("%w: pool %q: strategy cannot be changed", ErrInvalidPoolConfig, name)
+	}
+	if err := mp.pool.UpdateConfig(next.Config()); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPoolConfig, err)
+	}
+	mp.autoscaler = autoscaler
+	mp.spec = &spec
+
+	pm.logger.InfoContext(ctx, "pool updated",
+		slog.String("pool", name),
+		slog.Int("min_nodes", spec.MinNodes),
+		slog.Int("max_nodes", spec.MaxNodes),
+		slog.String("autoscaler", autoscalerType(autoscaler)),
+	)
+	return nil
+}
+
+// DeletePool stops autoscaling a pool and removes it once its nodes are gone.
+// Each node is drained through the notifier and terminated when drained, or
+// when the drain timeout expires. Without a notifier, nodes are terminated
+// right away. DeletePool returns once deletion has started; the pool reports
+// Deleting until it is removed.
+func (pm *PoolManager) DeletePool(ctx context.Context, name string) error {
+	pm.mu.Lock()
+	mp, ok := pm.pools[name]
+	if !ok {
+		pm.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrPoolNotFound, name)
+	}
+	if mp.deleting {
+		pm.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrPoolDeleting, name)
+	}
+	mp.deleting = true
+	if mp.cancel != nil {
+		mp.cancel()
+		mp.cancel = nil
+	}
+	runCtx := pm.runCtx
+	n := pm.notifier
+	pm.mu.Unlock()
+
+	if runCtx == nil {
+		runCtx = context.Background()
+	}
+
+	pm.logger.InfoContext(ctx, "pool deletion started",
+		slog.String("pool", name),
+		slog.Int("nodes", len(mp.pool.Nodes())),
+	)
+	go pm.drainAndRemove(runCtx, name, mp, n)
+	return nil
+}
+
+// drainAndRemove drains and terminates every node of a deleted pool, then
+// removes the pool.
+func (pm *PoolManager) drainAndRemove(ctx context.Context, name string, mp *managedPool, n notifier.Notifier) {
+	pending := make(map[string]bool)
+	for _, node := range mp.pool.Nodes() {
+		id := node.Node.ID
+		pending[id] = true
+
+		if pm.db != nil {
+			if err := pm.db.UpdateNodeStatus(ctx, id, pb.NodeStatus_NODE_STATUS_DRAINING); err != nil {
+				pm.logger.Debug("failed to mark node draining",
+					slog.String("pool", name),
+					slog.String("node_id", id),
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+		if n != nil {
+			if err := n.Drain(ctx, id, "pool deleted"); err != nil {
+				pm.logger.Warn("failed to drain node",
+					slog.String("pool", name),
+					slog.String("node_id", id),
+					slog.String("notifier", n.Name()),
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+	}
+
+	deadline := pm.clock.Now().Add(pm.drainTimeout)
+	ticker := pm.clock.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		expired := !pm.clock.Now().Before(deadline)
+		for id := range pending {
+			if !expired && !pm.isDrained(ctx, n, name, id) {
+				continue
+			}
+			if err := mp.pool.TerminateNode(ctx, id); err != nil {
+				pm.logger.Warn("failed to terminate node of deleted pool",
+					slog.String("pool", name),
+					slog.String("node_id", id),
+					slog.String("error", err.Error()),
+				)
+				if !expired {
+					continue
+				}
+			} else if pm.instanceManager != nil {
+				if err := pm.instanceManager.TrackTerminated(ctx, id); err != nil {
+					pm.logger.Warn("failed to track instance termination",
+						slog.String("instance_id", id),
+						slog.String("error", err.Error()),
+					)
+				}
+			}
+			delete(pending, id)
+		}
+		if len(pending) == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			pm.logger.Warn("pool deletion interrupted",
+				slog.String("pool", name),
+				slog.Int("remaining_nodes", len(pending)),
+			)
+			return
+		case <-ticker.C():
+		}
+	}
+
+	pm.mu.Lock()
+	if pm.pools[name] == mp {
+		delete(pm.pools, name)
+	}
+	pm.mu.Unlock()
+	pm.logger.Info("pool deleted", slog.String("pool", name))
+}
+
+// isDrained reports whether a node's workloads have moved off. Nodes are
+// considered drained when there is no notifier to ask.
+func (pm *PoolManager) isDrained(ctx context.Context, n notifier.Notifier, poolName, nodeID string) bool {
+	if n == nil {
+		return true
+	}
+	drained, err := n.IsDrained(ctx, nodeID)
+	if err != nil {
+		pm.logger.Warn("failed to check drain status",
+			slog.String("pool", poolName),
+			slog.String("node_id", nodeID),
+			slog.String("error", err.Error()),
+		)
+		return false
+	}
+	return drained
+}
+
+// build validates a pool configuration, applies defaults, and creates the
+// pool and its autoscaler.
+func (pm *PoolManager) build(name string, cfg config.PoolCfg) (*pool.Pool, pool.Autoscaler, config.PoolCfg, error) {
+	if pm.fleetConfig == nil || pm.buildPool == nil {
+		return nil, nil, config.PoolCfg{}, ErrRuntimePoolsDisabled
+	}
+	if name == "" {
+		return nil, nil, config.PoolCfg{}, fmt.Errorf("%w: pool name is required", ErrInvalidPoolConfig)
+	}
+	if err := pm.fleetConfig.ValidatePool(name, cfg); err != nil {
+		return nil, nil, config.PoolCfg{}, fmt.Errorf("%w: %w", ErrInvalidPoolConfig, err)
+	}
+	cfg = pm.fleetConfig.ApplyPoolDefaults(cfg)
+
+	autoscaler, err := config.BuildAutoscaler(cfg.Autoscaling)
+	if err != nil {
+		return nil, nil, config.PoolCfg{}, fmt.Errorf("%w: pool %q: %w", ErrInvalidPoolConfig, name, err)
+	}
+	p, err := pm.buildPool(name, cfg)
+	if err != nil {
+		return nil, nil, config.PoolCfg{}, fmt.Errorf("%w: pool %q: %w", ErrInvalidPoolConfig, name, err)
+	}
+	return p, autoscaler, cfg, nil
+}
+
+// sameProviders reports whether two provider lists select the same providers
+// the same way. Provider instances are not compared.
+func sameProviders(a, b []pool.ProviderConfig) bool {
+	return slices.EqualFunc(a, b, func(x, y pool.ProviderConfig) bool {
+		return x.Name == y.Name &&
+			x.Priority == y.Priority &&
+			x.Weight == y.Weight &&
+			x.InstanceType == y.InstanceType &&
+			slices.Equal(x.Regions, y.Regions)
+	})
+}
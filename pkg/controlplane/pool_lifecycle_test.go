@@ -0,0 +1,339 @@
+package controlplane
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NavarchProject/navarch/pkg/clock"
+	"github.com/NavarchProject/navarch/pkg/config"
+	"github.com/NavarchProject/navarch/pkg/pool"
+)
+
+type drainNotifier struct {
+	mu      sync.Mutex
+	drains  []string
+	drained map[string]bool
+}
+
+func (n *drainNotifier) Cordon(ctx context.Context, nodeID, reason string) error { return nil }
+func (n *drainNotifier) Uncordon(ctx context.Context, nodeID string) error       { return nil }
+func (n *drainNotifier) Drain(ctx context.Context, nodeID, reason string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.drains = append(n.drains, nodeID)
+	return nil
+}
+func (n *drainNotifier) IsDrained(ctx context.Context, nodeID string) (bool, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.drained[nodeID], nil
+}
+func (n *drainNotifier) Name() string { return "drain-test" }
+
+func (n *drainNotifier) setDrained(nodeID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.drained[nodeID] = true
+}
+
+func newLifecyclePoolManager(t *testing.T, clk clock.Clock, prov *mockProvider) *PoolManager {
+	t.Helper()
+	cfg := &config.Config{
+		Providers: map[string]config.ProviderCfg{"mock": {Type: "fake"}},
+	}
+	return NewPoolManager(PoolManagerConfig{
+		EvaluationInterval: time.Minute,
+		Clock:              clk,
+		Config:             cfg,
+		DrainTimeout:       time.Minute,
+		PoolBuilder: func(name string, pc config.PoolCfg) (*pool.Pool, error) {
+			return pool.NewWithOptions(pool.NewPoolOptions{
+				Config: pool.Config{
+					Name:           name,
+					InstanceType:   pc.InstanceType,
+					MinNodes:       pc.MinNodes,
+					MaxNodes:       pc.MaxNodes,
+					CooldownPeriod: pc.Cooldown,
+					Labels:         pc.Labels,
+				},
+				Providers:        []pool.ProviderConfig{{Name: pc.Provider, Provider: prov}},
+				ProviderStrategy: pc.Strategy,
+				Clock:            clk,
+			})
+		},
+	}, nil, nil, nil)
+}
+
+// waitForPoolRemoval advances the clock until the pool is gone.
+func waitForPoolRemoval(t *testing.T, pm *PoolManager, fakeClock *clock.FakeClock, name string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, ok := pm.GetPool(name); !ok {
+			return
+		}
+		fakeClock.Advance(drainPollInterval)
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("pool %q was not removed", name)
+}
+
+func TestPoolManager_CreatePool(t *testing.T) {
+	ctx := context.Background()
+	pm := newLifecyclePoolManager(t, clock.NewFakeClock(time.Now()), &mockProvider{})
+
+	spec := config.PoolCfg{
+		Provider:     "mock",
+		InstanceType: "gpu-8x",
+		MaxNodes:     4,
+		Autoscaling:  &config.AutoscalingCfg{Type: "reactive"},
+	}
+	if err := pm.CreatePool(ctx, "training", spec); err != nil {
+		t.Fatalf("CreatePool failed: %v", err)
+	}
+
+	info, err := pm.DescribePool(ctx, "training")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.AutoscalerType != "reactive" {
+		t.Errorf("autoscaler type = %q, want reactive", info.AutoscalerType)
+	}
+	if info.Config.CooldownPeriod != 5*time.Minute {
+		t.Errorf("cooldown = %v, want default of 5m", info.Config.CooldownPeriod)
+	}
+
+	if err := pm.CreatePool(ctx, "training", spec); !errors.Is(err, ErrPoolExists) {
+		t.Errorf("duplicate CreatePool error = %v, want ErrPoolExists", err)
+	}
+
+	tests := []struct {
+		name string
+		spec config.PoolCfg
+	}{
+		{"unknown_provider", config.PoolCfg{Provider: "other", InstanceType: "gpu-8x", MaxNodes: 1}},
+		{"min_exceeds_max", config.PoolCfg{Provider: "mock", InstanceType: "gpu-8x", MinNodes: 3, MaxNodes: 1}},
+		{"bad_autoscaler", config.PoolCfg{Provider: "mock", InstanceType: "gpu-8x", MaxNodes: 1, Autoscaling: &config.AutoscalingCfg{Type: "bogus"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := pm.CreatePool(ctx, tt.name, tt.spec)
+			if !errors.Is(err, ErrInvalidPoolConfig) {
+				t.Errorf("error = %v, want ErrInvalidPoolConfig", err)
+			}
+			if _, ok := pm.GetPool(tt.name); ok {
+				t.Error("invalid pool should not be registered")
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		bare := NewPoolManager(PoolManagerConfig{}, nil, nil, nil)
+		if err := bare.CreatePool(ctx, "training", spec); !errors.Is(err, ErrRuntimePoolsDisabled) {
+			t.Errorf("error = %v, want ErrRuntimePoolsDisabled", err)
+		}
+	})
+}
+
+func TestPoolManager_CreatePool_StartsAutoscaler(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	prov := &mockProvider{}
+	pm := newLifecyclePoolManager(t, fakeClock, prov)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pm.Start(ctx)
+	defer pm.Stop()
+
+	// min_nodes with a reactive autoscaler and no metrics scales the pool
+	// up to its minimum on the first evaluation.
+	if err := pm.CreatePool(ctx, "late", config.PoolCfg{
+		Provider:     "mock",
+		InstanceType: "gpu-8x",
+		MinNodes:     2,
+		MaxNodes:     4,
+		Autoscaling:  &config.AutoscalingCfg{Type: "reactive"},
+	}); err != nil {
+		t.Fatalf("CreatePool failed: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for prov.provisions.Load() == 0 && time.Now().Before(deadline) {
+		fakeClock.Advance(time.Minute)
+		time.Sleep(time.Millisecond)
+	}
+	if prov.provisions.Load() == 0 {
+		t.Error("expected autoscaler of a pool created after Start to run")
+	}
+}
+
+func TestPoolManager_UpdatePool(t *testing.T) {
+	ctx := context.Background()
+	pm := newLifecyclePoolManager(t, clock.NewFakeClock(time.Now()), &mockProvider{})
+
+	spec := config.PoolCfg{
+		Provider:     "mock",
+		InstanceType: "gpu-8x",
+		MaxNodes:     4,
+	}
+	if err := pm.CreatePool(ctx, "training", spec); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.ScalePool(ctx, "training", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	spec.MaxNodes = 8
+	spec.Labels = map[string]string{"team": "ml"}
+	spec.Autoscaling = &config.AutoscalingCfg{Type: "queue"}
+	if err := pm.UpdatePool(ctx, "training", spec); err != nil {
+		t.Fatalf("UpdatePool failed: %v", err)
+	}
+
+	info, _ := pm.DescribePool(ctx, "training")
+	if info.Config.MaxNodes != 8 {
+		t.Errorf("max nodes = %d, want 8", info.Config.MaxNodes)
+	}
+	if info.Config.Labels["team"] != "ml" {
+		t.Errorf("labels = %v, want team=ml", info.Config.Labels)
+	}
+	if info.AutoscalerType != "queue" {
+		t.Errorf("autoscaler type = %q, want queue", info.AutoscalerType)
+	}
+	if info.Status.TotalNodes != 2 {
+		t.Errorf("total nodes = %d, want existing 2 nodes kept", info.Status.TotalNodes)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*config.PoolCfg)
+	}{
+		{"instance_type", func(c *config.PoolCfg) { c.InstanceType = "gpu-4x" }},
+		{"strategy", func(c *config.PoolCfg) { c.Strategy = "cost" }},
+		{"invalid", func(c *config.PoolCfg) { c.MinNodes = 10 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := spec
+			tt.mutate(&next)
+			if err := pm.UpdatePool(ctx, "training", next); !errors.Is(err, ErrInvalidPoolConfig) {
+				t.Errorf("error = %v, want ErrInvalidPoolConfig", err)
+			}
+			info, _ := pm.DescribePool(ctx, "training")
+			if info.Config.InstanceType != "gpu-8x" || info.Config.MaxNodes != 8 {
+				t.Errorf("rejected update changed the pool: %+v", info.Config)
+			}
+		})
+	}
+
+	if err := pm.UpdatePool(ctx, "nonexistent", spec); !errors.Is(err, ErrPoolNotFound) {
+		t.Errorf("error = %v, want ErrPoolNotFound", err)
+	}
+}
+
+func TestPoolManager_DeletePool(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("waits_for_drain", func(t *testing.T) {
+		fakeClock := clock.NewFakeClock(time.Now())
+		prov := &mockProvider{}
+		pm := newLifecyclePoolManager(t, fakeClock, prov)
+		n := &drainNotifier{drained: make(map[string]bool)}
+		pm.SetNotifier(n)
+
+		spec := config.PoolCfg{Provider: "mock", InstanceType: "gpu-8x", MaxNodes: 4}
+		if err := pm.CreatePool(ctx, "training", spec); err != nil {
+			t.Fatal(err)
+		}
+		if err := pm.ScalePool(ctx, "training", 2); err != nil {
+			t.Fatal(err)
+		}
+		p, _ := pm.GetPool("training")
+		nodes := p.Nodes()
+
+		if err := pm.DeletePool(ctx, "training"); err != nil {
+			t.Fatalf("DeletePool failed: %v", err)
+		}
+		info, err := pm.DescribePool(ctx, "training")
+		if err != nil {
+			t.Fatalf("pool removed before nodes drained: %v", err)
+		}
+		if !info.Deleting {
+			t.Error("expected pool to report Deleting")
+		}
+
+		if err := pm.DeletePool(ctx, "training"); !errors.Is(err, ErrPoolDeleting) {
+			t.Errorf("second DeletePool error = %v, want ErrPoolDeleting", err)
+		}
+		if err := pm.ScalePool(ctx, "training", 4); !errors.Is(err, ErrPoolDeleting) {
+			t.Errorf("ScalePool error = %v, want ErrPoolDeleting", err)
+		}
+		if err := pm.UpdatePool(ctx, "training", spec); !errors.Is(err, ErrPoolDeleting) {
+			t.Errorf("UpdatePool error = %v, want ErrPoolDeleting", err)
+		}
+
+		// Nothing is terminated until the notifier reports a node drained.
+		fakeClock.Advance(drainPollInterval)
+		time.Sleep(10 * time.Millisecond)
+		if got := prov.terminates.Load(); got != 0 {
+			t.Fatalf("terminated %d nodes before drain completed", got)
+		}
+
+		for _, node := range nodes {
+			n.setDrained(node.Node.ID)
+		}
+		waitForPoolRemoval(t, pm, fakeClock, "training")
+
+		if got := prov.terminates.Load(); got != 2 {
+			t.Errorf("terminated %d nodes, want 2", got)
+		}
+		n.mu.Lock()
+		drains := len(n.drains)
+		n.mu.Unlock()
+		if drains != 2 {
+			t.Errorf("drained %d nodes, want 2", drains)
+		}
+	})
+
+	t.Run("timeout_terminates", func(t *testing.T) {
+		fakeClock := clock.NewFakeClock(time.Now())
+		prov := &mockProvider{}
+		pm := newLifecyclePoolManager(t, fakeClock, prov)
+		pm.SetNotifier(&drainNotifier{drained: make(map[string]bool)})
+
+		if err := pm.CreatePool(ctx, "training", config.PoolCfg{Provider: "mock", InstanceType: "gpu-8x", MaxNodes: 4}); err != nil {
+			t.Fatal(err)
+		}
+		if err := pm.ScalePool(ctx, "training", 1); err != nil {
+			t.Fatal(err)
+		}
+		if err := pm.DeletePool(ctx, "training"); err != nil {
+			t.Fatal(err)
+		}
+
+		waitForPoolRemoval(t, pm, fakeClock, "training")
+		if got := prov.terminates.Load(); got != 1 {
+			t.Errorf("terminated %d nodes, want 1", got)
+		}
+	})
+
+	t.Run("without_notifier", func(t *testing.T) {
+		fakeClock := clock.NewFakeClock(time.Now())
+		pm := newLifecyclePoolManager(t, fakeClock, &mockProvider{})
+
+		if err := pm.CreatePool(ctx, "empty", config.PoolCfg{Provider: "mock", InstanceType: "gpu-8x", MaxNodes: 1}); err != nil {
+			t.Fatal(err)
+		}
+		if err := pm.DeletePool(ctx, "empty"); err != nil {
+			t.Fatal(err)
+		}
+		waitForPoolRemoval(t, pm, fakeClock, "empty")
+	})
+
+	if err := newLifecyclePoolManager(t, clock.Real(), &mockProvider{}).DeletePool(ctx, "nonexistent"); !errors.Is(err, ErrPoolNotFound) {
+		t.Errorf("error = %v, want ErrPoolNotFound", err)
+	}
+}
@@ -2,18 +2,33 @@ package controlplane
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/NavarchProject/navarch/pkg/bootstrap"
 	"github.com/NavarchProject/navarch/pkg/clock"
+	"github.com/NavarchProject/navarch/pkg/config"
 	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	"github.com/NavarchProject/navarch/pkg/notifier"
 	"github.com/NavarchProject/navarch/pkg/pool"
 	"github.com/NavarchProject/navarch/pkg/provider"
 )
 
+var (
+	ErrPoolNotFound      = errors.New("pool not found")
+	ErrPoolExists        = errors.New("pool already exists")
+	ErrPoolDeleting      = errors.New("pool is being deleted")
+	ErrInvalidPoolConfig = errors.New("invalid pool configuration")
+
+	// ErrRuntimePoolsDisabled is returned by CreatePool and UpdatePool when
+	// the pool manager was created without a Config and PoolBuilder.
+	ErrRuntimePoolsDisabled = errors.New("runtime pool configuration is not enabled")
+)
+
 // PoolManager orchestrates multiple GPU node pools, running autoscalers
 // and acting on scaling recommendations. It integrates with InstanceManager
 // to maintain visibility into instance lifecycle from provisioning through
@@ -27,12 +42,36 @@ type PoolManager struct {
 	metrics         MetricsSource
 	instanceManager *InstanceManager
 	db              db.DB
+	notifier        notifier.Notifier
+	fleetConfig     *config.Config
+	buildPool       PoolBuilder
+	drainTimeout    time.Duration
+	clusterID       string
+	runCtx          context.Context // Set by Start; parent of autoscaler loops for pools added later
 }
 
 type managedPool struct {
 	pool       *pool.Pool
 	autoscaler pool.Autoscaler
 	cancel     context.CancelFunc
+	spec       *config.PoolCfg // Configuration the pool was built from; nil if added with AddPool
+	deleting   bool            // Set by DeletePool while nodes drain
+
+	// Most recent autoscaler output, guarded by PoolManager.mu.
+	lastRecommendation *pool.ScaleRecommendation
+	lastEvaluatedAt    time.Time
+}
+
+// PoolInfo describes a pool: its configuration, current status, and the
+// state of its autoscaler.
+type PoolInfo struct {
+	Config             pool.Config
+	Providers          []string // Provider names in priority order
+	Status             pool.Status
+	AutoscalerType     string                    // "none" if the pool has no autoscaler
+	LastRecommendation *pool.ScaleRecommendation // nil until the autoscaler first runs
+	LastEvaluatedAt    time.Time
+	Deleting           bool // Nodes are draining before the pool is removed
 }
 
 // MetricsSource provides pool metrics for autoscaler decisions.
@@ -55,9 +94,25 @@ type PoolMetrics struct {
 type PoolManagerConfig struct {
 	EvaluationInterval time.Duration // How often to run autoscaler (default: 30s)
 	Clock              clock.Clock   // Clock for time operations. If nil, uses real time.
-	DB                 db.DB         // Database for storing bootstrap logs. Optional.
+	DB                 db.DB         // Database for bootstrap logs and drain status. Optional.
+
+	// Config provides the providers and defaults that pools created at
+	// runtime are validated against. Required, with PoolBuilder, for
+	// CreatePool and UpdatePool.
+	Config      *config.Config
+	PoolBuilder PoolBuilder
+
+	DrainTimeout time.Duration // Max wait for a deleted pool's nodes to drain (default: 30m)
+
+	// ClusterID identifies this control plane's instances by their
+	// pool.ClusterLabel. Default: pool.DefaultClusterID.
+	ClusterID string
 }
 
+// PoolBuilder creates a pool, without autoscaler, from its configuration.
+// The configuration has already been validated and had defaults applied.
+type PoolBuilder func(name string, cfg config.PoolCfg) (*pool.Pool, error)
+
 // NewPoolManager creates a new pool manager.
 // The instanceManager parameter is optional; if nil, instance lifecycle tracking is disabled.
 func NewPoolManager(cfg PoolManagerConfig, metrics MetricsSource, instanceManager *InstanceManager, logger *slog.Logger) *PoolManager {
@@ -72,6 +127,14 @@ func NewPoolManager(cfg PoolManagerConfig, metrics MetricsSource, instanceManage
 	if clk == nil {
 		clk = clock.Real()
 	}
+	drainTimeout := cfg.DrainTimeout
+	if drainTimeout == 0 {
+		drainTimeout = 30 * time.Minute
+	}
+	clusterID := cfg.ClusterID
+	if clusterID == "" {
+		clusterID = pool.DefaultClusterID
+	}
 	return &PoolManager{
 		pools:           make(map[string]*managedPool),
 		logger:          logger,
@@ -80,17 +143,34 @@ func NewPoolManager(cfg PoolManagerConfig, metrics MetricsSource, instanceManage
 		metrics:         metrics,
 		instanceManager: instanceManager,
 		db:              cfg.DB,
+		fleetConfig:     cfg.Config,
+		buildPool:       cfg.PoolBuilder,
+		drainTimeout:    drainTimeout,
+		clusterID:       clusterID,
 	}
 }
 
+// SetNotifier sets the notifier used to drain nodes of deleted pools.
+// If not set, nodes are terminated without waiting for workloads to drain.
+// A deletion already in progress keeps the notifier it started with.
+func (pm *PoolManager) SetNotifier(n notifier.Notifier) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.notifier = n
+}
+
 // AddPool registers a pool with its autoscaler.
 func (pm *PoolManager) AddPool(p *pool.Pool, autoscaler pool.Autoscaler) error {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
+	_, err := pm.addPoolLocked(p, autoscaler)
+	return err
+}
 
+func (pm *PoolManager) addPoolLocked(p *pool.Pool, autoscaler pool.Autoscaler) (*managedPool, error) {
 	name := p.Config().Name
 	if _, exists := pm.pools[name]; exists {
-		return fmt.Errorf("pool %q already exists", name)
+		return nil, fmt.Errorf("%w: %s", ErrPoolExists, name)
 	}
 
 	// Wire up bootstrap log recording if DB is available
@@ -98,12 +178,18 @@ func (pm *PoolManager) AddPool(p *pool.Pool, autoscaler pool.Autoscaler) error {
 		p.SetBootstrapCallback(pm.makeBootstrapCallback(name))
 	}
 
-	pm.pools[name] = &managedPool{
+	mp := &managedPool{
 		pool:       p,
 		autoscaler: autoscaler,
 	}
+	pm.pools[name] = mp
 	pm.logger.Info("pool registered", slog.String("pool", name))
-	return nil
+
+	// Pools added after Start get their autoscaler loop right away.
+	if pm.runCtx != nil {
+		pm.startAutoscalerLocked(name, mp)
+	}
+	return mp, nil
 }
 
 // RemovePool unregisters a pool and stops its autoscaler.
@@ -113,7 +199,7 @@ func (pm *PoolManager) RemovePool(name string) error {
 
 	mp, exists := pm.pools[name]
 	if !exists {
-		return fmt.Errorf("pool %q not found", name)
+		return fmt.Errorf("%w: %s", ErrPoolNotFound, name)
 	}
 	if mp.cancel != nil {
 		mp.cancel()
@@ -128,14 +214,22 @@ func (pm *PoolManager) Start(ctx context.Context) {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
+	pm.runCtx = ctx
 	for name, mp := range pm.pools {
-		loopCtx, cancel := context.WithCancel(ctx)
-		mp.cancel = cancel
-		go pm.runAutoscalerLoop(loopCtx, name, mp)
+		pm.startAutoscalerLocked(name, mp)
 	}
 	pm.logger.Info("pool manager started", slog.Int("pools", len(pm.pools)))
 }
 
+func (pm *PoolManager) startAutoscalerLocked(name string, mp *managedPool) {
+	if mp.deleting {
+		return
+	}
+	loopCtx, cancel := context.WithCancel(pm.runCtx)
+	mp.cancel = cancel
+	go pm.runAutoscalerLoop(loopCtx, name, mp)
+}
+
 // Stop halts all autoscaler loops.
 func (pm *PoolManager) Stop() {
 	pm.mu.Lock()
@@ -146,9 +240,174 @@ func (pm *PoolManager) Stop() {
 			mp.cancel()
 		}
 	}
+	pm.runCtx = nil
 	pm.logger.Info("pool manager stopped")
 }
 
+// ReconcileReport summarizes a reconciliation pass.
+type ReconcileReport struct {
+	Adopted  []ReconciledInstance // Instances re-adopted into their pool
+	Orphaned []ReconciledInstance // Pool-labelled instances not adopted
+}
+
+// ReconciledInstance identifies an instance seen during reconciliation.
+type ReconciledInstance struct {
+	InstanceID string
+	Provider   string // Provider name as configured in the pool
+	Pool       string // Value of the pool label
+}
+
+// Reconcile lists each provider's instances and re-adopts those carrying
+// this control plane's ownership labels for a registered pool into that pool
+// and into the InstanceManager. Instances labelled with a pool that is not
+// registered, or that the pool does not use the listing provider for, are
+// reported as orphans. So are instances that only carry the generic pool
+// label: that label alone does not show they were provisioned by Navarch, so
+// they are left for an operator to check rather than adopted. Instances
+// without a pool label are not managed by Navarch and are ignored.
+//
+// Call Reconcile after adding pools and before Start so the autoscaler sees
+// existing capacity instead of provisioning duplicates. Providers that fail
+// to list are skipped; their errors are returned alongside the partial report.
+func (pm *PoolManager) Reconcile(ctx context.Context) (*ReconcileReport, error) {
+	pools, entries, err := pm.inventory(ctx)
+
+	report := &ReconcileReport{}
+	for _, e := range entries {
+		instance := ReconciledInstance{
+			InstanceID: e.node.ID,
+			Provider:   e.providerName,
+			Pool:       e.poolName,
+		}
+
+		mp, ok := pools[e.poolName]
+		if !ok || !e.owned || !mp.pool.Adopt(e.node, e.providerName) {
+			if ok && mp.pool.HasNode(e.node.ID) {
+				continue
+			}
+			msg := "orphaned instance"
+			if !e.owned {
+				msg = "instance without ownership labels not adopted"
+			}
+			pm.logger.Warn(msg,
+				slog.String("instance_id", e.node.ID),
+				slog.String("provider", e.providerName),
+				slog.String("pool", e.poolName),
+			)
+			report.Orphaned = append(report.Orphaned, instance)
+			continue
+		}
+
+		report.Adopted = append(report.Adopted, instance)
+		if pm.instanceManager != nil {
+			node := e.node
+			if err := pm.instanceManager.TrackAdopted(ctx, node.ID, node.Provider, node.Region, node.Zone, node.InstanceType, e.poolName, node.Labels); err != nil {
+				pm.logger.Warn("failed to track adopted instance",
+					slog.String("instance_id", node.ID),
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+	}
+
+	pm.logger.Info("reconciliation complete",
+		slog.Int("adopted", len(report.Adopted)),
+		slog.Int("orphaned", len(report.Orphaned)),
+	)
+	return report, err
+}
+
+// findProvider returns the provider registered under name by any pool,
+// matching either the configured provider name or the provider's own Name.
+func (pm *PoolManager) findProvider(name string) provider.Provider {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	for _, mp := range pm.pools {
+		for _, pc := range mp.pool.Providers() {
+			if pc.Name == name || pc.Provider.Name() == name {
+				return pc.Provider
+			}
+		}
+	}
+	return nil
+}
+
+// inventoryEntry is a live, pool-labelled instance reported by a provider.
+type inventoryEntry struct {
+	node         *provider.Node
+	poolName     string // Value of the owner label, or of the pool label if it has none
+	providerName string // Provider name as configured in the pool
+	provider     provider.Provider
+	owned        bool // Carries this control plane's owner and cluster labels
+}
+
+// inventory lists the instances of every provider used by a registered pool
+// and returns the pool-labelled ones that are not terminating or terminated,
+// along with a snapshot of the registered pools. Instances labelled for
+// another cluster are left out. Providers that fail to list are skipped and
+// their errors joined into the returned error.
+func (pm *PoolManager) inventory(ctx context.Context) (map[string]*managedPool, []inventoryEntry, error) {
+	pm.mu.RLock()
+	pools := make(map[string]*managedPool, len(pm.pools))
+	for name, mp := range pm.pools {
+		pools[name] = mp
+	}
+	pm.mu.RUnlock()
+
+	// Providers may back several pools; list each one only once.
+	providers := make(map[string]provider.Provider)
+	for _, mp := range pools {
+		for _, pc := range mp.pool.Providers() {
+			providers[pc.Name] = pc.Provider
+		}
+	}
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var entries []inventoryEntry
+	var errs []error
+	for _, providerName := range names {
+		prov := providers[providerName]
+		nodes, err := prov.List(ctx)
+		if err != nil {
+			pm.logger.Error("failed to list provider instances",
+				slog.String("provider", providerName),
+				slog.String("error", err.Error()),
+			)
+			errs = append(errs, fmt.Errorf("provider %s: %w", providerName, err))
+			continue
+		}
+
+		for _, node := range nodes {
+			// Instances provisioned before the owner label existed only
+			// carry the pool label.
+			poolName := node.Labels[pool.OwnerLabel]
+			owned := poolName != "" && node.Labels[pool.ClusterLabel] == pm.clusterID
+			if poolName == "" {
+				poolName = node.Labels[pool.NameLabel]
+			}
+			if poolName == "" || node.Status == "terminating" || node.Status == "terminated" {
+				continue
+			}
+			if cluster := node.Labels[pool.ClusterLabel]; cluster != "" && cluster != pm.clusterID {
+				continue
+			}
+			entries = append(entries, inventoryEntry{
+				node:         node,
+				poolName:     poolName,
+				providerName: providerName,
+				provider:     prov,
+				owned:        owned,
+			})
+		}
+	}
+	return pools, entries, errors.Join(errs...)
+}
+
 // GetPool returns a pool by name.
 func (pm *PoolManager) GetPool(name string) (*pool.Pool, bool) {
 	pm.mu.RLock()
@@ -177,11 +436,90 @@ func (pm *PoolManager) GetPoolStatus(name string) (pool.Status, error) {
 	defer pm.mu.RUnlock()
 	mp, ok := pm.pools[name]
 	if !ok {
-		return pool.Status{}, fmt.Errorf("pool %q not found", name)
+		return pool.Status{}, fmt.Errorf("%w: %s", ErrPoolNotFound, name)
 	}
 	return mp.pool.Status(), nil
 }
 
+// DescribePool returns the configuration, status, and autoscaler state of a
+// pool. Utilization is filled in from the metrics source when one is set.
+func (pm *PoolManager) DescribePool(ctx context.Context, name string) (PoolInfo, error) {
+	pm.mu.RLock()
+	mp, ok := pm.pools[name]
+	var info PoolInfo
+	if ok {
+		info = PoolInfo{
+			Config:          mp.pool.Config(),
+			Status:          mp.pool.Status(),
+			AutoscalerType:  autoscalerType(mp.autoscaler),
+			LastEvaluatedAt: mp.lastEvaluatedAt,
+			Deleting:        mp.deleting,
+		}
+		if mp.lastRecommendation != nil {
+			rec := *mp.lastRecommendation
+			info.LastRecommendation = &rec
+		}
+		for _, pc := range mp.pool.Providers() {
+			info.Providers = append(info.Providers, pc.Name)
+		}
+	}
+	pm.mu.RUnlock()
+	if !ok {
+		return PoolInfo{}, fmt.Errorf("%w: %s", ErrPoolNotFound, name)
+	}
+
+	if pm.metrics != nil {
+		metrics, err := pm.metrics.GetPoolMetrics(ctx, name)
+		if err != nil {
+			pm.logger.Warn("failed to get pool metrics",
+				slog.String("pool", name),
+				slog.String("error", err.Error()),
+			)
+		} else if metrics != nil {
+			info.Status.Utilization = metrics.Utilization
+		}
+	}
+
+	return info, nil
+}
+
+// DescribePools returns every pool, sorted by name.
+func (pm *PoolManager) DescribePools(ctx context.Context) []PoolInfo {
+	names := pm.ListPools()
+	sort.Strings(names)
+
+	infos := make([]PoolInfo, 0, len(names))
+	for _, name := range names {
+		info, err := pm.DescribePool(ctx, name)
+		if err != nil {
+			// Removed since ListPools; skip it.
+			continue
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// autoscalerType returns the configuration name of an autoscaler.
+func autoscalerType(a pool.Autoscaler) string {
+	switch a.(type) {
+	case nil:
+		return "none"
+	case *pool.ReactiveAutoscaler:
+		return "reactive"
+	case *pool.QueueBasedAutoscaler:
+		return "queue"
+	case *pool.ScheduledAutoscaler:
+		return "scheduled"
+	case *pool.PredictiveAutoscaler:
+		return "predictive"
+	case *pool.CompositeAutoscaler:
+		return "composite"
+	default:
+		return fmt.Sprintf("%T", a)
+	}
+}
+
 func (pm *PoolManager) runAutoscalerLoop(ctx context.Context, name string, mp *managedPool) {
 	ticker := pm.clock.NewTicker(pm.interval)
 	defer ticker.Stop()
@@ -203,7 +541,11 @@ func (pm *PoolManager) runAutoscalerLoop(ctx context.Context, name string, mp *m
 }
 
 func (pm *PoolManager) evaluate(ctx context.Context, name string, mp *managedPool) {
-	if mp.autoscaler == nil {
+	// UpdatePool may swap the autoscaler.
+	pm.mu.RLock()
+	autoscaler := mp.autoscaler
+	pm.mu.RUnlock()
+	if autoscaler == nil {
 		return
 	}
 
@@ -216,7 +558,7 @@ func (pm *PoolManager) evaluate(ctx context.Context, name string, mp *managedPoo
 		return
 	}
 
-	rec, err := mp.autoscaler.Recommend(ctx, state)
+	rec, err := autoscaler.Recommend(ctx, state)
 	if err != nil {
 		pm.logger.Error("autoscaler recommendation failed",
 			slog.String("pool", name),
@@ -225,6 +567,11 @@ func (pm *PoolManager) evaluate(ctx context.Context, name string, mp *managedPoo
 		return
 	}
 
+	pm.mu.Lock()
+	mp.lastRecommendation = &rec
+	mp.lastEvaluatedAt = pm.clock.Now()
+	pm.mu.Unlock()
+
 	pm.actOnRecommendation(ctx, name, mp, state.CurrentNodes, rec)
 }
 
@@ -405,9 +752,13 @@ func (pm *PoolManager) trackTerminatedInstances(ctx context.Context, before, aft
 func (pm *PoolManager) ScalePool(ctx context.Context, name string, target int) error {
 	pm.mu.RLock()
 	mp, ok := pm.pools[name]
+	deleting := ok && mp.deleting
 	pm.mu.RUnlock()
 	if !ok {
-		return fmt.Errorf("pool %q not found", name)
+		return fmt.Errorf("%w: %s", ErrPoolNotFound, name)
+	}
+	if deleting {
+		return fmt.Errorf("%w: %s", ErrPoolDeleting, name)
 	}
 
 	status := mp.pool.Status()
@@ -416,10 +767,16 @@ func (pm *PoolManager) ScalePool(ctx context.Context, name string, target int) e
 	}
 
 	if target > status.TotalNodes {
-		_, err := mp.pool.ScaleUp(ctx, target-status.TotalNodes)
+		nodes, err := mp.pool.ScaleUp(ctx, target-status.TotalNodes)
+		// ScaleUp may provision some nodes before failing; track those too.
+		pm.trackProvisionedInstances(ctx, name, mp, nodes)
 		return err
 	}
-	return mp.pool.ScaleDown(ctx, status.TotalNodes-target)
+
+	nodesBefore := mp.pool.Nodes()
+	err := mp.pool.ScaleDown(ctx, status.TotalNodes-target)
+	pm.trackTerminatedInstances(ctx, nodesBefore, mp.pool.Nodes())
+	return err
 }
 
 // OnNodeUnhealthy implements NodeHealthObserver. It finds the pool containing
@@ -470,6 +827,17 @@ func (pm *PoolManager) OnNodeUnhealthy(ctx context.Context, nodeID string) {
 // handleUnhealthyNode processes an unhealthy node and triggers replacement
 // if the pool is configured for auto-replacement.
 func (pm *PoolManager) handleUnhealthyNode(ctx context.Context, nodeID, poolName string, mp *managedPool) error {
+	pm.mu.RLock()
+	deleting := mp.deleting
+	pm.mu.RUnlock()
+	if deleting {
+		pm.logger.Debug("pool is being deleted, skipping replacement",
+			slog.String("pool", poolName),
+			slog.String("node_id", nodeID),
+		)
+		return nil
+	}
+
 	cfg := mp.pool.Config()
 	if !cfg.AutoReplace {
 		pm.logger.Debug("auto-replace disabled, skipping replacement",
@@ -180,6 +180,71 @@ func TestPoolManager_ScalePool(t *testing.T) {
 	}
 }
 
+func TestPoolManager_DescribePool(t *testing.T) {
+	metrics := &mockMetrics{utilization: 90}
+	pm := NewPoolManager(PoolManagerConfig{}, metrics, nil, nil)
+
+	prov := &mockProvider{}
+	p, _ := pool.NewSimple(pool.Config{
+		Name:     "describe-test",
+		MinNodes: 1,
+		MaxNodes: 5,
+	}, prov, "mock")
+	pm.AddPool(p, pool.NewReactiveAutoscaler(80, 20))
+
+	manual, _ := pool.NewSimple(pool.Config{Name: "manual", MaxNodes: 2}, prov, "mock")
+	pm.AddPool(manual, nil)
+
+	ctx := context.Background()
+	info, err := pm.DescribePool(ctx, "describe-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.AutoscalerType != "reactive" {
+		t.Errorf("autoscaler type = %q, want reactive", info.AutoscalerType)
+	}
+	if info.LastRecommendation != nil {
+		t.Error("expected no recommendation before the autoscaler runs")
+	}
+	if info.Status.Utilization != 90 {
+		t.Errorf("utilization = %v, want 90", info.Status.Utilization)
+	}
+	if len(info.Providers) != 1 || info.Providers[0] != "mock" {
+		t.Errorf("providers = %v, want [mock]", info.Providers)
+	}
+	if info.Config.MaxNodes != 5 {
+		t.Errorf("max nodes = %d, want 5", info.Config.MaxNodes)
+	}
+
+	pm.mu.RLock()
+	mp := pm.pools["describe-test"]
+	pm.mu.RUnlock()
+	pm.evaluate(ctx, "describe-test", mp)
+
+	info, _ = pm.DescribePool(ctx, "describe-test")
+	if info.LastRecommendation == nil {
+		t.Fatal("expected a recommendation after evaluation")
+	}
+	if info.LastRecommendation.TargetNodes != 1 {
+		t.Errorf("recommended %d nodes, want 1", info.LastRecommendation.TargetNodes)
+	}
+	if info.LastEvaluatedAt.IsZero() {
+		t.Error("expected LastEvaluatedAt to be set")
+	}
+
+	pools := pm.DescribePools(ctx)
+	if len(pools) != 2 || pools[0].Config.Name != "describe-test" || pools[1].Config.Name != "manual" {
+		t.Fatalf("DescribePools returned unexpected pools: %+v", pools)
+	}
+	if pools[1].AutoscalerType != "none" {
+		t.Errorf("manual pool autoscaler type = %q, want none", pools[1].AutoscalerType)
+	}
+
+	if _, err := pm.DescribePool(ctx, "nonexistent"); err == nil {
+		t.Error("expected error for nonexistent pool")
+	}
+}
+
 func TestPoolManager_StartStop(t *testing.T) {
 	fakeClock := clock.NewFakeClock(time.Now())
 	pm := NewPoolManager(PoolManagerConfig{
@@ -484,3 +549,99 @@ func TestPoolManager_IntegrationWithDBMetrics(t *testing.T) {
 	cancel()
 	pm.Stop()
 }
+
+type listingProvider struct {
+	mockProvider
+	nodes   []*provider.Node
+	listErr error
+}
+
+func (l *listingProvider) List(ctx context.Context) ([]*provider.Node, error) {
+	return l.nodes, l.listErr
+}
+
+func TestPoolManager_Reconcile(t *testing.T) {
+	database := db.NewInMemDB()
+	defer database.Close()
+	im := NewInstanceManager(database, DefaultInstanceManagerConfig(), nil)
+	pm := NewPoolManager(PoolManagerConfig{}, nil, im, nil)
+	ctx := context.Background()
+
+	owned := func(poolName, cluster string) map[string]string {
+		return map[string]string{pool.NameLabel: poolName, pool.OwnerLabel: poolName, pool.ClusterLabel: cluster}
+	}
+	prov := &listingProvider{nodes: []*provider.Node{
+		{ID: "i-1", Provider: "mock", Status: "running", Labels: owned("training", pool.DefaultClusterID)},
+		{ID: "i-2", Provider: "mock", Status: "provisioning", Labels: owned("training", pool.DefaultClusterID)},
+		{ID: "i-3", Provider: "mock", Status: "running", Labels: owned("deleted-pool", pool.DefaultClusterID)},
+		{ID: "i-4", Provider: "mock", Status: "terminated", Labels: owned("training", pool.DefaultClusterID)},
+		{ID: "i-5", Provider: "mock", Status: "running"},
+		{ID: "i-6", Provider: "mock", Status: "running", Labels: owned("training", "other")},
+		// A plain pool label may come from other tooling in the account.
+		{ID: "i-7", Provider: "mock", Status: "running", Labels: map[string]string{pool.NameLabel: "training"}},
+	}}
+	training, _ := pool.NewSimple(pool.Config{Name: "training", MaxNodes: 5}, prov, "mock")
+	inference, _ := pool.NewSimple(pool.Config{Name: "inference", MaxNodes: 5}, prov, "mock")
+	pm.AddPool(training, nil)
+	pm.AddPool(inference, nil)
+
+	report, err := pm.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	if len(report.Adopted) != 2 {
+		t.Fatalf("Expected 2 adopted instances, got %d", len(report.Adopted))
+	}
+	var orphaned []string
+	for _, o := range report.Orphaned {
+		orphaned = append(orphaned, o.InstanceID)
+	}
+	if fmt.Sprint(orphaned) != "[i-3 i-7]" {
+		t.Errorf("Expected i-3 and i-7 to be orphaned, got %v", orphaned)
+	}
+	if training.Status().TotalNodes != 2 {
+		t.Errorf("Expected training pool to have 2 nodes, got %d", training.Status().TotalNodes)
+	}
+	if inference.Status().TotalNodes != 0 {
+		t.Errorf("Expected inference pool to have 0 nodes, got %d", inference.Status().TotalNodes)
+	}
+
+	instance, err := im.GetInstance(ctx, "i-1")
+	if err != nil {
+		t.Fatalf("Expected adopted instance to be tracked: %v", err)
+	}
+	if instance.PoolName != "training" || instance.State != pb.InstanceState_INSTANCE_STATE_RUNNING {
+		t.Errorf("Unexpected instance record: pool=%s state=%v", instance.PoolName, instance.State)
+	}
+
+	// A second pass is a no-op.
+	report, _ = pm.Reconcile(ctx)
+	if len(report.Adopted) != 0 {
+		t.Errorf("Expected no adoptions on second pass, got %d", len(report.Adopted))
+	}
+	if training.Status().TotalNodes != 2 {
+		t.Errorf("Expected training pool to still have 2 nodes, got %d", training.Status().TotalNodes)
+	}
+}
+
+func TestPoolManager_Reconcile_ListError(t *testing.T) {
+	pm := NewPoolManager(PoolManagerConfig{}, nil, nil, nil)
+
+	failing := &listingProvider{listErr: fmt.Errorf("api unavailable")}
+	healthy := &listingProvider{nodes: []*provider.Node{
+		{ID: "i-1", Status: "running", Labels: map[string]string{pool.OwnerLabel: "b", pool.ClusterLabel: pool.DefaultClusterID}},
+	}}
+	a, _ := pool.NewSimple(pool.Config{Name: "a", MaxNodes: 5}, failing, "failing")
+	b, _ := pool.NewSimple(pool.Config{Name: "b", MaxNodes: 5}, healthy, "healthy")
+	pm.AddPool(a, nil)
+	pm.AddPool(b, nil)
+
+	report, err := pm.Reconcile(context.Background())
+	if err == nil {
+		t.Error("Expected error when a provider fails to list")
+	}
+	if len(report.Adopted) != 1 {
+		t.Errorf("Expected healthy provider to still be reconciled, got %d adopted", len(report.Adopted))
+	}
+}
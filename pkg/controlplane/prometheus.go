@@ -2,6 +2,7 @@ package controlplane
 
 import (
 	"context"
+	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -19,6 +20,10 @@ type PrometheusMetrics struct {
 
 	// Health metrics (pulled from DB on each scrape)
 	nodeHealthStatus *prometheus.GaugeVec
+	nodeHeld         *prometheus.GaugeVec
+
+	// Burn-in metrics (counted as nodes finish burn-in)
+	burnIns *prometheus.CounterVec
 }
 
 // NewPrometheusMetrics creates a new PrometheusMetrics instance.
@@ -46,6 +51,20 @@ func NewPrometheusMetrics(database db.DB) *PrometheusMetrics {
 			},
 			[]string{"node_id", "status"},
 		),
+		nodeHeld: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "navarch_node_held",
+				Help: "Nodes held in their status by the control plane, such as flapping nodes (1=held)",
+			},
+			[]string{"node_id", "reason"},
+		),
+		burnIns: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "navarch_burn_in_total",
+				Help: "Nodes that finished burn-in by provider, pool, and result (passed, failed, timeout)",
+			},
+			[]string{"provider", "pool", "result"},
+		),
 	}
 
 	return pm
@@ -56,6 +75,8 @@ func (pm *PrometheusMetrics) Describe(ch chan<- *prometheus.Desc) {
 	pm.nodesTotal.Describe(ch)
 	pm.gpusTotal.Describe(ch)
 	pm.nodeHealthStatus.Describe(ch)
+	pm.nodeHeld.Describe(ch)
+	pm.burnIns.Describe(ch)
 }
 
 // Collect implements prometheus.Collector and updates metrics from the database.
@@ -67,6 +88,22 @@ func (pm *PrometheusMetrics) Collect(ch chan<- prometheus.Metric) {
 	pm.nodesTotal.Collect(ch)
 	pm.gpusTotal.Collect(ch)
 	pm.nodeHealthStatus.Collect(ch)
+	pm.nodeHeld.Collect(ch)
+	pm.burnIns.Collect(ch)
+}
+
+// OnBurnInComplete implements BurnInObserver by counting the result against
+// the node's provider and pool.
+func (pm *PrometheusMetrics) OnBurnInComplete(ctx context.Context, node *db.NodeRecord, result string) {
+	provider := node.Provider
+	if provider == "" {
+		provider = "unknown"
+	}
+	var pool string
+	if node.Metadata != nil {
+		pool = node.Metadata.Labels["pool"]
+	}
+	pm.burnIns.WithLabelValues(provider, pool, result).Inc()
 }
 
 func (pm *PrometheusMetrics) collectNodeMetrics(ctx context.Context) {
@@ -107,11 +144,26 @@ func (pm *PrometheusMetrics) collectHealthMetrics(ctx context.Context) {
 	}
 
 	pm.nodeHealthStatus.Reset()
+	pm.nodeHeld.Reset()
 	for _, node := range nodes {
 		healthStatus := healthStatusString(node.HealthStatus)
 		healthValue := healthStatusValue(node.HealthStatus)
 		pm.nodeHealthStatus.WithLabelValues(node.NodeID, healthStatus).Set(healthValue)
+
+		if !node.HoldUntil.IsZero() {
+			pm.nodeHeld.WithLabelValues(node.NodeID, holdReasonLabel(node.StatusReason)).Set(1)
+		}
+	}
+}
+
+// holdReasonLabel returns the kind of hold from a status reason such as
+// "flapping: 4 health transitions in 30m0s", keeping label values few.
+func holdReasonLabel(reason string) string {
+	kind, _, _ := strings.Cut(reason, ":")
+	if kind == "" {
+		return "unknown"
 	}
+	return kind
 }
 
 func nodeStatusString(status pb.NodeStatus) string {
@@ -126,6 +178,8 @@ func nodeStatusString(status pb.NodeStatus) string {
 		return "unhealthy"
 	case pb.NodeStatus_NODE_STATUS_TERMINATED:
 		return "terminated"
+	case pb.NodeStatus_NODE_STATUS_QUALIFYING:
+		return "qualifying"
 	default:
 		return "unknown"
 	}
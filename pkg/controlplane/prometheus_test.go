@@ -2,7 +2,9 @@ package controlplane
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
@@ -93,6 +95,59 @@ func TestPrometheusMetrics_HealthStatus(t *testing.T) {
 	}
 }
 
+func TestPrometheusMetrics_NodeHeld(t *testing.T) {
+	database := db.NewInMemDB()
+	defer database.Close()
+	ctx := context.Background()
+
+	database.RegisterNode(ctx, &db.NodeRecord{NodeID: "node-1"})
+	database.RegisterNode(ctx, &db.NodeRecord{NodeID: "node-2"})
+	database.UpdateNodeHold(ctx, "node-2", "flapping: 4 health transitions in 30m0s", time.Now().Add(time.Hour))
+
+	pm := NewPrometheusMetrics(database)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(pm)
+
+	expected := `
+# HELP navarch_node_held Nodes held in their status by the control plane, such as flapping nodes (1=held)
+# TYPE navarch_node_held gauge
+navarch_node_held{node_id="node-2",reason="flapping"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "navarch_node_held"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPrometheusMetrics_BurnIn(t *testing.T) {
+	database := db.NewInMemDB()
+	defer database.Close()
+	ctx := context.Background()
+
+	pm := NewPrometheusMetrics(database)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(pm)
+
+	node := &db.NodeRecord{
+		NodeID:   "node-1",
+		Provider: "lambda",
+		Metadata: &pb.NodeMetadata{Labels: map[string]string{"pool": "training"}},
+	}
+	pm.OnBurnInComplete(ctx, node, BurnInPassed)
+	pm.OnBurnInComplete(ctx, node, BurnInFailed)
+	pm.OnBurnInComplete(ctx, node, BurnInFailed)
+
+	expected := `
+# HELP navarch_burn_in_total Nodes that finished burn-in by provider, pool, and result (passed, failed, timeout)
+# TYPE navarch_burn_in_total counter
+navarch_burn_in_total{pool="training",provider="lambda",result="failed"} 2
+navarch_burn_in_total{pool="training",provider="lambda",result="passed"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "navarch_burn_in_total"); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestPrometheusMetrics_EmptyDatabase(t *testing.T) {
 	database := db.NewInMemDB()
 	defer database.Close()
@@ -119,6 +174,7 @@ func TestNodeStatusString(t *testing.T) {
 		{pb.NodeStatus_NODE_STATUS_DRAINING, "draining"},
 		{pb.NodeStatus_NODE_STATUS_UNHEALTHY, "unhealthy"},
 		{pb.NodeStatus_NODE_STATUS_TERMINATED, "terminated"},
+		{pb.NodeStatus_NODE_STATUS_QUALIFYING, "qualifying"},
 		{pb.NodeStatus_NODE_STATUS_UNKNOWN, "unknown"},
 	}
 
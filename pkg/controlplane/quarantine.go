@@ -0,0 +1,196 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	"github.com/NavarchProject/navarch/pkg/health"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+// defaultMinHealthyGPUs is the healthy GPU threshold for nodes outside a
+// managed pool.
+const defaultMinHealthyGPUs = 1
+
+// healthStatusFromResult converts a policy result to a health status.
+func healthStatusFromResult(r health.Result) pb.HealthStatus {
+	switch r {
+	case health.ResultHealthy:
+		return pb.HealthStatus_HEALTH_STATUS_HEALTHY
+	case health.ResultDegraded:
+		return pb.HealthStatus_HEALTH_STATUS_DEGRADED
+	case health.ResultUnhealthy:
+		return pb.HealthStatus_HEALTH_STATUS_UNHEALTHY
+	default:
+		return pb.HealthStatus_HEALTH_STATUS_UNKNOWN
+	}
+}
+
+// withoutQuarantinedGPUs drops events from GPUs the node has quarantined.
+// A quarantined GPU is already out of service, so its events must not count
+// against the rest of the node.
+func withoutQuarantinedGPUs(node *db.NodeRecord, events []*pb.HealthEvent) []*pb.HealthEvent {
+	quarantined := make(map[int32]bool)
+	for _, g := range node.GPUHealth {
+		if g.Quarantined {
+			quarantined[int32(g.Index)] = true
+		}
+	}
+	if len(quarantined) == 0 {
+		return events
+	}
+
+	kept := make([]*pb.HealthEvent, 0, len(events))
+	for _, e := range events {
+		if e.GpuIndex >= 0 && quarantined[e.GpuIndex] {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+// updateGPUHealth records the results of GPU-scoped rule matches on the
+// node's GPUs. A GPU a rule found unhealthy is quarantined and the node
+// agent is told to hide it. If that leaves the node with fewer healthy GPUs
+// than its pool requires, the node is marked unhealthy so it is replaced.
+func (s *Server) updateGPUHealth(ctx context.Context, node *db.NodeRecord, matches []health.RuleMatch) error {
+	// The worst match for each GPU in this report decides its status.
+	worst := make(map[int]*health.RuleMatch)
+	for i := range matches {
+		m := &matches[i]
+		if !m.GPUScoped {
+			continue
+		}
+		// Health statuses are numbered in order of severity.
+		prev, ok := worst[m.Event.GPUIndex]
+		if !ok || healthStatusFromResult(m.Result) > healthStatusFromResult(prev.Result) {
+			worst[m.Event.GPUIndex] = m
+		}
+	}
+	if len(worst) == 0 {
+		return nil
+	}
+
+	now := s.clock.Now()
+	records := make(map[int]*db.GPUHealthRecord, len(node.GPUHealth))
+	for _, g := range node.GPUHealth {
+		records[g.Index] = g
+	}
+
+	var changed bool
+	var quarantine []*health.RuleMatch
+	for _, index := range slices.Sorted(maps.Keys(worst)) {
+		m := worst[index]
+		status := healthStatusFromResult(m.Result)
+		rec, ok := records[index]
+		if !ok {
+			rec = &db.GPUHealthRecord{Index: index, UUID: gpuUUID(node, m)}
+			records[index] = rec
+			node.GPUHealth = append(node.GPUHealth, rec)
+		}
+		if ok && rec.Status == status {
+			continue
+		}
+		rec.Status = status
+		rec.Reason = m.Rule
+		rec.UpdatedAt = now
+		changed = true
+
+		if status == pb.HealthStatus_HEALTH_STATUS_UNHEALTHY && !rec.Quarantined {
+			rec.Quarantined = true
+			quarantine = append(quarantine, m)
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := s.db.UpdateNodeGPUHealth(ctx, node.NodeID, node.GPUHealth); err != nil {
+		return fmt.Errorf("update GPU health: %w", err)
+	}
+
+	for _, m := range quarantine {
+		s.logger.WarnContext(ctx, "quarantining GPU",
+			slog.String("node_id", node.NodeID),
+			slog.Int("gpu_index", m.Event.GPUIndex),
+			slog.String("rule", m.Rule),
+		)
+		params := map[string]string{
+			"gpu_index": strconv.Itoa(m.Event.GPUIndex),
+			"reason":    fmt.Sprintf("health policy rule %s matched", m.Rule),
+		}
+		if uuid := records[m.Event.GPUIndex].UUID; uuid != "" {
+			params["gpu_uuid"] = uuid
+		}
+		if _, err := s.issueCommand(ctx, &pb.IssueCommandRequest{
+			NodeId:      node.NodeID,
+			CommandType: pb.NodeCommandType_NODE_COMMAND_TYPE_QUARANTINE_GPU,
+			Parameters:  params,
+		}, healthPolicyIssuer+m.Rule); err != nil {
+			s.logger.ErrorContext(ctx, "failed to issue GPU quarantine command",
+				slog.String("node_id", node.NodeID),
+				slog.Int("gpu_index", m.Event.GPUIndex),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	if len(quarantine) == 0 || len(node.GPUs) == 0 {
+		return nil
+	}
+
+	healthy := len(node.GPUs)
+	for _, g := range node.GPUHealth {
+		if g.Quarantined {
+			healthy--
+		}
+	}
+	minHealthy := s.minHealthyGPUs(node)
+	if healthy >= minHealthy {
+		return nil
+	}
+
+	s.logger.WarnContext(ctx, "too few healthy GPUs, marking node unhealthy",
+		slog.String("node_id", node.NodeID),
+		slog.Int("healthy_gpus", healthy),
+		slog.Int("min_healthy_gpus", minHealthy),
+	)
+	if err := s.db.UpdateNodeHealthStatus(ctx, node.NodeID, pb.HealthStatus_HEALTH_STATUS_UNHEALTHY); err != nil {
+		return fmt.Errorf("update health status: %w", err)
+	}
+	if err := s.db.UpdateNodeStatus(ctx, node.NodeID, pb.NodeStatus_NODE_STATUS_UNHEALTHY); err != nil {
+		return fmt.Errorf("update status: %w", err)
+	}
+	return nil
+}
+
+// minHealthyGPUs returns the fewest healthy GPUs the node's pool allows.
+func (s *Server) minHealthyGPUs(node *db.NodeRecord) int {
+	if s.poolManager == nil || node.Metadata == nil {
+		return defaultMinHealthyGPUs
+	}
+	p, ok := s.poolManager.GetPool(node.Metadata.Labels["pool"])
+	if !ok || p.Config().MinHealthyGPUs == 0 {
+		return defaultMinHealthyGPUs
+	}
+	return p.Config().MinHealthyGPUs
+}
+
+// gpuUUID returns the UUID of the GPU a match's event came from: the event's
+// own, or else the one the node registered for that index.
+func gpuUUID(node *db.NodeRecord, m *health.RuleMatch) string {
+	if m.Event.GPUUUID != "" {
+		return m.Event.GPUUUID
+	}
+	for _, g := range node.GPUs {
+		if int(g.Index) == m.Event.GPUIndex {
+			return g.Uuid
+		}
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"connectrpc.com/connect"
+
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	"github.com/NavarchProject/navarch/pkg/health"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+func TestGPUQuarantine(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewInMemDB()
+	defer database.Close()
+
+	policy, err := health.ParsePolicy([]byte(`
+rules:
+  - name: fatal-xid
+    condition: event.event_type == "xid" && event.metrics.xid_code == 79
+    result: unhealthy
+    scope: gpu
+  - name: default
+    condition: "true"
+    result: healthy
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.HealthPolicy = policy
+	srv := NewServer(database, cfg, nil, nil)
+
+	register := func(nodeID string, gpuCount int) {
+		t.Helper()
+		var gpus []*pb.GPUInfo
+		for i := 0; i < gpuCount; i++ {
+			gpus = append(gpus, &pb.GPUInfo{Index: int32(i), Uuid: fmt.Sprintf("GPU-%s-%d", nodeID, i)})
+		}
+		if _, err := srv.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{NodeId: nodeID, Gpus: gpus})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	xid79 := func(nodeID string, gpuIndex int32) {
+		t.Helper()
+		if _, err := srv.ReportHealth(ctx, connect.NewRequest(&pb.ReportHealthRequest{
+			NodeId: nodeID,
+			Events: []*pb.HealthEvent{{
+				EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_XID,
+				GpuIndex:  gpuIndex,
+				Metrics:   map[string]string{"xid_code": "79"},
+			}},
+		})); err != nil {
+			t.Fatalf("ReportHealth failed: %v", err)
+		}
+	}
+	getNode := func(nodeID string) *db.NodeRecord {
+		t.Helper()
+		node, err := database.GetNode(ctx, nodeID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return node
+	}
+	quarantineCommands := func(nodeID string) []*db.CommandRecord {
+		t.Helper()
+		cmds, err := database.ListCommands(ctx, db.CommandFilter{NodeID: nodeID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var quarantine []*db.CommandRecord
+		for _, cmd := range cmds {
+			if cmd.Type == pb.NodeCommandType_NODE_COMMAND_TYPE_QUARANTINE_GPU {
+				quarantine = append(quarantine, cmd)
+			}
+		}
+		return quarantine
+	}
+
+	t.Run("quarantines_failed_gpu", func(t *testing.T) {
+		register("node-1", 8)
+		xid79("node-1", 3)
+
+		node := getNode("node-1")
+		if node.Status != pb.NodeStatus_NODE_STATUS_ACTIVE {
+			t.Errorf("Status = %v, want ACTIVE", node.Status)
+		}
+		if node.HealthStatus != pb.HealthStatus_HEALTH_STATUS_DEGRADED {
+			t.Errorf("HealthStatus = %v, want DEGRADED", node.HealthStatus)
+		}
+		if len(node.GPUHealth) != 1 {
+			t.Fatalf("GPUHealth = %+v, want one GPU", node.GPUHealth)
+		}
+		g := node.GPUHealth[0]
+		if g.Index != 3 || g.UUID != "GPU-node-1-3" || !g.Quarantined ||
+			g.Status != pb.HealthStatus_HEALTH_STATUS_UNHEALTHY || g.Reason != "fatal-xid" {
+			t.Errorf("GPUHealth[0] = %+v, want GPU 3 quarantined by fatal-xid", g)
+		}
+
+		cmds := quarantineCommands("node-1")
+		if len(cmds) != 1 {
+			t.Fatalf("got %d quarantine commands, want 1", len(cmds))
+		}
+		if cmds[0].Parameters["gpu_index"] != "3" || cmds[0].Parameters["gpu_uuid"] != "GPU-node-1-3" ||
+			cmds[0].IssuedBy != "health-policy/fatal-xid" || cmds[0].Status != db.CommandStatusPending {
+			t.Errorf("command = %+v, want pending quarantine of GPU 3", cmds[0])
+		}
+
+		xid79("node-1", 3)
+		if got := len(quarantineCommands("node-1")); got != 1 {
+			t.Errorf("repeat event issued more commands: %d, want 1", got)
+		}
+	})
+
+	t.Run("replaced_below_min_healthy", func(t *testing.T) {
+		register("node-2", 2)
+		xid79("node-2", 0)
+		if got := getNode("node-2").Status; got != pb.NodeStatus_NODE_STATUS_ACTIVE {
+			t.Fatalf("Status = %v after one of two GPUs failed, want ACTIVE", got)
+		}
+
+		xid79("node-2", 1)
+		node := getNode("node-2")
+		if node.Status != pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+			t.Errorf("Status = %v with no healthy GPUs left, want UNHEALTHY", node.Status)
+		}
+		if node.HealthStatus != pb.HealthStatus_HEALTH_STATUS_UNHEALTHY {
+			t.Errorf("HealthStatus = %v, want UNHEALTHY", node.HealthStatus)
+		}
+	})
+
+	t.Run("node_info", func(t *testing.T) {
+		resp, err := srv.GetNode(ctx, connect.NewRequest(&pb.GetNodeRequest{NodeId: "node-1"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gpus := resp.Msg.Node.GpuHealth
+		if len(gpus) != 1 || gpus[0].Index != 3 || !gpus[0].Quarantined {
+			t.Errorf("GpuHealth = %v, want GPU 3 quarantined", gpus)
+		}
+	})
+}
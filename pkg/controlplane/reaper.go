@@ -0,0 +1,357 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/NavarchProject/navarch/pkg/clock"
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	"github.com/NavarchProject/navarch/pkg/provider"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+// Reaper terminates cloud instances that are costing money without serving a
+// pool: instances the InstanceManager marked FAILED, and instances that carry
+// this control plane's owner and cluster labels but do not belong to any
+// registered pool. Candidates must stay
+// eligible for a grace period before they are terminated. Every action, or
+// intended action in dry-run mode, is appended to the instance's StatusMessage.
+type Reaper struct {
+	db          db.DB
+	poolManager *PoolManager
+	clock       clock.Clock
+	logger      *slog.Logger
+	config      ReaperConfig
+
+	mu         sync.Mutex
+	started    bool
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	candidates map[string]*reapCandidate // instance ID -> candidate; only touched by reap
+}
+
+// ReaperConfig configures the reaper behavior.
+type ReaperConfig struct {
+	// GracePeriod is how long an instance must remain eligible before it is
+	// terminated. Default: 30 minutes.
+	GracePeriod time.Duration
+
+	// Interval is how often to scan for candidates. Default: 5 minutes.
+	Interval time.Duration
+
+	// DryRun records what would be terminated without terminating anything.
+	DryRun bool
+
+	// Clock is the clock to use for time operations. If nil, uses real time.
+	Clock clock.Clock
+}
+
+// DefaultReaperConfig returns sensible defaults for the reaper.
+func DefaultReaperConfig() ReaperConfig {
+	return ReaperConfig{
+		GracePeriod: 30 * time.Minute,
+		Interval:    5 * time.Minute,
+	}
+}
+
+// reapCandidate is an instance that is eligible for termination.
+type reapCandidate struct {
+	instanceID string
+	poolName   string
+	reason     string
+	firstSeen  time.Time
+	node       *provider.Node // Set for orphans, which may have no instance record
+	terminate  func(ctx context.Context) error
+}
+
+// NewReaper creates a new reaper. Pools and providers are looked up through
+// poolManager on every pass.
+func NewReaper(database db.DB, poolManager *PoolManager, config ReaperConfig, logger *slog.Logger) *Reaper {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if config.GracePeriod == 0 {
+		config.GracePeriod = DefaultReaperConfig().GracePeriod
+	}
+	if config.Interval == 0 {
+		config.Interval = DefaultReaperConfig().Interval
+	}
+
+	clk := config.Clock
+	if clk == nil {
+		clk = clock.Real()
+	}
+
+	return &Reaper{
+		db:          database,
+		poolManager: poolManager,
+		clock:       clk,
+		logger:      logger.With(slog.String("component", "reaper")),
+		config:      config,
+		candidates:  make(map[string]*reapCandidate),
+	}
+}
+
+// Start begins the background reaping loop.
+func (r *Reaper) Start(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started {
+		return
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.started = true
+
+	r.wg.Add(1)
+	go r.reapLoop(ctx)
+
+	r.logger.Info("reaper started",
+		slog.Duration("grace_period", r.config.GracePeriod),
+		slog.Duration("interval", r.config.Interval),
+		slog.Bool("dry_run", r.config.DryRun),
+	)
+}
+
+// Stop stops the reaper.
+func (r *Reaper) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.started {
+		return
+	}
+
+	r.cancel()
+	r.wg.Wait()
+	r.started = false
+
+	r.logger.Info("reaper stopped")
+}
+
+func (r *Reaper) reapLoop(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := r.clock.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			r.reap(ctx)
+		}
+	}
+}
+
+// reap runs a single pass: it collects the current candidates, forgets
+// instances that are no longer eligible, and acts on those whose grace
+// period has elapsed.
+func (r *Reaper) reap(ctx context.Context) {
+	now := r.clock.Now()
+	current := make(map[string]*reapCandidate)
+
+	for _, c := range r.failedCandidates(ctx) {
+		current[c.instanceID] = c
+	}
+	for _, c := range r.orphanCandidates(ctx) {
+		if _, ok := current[c.instanceID]; !ok {
+			current[c.instanceID] = c
+		}
+	}
+
+	for id, c := range current {
+		if prev, ok := r.candidates[id]; ok {
+			c.firstSeen = prev.firstSeen
+		} else {
+			c.firstSeen = now
+		}
+	}
+	r.candidates = current
+
+	for id, c := range current {
+		if now.Sub(c.firstSeen) < r.config.GracePeriod {
+			continue
+		}
+
+		if r.config.DryRun {
+			r.logger.Info("would terminate instance (dry run)",
+				slog.String("instance_id", id),
+				slog.String("pool", c.poolName),
+				slog.String("reason", c.reason),
+			)
+			r.record(ctx, c, false, "reaper (dry run): would terminate, "+c.reason)
+			continue
+		}
+
+		if err := c.terminate(ctx); err != nil {
+			r.logger.Error("failed to terminate instance",
+				slog.String("instance_id", id),
+				slog.String("pool", c.poolName),
+				slog.String("error", err.Error()),
+			)
+			r.record(ctx, c, false, "reaper: terminate failed: "+err.Error())
+			continue
+		}
+
+		r.logger.Warn("terminated instance",
+			slog.String("instance_id", id),
+			slog.String("pool", c.poolName),
+			slog.String("reason", c.reason),
+		)
+		r.record(ctx, c, true, "reaper: terminated, "+c.reason)
+		delete(r.candidates, id)
+	}
+}
+
+// failedCandidates returns instances in the FAILED state whose pool has not
+// opted out of reaping.
+func (r *Reaper) failedCandidates(ctx context.Context) []*reapCandidate {
+	failed, err := r.db.ListInstancesByState(ctx, pb.InstanceState_INSTANCE_STATE_FAILED)
+	if err != nil {
+		r.logger.Error("failed to list failed instances",
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+
+	var candidates []*reapCandidate
+	for _, instance := range failed {
+		if r.optedOut(instance.PoolName) {
+			continue
+		}
+		candidates = append(candidates, &reapCandidate{
+			instanceID: instance.InstanceID,
+			poolName:   instance.PoolName,
+			reason:     fmt.Sprintf("instance failed for more than %s", r.config.GracePeriod),
+			terminate: func(ctx context.Context) error {
+				return r.terminateFailed(ctx, instance)
+			},
+		})
+	}
+	return candidates
+}
+
+// orphanCandidates returns provider instances owned by this control plane
+// that do not match a registered pool, or whose pool does not use the
+// provider they run on. Instances with only the generic pool label may belong
+// to other tooling and are never candidates.
+func (r *Reaper) orphanCandidates(ctx context.Context) []*reapCandidate {
+	pools, entries, err := r.poolManager.inventory(ctx)
+	if err != nil {
+		r.logger.Warn("provider inventory incomplete", slog.String("error", err.Error()))
+	}
+
+	var candidates []*reapCandidate
+	for _, e := range entries {
+		if !e.owned {
+			continue
+		}
+		reason := fmt.Sprintf("orphaned, pool %q is not configured", e.poolName)
+		if mp, ok := pools[e.poolName]; ok {
+			if mp.pool.Config().DisableReaper || poolUsesProvider(mp, e.providerName) {
+				continue
+			}
+			reason = fmt.Sprintf("orphaned, pool %q does not use provider %q", e.poolName, e.providerName)
+		}
+
+		candidates = append(candidates, &reapCandidate{
+			instanceID: e.node.ID,
+			poolName:   e.poolName,
+			reason:     reason,
+			node:       e.node,
+			terminate: func(ctx context.Context) error {
+				return e.provider.Terminate(ctx, e.node.ID)
+			},
+		})
+	}
+	return candidates
+}
+
+// terminateFailed terminates a failed instance through its pool if the pool
+// still tracks it, so the node is also removed from pool membership.
+// Otherwise it terminates the instance directly through its provider.
+func (r *Reaper) terminateFailed(ctx context.Context, instance *db.InstanceRecord) error {
+	if p, ok := r.poolManager.GetPool(instance.PoolName); ok && p.HasNode(instance.InstanceID) {
+		return p.TerminateNode(ctx, instance.InstanceID)
+	}
+
+	prov := r.poolManager.findProvider(instance.Provider)
+	if prov == nil {
+		return fmt.Errorf("provider %s not found", instance.Provider)
+	}
+	return prov.Terminate(ctx, instance.InstanceID)
+}
+
+// optedOut reports whether the named pool has disabled reaping.
+func (r *Reaper) optedOut(poolName string) bool {
+	p, ok := r.poolManager.GetPool(poolName)
+	return ok && p.Config().DisableReaper
+}
+
+// record appends msg to the instance's StatusMessage, creating an instance
+// record for orphans that have none. Messages already present are not
+// repeated, so dry-run passes do not grow the message.
+func (r *Reaper) record(ctx context.Context, c *reapCandidate, terminated bool, msg string) {
+	state := pb.InstanceState_INSTANCE_STATE_TERMINATED
+
+	existing, err := r.db.GetInstance(ctx, c.instanceID)
+	if err != nil {
+		if c.node == nil {
+			return
+		}
+		if !terminated {
+			state = pb.InstanceState_INSTANCE_STATE_RUNNING
+		}
+		record := &db.InstanceRecord{
+			InstanceID:    c.node.ID,
+			Provider:      c.node.Provider,
+			Region:        c.node.Region,
+			Zone:          c.node.Zone,
+			InstanceType:  c.node.InstanceType,
+			State:         state,
+			StatusMessage: msg,
+			PoolName:      c.poolName,
+			CreatedAt:     r.clock.Now(),
+			Labels:        c.node.Labels,
+		}
+		if err := r.db.CreateInstance(ctx, record); err != nil {
+			r.logger.Error("failed to record reaper action",
+				slog.String("instance_id", c.instanceID),
+				slog.String("error", err.Error()),
+			)
+		}
+		return
+	}
+
+	if !terminated {
+		state = existing.State
+		if strings.Contains(existing.StatusMessage, msg) {
+			return
+		}
+	}
+	if existing.StatusMessage != "" {
+		msg = existing.StatusMessage + "; " + msg
+	}
+	if err := r.db.UpdateInstanceState(ctx, c.instanceID, state, msg); err != nil {
+		r.logger.Error("failed to record reaper action",
+			slog.String("instance_id", c.instanceID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func poolUsesProvider(mp *managedPool, providerName string) bool {
+	for _, pc := range mp.pool.Providers() {
+		if pc.Name == providerName {
+			return true
+		}
+	}
+	return false
+}
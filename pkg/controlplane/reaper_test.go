@@ -0,0 +1,202 @@
+package controlplane
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NavarchProject/navarch/pkg/clock"
+	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	"github.com/NavarchProject/navarch/pkg/pool"
+	"github.com/NavarchProject/navarch/pkg/provider"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+type reaperFixture struct {
+	clock    *clock.FakeClock
+	db       db.DB
+	im       *InstanceManager
+	pm       *PoolManager
+	prov     *listingProvider
+	pool     *pool.Pool
+	reaper   *Reaper
+	instance string
+}
+
+// newReaperFixture creates a pool with one provisioned instance that has been
+// marked FAILED.
+func newReaperFixture(t *testing.T, poolCfg pool.Config, reaperCfg ReaperConfig) *reaperFixture {
+	t.Helper()
+	ctx := context.Background()
+
+	fakeClock := clock.NewFakeClock(time.Now())
+	database := db.NewInMemDB()
+	t.Cleanup(func() { database.Close() })
+
+	im := NewInstanceManager(database, InstanceManagerConfig{Clock: fakeClock}, nil)
+	pm := NewPoolManager(PoolManagerConfig{Clock: fakeClock}, nil, im, nil)
+
+	prov := &listingProvider{}
+	poolCfg.Name = "training"
+	poolCfg.MaxNodes = 5
+	p, _ := pool.NewSimple(poolCfg, prov, "mock")
+	pm.AddPool(p, nil)
+
+	nodes, err := p.ScaleUp(ctx, 1)
+	if err != nil {
+		t.Fatalf("ScaleUp failed: %v", err)
+	}
+	id := nodes[0].ID
+	im.TrackProvisioning(ctx, id, "mock", "", "", "", "training", nil)
+	im.TrackProvisioningFailed(ctx, id, "registration timeout exceeded")
+
+	reaperCfg.Clock = fakeClock
+	return &reaperFixture{
+		clock:    fakeClock,
+		db:       database,
+		im:       im,
+		pm:       pm,
+		prov:     prov,
+		pool:     p,
+		reaper:   NewReaper(database, pm, reaperCfg, nil),
+		instance: id,
+	}
+}
+
+func TestReaper_FailedInstance(t *testing.T) {
+	f := newReaperFixture(t, pool.Config{}, ReaperConfig{GracePeriod: 10 * time.Minute})
+	ctx := context.Background()
+
+	f.reaper.reap(ctx)
+	if f.prov.terminates.Load() != 0 {
+		t.Fatal("Expected no termination before grace period")
+	}
+
+	f.clock.Advance(11 * time.Minute)
+	f.reaper.reap(ctx)
+	if f.prov.terminates.Load() != 1 {
+		t.Fatalf("Expected 1 termination, got %d", f.prov.terminates.Load())
+	}
+	if f.pool.HasNode(f.instance) {
+		t.Error("Expected reaped node to be removed from pool")
+	}
+
+	instance, _ := f.im.GetInstance(ctx, f.instance)
+	if instance.State != pb.InstanceState_INSTANCE_STATE_TERMINATED {
+		t.Errorf("Expected state TERMINATED, got %v", instance.State)
+	}
+	if !strings.HasPrefix(instance.StatusMessage, "registration timeout exceeded; reaper: terminated") {
+		t.Errorf("Expected reaper action appended to status message, got %q", instance.StatusMessage)
+	}
+
+	f.reaper.reap(ctx)
+	if f.prov.terminates.Load() != 1 {
+		t.Errorf("Expected terminated instance not to be reaped again, got %d terminations", f.prov.terminates.Load())
+	}
+}
+
+func TestReaper_DryRun(t *testing.T) {
+	f := newReaperFixture(t, pool.Config{}, ReaperConfig{GracePeriod: 10 * time.Minute, DryRun: true})
+	ctx := context.Background()
+
+	f.reaper.reap(ctx)
+	f.clock.Advance(11 * time.Minute)
+	f.reaper.reap(ctx)
+	f.reaper.reap(ctx)
+
+	if f.prov.terminates.Load() != 0 {
+		t.Fatalf("Expected no terminations in dry run, got %d", f.prov.terminates.Load())
+	}
+	if !f.pool.HasNode(f.instance) {
+		t.Error("Expected node to remain in pool in dry run")
+	}
+
+	instance, _ := f.im.GetInstance(ctx, f.instance)
+	if instance.State != pb.InstanceState_INSTANCE_STATE_FAILED {
+		t.Errorf("Expected state FAILED, got %v", instance.State)
+	}
+	if strings.Count(instance.StatusMessage, "reaper (dry run): would terminate") != 1 {
+		t.Errorf("Expected dry run action recorded once, got %q", instance.StatusMessage)
+	}
+}
+
+func TestReaper_PoolOptOut(t *testing.T) {
+	f := newReaperFixture(t, pool.Config{DisableReaper: true}, ReaperConfig{GracePeriod: 10 * time.Minute})
+	ctx := context.Background()
+
+	f.reaper.reap(ctx)
+	f.clock.Advance(11 * time.Minute)
+	f.reaper.reap(ctx)
+
+	if f.prov.terminates.Load() != 0 {
+		t.Errorf("Expected opted-out pool not to be reaped, got %d terminations", f.prov.terminates.Load())
+	}
+}
+
+func TestReaper_GracePeriodResets(t *testing.T) {
+	f := newReaperFixture(t, pool.Config{}, ReaperConfig{GracePeriod: 10 * time.Minute})
+	ctx := context.Background()
+
+	f.reaper.reap(ctx)
+	f.clock.Advance(5 * time.Minute)
+
+	// The instance recovers, then fails again.
+	f.im.TrackAdopted(ctx, f.instance, "mock", "", "", "", "training", nil)
+	f.reaper.reap(ctx)
+	f.im.TrackProvisioningFailed(ctx, f.instance, "failed again")
+	f.reaper.reap(ctx)
+
+	f.clock.Advance(6 * time.Minute)
+	f.reaper.reap(ctx)
+	if f.prov.terminates.Load() != 0 {
+		t.Errorf("Expected grace period to restart after recovery, got %d terminations", f.prov.terminates.Load())
+	}
+}
+
+func TestReaper_Orphans(t *testing.T) {
+	f := newReaperFixture(t, pool.Config{}, ReaperConfig{GracePeriod: 10 * time.Minute})
+	ctx := context.Background()
+
+	// Resolve the failed instance so only orphans are considered.
+	f.im.TrackAdopted(ctx, f.instance, "mock", "", "", "", "training", nil)
+
+	owned := func(poolName, cluster string) map[string]string {
+		return map[string]string{pool.NameLabel: poolName, pool.OwnerLabel: poolName, pool.ClusterLabel: cluster}
+	}
+	f.prov.nodes = []*provider.Node{
+		{ID: "orphan-1", Provider: "mock", Status: "running", Labels: owned("deleted-pool", pool.DefaultClusterID)},
+		{ID: "unmanaged-1", Provider: "mock", Status: "running"},
+		{ID: "unadopted-1", Provider: "mock", Status: "running", Labels: owned("training", pool.DefaultClusterID)},
+		// A plain pool label may come from other tooling in the account.
+		{ID: "foreign-1", Provider: "mock", Status: "running", Labels: map[string]string{"pool": "deleted-pool"}},
+		{ID: "other-cluster-1", Provider: "mock", Status: "running", Labels: owned("deleted-pool", "other")},
+	}
+
+	f.reaper.reap(ctx)
+	f.clock.Advance(11 * time.Minute)
+	f.reaper.reap(ctx)
+
+	if f.prov.terminates.Load() != 1 {
+		t.Fatalf("Expected only the orphan to be terminated, got %d terminations", f.prov.terminates.Load())
+	}
+
+	instance, err := f.im.GetInstance(ctx, "orphan-1")
+	if err != nil {
+		t.Fatalf("Expected reaper to record an instance for the orphan: %v", err)
+	}
+	if instance.State != pb.InstanceState_INSTANCE_STATE_TERMINATED {
+		t.Errorf("Expected state TERMINATED, got %v", instance.State)
+	}
+	if instance.PoolName != "deleted-pool" {
+		t.Errorf("Expected pool_name deleted-pool, got %s", instance.PoolName)
+	}
+	if !strings.Contains(instance.StatusMessage, `pool "deleted-pool" is not configured`) {
+		t.Errorf("Unexpected status message: %q", instance.StatusMessage)
+	}
+	for _, id := range []string{"unmanaged-1", "foreign-1", "other-cluster-1"} {
+		if _, err := f.im.GetInstance(ctx, id); err == nil {
+			t.Errorf("Expected %s, which this control plane does not own, to be ignored", id)
+		}
+	}
+}
@@ -2,14 +2,21 @@ package controlplane
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
+	"sync"
+	"time"
 
 	"connectrpc.com/connect"
 	"github.com/google/uuid"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/NavarchProject/navarch/pkg/auth"
 	"github.com/NavarchProject/navarch/pkg/clock"
+	"github.com/NavarchProject/navarch/pkg/config"
 	"github.com/NavarchProject/navarch/pkg/controlplane/db"
 	"github.com/NavarchProject/navarch/pkg/gpu"
 	"github.com/NavarchProject/navarch/pkg/health"
@@ -33,7 +40,23 @@ type Server struct {
 	instanceManager *InstanceManager
 	healthObserver  NodeHealthObserver
 	healthEvaluator *health.Evaluator
+	healthHistory   *health.History
+	poolEvalMu      sync.RWMutex
+	poolEvaluators  map[string]*health.Evaluator
+	notifierMu      sync.RWMutex
 	notifier        notifier.Notifier
+	poolManager     *PoolManager
+	commandWatchers *commandWatchers
+	nodeEvents      *nodeEvents
+	actionLimiter   *actionLimiter
+	eventPruneMu    sync.Mutex
+	lastEventPrune  time.Time
+	cmdPruneMu      sync.Mutex
+	lastCmdPrune    time.Time
+	gpuInventoryMu  sync.Mutex // Serializes GPU inventory updates
+	flaps           *flapDetector
+	burnInMu        sync.Mutex // Serializes the end of burn-in
+	burnInObserver  BurnInObserver
 }
 
 // Config holds configuration for the control plane server.
@@ -43,6 +66,53 @@ type Config struct {
 	EnabledHealthChecks        []string
 	HealthPolicy               *health.Policy // Health policy for CEL evaluation. If nil, uses default.
 	Clock                      clock.Clock    // Clock for time operations. If nil, uses real time.
+
+	// PoolHealthPolicies gives pools their own health policy, keyed by pool
+	// name. Nodes belong to the pool named by their "pool" label; nodes of
+	// pools without a policy here use HealthPolicy.
+	PoolHealthPolicies map[string]*health.Policy
+
+	// HealthPolicyDir is the directory that pool specs received over RPC
+	// name health policy files in, by paths relative to it. If empty, such
+	// specs may not name a health policy; SetHealthPolicy sets one instead.
+	HealthPolicyDir string
+
+	// CommandAckTimeout is how long a delivered command may go unacknowledged
+	// before it is redelivered. Default: 2 minutes.
+	CommandAckTimeout time.Duration
+
+	// CommandMaxDeliveries is how many times a command is delivered before it
+	// is marked failed for lack of acknowledgement. Default: 3.
+	CommandMaxDeliveries int
+
+	// NodeWatchResyncInterval is how often every node is checked for changes
+	// made outside the server, such as heartbeat timeouts, while any
+	// WatchNodes stream is open. Default: 5 seconds.
+	NodeWatchResyncInterval time.Duration
+
+	// NodeWatchHistory is how many node events are retained so that watches
+	// can resume. Default: 1000.
+	NodeWatchHistory int
+
+	// HealthHistoryWindow is how long each node's health events are kept for
+	// policy rules that use history. Default: 1 hour.
+	HealthHistoryWindow time.Duration
+
+	// HealthHistoryMaxEvents caps the health events kept per node.
+	// Default: 1000.
+	HealthHistoryMaxEvents int
+
+	// HealthEventRetention is how long raw health events are kept in the
+	// database for ListHealthEvents. Default: 30 days.
+	HealthEventRetention time.Duration
+
+	// CommandRetention is how long finished commands are kept in the
+	// database, including those of deleted nodes. Default: 30 days.
+	CommandRetention time.Duration
+
+	// FlapDetection holds down nodes whose health flaps. Disabled unless
+	// Transitions is set; other unset fields use DefaultFlapConfig.
+	FlapDetection FlapConfig
 }
 
 // DefaultConfig returns a sensible default configuration.
@@ -51,6 +121,14 @@ func DefaultConfig() Config {
 		HealthCheckIntervalSeconds: 60,
 		HeartbeatIntervalSeconds:   30,
 		EnabledHealthChecks:        []string{"boot", "nvml", "xid"},
+		CommandAckTimeout:          2 * time.Minute,
+		CommandMaxDeliveries:       3,
+		NodeWatchResyncInterval:    5 * time.Second,
+		NodeWatchHistory:           1000,
+		HealthHistoryWindow:        health.DefaultHistoryWindow,
+		HealthHistoryMaxEvents:     health.DefaultHistoryMaxEvents,
+		HealthEventRetention:       30 * 24 * time.Hour,
+		CommandRetention:           30 * 24 * time.Hour,
 	}
 }
 
@@ -66,6 +144,25 @@ func NewServer(database db.DB, cfg Config, instanceManager *InstanceManager, log
 	if clk == nil {
 		clk = clock.Real()
 	}
+	if cfg.CommandAckTimeout == 0 {
+		cfg.CommandAckTimeout = DefaultConfig().CommandAckTimeout
+	}
+	if cfg.CommandMaxDeliveries == 0 {
+		cfg.CommandMaxDeliveries = DefaultConfig().CommandMaxDeliveries
+	}
+	if cfg.NodeWatchResyncInterval == 0 {
+		cfg.NodeWatchResyncInterval = DefaultConfig().NodeWatchResyncInterval
+	}
+	if cfg.NodeWatchHistory == 0 {
+		cfg.NodeWatchHistory = DefaultConfig().NodeWatchHistory
+	}
+	if cfg.HealthEventRetention == 0 {
+		cfg.HealthEventRetention = DefaultConfig().HealthEventRetention
+	}
+	if cfg.CommandRetention == 0 {
+		cfg.CommandRetention = DefaultConfig().CommandRetention
+	}
+	cfg.FlapDetection = cfg.FlapDetection.withDefaults()
 
 	metricsSource := NewDBMetricsSourceWithClock(database, clk, logger)
 
@@ -79,8 +176,20 @@ func NewServer(database db.DB, cfg Config, instanceManager *InstanceManager, log
 		logger.Error("failed to create health evaluator, CEL policies disabled", slog.String("error", err.Error()))
 		evaluator = nil
 	}
+	poolEvaluators := make(map[string]*health.Evaluator)
+	for pool, policy := range cfg.PoolHealthPolicies {
+		e, err := health.NewEvaluator(policy)
+		if err != nil {
+			logger.Error("failed to create pool health evaluator, pool uses the server policy",
+				slog.String("pool", pool),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		poolEvaluators[pool] = e
+	}
 
-	return &Server{
+	s := &Server{
 		db:              database,
 		config:          cfg,
 		clock:           clk,
@@ -88,7 +197,15 @@ func NewServer(database db.DB, cfg Config, instanceManager *InstanceManager, log
 		metricsSource:   metricsSource,
 		instanceManager: instanceManager,
 		healthEvaluator: evaluator,
+		poolEvaluators:  poolEvaluators,
+		healthHistory:   health.NewHistory(cfg.HealthHistoryWindow, cfg.HealthHistoryMaxEvents),
+		commandWatchers: newCommandWatchers(),
+		nodeEvents:      newNodeEvents(uint64(max(clk.Now().UnixMicro(), 0)), cfg.NodeWatchHistory),
+		actionLimiter:   newActionLimiter(),
+		flaps:           newFlapDetector(),
 	}
+	s.nodeEvents.resync = s.resyncNodes
+	return s
 }
 
 // SetHealthObserver sets the observer to be notified on health status changes.
@@ -98,11 +215,76 @@ func (s *Server) SetHealthObserver(observer NodeHealthObserver) {
 
 // SetNotifier sets the notifier for cordon/drain operations.
 // If not set, cordon/drain only update internal status without notifying
-// an external workload system.
+// an external workload system. It may be called while serving.
 func (s *Server) SetNotifier(n notifier.Notifier) {
+	s.notifierMu.Lock()
+	defer s.notifierMu.Unlock()
 	s.notifier = n
 }
 
+func (s *Server) currentNotifier() notifier.Notifier {
+	s.notifierMu.RLock()
+	defer s.notifierMu.RUnlock()
+	return s.notifier
+}
+
+// UpdateHealthPolicy replaces the policy used to evaluate health events.
+// The running policy is kept if the new one fails to compile.
+func (s *Server) UpdateHealthPolicy(policy *health.Policy) error {
+	if s.healthEvaluator == nil {
+		return fmt.Errorf("health evaluator is not available")
+	}
+	return s.healthEvaluator.UpdatePolicy(policy)
+}
+
+// SetPoolHealthPolicy gives a pool its own health policy, used for nodes
+// whose "pool" label names the pool. If the pool already has a policy, the
+// replaced one is kept for rollback. A nil policy removes the pool's policy
+// so that its nodes use the server policy again.
+func (s *Server) SetPoolHealthPolicy(pool string, policy *health.Policy) error {
+	s.poolEvalMu.Lock()
+	defer s.poolEvalMu.Unlock()
+
+	if policy == nil {
+		delete(s.poolEvaluators, pool)
+		return nil
+	}
+	if e, ok := s.poolEvaluators[pool]; ok {
+		return e.UpdatePolicy(policy)
+	}
+	e, err := health.NewEvaluator(policy)
+	if err != nil {
+		return err
+	}
+	s.poolEvaluators[pool] = e
+	return nil
+}
+
+// poolEvaluator returns the evaluator of a pool with its own health policy,
+// or nil.
+func (s *Server) poolEvaluator(pool string) *health.Evaluator {
+	s.poolEvalMu.RLock()
+	defer s.poolEvalMu.RUnlock()
+	return s.poolEvaluators[pool]
+}
+
+// evaluatorFor returns the evaluator for a node's health events: its pool's
+// if the pool has its own policy, otherwise the server's.
+func (s *Server) evaluatorFor(node *db.NodeRecord) *health.Evaluator {
+	if node.Metadata != nil {
+		if e := s.poolEvaluator(node.Metadata.Labels["pool"]); e != nil {
+			return e
+		}
+	}
+	return s.healthEvaluator
+}
+
+// SetPoolManager sets the pool manager served by the pool RPCs.
+// If not set, ListPools returns no pools.
+func (s *Server) SetPoolManager(pm *PoolManager) {
+	s.poolManager = pm
+}
+
 func (s *Server) RegisterNode(ctx context.Context, req *connect.Request[pb.RegisterNodeRequest]) (*connect.Response[pb.RegisterNodeResponse], error) {
 	s.logger.InfoContext(ctx, "registering node",
 		slog.String("node_id", req.Msg.NodeId),
@@ -116,6 +298,23 @@ func (s *Server) RegisterNode(ctx context.Context, req *connect.Request[pb.Regis
 		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("node_id is required"))
 	}
 
+	// New nodes of a pool with burn-in qualify before they become active.
+	// Re-registering does not skip burn-in.
+	status := pb.NodeStatus_NODE_STATUS_ACTIVE
+	burnIn, burnInPool := s.burnInFor(req.Msg.Metadata)
+	existing, err := s.db.GetNode(ctx, req.Msg.NodeId)
+	newNode := errors.Is(err, db.ErrNodeNotFound)
+	if err != nil && !newNode {
+		s.logger.ErrorContext(ctx, "failed to look up node",
+			slog.String("node_id", req.Msg.NodeId),
+			slog.String("error", err.Error()),
+		)
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to look up node: %w", err))
+	}
+	if (newNode && burnInPool) || (!newNode && existing.Status == pb.NodeStatus_NODE_STATUS_QUALIFYING) {
+		status = pb.NodeStatus_NODE_STATUS_QUALIFYING
+	}
+
 	record := &db.NodeRecord{
 		NodeID:       req.Msg.NodeId,
 		Provider:     req.Msg.Provider,
@@ -124,7 +323,7 @@ func (s *Server) RegisterNode(ctx context.Context, req *connect.Request[pb.Regis
 		InstanceType: req.Msg.InstanceType,
 		GPUs:         req.Msg.Gpus,
 		Metadata:     req.Msg.Metadata,
-		Status:       pb.NodeStatus_NODE_STATUS_ACTIVE,
+		Status:       status,
 		Config: &pb.NodeConfig{
 			HealthCheckIntervalSeconds: s.config.HealthCheckIntervalSeconds,
 			HeartbeatIntervalSeconds:   s.config.HeartbeatIntervalSeconds,
@@ -140,6 +339,17 @@ func (s *Server) RegisterNode(ctx context.Context, req *connect.Request[pb.Regis
 		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("registration failed: %w", err))
 	}
 
+	s.recordGPUsSeen(ctx, record)
+
+	// Re-registering does not lift a hold-down.
+	if record.HoldUntil.After(s.clock.Now()) {
+		s.holdStatus(ctx, record)
+	}
+	if newNode && burnInPool {
+		s.startBurnIn(ctx, record, burnIn)
+	}
+	s.nodeEvents.syncNode(ctx, s.db, req.Msg.NodeId)
+
 	// Update instance tracking if enabled
 	// The node_id is the same as the instance_id from the cloud provider
 	if s.instanceManager != nil {
@@ -182,11 +392,16 @@ func (s *Server) ReportHealth(ctx context.Context, req *connect.Request[pb.Repor
 		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("node not found: %s", req.Msg.NodeId))
 	}
 	wasUnhealthy := node.Status == pb.NodeStatus_NODE_STATUS_UNHEALTHY
+	wasQualifying := node.Status == pb.NodeStatus_NODE_STATUS_QUALIFYING
 
 	// Evaluate health events with CEL policies if present
 	results := req.Msg.Results
-	if len(req.Msg.Events) > 0 && s.healthEvaluator != nil {
-		evalResult := s.evaluateHealthEvents(ctx, req.Msg.Events)
+	var matches []health.RuleMatch
+	evaluator := s.evaluatorFor(node)
+	events := withoutQuarantinedGPUs(node, req.Msg.Events)
+	if len(events) > 0 && evaluator != nil {
+		var evalResult *pb.HealthCheckResult
+		evalResult, matches = s.evaluateHealthEvents(ctx, evaluator, req.Msg.NodeId, events)
 		if evalResult != nil {
 			results = append(results, evalResult)
 		}
@@ -206,6 +421,15 @@ func (s *Server) ReportHealth(ctx context.Context, req *connect.Request[pb.Repor
 		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to record health check: %w", err))
 	}
 
+	s.recordHealthEvents(ctx, node, req.Msg.Events, events, matches)
+
+	if err := s.updateGPUHealth(ctx, node, matches); err != nil {
+		s.logger.ErrorContext(ctx, "failed to update GPU health",
+			slog.String("node_id", req.Msg.NodeId),
+			slog.String("error", err.Error()),
+		)
+	}
+
 	// Fetch updated node status (RecordHealthCheck may have updated it)
 	node, err = s.db.GetNode(ctx, req.Msg.NodeId)
 	if err != nil {
@@ -217,11 +441,28 @@ func (s *Server) ReportHealth(ctx context.Context, req *connect.Request[pb.Repor
 		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to fetch node status: %w", err))
 	}
 
+	s.checkFlapping(ctx, node)
+	s.nodeEvents.syncNode(ctx, s.db, req.Msg.NodeId)
+
+	// Run the actions of matched rules. They must finish even if the node
+	// stops waiting for the response.
+	if len(matches) > 0 {
+		s.runRuleActions(context.WithoutCancel(ctx), evaluator.Policy(), node, matches)
+	}
+
 	// Notify observer if node transitioned to unhealthy.
 	// Use background context since request context may be cancelled after response.
 	if !wasUnhealthy && node.Status == pb.NodeStatus_NODE_STATUS_UNHEALTHY && s.healthObserver != nil {
 		go s.healthObserver.OnNodeUnhealthy(context.Background(), req.Msg.NodeId)
 	}
+	if wasQualifying && node.Status == pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+		s.logger.WarnContext(ctx, "node failed burn-in",
+			slog.String("node_id", node.NodeID),
+			slog.String("provider", node.Provider),
+			slog.String("result", BurnInFailed),
+		)
+		s.observeBurnIn(ctx, node, BurnInFailed)
+	}
 
 	return connect.NewResponse(&pb.ReportHealthResponse{
 		Acknowledged: true,
@@ -229,31 +470,28 @@ func (s *Server) ReportHealth(ctx context.Context, req *connect.Request[pb.Repor
 	}), nil
 }
 
-// evaluateHealthEvents evaluates raw health events against CEL policies.
-func (s *Server) evaluateHealthEvents(ctx context.Context, protoEvents []*pb.HealthEvent) *pb.HealthCheckResult {
+// evaluateHealthEvents evaluates raw health events against the node's
+// policy, with the node's recent events as history, and adds them to the
+// history. It also returns the rule matches, whose actions are run once the
+// result has been recorded.
+func (s *Server) evaluateHealthEvents(ctx context.Context, evaluator *health.Evaluator, nodeID string, protoEvents []*pb.HealthEvent) (*pb.HealthCheckResult, []health.RuleMatch) {
 	// Convert proto events to internal format
 	events := gpu.HealthEventsFromProto(protoEvents)
 
-	result, err := s.healthEvaluator.Evaluate(ctx, events)
+	now := s.clock.Now()
+	past := s.healthHistory.Events(nodeID, now)
+	s.healthHistory.Record(nodeID, events, now)
+
+	result, err := evaluator.EvaluateWithHistory(ctx, events, past)
 	if err != nil {
 		s.logger.ErrorContext(ctx, "failed to evaluate health events",
 			slog.String("error", err.Error()),
 		)
-		return nil
+		return nil, nil
 	}
 
 	// Convert evaluation result to health check result
-	var status pb.HealthStatus
-	switch result.Status {
-	case health.ResultHealthy:
-		status = pb.HealthStatus_HEALTH_STATUS_HEALTHY
-	case health.ResultDegraded:
-		status = pb.HealthStatus_HEALTH_STATUS_DEGRADED
-	case health.ResultUnhealthy:
-		status = pb.HealthStatus_HEALTH_STATUS_UNHEALTHY
-	default:
-		status = pb.HealthStatus_HEALTH_STATUS_UNKNOWN
-	}
+	status := healthStatusFromResult(result.Status)
 
 	msg := fmt.Sprintf("CEL policy evaluation: %s", result.Status)
 	if result.MatchedRule != "" {
@@ -270,7 +508,7 @@ func (s *Server) evaluateHealthEvents(ctx context.Context, protoEvents []*pb.Hea
 		CheckName: "cel_policy",
 		Status:    status,
 		Message:   msg,
-	}
+	}, result.AllMatches
 }
 
 // SendHeartbeat handles heartbeat messages from nodes.
@@ -304,30 +542,104 @@ func (s *Server) SendHeartbeat(ctx context.Context, req *connect.Request[pb.Hear
 		}
 	}
 
+	s.checkBurnInTimeout(ctx, req.Msg.NodeId)
+
+	// Heartbeats arrive whether or not any node reports events, so they
+	// drive eviction of nodes that have left, such as replaced nodes.
+	s.healthHistory.Prune(s.clock.Now())
+
 	return connect.NewResponse(&pb.HeartbeatResponse{
 		Acknowledged: true,
 	}), nil
 }
 
-// GetNodeCommands returns pending commands for a node.
+// GetNodeCommands returns pending commands for a node, along with any
+// delivered commands whose acknowledgement is overdue.
 func (s *Server) GetNodeCommands(ctx context.Context, req *connect.Request[pb.GetNodeCommandsRequest]) (*connect.Response[pb.GetNodeCommandsResponse], error) {
 	if req.Msg.NodeId == "" {
 		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("node_id is required"))
 	}
 
-	commands, err := s.db.GetPendingCommands(ctx, req.Msg.NodeId)
+	commands, err := s.deliverCommands(ctx, req.Msg.NodeId)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&pb.GetNodeCommandsResponse{
+		Commands: commands,
+	}), nil
+}
+
+// WatchCommands streams commands to a node as they are issued. Commands
+// already waiting for the node are sent first, and overdue acknowledgements
+// are redelivered on the same schedule as GetNodeCommands.
+func (s *Server) WatchCommands(ctx context.Context, req *connect.Request[pb.WatchCommandsRequest], stream *connect.ServerStream[pb.NodeCommand]) error {
+	nodeID := req.Msg.NodeId
+	if nodeID == "" {
+		return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("node_id is required"))
+	}
+	if _, err := s.db.GetNode(ctx, nodeID); err != nil {
+		return connect.NewError(connect.CodeNotFound, fmt.Errorf("node not found: %s", nodeID))
+	}
+
+	// Subscribe before the first delivery so no command issued in between is missed.
+	notify, unsubscribe := s.commandWatchers.subscribe(nodeID)
+	defer unsubscribe()
+
+	ticker := s.clock.NewTicker(s.config.CommandAckTimeout)
+	defer ticker.Stop()
+
+	s.logger.InfoContext(ctx, "command stream opened", slog.String("node_id", nodeID))
+	defer s.logger.InfoContext(ctx, "command stream closed", slog.String("node_id", nodeID))
+
+	for {
+		commands, err := s.deliverCommands(ctx, nodeID)
+		if err != nil {
+			return err
+		}
+		for _, cmd := range commands {
+			if err := stream.Send(cmd); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-notify:
+		case <-ticker.C():
+		}
+	}
+}
+
+// deliverCommands returns the commands a node should execute now and marks
+// them delivered: pending commands plus delivered commands whose
+// acknowledgement is overdue.
+func (s *Server) deliverCommands(ctx context.Context, nodeID string) ([]*pb.NodeCommand, error) {
+	commands, err := s.db.GetPendingCommands(ctx, nodeID)
 	if err != nil {
 		s.logger.ErrorContext(ctx, "failed to get commands",
-			slog.String("node_id", req.Msg.NodeId),
+			slog.String("node_id", nodeID),
 			slog.String("error", err.Error()),
 		)
 		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to get commands: %w", err))
 	}
 
+	unacknowledged, err := s.unacknowledgedCommands(ctx, nodeID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "failed to check for unacknowledged commands",
+			slog.String("node_id", nodeID),
+			slog.String("error", err.Error()),
+		)
+		// Continue with pending commands - redelivery is retried on the next poll
+	}
+	commands = append(commands, unacknowledged...)
+
 	if len(commands) > 0 {
 		s.logger.DebugContext(ctx, "returning pending commands",
-			slog.String("node_id", req.Msg.NodeId),
+			slog.String("node_id", nodeID),
 			slog.Int("command_count", len(commands)),
+			slog.Int("redelivered", len(unacknowledged)),
 		)
 	}
 
@@ -340,8 +652,8 @@ func (s *Server) GetNodeCommands(ctx context.Context, req *connect.Request[pb.Ge
 			IssuedAt:   timestamppb.New(cmd.IssuedAt),
 		}
 
-		if err := s.db.UpdateCommandStatus(ctx, cmd.CommandID, "acknowledged"); err != nil {
-			s.logger.WarnContext(ctx, "failed to mark command as acknowledged",
+		if err := s.db.MarkCommandDelivered(ctx, cmd.CommandID); err != nil {
+			s.logger.WarnContext(ctx, "failed to mark command as delivered",
 				slog.String("command_id", cmd.CommandID),
 				slog.String("error", err.Error()),
 			)
@@ -349,14 +661,124 @@ func (s *Server) GetNodeCommands(ctx context.Context, req *connect.Request[pb.Ge
 		}
 	}
 
-	return connect.NewResponse(&pb.GetNodeCommandsResponse{
-		Commands: pbCommands,
+	return pbCommands, nil
+}
+
+// unacknowledgedCommands returns delivered commands for a node that were not
+// acknowledged within the ack timeout and should be redelivered. Commands that
+// have used up their delivery attempts are marked failed instead.
+func (s *Server) unacknowledgedCommands(ctx context.Context, nodeID string) ([]*db.CommandRecord, error) {
+	commands, err := s.db.ListCommands(ctx, db.CommandFilter{NodeID: nodeID, Status: db.CommandStatusDelivered})
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now()
+	var redeliver []*db.CommandRecord
+	for _, cmd := range commands {
+		if cmd.Status != db.CommandStatusDelivered || now.Sub(cmd.DeliveredAt) < s.config.CommandAckTimeout {
+			continue
+		}
+
+		if cmd.DeliveryAttempts >= s.config.CommandMaxDeliveries {
+			msg := fmt.Sprintf("not acknowledged after %d deliveries", cmd.DeliveryAttempts)
+			if err := s.db.UpdateCommandResult(ctx, cmd.CommandID, db.CommandStatusFailed, msg, ""); err != nil {
+				s.logger.WarnContext(ctx, "failed to mark unacknowledged command as failed",
+					slog.String("command_id", cmd.CommandID),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+			s.logger.WarnContext(ctx, "command was never acknowledged",
+				slog.String("command_id", cmd.CommandID),
+				slog.String("node_id", nodeID),
+				slog.Int("delivery_attempts", cmd.DeliveryAttempts),
+			)
+			continue
+		}
+
+		s.logger.InfoContext(ctx, "redelivering unacknowledged command",
+			slog.String("command_id", cmd.CommandID),
+			slog.String("node_id", nodeID),
+			slog.Int("delivery_attempts", cmd.DeliveryAttempts),
+		)
+		redeliver = append(redeliver, cmd)
+	}
+	return redeliver, nil
+}
+
+// AcknowledgeCommand records the progress or outcome of a command reported by
+// the node that executed it.
+func (s *Server) AcknowledgeCommand(ctx context.Context, req *connect.Request[pb.AcknowledgeCommandRequest]) (*connect.Response[pb.AcknowledgeCommandResponse], error) {
+	if req.Msg.NodeId == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("node_id is required"))
+	}
+	if req.Msg.CommandId == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("command_id is required"))
+	}
+
+	status, ok := commandStatusFromProto(req.Msg.Status)
+	if !ok {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("status must be RUNNING, COMPLETED, or FAILED, got %s", req.Msg.Status.String()))
+	}
+
+	cmd, err := s.db.GetCommand(ctx, req.Msg.CommandId)
+	if err != nil || cmd.NodeID != req.Msg.NodeId {
+		s.logger.WarnContext(ctx, "acknowledgement for unknown command",
+			slog.String("command_id", req.Msg.CommandId),
+			slog.String("node_id", req.Msg.NodeId),
+		)
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("command not found: %s", req.Msg.CommandId))
+	}
+	if cmd.Status == db.CommandStatusCompleted || cmd.Status == db.CommandStatusFailed {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf("command %s already %s", cmd.CommandID, cmd.Status))
+	}
+
+	if err := s.db.UpdateCommandResult(ctx, cmd.CommandID, status, req.Msg.Message, req.Msg.Output); err != nil {
+		s.logger.ErrorContext(ctx, "failed to record command status",
+			slog.String("command_id", cmd.CommandID),
+			slog.String("error", err.Error()),
+		)
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to record command status: %w", err))
+	}
+
+	s.logger.InfoContext(ctx, "command status updated",
+		slog.String("command_id", cmd.CommandID),
+		slog.String("node_id", cmd.NodeID),
+		slog.String("command_type", cmd.Type.String()),
+		slog.String("status", status),
+		slog.String("message", req.Msg.Message),
+	)
+
+	if cmd.IssuedBy == burnInIssuer && status != db.CommandStatusRunning {
+		s.burnInDiagnosticsFinished(ctx, cmd, status, req.Msg.Output)
+	}
+
+	return connect.NewResponse(&pb.AcknowledgeCommandResponse{
+		Acknowledged: true,
 	}), nil
 }
 
+// commandStatusFromProto converts a status reported by a node to its database
+// representation. Only statuses a node may report are accepted.
+func commandStatusFromProto(status pb.CommandStatus) (string, bool) {
+	switch status {
+	case pb.CommandStatus_COMMAND_STATUS_RUNNING:
+		return db.CommandStatusRunning, true
+	case pb.CommandStatus_COMMAND_STATUS_COMPLETED:
+		return db.CommandStatusCompleted, true
+	case pb.CommandStatus_COMMAND_STATUS_FAILED:
+		return db.CommandStatusFailed, true
+	default:
+		return "", false
+	}
+}
+
 // ListNodes returns all registered nodes with optional filters.
 func (s *Server) ListNodes(ctx context.Context, req *connect.Request[pb.ListNodesRequest]) (*connect.Response[pb.ListNodesResponse], error) {
-	nodes, err := s.db.ListNodes(ctx)
+	// Listing through the event log records any change made since the last
+	// sync, so a watch resumed from the version sees only later changes.
+	nodes, version, err := s.nodeEvents.sync(ctx, s.db)
 	if err != nil {
 		s.logger.ErrorContext(ctx, "failed to list nodes",
 			slog.String("error", err.Error()),
@@ -380,18 +802,7 @@ func (s *Server) ListNodes(ctx context.Context, req *connect.Request[pb.ListNode
 
 	pbNodes := make([]*pb.NodeInfo, len(filtered))
 	for i, node := range filtered {
-		pbNodes[i] = &pb.NodeInfo{
-			NodeId:        node.NodeID,
-			Provider:      node.Provider,
-			Region:        node.Region,
-			Zone:          node.Zone,
-			InstanceType:  node.InstanceType,
-			Status:        node.Status,
-			HealthStatus:  node.HealthStatus,
-			LastHeartbeat: timestamppb.New(node.LastHeartbeat),
-			Gpus:          node.GPUs,
-			Metadata:      node.Metadata,
-		}
+		pbNodes[i] = nodeRecordToProto(node)
 	}
 
 	s.logger.DebugContext(ctx, "listed nodes",
@@ -400,7 +811,8 @@ func (s *Server) ListNodes(ctx context.Context, req *connect.Request[pb.ListNode
 	)
 
 	return connect.NewResponse(&pb.ListNodesResponse{
-		Nodes: pbNodes,
+		Nodes:           pbNodes,
+		ResourceVersion: version,
 	}), nil
 }
 
@@ -418,50 +830,88 @@ func (s *Server) GetNode(ctx context.Context, req *connect.Request[pb.GetNodeReq
 		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("node not found: %s", req.Msg.NodeId))
 	}
 
-	pbNode := &pb.NodeInfo{
-		NodeId:        node.NodeID,
-		Provider:      node.Provider,
-		Region:        node.Region,
-		Zone:          node.Zone,
-		InstanceType:  node.InstanceType,
-		Status:        node.Status,
-		HealthStatus:  node.HealthStatus,
-		LastHeartbeat: timestamppb.New(node.LastHeartbeat),
-		Gpus:          node.GPUs,
-		Metadata:      node.Metadata,
-	}
-
 	return connect.NewResponse(&pb.GetNodeResponse{
-		Node: pbNode,
+		Node: nodeRecordToProto(node),
 	}), nil
 }
 
+// callerSubject returns the authenticated subject of the request, or "" for
+// an unauthenticated request.
+func callerSubject(ctx context.Context) string {
+	if id := auth.IdentityFromContext(ctx); id != nil {
+		return id.Subject
+	}
+	return ""
+}
+
 // IssueCommand issues a command to a specific node.
 func (s *Server) IssueCommand(ctx context.Context, req *connect.Request[pb.IssueCommandRequest]) (*connect.Response[pb.IssueCommandResponse], error) {
-	if req.Msg.NodeId == "" {
+	resp, err := s.issueCommand(ctx, req.Msg, callerSubject(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(resp), nil
+}
+
+const (
+	// commandPruneInterval is how often finished commands older than the
+	// retention period are deleted.
+	commandPruneInterval = time.Hour
+
+	// defaultCommandLimit is how many commands ListCommands returns when the
+	// request sets no limit.
+	defaultCommandLimit = 1000
+)
+
+// pruneCommands deletes finished commands older than the retention period,
+// at most once per commandPruneInterval.
+func (s *Server) pruneCommands(ctx context.Context, now time.Time) {
+	s.cmdPruneMu.Lock()
+	if now.Sub(s.lastCmdPrune) < commandPruneInterval {
+		s.cmdPruneMu.Unlock()
+		return
+	}
+	s.lastCmdPrune = now
+	s.cmdPruneMu.Unlock()
+
+	n, err := s.db.DeleteCommandsBefore(ctx, now.Add(-s.config.CommandRetention))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to delete expired commands", slog.String("error", err.Error()))
+		return
+	}
+	if n > 0 {
+		s.logger.DebugContext(ctx, "deleted expired commands", slog.Int("count", n))
+	}
+}
+
+// issueCommand carries out or queues a node command on behalf of issuedBy.
+// Errors are connect errors.
+func (s *Server) issueCommand(ctx context.Context, msg *pb.IssueCommandRequest, issuedBy string) (*pb.IssueCommandResponse, error) {
+	if msg.NodeId == "" {
 		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("node_id is required"))
 	}
-	if req.Msg.CommandType == pb.NodeCommandType_NODE_COMMAND_TYPE_UNKNOWN {
+	if msg.CommandType == pb.NodeCommandType_NODE_COMMAND_TYPE_UNKNOWN {
 		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("command_type is required"))
 	}
 
-	node, err := s.db.GetNode(ctx, req.Msg.NodeId)
+	node, err := s.db.GetNode(ctx, msg.NodeId)
 	if err != nil {
 		s.logger.WarnContext(ctx, "cannot issue command to unknown node",
-			slog.String("node_id", req.Msg.NodeId),
+			slog.String("node_id", msg.NodeId),
 		)
-		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("node not found: %s", req.Msg.NodeId))
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("node not found: %s", msg.NodeId))
 	}
+	s.pruneCommands(ctx, s.clock.Now())
 
 	// Handle node status updates for cordon/uncordon/drain commands.
-	reason := req.Msg.Parameters["reason"]
-	nodeID := req.Msg.NodeId
+	reason := msg.Parameters["reason"]
+	nodeID := msg.NodeId
 	previousStatus := node.Status
 
-	switch req.Msg.CommandType {
+	switch msg.CommandType {
 	case pb.NodeCommandType_NODE_COMMAND_TYPE_CORDON:
 		err := s.updateStatusAndNotify(ctx, nodeID, pb.NodeStatus_NODE_STATUS_CORDONED, previousStatus,
-			func() error { return s.notifier.Cordon(ctx, nodeID, reason) })
+			func(n notifier.Notifier) error { return n.Cordon(ctx, nodeID, reason) })
 		if err != nil {
 			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to cordon node: %w", err))
 		}
@@ -471,14 +921,24 @@ func (s *Server) IssueCommand(ctx context.Context, req *connect.Request[pb.Issue
 			return nil, connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf("node %s is not cordoned (current status: %s)", nodeID, node.Status.String()))
 		}
 		err := s.updateStatusAndNotify(ctx, nodeID, pb.NodeStatus_NODE_STATUS_ACTIVE, previousStatus,
-			func() error { return s.notifier.Uncordon(ctx, nodeID) })
+			func(n notifier.Notifier) error { return n.Uncordon(ctx, nodeID) })
 		if err != nil {
 			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to uncordon node: %w", err))
 		}
+		// Uncordoning by hand overrides a hold-down.
+		if !node.HoldUntil.IsZero() {
+			if err := s.clearHold(ctx, nodeID); err != nil {
+				s.logger.ErrorContext(ctx, "failed to clear node hold-down",
+					slog.String("node_id", nodeID),
+					slog.String("error", err.Error()),
+				)
+			}
+			s.nodeEvents.syncNode(ctx, s.db, nodeID)
+		}
 
 	case pb.NodeCommandType_NODE_COMMAND_TYPE_DRAIN:
 		err := s.updateStatusAndNotify(ctx, nodeID, pb.NodeStatus_NODE_STATUS_DRAINING, previousStatus,
-			func() error { return s.notifier.Drain(ctx, nodeID, reason) })
+			func(n notifier.Notifier) error { return n.Drain(ctx, nodeID, reason) })
 		if err != nil {
 			return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to drain node: %w", err))
 		}
@@ -487,9 +947,9 @@ func (s *Server) IssueCommand(ctx context.Context, req *connect.Request[pb.Issue
 	// Cordon/uncordon/drain are control-plane-only operations.
 	// Record them in the DB for audit trail, but mark as "completed" so they
 	// don't appear in GetPendingCommands (nodes don't need to act on them).
-	if req.Msg.CommandType == pb.NodeCommandType_NODE_COMMAND_TYPE_CORDON ||
-		req.Msg.CommandType == pb.NodeCommandType_NODE_COMMAND_TYPE_UNCORDON ||
-		req.Msg.CommandType == pb.NodeCommandType_NODE_COMMAND_TYPE_DRAIN {
+	if msg.CommandType == pb.NodeCommandType_NODE_COMMAND_TYPE_CORDON ||
+		msg.CommandType == pb.NodeCommandType_NODE_COMMAND_TYPE_UNCORDON ||
+		msg.CommandType == pb.NodeCommandType_NODE_COMMAND_TYPE_DRAIN {
 
 		commandID := uuid.New().String()
 		issuedAt := s.clock.Now()
@@ -497,10 +957,11 @@ func (s *Server) IssueCommand(ctx context.Context, req *connect.Request[pb.Issue
 		record := &db.CommandRecord{
 			CommandID:  commandID,
 			NodeID:     nodeID,
-			Type:       req.Msg.CommandType,
-			Parameters: req.Msg.Parameters,
+			Type:       msg.CommandType,
+			Parameters: msg.Parameters,
 			IssuedAt:   issuedAt,
-			Status:     "completed", // CP-only: don't queue to node
+			IssuedBy:   issuedBy,
+			Status:     db.CommandStatusCompleted, // CP-only: don't queue to node
 		}
 
 		if err := s.db.CreateCommand(ctx, record); err != nil {
@@ -515,13 +976,14 @@ func (s *Server) IssueCommand(ctx context.Context, req *connect.Request[pb.Issue
 		s.logger.InfoContext(ctx, "processed control plane command",
 			slog.String("command_id", commandID),
 			slog.String("node_id", nodeID),
-			slog.String("command_type", req.Msg.CommandType.String()),
+			slog.String("command_type", msg.CommandType.String()),
+			slog.String("issued_by", issuedBy),
 		)
 
-		return connect.NewResponse(&pb.IssueCommandResponse{
+		return &pb.IssueCommandResponse{
 			CommandId: commandID,
 			IssuedAt:  timestamppb.New(issuedAt),
-		}), nil
+		}, nil
 	}
 
 	// For other command types, queue to the node agent
@@ -530,34 +992,160 @@ func (s *Server) IssueCommand(ctx context.Context, req *connect.Request[pb.Issue
 
 	record := &db.CommandRecord{
 		CommandID:  commandID,
-		NodeID:     req.Msg.NodeId,
-		Type:       req.Msg.CommandType,
-		Parameters: req.Msg.Parameters,
+		NodeID:     msg.NodeId,
+		Type:       msg.CommandType,
+		Parameters: msg.Parameters,
 		IssuedAt:   issuedAt,
-		Status:     "pending",
+		IssuedBy:   issuedBy,
+		Status:     db.CommandStatusPending,
 	}
 
 	if err := s.db.CreateCommand(ctx, record); err != nil {
 		s.logger.ErrorContext(ctx, "failed to create command",
 			slog.String("command_id", commandID),
-			slog.String("node_id", req.Msg.NodeId),
+			slog.String("node_id", msg.NodeId),
 			slog.String("error", err.Error()),
 		)
 		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to create command: %w", err))
 	}
+	s.commandWatchers.notify(msg.NodeId)
 
 	s.logger.InfoContext(ctx, "issued command",
 		slog.String("command_id", commandID),
-		slog.String("node_id", req.Msg.NodeId),
-		slog.String("command_type", req.Msg.CommandType.String()),
+		slog.String("node_id", msg.NodeId),
+		slog.String("command_type", msg.CommandType.String()),
+		slog.String("issued_by", issuedBy),
 	)
 
-	return connect.NewResponse(&pb.IssueCommandResponse{
+	return &pb.IssueCommandResponse{
 		CommandId: commandID,
 		IssuedAt:  timestamppb.New(issuedAt),
+	}, nil
+}
+
+// ListCommands returns issued commands with optional filters.
+func (s *Server) ListCommands(ctx context.Context, req *connect.Request[pb.ListCommandsRequest]) (*connect.Response[pb.ListCommandsResponse], error) {
+	if req.Msg.Limit < 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("limit must be >= 0"))
+	}
+
+	filter := db.CommandFilter{
+		NodeID:   req.Msg.NodeId,
+		Type:     req.Msg.CommandType,
+		IssuedBy: req.Msg.IssuedBy,
+		Limit:    int(req.Msg.Limit),
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultCommandLimit
+	}
+	if req.Msg.Status != pb.CommandStatus_COMMAND_STATUS_UNKNOWN {
+		var ok bool
+		if filter.Status, ok = commandStatusToDB(req.Msg.Status); !ok {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("invalid status: %s", req.Msg.Status.String()))
+		}
+	}
+	if req.Msg.IssuedAfter != nil {
+		filter.IssuedAfter = req.Msg.IssuedAfter.AsTime()
+	}
+	if req.Msg.IssuedBefore != nil {
+		filter.IssuedBefore = req.Msg.IssuedBefore.AsTime()
+	}
+
+	commands, err := s.db.ListCommands(ctx, filter)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list commands",
+			slog.String("error", err.Error()),
+		)
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to list commands: %w", err))
+	}
+
+	pbCommands := make([]*pb.CommandInfo, len(commands))
+	for i, cmd := range commands {
+		pbCommands[i] = commandRecordToProto(cmd)
+	}
+
+	s.logger.DebugContext(ctx, "listed commands", slog.Int("count", len(commands)))
+
+	return connect.NewResponse(&pb.ListCommandsResponse{
+		Commands: pbCommands,
 	}), nil
 }
 
+// GetCommand returns details about a specific command.
+func (s *Server) GetCommand(ctx context.Context, req *connect.Request[pb.GetCommandRequest]) (*connect.Response[pb.GetCommandResponse], error) {
+	if req.Msg.CommandId == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("command_id is required"))
+	}
+
+	cmd, err := s.db.GetCommand(ctx, req.Msg.CommandId)
+	if err != nil {
+		s.logger.WarnContext(ctx, "command not found",
+			slog.String("command_id", req.Msg.CommandId),
+		)
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("command not found: %s", req.Msg.CommandId))
+	}
+
+	return connect.NewResponse(&pb.GetCommandResponse{
+		Command: commandRecordToProto(cmd),
+	}), nil
+}
+
+// commandRecordToProto converts a db.CommandRecord to a pb.CommandInfo.
+func commandRecordToProto(record *db.CommandRecord) *pb.CommandInfo {
+	info := &pb.CommandInfo{
+		CommandId:        record.CommandID,
+		NodeId:           record.NodeID,
+		Type:             record.Type,
+		Parameters:       record.Parameters,
+		Status:           commandStatusToProto(record.Status),
+		IssuedAt:         timestamppb.New(record.IssuedAt),
+		IssuedBy:         record.IssuedBy,
+		DeliveryAttempts: int32(record.DeliveryAttempts),
+		Message:          record.Message,
+		Output:           record.Output,
+	}
+
+	if !record.DeliveredAt.IsZero() {
+		info.DeliveredAt = timestamppb.New(record.DeliveredAt)
+	}
+	if !record.UpdatedAt.IsZero() {
+		info.UpdatedAt = timestamppb.New(record.UpdatedAt)
+	}
+
+	return info
+}
+
+// commandStatusToProto converts a database command status to its proto enum.
+func commandStatusToProto(status string) pb.CommandStatus {
+	switch status {
+	case db.CommandStatusPending:
+		return pb.CommandStatus_COMMAND_STATUS_PENDING
+	case db.CommandStatusDelivered:
+		return pb.CommandStatus_COMMAND_STATUS_DELIVERED
+	case db.CommandStatusRunning:
+		return pb.CommandStatus_COMMAND_STATUS_RUNNING
+	case db.CommandStatusCompleted:
+		return pb.CommandStatus_COMMAND_STATUS_COMPLETED
+	case db.CommandStatusFailed:
+		return pb.CommandStatus_COMMAND_STATUS_FAILED
+	default:
+		return pb.CommandStatus_COMMAND_STATUS_UNKNOWN
+	}
+}
+
+// commandStatusToDB converts a proto command status to its database
+// representation.
+func commandStatusToDB(status pb.CommandStatus) (string, bool) {
+	switch status {
+	case pb.CommandStatus_COMMAND_STATUS_PENDING:
+		return db.CommandStatusPending, true
+	case pb.CommandStatus_COMMAND_STATUS_DELIVERED:
+		return db.CommandStatusDelivered, true
+	default:
+		return commandStatusFromProto(status)
+	}
+}
+
 // ListInstances returns all tracked instances with optional filters.
 func (s *Server) ListInstances(ctx context.Context, req *connect.Request[pb.ListInstancesRequest]) (*connect.Response[pb.ListInstancesResponse], error) {
 	instances, err := s.db.ListInstances(ctx)
@@ -647,6 +1235,464 @@ func (s *Server) instanceRecordToProto(record *db.InstanceRecord) *pb.InstanceIn
 	return info
 }
 
+// ListPools returns all pools with their status and autoscaler state.
+func (s *Server) ListPools(ctx context.Context, req *connect.Request[pb.ListPoolsRequest]) (*connect.Response[pb.ListPoolsResponse], error) {
+	var pools []*pb.PoolInfo
+	if s.poolManager != nil {
+		for _, info := range s.poolManager.DescribePools(ctx) {
+			pools = append(pools, poolInfoToProto(info))
+		}
+	}
+
+	return connect.NewResponse(&pb.ListPoolsResponse{
+		Pools: pools,
+	}), nil
+}
+
+// GetPool returns details about a specific pool.
+func (s *Server) GetPool(ctx context.Context, req *connect.Request[pb.GetPoolRequest]) (*connect.Response[pb.GetPoolResponse], error) {
+	if req.Msg.Name == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("name is required"))
+	}
+
+	info, err := s.describePool(ctx, req.Msg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&pb.GetPoolResponse{
+		Pool: poolInfoToProto(info),
+	}), nil
+}
+
+// ScalePool scales a pool to the requested node count.
+func (s *Server) ScalePool(ctx context.Context, req *connect.Request[pb.ScalePoolRequest]) (*connect.Response[pb.ScalePoolResponse], error) {
+	if req.Msg.Name == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("name is required"))
+	}
+
+	info, err := s.describePool(ctx, req.Msg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	target := int(req.Msg.TargetNodes)
+	if target < info.Config.MinNodes || target > info.Config.MaxNodes {
+		return nil, connect.NewError(connect.CodeInvalidArgument,
+			fmt.Errorf("target_nodes %d is outside pool limits [%d, %d]", target, info.Config.MinNodes, info.Config.MaxNodes))
+	}
+
+	s.logger.InfoContext(ctx, "scaling pool",
+		slog.String("pool", req.Msg.Name),
+		slog.Int("from", info.Status.TotalNodes),
+		slog.Int("to", target),
+		slog.String("issued_by", callerSubject(ctx)),
+	)
+
+	if err := s.poolManager.ScalePool(ctx, req.Msg.Name, target); err != nil {
+		if errors.Is(err, ErrPoolDeleting) {
+			return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+		}
+		s.logger.ErrorContext(ctx, "failed to scale pool",
+			slog.String("pool", req.Msg.Name),
+			slog.String("error", err.Error()),
+		)
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to scale pool: %w", err))
+	}
+
+	info, err = s.describePool(ctx, req.Msg.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(&pb.ScalePoolResponse{
+		Pool: poolInfoToProto(info),
+	}), nil
+}
+
+// CreatePool adds a pool from a YAML spec without restarting.
+func (s *Server) CreatePool(ctx context.Context, req *connect.Request[pb.CreatePoolRequest]) (*connect.Response[pb.CreatePoolResponse], error) {
+	cfg, err := s.parsePoolRequest(req.Msg.Name, req.Msg.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "creating pool",
+		slog.String("pool", req.Msg.Name),
+		slog.String("issued_by", callerSubject(ctx)),
+	)
+
+	policy, err := s.loadPoolHealthPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.poolManager.CreatePool(ctx, req.Msg.Name, cfg); err != nil {
+		return nil, poolLifecycleError(err)
+	}
+	// Also clears any policy left by an earlier pool of the same name.
+	if err := s.SetPoolHealthPolicy(req.Msg.Name, policy); err != nil {
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to set pool health policy: %w", err))
+	}
+
+	info, err := s.describePool(ctx, req.Msg.Name)
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&pb.CreatePoolResponse{
+		Pool: poolInfoToProto(info),
+	}), nil
+}
+
+// UpdatePool replaces a pool's configuration from a YAML spec.
+func (s *Server) UpdatePool(ctx context.Context, req *connect.Request[pb.UpdatePoolRequest]) (*connect.Response[pb.UpdatePoolResponse], error) {
+	cfg, err := s.parsePoolRequest(req.Msg.Name, req.Msg.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "updating pool",
+		slog.String("pool", req.Msg.Name),
+		slog.String("issued_by", callerSubject(ctx)),
+	)
+
+	policy, err := s.loadPoolHealthPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.poolManager.UpdatePool(ctx, req.Msg.Name, cfg); err != nil {
+		return nil, poolLifecycleError(err)
+	}
+	// The spec replaces the whole pool configuration, so a spec without a
+	// health_policy puts the pool back on the server policy.
+	if err := s.SetPoolHealthPolicy(req.Msg.Name, policy); err != nil {
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to set pool health policy: %w", err))
+	}
+
+	info, err := s.describePool(ctx, req.Msg.Name)
+	if err != nil {
+		return nil, err
+	}
+	return connect.NewResponse(&pb.UpdatePoolResponse{
+		Pool: poolInfoToProto(info),
+	}), nil
+}
+
+// DeletePool starts draining a pool's nodes; the pool is removed once they
+// are terminated.
+func (s *Server) DeletePool(ctx context.Context, req *connect.Request[pb.DeletePoolRequest]) (*connect.Response[pb.DeletePoolResponse], error) {
+	if req.Msg.Name == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("name is required"))
+	}
+	if s.poolManager == nil {
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("pool not found: %s", req.Msg.Name))
+	}
+
+	s.logger.InfoContext(ctx, "deleting pool",
+		slog.String("pool", req.Msg.Name),
+		slog.String("issued_by", callerSubject(ctx)),
+	)
+
+	if err := s.poolManager.DeletePool(ctx, req.Msg.Name); err != nil {
+		return nil, poolLifecycleError(err)
+	}
+
+	// The pool may already be gone if it had no nodes.
+	resp := &pb.DeletePoolResponse{}
+	if info, err := s.poolManager.DescribePool(ctx, req.Msg.Name); err == nil {
+		resp.Pool = poolInfoToProto(info)
+	}
+	return connect.NewResponse(resp), nil
+}
+
+func (s *Server) parsePoolRequest(name, spec string) (config.PoolCfg, error) {
+	if name == "" {
+		return config.PoolCfg{}, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("name is required"))
+	}
+	if spec == "" {
+		return config.PoolCfg{}, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("spec is required"))
+	}
+	if s.poolManager == nil {
+		return config.PoolCfg{}, connect.NewError(connect.CodeFailedPrecondition, ErrRuntimePoolsDisabled)
+	}
+	cfg, err := config.ParsePool([]byte(spec))
+	if err != nil {
+		return config.PoolCfg{}, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	return cfg, nil
+}
+
+// loadPoolHealthPolicy loads the health policy file a pool spec names, or
+// returns nil if it names none. The path must stay within HealthPolicyDir, so
+// that callers cannot have the control plane read arbitrary files. The
+// policy is compiled here, before the pool is changed, so that a bad rule
+// condition leaves the pool as it was.
+func (s *Server) loadPoolHealthPolicy(cfg config.PoolCfg) (*health.Policy, error) {
+	if cfg.HealthPolicy == "" {
+		return nil, nil
+	}
+	if s.config.HealthPolicyDir == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument,
+			fmt.Errorf("health_policy cannot be set over RPC without a server health policy directory; use SetHealthPolicy"))
+	}
+
+	root, err := os.OpenRoot(s.config.HealthPolicyDir)
+	if err != nil {
+		s.logger.Error("failed to open health policy directory", slog.String("error", err.Error()))
+		return nil, connect.NewError(connect.CodeInternal, fmt.Errorf("failed to open health policy directory"))
+	}
+	defer root.Close()
+	notReadable := connect.NewError(connect.CodeInvalidArgument,
+		fmt.Errorf("health_policy %q is not a readable file in the health policy directory", cfg.HealthPolicy))
+	f, err := root.Open(cfg.HealthPolicy)
+	if err != nil {
+		return nil, notReadable
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, notReadable
+	}
+	policy, err := health.ParsePolicy(data)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("health_policy: %w", err))
+	}
+	if _, err := health.NewEvaluator(policy); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("health_policy: %w", err))
+	}
+	return policy, nil
+}
+
+// poolLifecycleError maps pool manager errors to Connect codes.
+func poolLifecycleError(err error) error {
+	switch {
+	case errors.Is(err, ErrInvalidPoolConfig):
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	case errors.Is(err, ErrPoolExists):
+		return connect.NewError(connect.CodeAlreadyExists, err)
+	case errors.Is(err, ErrPoolNotFound):
+		return connect.NewError(connect.CodeNotFound, err)
+	case errors.Is(err, ErrPoolDeleting), errors.Is(err, ErrRuntimePoolsDisabled):
+		return connect.NewError(connect.CodeFailedPrecondition, err)
+	default:
+		return connect.NewError(connect.CodeInternal, err)
+	}
+}
+
+// knownPool reports whether the pool manager has a pool with the name.
+func (s *Server) knownPool(name string) bool {
+	if s.poolManager == nil {
+		return false
+	}
+	_, ok := s.poolManager.GetPool(name)
+	return ok
+}
+
+func (s *Server) describePool(ctx context.Context, name string) (PoolInfo, error) {
+	if s.poolManager == nil {
+		return PoolInfo{}, connect.NewError(connect.CodeNotFound, fmt.Errorf("pool not found: %s", name))
+	}
+	info, err := s.poolManager.DescribePool(ctx, name)
+	if err != nil {
+		return PoolInfo{}, connect.NewError(connect.CodeNotFound, fmt.Errorf("pool not found: %s", name))
+	}
+	return info, nil
+}
+
+func poolInfoToProto(info PoolInfo) *pb.PoolInfo {
+	cfg := info.Config
+	pbInfo := &pb.PoolInfo{
+		Name:            cfg.Name,
+		InstanceType:    cfg.InstanceType,
+		Region:          cfg.Region,
+		Zones:           cfg.Zones,
+		MinNodes:        int32(cfg.MinNodes),
+		MaxNodes:        int32(cfg.MaxNodes),
+		CooldownSeconds: int64(cfg.CooldownPeriod.Seconds()),
+		AutoReplace:     cfg.AutoReplace,
+		MinHealthyGpus:  int32(cfg.MinHealthyGPUs),
+		Providers:       info.Providers,
+		Labels:          cfg.Labels,
+		AutoscalerType:  info.AutoscalerType,
+		Deleting:        info.Deleting,
+		Status: &pb.PoolStatus{
+			TotalNodes:       int32(info.Status.TotalNodes),
+			HealthyNodes:     int32(info.Status.HealthyNodes),
+			UnhealthyNodes:   int32(info.Status.UnhealthyNodes),
+			CordonedNodes:    int32(info.Status.CordonedNodes),
+			Utilization:      info.Status.Utilization,
+			CanScaleUp:       info.Status.CanScaleUp,
+			CanScaleDown:     info.Status.CanScaleDown,
+			BootstrapFailed:  int32(info.Status.BootstrapFailed),
+			BootstrapPending: int32(info.Status.BootstrapPending),
+		},
+	}
+	if info.LastRecommendation != nil {
+		pbInfo.LastRecommendation = &pb.ScaleRecommendation{
+			TargetNodes: int32(info.LastRecommendation.TargetNodes),
+			Reason:      info.LastRecommendation.Reason,
+		}
+	}
+	if !info.LastEvaluatedAt.IsZero() {
+		pbInfo.LastEvaluatedAt = timestamppb.New(info.LastEvaluatedAt)
+	}
+	return pbInfo
+}
+
+// GetHealthPolicy returns the health policy in effect, for the server or for
+// a pool, and the policy a rollback would restore.
+func (s *Server) GetHealthPolicy(ctx context.Context, req *connect.Request[pb.GetHealthPolicyRequest]) (*connect.Response[pb.GetHealthPolicyResponse], error) {
+	if s.healthEvaluator == nil {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf("health evaluation is disabled"))
+	}
+
+	evaluator, inherited := s.healthEvaluator, false
+	if req.Msg.Pool != "" {
+		if !s.knownPool(req.Msg.Pool) {
+			return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("pool not found: %s", req.Msg.Pool))
+		}
+		if e := s.poolEvaluator(req.Msg.Pool); e != nil {
+			evaluator = e
+		} else {
+			inherited = true
+		}
+	}
+
+	policy, err := healthPolicyToProto(evaluator.Policy())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	resp := &pb.GetHealthPolicyResponse{Policy: policy, Inherited: inherited}
+	if !inherited {
+		resp.Previous, err = healthPolicyToProto(evaluator.PreviousPolicy())
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInternal, err)
+		}
+	}
+	return connect.NewResponse(resp), nil
+}
+
+// SetHealthPolicy replaces the server or a pool's health policy, or rolls
+// back to the previous one. A policy that does not parse or compile is
+// rejected and the current policy stays in effect.
+func (s *Server) SetHealthPolicy(ctx context.Context, req *connect.Request[pb.SetHealthPolicyRequest]) (*connect.Response[pb.SetHealthPolicyResponse], error) {
+	if s.healthEvaluator == nil {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf("health evaluation is disabled"))
+	}
+
+	issuedBy := callerSubject(ctx)
+
+	pool := req.Msg.Pool
+	evaluator := s.healthEvaluator
+	if pool != "" {
+		if !s.knownPool(pool) {
+			return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("pool not found: %s", pool))
+		}
+		evaluator = s.poolEvaluator(pool)
+	}
+
+	if req.Msg.Rollback {
+		if req.Msg.Policy != "" {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("policy must be empty when rollback is set"))
+		}
+		if evaluator == nil {
+			return nil, connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf("pool %s has no health policy of its own", pool))
+		}
+		if err := evaluator.Rollback(); err != nil {
+			if errors.Is(err, health.ErrNoPreviousPolicy) {
+				return nil, connect.NewError(connect.CodeFailedPrecondition, err)
+			}
+			return nil, connect.NewError(connect.CodeInternal, err)
+		}
+		s.logger.InfoContext(ctx, "health policy rolled back",
+			slog.String("pool", pool),
+			slog.Int("rules", len(evaluator.Policy().Rules)),
+			slog.String("issued_by", issuedBy),
+		)
+	} else {
+		if req.Msg.Policy == "" {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("policy is required"))
+		}
+		policy, err := health.ParsePolicy([]byte(req.Msg.Policy))
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		if pool != "" {
+			err = s.SetPoolHealthPolicy(pool, policy)
+			evaluator = s.poolEvaluator(pool)
+		} else {
+			err = evaluator.UpdatePolicy(policy)
+		}
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		s.logger.InfoContext(ctx, "health policy updated",
+			slog.String("pool", pool),
+			slog.Int("rules", len(policy.Rules)),
+			slog.String("issued_by", issuedBy),
+		)
+	}
+
+	policy, err := healthPolicyToProto(evaluator.Policy())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	previous, err := healthPolicyToProto(evaluator.PreviousPolicy())
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	return connect.NewResponse(&pb.SetHealthPolicyResponse{
+		Policy:   policy,
+		Previous: previous,
+	}), nil
+}
+
+// ValidateHealthPolicy parses and compiles a health policy without applying
+// it. An invalid policy is reported in the response, not as an RPC error.
+func (s *Server) ValidateHealthPolicy(ctx context.Context, req *connect.Request[pb.ValidateHealthPolicyRequest]) (*connect.Response[pb.ValidateHealthPolicyResponse], error) {
+	if s.healthEvaluator == nil {
+		return nil, connect.NewError(connect.CodeFailedPrecondition, fmt.Errorf("health evaluation is disabled"))
+	}
+	if req.Msg.Policy == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("policy is required"))
+	}
+
+	policy, err := health.ParsePolicy([]byte(req.Msg.Policy))
+	if err != nil {
+		return connect.NewResponse(&pb.ValidateHealthPolicyResponse{Error: err.Error()}), nil
+	}
+	resp := &pb.ValidateHealthPolicyResponse{RuleCount: int32(len(policy.Rules))}
+	if err := s.healthEvaluator.Validate(policy); err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.Valid = true
+	}
+	return connect.NewResponse(resp), nil
+}
+
+func healthPolicyToProto(policy *health.Policy) (*pb.HealthPolicy, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	data, err := policy.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("marshal health policy: %w", err)
+	}
+	pbPolicy := &pb.HealthPolicy{Yaml: string(data)}
+	for _, rule := range policy.Rules {
+		pbPolicy.Rules = append(pbPolicy.Rules, &pb.HealthPolicyRule{
+			Name:        rule.Name,
+			Description: rule.Description,
+			Condition:   rule.Condition,
+			Result:      string(rule.Result),
+			Actions:     actionTypes(rule.Actions),
+			Scope:       string(rule.Scope),
+		})
+	}
+	return pbPolicy, nil
+}
+
 // updateStatusAndNotify updates a node's status and notifies the external system.
 // If notification fails, the status change is rolled back.
 func (s *Server) updateStatusAndNotify(
@@ -654,14 +1700,15 @@ func (s *Server) updateStatusAndNotify(
 	nodeID string,
 	newStatus pb.NodeStatus,
 	previousStatus pb.NodeStatus,
-	notify func() error,
+	notify func(notifier.Notifier) error,
 ) error {
 	if err := s.db.UpdateNodeStatus(ctx, nodeID, newStatus); err != nil {
 		return err
 	}
+	defer s.nodeEvents.syncNode(ctx, s.db, nodeID)
 
-	if s.notifier != nil && notify != nil {
-		if err := notify(); err != nil {
+	if n := s.currentNotifier(); n != nil && notify != nil {
+		if err := notify(n); err != nil {
 			s.logger.ErrorContext(ctx, "notifier failed, rolling back status",
 				slog.String("node_id", nodeID),
 				slog.String("error", err.Error()),
@@ -677,3 +1724,49 @@ func (s *Server) updateStatusAndNotify(
 	}
 	return nil
 }
+
+// commandWatchers tracks open WatchCommands streams so that newly issued
+// commands can be pushed to nodes instead of waiting for the next poll.
+type commandWatchers struct {
+	mu       sync.Mutex
+	watchers map[string]map[chan struct{}]struct{} // node ID -> notification channels
+}
+
+func newCommandWatchers() *commandWatchers {
+	return &commandWatchers{watchers: make(map[string]map[chan struct{}]struct{})}
+}
+
+// subscribe registers a watcher for a node. The returned channel receives a
+// value whenever a command is issued to the node; notifications coalesce if
+// the watcher is busy.
+func (w *commandWatchers) subscribe(nodeID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	w.mu.Lock()
+	if w.watchers[nodeID] == nil {
+		w.watchers[nodeID] = make(map[chan struct{}]struct{})
+	}
+	w.watchers[nodeID][ch] = struct{}{}
+	w.mu.Unlock()
+
+	return ch, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.watchers[nodeID], ch)
+		if len(w.watchers[nodeID]) == 0 {
+			delete(w.watchers, nodeID)
+		}
+	}
+}
+
+// notify wakes every watcher for a node.
+func (w *commandWatchers) notify(nodeID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch := range w.watchers[nodeID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
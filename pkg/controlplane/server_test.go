@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -11,7 +14,11 @@ import (
 	"connectrpc.com/connect"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/NavarchProject/navarch/pkg/auth"
+	"github.com/NavarchProject/navarch/pkg/clock"
 	"github.com/NavarchProject/navarch/pkg/controlplane/db"
+	"github.com/NavarchProject/navarch/pkg/health"
+	"github.com/NavarchProject/navarch/pkg/pool"
 	pb "github.com/NavarchProject/navarch/proto"
 )
 
@@ -130,6 +137,35 @@ func TestRegisterNode(t *testing.T) {
 			t.Errorf("Expected 1 node after duplicate registration, got %d", len(nodes))
 		}
 	})
+
+	t.Run("lookup_error", func(t *testing.T) {
+		database := db.NewInMemDB()
+		defer database.Close()
+		srv := NewServer(&getNodeErrorDB{DB: database}, DefaultConfig(), nil, nil)
+		ctx := context.Background()
+
+		// A failed lookup must not be taken for a new node, which would
+		// reset a qualifying node's burn-in state.
+		_, err := srv.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{
+			NodeId:   "node-1",
+			Provider: "gcp",
+		}))
+		if connect.CodeOf(err) != connect.CodeInternal {
+			t.Fatalf("Expected Internal error, got: %v", err)
+		}
+		if nodes, _ := database.ListNodes(ctx); len(nodes) != 0 {
+			t.Errorf("Expected no node to be registered, got %d", len(nodes))
+		}
+	})
+}
+
+// getNodeErrorDB fails every GetNode call.
+type getNodeErrorDB struct {
+	db.DB
+}
+
+func (d *getNodeErrorDB) GetNode(ctx context.Context, nodeID string) (*db.NodeRecord, error) {
+	return nil, fmt.Errorf("database unavailable")
 }
 
 // TestSendHeartbeat tests the heartbeat flow
@@ -479,6 +515,276 @@ func TestGetNodeCommands(t *testing.T) {
 	})
 }
 
+// TestAcknowledgeCommand tests command status reporting and redelivery
+func TestAcknowledgeCommand(t *testing.T) {
+	setup := func(t *testing.T) (*Server, db.DB, *clock.FakeClock, string) {
+		fakeClock := clock.NewFakeClock(time.Now())
+		database := db.NewInMemDBWithClock(fakeClock)
+		t.Cleanup(func() { database.Close() })
+
+		cfg := DefaultConfig()
+		cfg.Clock = fakeClock
+		cfg.CommandAckTimeout = time.Minute
+		cfg.CommandMaxDeliveries = 2
+		srv := NewServer(database, cfg, nil, nil)
+
+		ctx := context.Background()
+		srv.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{NodeId: "node-1"}))
+		resp, err := srv.IssueCommand(ctx, connect.NewRequest(&pb.IssueCommandRequest{
+			NodeId:      "node-1",
+			CommandType: pb.NodeCommandType_NODE_COMMAND_TYPE_RUN_DIAGNOSTIC,
+		}))
+		if err != nil {
+			t.Fatalf("IssueCommand failed: %v", err)
+		}
+		return srv, database, fakeClock, resp.Msg.CommandId
+	}
+
+	poll := func(t *testing.T, srv *Server) []*pb.NodeCommand {
+		resp, err := srv.GetNodeCommands(context.Background(), connect.NewRequest(&pb.GetNodeCommandsRequest{NodeId: "node-1"}))
+		if err != nil {
+			t.Fatalf("GetNodeCommands failed: %v", err)
+		}
+		return resp.Msg.Commands
+	}
+
+	ack := func(srv *Server, commandID string, status pb.CommandStatus, message, output string) error {
+		_, err := srv.AcknowledgeCommand(context.Background(), connect.NewRequest(&pb.AcknowledgeCommandRequest{
+			NodeId:    "node-1",
+			CommandId: commandID,
+			Status:    status,
+			Message:   message,
+			Output:    output,
+		}))
+		return err
+	}
+
+	t.Run("lifecycle", func(t *testing.T) {
+		srv, database, fakeClock, commandID := setup(t)
+		ctx := context.Background()
+
+		if got := poll(t, srv); len(got) != 1 {
+			t.Fatalf("Expected 1 command, got %d", len(got))
+		}
+		cmd, _ := database.GetCommand(ctx, commandID)
+		if cmd.Status != db.CommandStatusDelivered {
+			t.Errorf("Expected status %s after delivery, got %s", db.CommandStatusDelivered, cmd.Status)
+		}
+
+		if err := ack(srv, commandID, pb.CommandStatus_COMMAND_STATUS_RUNNING, "", ""); err != nil {
+			t.Fatalf("AcknowledgeCommand(RUNNING) failed: %v", err)
+		}
+
+		// A running command is not redelivered, however long it takes.
+		fakeClock.Advance(10 * time.Minute)
+		if got := poll(t, srv); len(got) != 0 {
+			t.Errorf("Expected running command not to be redelivered, got %d", len(got))
+		}
+
+		if err := ack(srv, commandID, pb.CommandStatus_COMMAND_STATUS_COMPLETED, "all tests passed", "GPU 0: PASS"); err != nil {
+			t.Fatalf("AcknowledgeCommand(COMPLETED) failed: %v", err)
+		}
+		cmd, _ = database.GetCommand(ctx, commandID)
+		if cmd.Status != db.CommandStatusCompleted || cmd.Message != "all tests passed" || cmd.Output != "GPU 0: PASS" {
+			t.Errorf("Result not recorded: status=%s message=%q output=%q", cmd.Status, cmd.Message, cmd.Output)
+		}
+
+		// Final statuses cannot be overwritten.
+		err := ack(srv, commandID, pb.CommandStatus_COMMAND_STATUS_FAILED, "", "")
+		if connect.CodeOf(err) != connect.CodeFailedPrecondition {
+			t.Errorf("Expected FailedPrecondition for finished command, got %v", err)
+		}
+	})
+
+	t.Run("redelivers_until_max_deliveries", func(t *testing.T) {
+		srv, database, fakeClock, commandID := setup(t)
+		ctx := context.Background()
+
+		poll(t, srv)
+
+		// Not yet overdue.
+		fakeClock.Advance(30 * time.Second)
+		if got := poll(t, srv); len(got) != 0 {
+			t.Errorf("Expected no redelivery before ack timeout, got %d", len(got))
+		}
+
+		fakeClock.Advance(time.Minute)
+		got := poll(t, srv)
+		if len(got) != 1 || got[0].CommandId != commandID {
+			t.Fatalf("Expected command to be redelivered, got %v", got)
+		}
+		if cmd, _ := database.GetCommand(ctx, commandID); cmd.DeliveryAttempts != 2 {
+			t.Errorf("Expected 2 delivery attempts, got %d", cmd.DeliveryAttempts)
+		}
+
+		fakeClock.Advance(2 * time.Minute)
+		if got := poll(t, srv); len(got) != 0 {
+			t.Errorf("Expected no delivery after max attempts, got %d", len(got))
+		}
+		cmd, _ := database.GetCommand(ctx, commandID)
+		if cmd.Status != db.CommandStatusFailed {
+			t.Errorf("Expected status %s after max deliveries, got %s", db.CommandStatusFailed, cmd.Status)
+		}
+		if cmd.Message == "" {
+			t.Error("Expected failure message for unacknowledged command")
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		srv, _, _, commandID := setup(t)
+		ctx := context.Background()
+
+		tests := []struct {
+			name string
+			req  *pb.AcknowledgeCommandRequest
+			code connect.Code
+		}{
+			{"missing_node_id", &pb.AcknowledgeCommandRequest{CommandId: commandID, Status: pb.CommandStatus_COMMAND_STATUS_RUNNING}, connect.CodeInvalidArgument},
+			{"missing_command_id", &pb.AcknowledgeCommandRequest{NodeId: "node-1", Status: pb.CommandStatus_COMMAND_STATUS_RUNNING}, connect.CodeInvalidArgument},
+			{"pending_status", &pb.AcknowledgeCommandRequest{NodeId: "node-1", CommandId: commandID, Status: pb.CommandStatus_COMMAND_STATUS_PENDING}, connect.CodeInvalidArgument},
+			{"unknown_command", &pb.AcknowledgeCommandRequest{NodeId: "node-1", CommandId: "missing", Status: pb.CommandStatus_COMMAND_STATUS_RUNNING}, connect.CodeNotFound},
+			{"wrong_node", &pb.AcknowledgeCommandRequest{NodeId: "node-2", CommandId: commandID, Status: pb.CommandStatus_COMMAND_STATUS_RUNNING}, connect.CodeNotFound},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := srv.AcknowledgeCommand(ctx, connect.NewRequest(tt.req))
+				if connect.CodeOf(err) != tt.code {
+					t.Errorf("Expected %v, got %v", tt.code, err)
+				}
+			})
+		}
+	})
+}
+
+// TestListCommands tests command auditing via ListCommands and GetCommand
+func TestListCommands(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	database := db.NewInMemDBWithClock(fakeClock)
+	defer database.Close()
+	cfg := DefaultConfig()
+	cfg.Clock = fakeClock
+	srv := NewServer(database, cfg, nil, nil)
+	ctx := context.Background()
+	start := fakeClock.Now()
+
+	srv.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{NodeId: "node-1"}))
+	srv.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{NodeId: "node-2"}))
+
+	issue := func(ctx context.Context, nodeID string, cmdType pb.NodeCommandType) string {
+		resp, err := srv.IssueCommand(ctx, connect.NewRequest(&pb.IssueCommandRequest{
+			NodeId:      nodeID,
+			CommandType: cmdType,
+		}))
+		if err != nil {
+			t.Fatalf("IssueCommand failed: %v", err)
+		}
+		fakeClock.Advance(time.Minute)
+		return resp.Msg.CommandId
+	}
+
+	oncall := auth.ContextWithIdentity(ctx, &auth.Identity{Subject: "user:oncall@example.com"})
+	cordonID := issue(oncall, "node-1", pb.NodeCommandType_NODE_COMMAND_TYPE_CORDON)
+	diagID := issue(ctx, "node-1", pb.NodeCommandType_NODE_COMMAND_TYPE_RUN_DIAGNOSTIC)
+	terminateID := issue(ctx, "node-2", pb.NodeCommandType_NODE_COMMAND_TYPE_TERMINATE)
+
+	list := func(t *testing.T, req *pb.ListCommandsRequest) []string {
+		resp, err := srv.ListCommands(ctx, connect.NewRequest(req))
+		if err != nil {
+			t.Fatalf("ListCommands failed: %v", err)
+		}
+		var ids []string
+		for _, cmd := range resp.Msg.Commands {
+			ids = append(ids, cmd.CommandId)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name string
+		req  *pb.ListCommandsRequest
+		want []string
+	}{
+		{"all", &pb.ListCommandsRequest{}, []string{cordonID, diagID, terminateID}},
+		{"by_node", &pb.ListCommandsRequest{NodeId: "node-1"}, []string{cordonID, diagID}},
+		{"by_type", &pb.ListCommandsRequest{CommandType: pb.NodeCommandType_NODE_COMMAND_TYPE_TERMINATE}, []string{terminateID}},
+		{"by_status", &pb.ListCommandsRequest{Status: pb.CommandStatus_COMMAND_STATUS_COMPLETED}, []string{cordonID}},
+		{"issued_after", &pb.ListCommandsRequest{IssuedAfter: timestamppb.New(start.Add(time.Minute))}, []string{diagID, terminateID}},
+		{"issued_before", &pb.ListCommandsRequest{IssuedBefore: timestamppb.New(start.Add(time.Minute))}, []string{cordonID}},
+		{"by_issuer", &pb.ListCommandsRequest{IssuedBy: "user:oncall@example.com"}, []string{cordonID}},
+		{"limit", &pb.ListCommandsRequest{Limit: 2}, []string{diagID, terminateID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := list(t, tt.req); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("negative_limit", func(t *testing.T) {
+		_, err := srv.ListCommands(ctx, connect.NewRequest(&pb.ListCommandsRequest{Limit: -1}))
+		if connect.CodeOf(err) != connect.CodeInvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+	})
+
+	t.Run("get_command", func(t *testing.T) {
+		resp, err := srv.GetCommand(ctx, connect.NewRequest(&pb.GetCommandRequest{CommandId: cordonID}))
+		if err != nil {
+			t.Fatalf("GetCommand failed: %v", err)
+		}
+		cmd := resp.Msg.Command
+		if cmd.NodeId != "node-1" || cmd.Type != pb.NodeCommandType_NODE_COMMAND_TYPE_CORDON {
+			t.Errorf("Unexpected command: %v", cmd)
+		}
+		if cmd.IssuedBy != "user:oncall@example.com" {
+			t.Errorf("Expected issued_by user:oncall@example.com, got %q", cmd.IssuedBy)
+		}
+		if cmd.Status != pb.CommandStatus_COMMAND_STATUS_COMPLETED {
+			t.Errorf("Expected COMPLETED status, got %v", cmd.Status)
+		}
+	})
+
+	t.Run("get_command_reports_result", func(t *testing.T) {
+		srv.GetNodeCommands(ctx, connect.NewRequest(&pb.GetNodeCommandsRequest{NodeId: "node-1"}))
+		srv.AcknowledgeCommand(ctx, connect.NewRequest(&pb.AcknowledgeCommandRequest{
+			NodeId:    "node-1",
+			CommandId: diagID,
+			Status:    pb.CommandStatus_COMMAND_STATUS_FAILED,
+			Message:   "GPU 3 failed memory test",
+			Output:    "level 2: FAIL",
+		}))
+
+		resp, err := srv.GetCommand(ctx, connect.NewRequest(&pb.GetCommandRequest{CommandId: diagID}))
+		if err != nil {
+			t.Fatalf("GetCommand failed: %v", err)
+		}
+		cmd := resp.Msg.Command
+		if cmd.Status != pb.CommandStatus_COMMAND_STATUS_FAILED || cmd.Message != "GPU 3 failed memory test" || cmd.Output != "level 2: FAIL" {
+			t.Errorf("Result not reported: %v", cmd)
+		}
+		if cmd.DeliveryAttempts != 1 || cmd.DeliveredAt == nil || cmd.UpdatedAt == nil {
+			t.Errorf("Delivery state not reported: %v", cmd)
+		}
+	})
+
+	t.Run("get_unknown_command", func(t *testing.T) {
+		_, err := srv.GetCommand(ctx, connect.NewRequest(&pb.GetCommandRequest{CommandId: "missing"}))
+		if connect.CodeOf(err) != connect.CodeNotFound {
+			t.Errorf("Expected NotFound, got %v", err)
+		}
+	})
+
+	t.Run("retention", func(t *testing.T) {
+		fakeClock.Advance(cfg.CommandRetention)
+		uncordonID := issue(ctx, "node-1", pb.NodeCommandType_NODE_COMMAND_TYPE_UNCORDON)
+		// Finished commands past retention are pruned; the pending one stays.
+		if got, want := list(t, &pb.ListCommandsRequest{}), []string{terminateID, uncordonID}; fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+}
+
 // TestNodeLifecycle tests the complete node lifecycle
 func TestNodeLifecycle(t *testing.T) {
 	database := db.NewInMemDB()
@@ -1864,3 +2170,604 @@ func TestCELPolicyEvaluation(t *testing.T) {
 		}
 	})
 }
+
+func TestPoolRPCs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("no_pool_manager", func(t *testing.T) {
+		database := db.NewInMemDB()
+		defer database.Close()
+		srv := NewServer(database, DefaultConfig(), nil, nil)
+
+		resp, err := srv.ListPools(ctx, connect.NewRequest(&pb.ListPoolsRequest{}))
+		if err != nil {
+			t.Fatalf("ListPools failed: %v", err)
+		}
+		if len(resp.Msg.Pools) != 0 {
+			t.Errorf("Expected 0 pools, got %d", len(resp.Msg.Pools))
+		}
+
+		_, err = srv.GetPool(ctx, connect.NewRequest(&pb.GetPoolRequest{Name: "gpu"}))
+		if connect.CodeOf(err) != connect.CodeNotFound {
+			t.Errorf("Expected NotFound, got %v", err)
+		}
+	})
+
+	database := db.NewInMemDB()
+	defer database.Close()
+	srv := NewServer(database, DefaultConfig(), nil, nil)
+
+	pm := NewPoolManager(PoolManagerConfig{}, nil, nil, nil)
+	p, _ := pool.NewSimple(pool.Config{
+		Name:         "gpu",
+		InstanceType: "h100-8x",
+		MinNodes:     1,
+		MaxNodes:     4,
+		Labels:       map[string]string{"team": "ml"},
+	}, &mockProvider{}, "mock")
+	pm.AddPool(p, pool.NewQueueBasedAutoscaler(4))
+	srv.SetPoolManager(pm)
+
+	t.Run("list", func(t *testing.T) {
+		resp, err := srv.ListPools(ctx, connect.NewRequest(&pb.ListPoolsRequest{}))
+		if err != nil {
+			t.Fatalf("ListPools failed: %v", err)
+		}
+		if len(resp.Msg.Pools) != 1 {
+			t.Fatalf("Expected 1 pool, got %d", len(resp.Msg.Pools))
+		}
+		got := resp.Msg.Pools[0]
+		if got.Name != "gpu" || got.InstanceType != "h100-8x" {
+			t.Errorf("Unexpected pool: %s %s", got.Name, got.InstanceType)
+		}
+		if got.MinNodes != 1 || got.MaxNodes != 4 {
+			t.Errorf("Expected limits [1, 4], got [%d, %d]", got.MinNodes, got.MaxNodes)
+		}
+		if got.AutoscalerType != "queue" {
+			t.Errorf("Expected autoscaler type queue, got %q", got.AutoscalerType)
+		}
+		if got.Labels["team"] != "ml" {
+			t.Errorf("Expected label team=ml, got %v", got.Labels)
+		}
+		if got.LastRecommendation != nil || got.LastEvaluatedAt != nil {
+			t.Error("Expected no recommendation before the autoscaler runs")
+		}
+	})
+
+	t.Run("get_validation", func(t *testing.T) {
+		_, err := srv.GetPool(ctx, connect.NewRequest(&pb.GetPoolRequest{}))
+		if connect.CodeOf(err) != connect.CodeInvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+		_, err = srv.GetPool(ctx, connect.NewRequest(&pb.GetPoolRequest{Name: "nonexistent"}))
+		if connect.CodeOf(err) != connect.CodeNotFound {
+			t.Errorf("Expected NotFound, got %v", err)
+		}
+	})
+
+	t.Run("scale", func(t *testing.T) {
+		resp, err := srv.ScalePool(ctx, connect.NewRequest(&pb.ScalePoolRequest{Name: "gpu", TargetNodes: 3}))
+		if err != nil {
+			t.Fatalf("ScalePool failed: %v", err)
+		}
+		if resp.Msg.Pool.Status.TotalNodes != 3 {
+			t.Errorf("Expected 3 nodes after scaling, got %d", resp.Msg.Pool.Status.TotalNodes)
+		}
+
+		got, err := srv.GetPool(ctx, connect.NewRequest(&pb.GetPoolRequest{Name: "gpu"}))
+		if err != nil {
+			t.Fatalf("GetPool failed: %v", err)
+		}
+		if got.Msg.Pool.Status.TotalNodes != 3 {
+			t.Errorf("Expected 3 nodes, got %d", got.Msg.Pool.Status.TotalNodes)
+		}
+	})
+
+	t.Run("scale_outside_limits", func(t *testing.T) {
+		for _, target := range []int32{0, 5} {
+			_, err := srv.ScalePool(ctx, connect.NewRequest(&pb.ScalePoolRequest{Name: "gpu", TargetNodes: target}))
+			if connect.CodeOf(err) != connect.CodeInvalidArgument {
+				t.Errorf("target %d: expected InvalidArgument, got %v", target, err)
+			}
+		}
+		_, err := srv.ScalePool(ctx, connect.NewRequest(&pb.ScalePoolRequest{Name: "nonexistent", TargetNodes: 1}))
+		if connect.CodeOf(err) != connect.CodeNotFound {
+			t.Errorf("Expected NotFound, got %v", err)
+		}
+	})
+}
+
+func TestPoolLifecycleRPCs(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewInMemDB()
+	defer database.Close()
+	srv := NewServer(database, DefaultConfig(), nil, nil)
+
+	t.Run("no_pool_manager", func(t *testing.T) {
+		_, err := srv.CreatePool(ctx, connect.NewRequest(&pb.CreatePoolRequest{Name: "gpu", Spec: "provider: mock"}))
+		if connect.CodeOf(err) != connect.CodeFailedPrecondition {
+			t.Errorf("Expected FailedPrecondition, got %v", err)
+		}
+	})
+
+	pm := newLifecyclePoolManager(t, clock.NewFakeClock(time.Now()), &mockProvider{})
+	srv.SetPoolManager(pm)
+
+	spec := `
+provider: mock
+instance_type: h100-8x
+min_nodes: 0
+max_nodes: 4
+labels:
+  team: ml
+autoscaling:
+  type: reactive
+`
+
+	t.Run("create", func(t *testing.T) {
+		resp, err := srv.CreatePool(ctx, connect.NewRequest(&pb.CreatePoolRequest{Name: "gpu", Spec: spec}))
+		if err != nil {
+			t.Fatalf("CreatePool failed: %v", err)
+		}
+		got := resp.Msg.Pool
+		if got.Name != "gpu" || got.MaxNodes != 4 || got.AutoscalerType != "reactive" {
+			t.Errorf("Unexpected pool: %v", got)
+		}
+
+		_, err = srv.CreatePool(ctx, connect.NewRequest(&pb.CreatePoolRequest{Name: "gpu", Spec: spec}))
+		if connect.CodeOf(err) != connect.CodeAlreadyExists {
+			t.Errorf("Expected AlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("create_validation", func(t *testing.T) {
+		for _, req := range []*pb.CreatePoolRequest{
+			{Spec: spec},
+			{Name: "other"},
+			{Name: "other", Spec: "max_nodes: [1"},
+			{Name: "other", Spec: "provider: unknown\ninstance_type: h100-8x\nmax_nodes: 1"},
+		} {
+			_, err := srv.CreatePool(ctx, connect.NewRequest(req))
+			if connect.CodeOf(err) != connect.CodeInvalidArgument {
+				t.Errorf("%+v: expected InvalidArgument, got %v", req, err)
+			}
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		updated := strings.Replace(spec, "max_nodes: 4", "max_nodes: 6", 1)
+		resp, err := srv.UpdatePool(ctx, connect.NewRequest(&pb.UpdatePoolRequest{Name: "gpu", Spec: updated}))
+		if err != nil {
+			t.Fatalf("UpdatePool failed: %v", err)
+		}
+		if resp.Msg.Pool.MaxNodes != 6 {
+			t.Errorf("Expected max nodes 6, got %d", resp.Msg.Pool.MaxNodes)
+		}
+
+		changed := strings.Replace(spec, "h100-8x", "a100-8x", 1)
+		_, err = srv.UpdatePool(ctx, connect.NewRequest(&pb.UpdatePoolRequest{Name: "gpu", Spec: changed}))
+		if connect.CodeOf(err) != connect.CodeInvalidArgument {
+			t.Errorf("Expected InvalidArgument for instance type change, got %v", err)
+		}
+
+		_, err = srv.UpdatePool(ctx, connect.NewRequest(&pb.UpdatePoolRequest{Name: "nonexistent", Spec: spec}))
+		if connect.CodeOf(err) != connect.CodeNotFound {
+			t.Errorf("Expected NotFound, got %v", err)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		_, err := srv.DeletePool(ctx, connect.NewRequest(&pb.DeletePoolRequest{Name: "nonexistent"}))
+		if connect.CodeOf(err) != connect.CodeNotFound {
+			t.Errorf("Expected NotFound, got %v", err)
+		}
+
+		if _, err := srv.DeletePool(ctx, connect.NewRequest(&pb.DeletePoolRequest{Name: "gpu"})); err != nil {
+			t.Fatalf("DeletePool failed: %v", err)
+		}
+
+		// With no nodes to drain the pool goes away almost immediately.
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if _, ok := pm.GetPool("gpu"); !ok {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		_, err = srv.GetPool(ctx, connect.NewRequest(&pb.GetPoolRequest{Name: "gpu"}))
+		if connect.CodeOf(err) != connect.CodeNotFound {
+			t.Errorf("Expected NotFound after delete, got %v", err)
+		}
+	})
+}
+
+func TestPoolLifecycleRPCs_HealthPolicy(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewInMemDB()
+	defer database.Close()
+
+	dir := t.TempDir()
+	writePolicy := func(path, condition string) {
+		t.Helper()
+		policy := "rules:\n  - name: default\n    condition: " + condition + "\n    result: healthy\n"
+		if err := os.WriteFile(path, []byte(policy), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writePolicy(filepath.Join(dir, "good.yaml"), `"true"`)
+	writePolicy(filepath.Join(dir, "bad-cel.yaml"), `"event.xid_code >"`)
+	outside := filepath.Join(t.TempDir(), "outside.yaml")
+	writePolicy(outside, `"true"`)
+
+	cfg := DefaultConfig()
+	cfg.HealthPolicyDir = dir
+	srv := NewServer(database, cfg, nil, nil)
+	pm := newLifecyclePoolManager(t, clock.NewFakeClock(time.Now()), &mockProvider{})
+	srv.SetPoolManager(pm)
+
+	spec := func(maxNodes int, policy string) string {
+		s := fmt.Sprintf("provider: mock\ninstance_type: h100-8x\nmax_nodes: %d\n", maxNodes)
+		if policy != "" {
+			s += "health_policy: " + policy + "\n"
+		}
+		return s
+	}
+
+	t.Run("rejected_before_create", func(t *testing.T) {
+		for _, policy := range []string{"bad-cel.yaml", "missing.yaml", "../" + filepath.Base(filepath.Dir(outside)) + "/outside.yaml", outside} {
+			_, err := srv.CreatePool(ctx, connect.NewRequest(&pb.CreatePoolRequest{Name: "gpu", Spec: spec(4, policy)}))
+			if connect.CodeOf(err) != connect.CodeInvalidArgument {
+				t.Errorf("%s: expected InvalidArgument, got %v", policy, err)
+			}
+			if _, ok := pm.GetPool("gpu"); ok {
+				t.Fatalf("%s: pool was created despite the rejected policy", policy)
+			}
+		}
+	})
+
+	t.Run("create_and_update", func(t *testing.T) {
+		if _, err := srv.CreatePool(ctx, connect.NewRequest(&pb.CreatePoolRequest{Name: "gpu", Spec: spec(4, "good.yaml")})); err != nil {
+			t.Fatalf("CreatePool failed: %v", err)
+		}
+		resp, err := srv.GetHealthPolicy(ctx, connect.NewRequest(&pb.GetHealthPolicyRequest{Pool: "gpu"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Msg.Inherited {
+			t.Error("Expected the pool to have its own policy")
+		}
+
+		_, err = srv.UpdatePool(ctx, connect.NewRequest(&pb.UpdatePoolRequest{Name: "gpu", Spec: spec(6, "bad-cel.yaml")}))
+		if connect.CodeOf(err) != connect.CodeInvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+		info, err := pm.DescribePool(ctx, "gpu")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Config.MaxNodes != 4 {
+			t.Errorf("Expected the rejected update to leave max nodes at 4, got %d", info.Config.MaxNodes)
+		}
+	})
+
+	t.Run("no_policy_dir", func(t *testing.T) {
+		srv := NewServer(database, DefaultConfig(), nil, nil)
+		srv.SetPoolManager(pm)
+		_, err := srv.CreatePool(ctx, connect.NewRequest(&pb.CreatePoolRequest{Name: "other", Spec: spec(4, outside)}))
+		if connect.CodeOf(err) != connect.CodeInvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+		if _, ok := pm.GetPool("other"); ok {
+			t.Error("Expected no pool to be created")
+		}
+	})
+}
+
+func TestHealthPolicyRPCs(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewInMemDB()
+	defer database.Close()
+	srv := NewServer(database, DefaultConfig(), nil, nil)
+
+	xidEvent := func() *pb.ReportHealthRequest {
+		return &pb.ReportHealthRequest{
+			NodeId: "node-1",
+			Events: []*pb.HealthEvent{
+				{
+					Timestamp: timestamppb.Now(),
+					GpuUuid:   "GPU-12345",
+					System:    pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_DRIVER,
+					EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_XID,
+					Metrics:   map[string]string{"xid_code": "79"},
+				},
+			},
+		}
+	}
+	srv.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{NodeId: "node-1"}))
+
+	lenient := `
+rules:
+  - name: ignore-everything
+    condition: "true"
+    result: healthy
+`
+
+	t.Run("get_default", func(t *testing.T) {
+		resp, err := srv.GetHealthPolicy(ctx, connect.NewRequest(&pb.GetHealthPolicyRequest{}))
+		if err != nil {
+			t.Fatalf("GetHealthPolicy failed: %v", err)
+		}
+		if len(resp.Msg.Policy.Rules) != len(health.DefaultPolicy().Rules) {
+			t.Errorf("Expected %d default rules, got %d", len(health.DefaultPolicy().Rules), len(resp.Msg.Policy.Rules))
+		}
+		if _, err := health.ParsePolicy([]byte(resp.Msg.Policy.Yaml)); err != nil {
+			t.Errorf("Returned YAML does not parse: %v", err)
+		}
+		if resp.Msg.Previous != nil {
+			t.Error("Expected no rollback target before any change")
+		}
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		resp, err := srv.ValidateHealthPolicy(ctx, connect.NewRequest(&pb.ValidateHealthPolicyRequest{Policy: lenient}))
+		if err != nil {
+			t.Fatalf("ValidateHealthPolicy failed: %v", err)
+		}
+		if !resp.Msg.Valid || resp.Msg.RuleCount != 1 {
+			t.Errorf("Expected valid policy with 1 rule, got %v", resp.Msg)
+		}
+
+		bad := strings.Replace(lenient, `"true"`, `event.no_such_field ==`, 1)
+		resp, err = srv.ValidateHealthPolicy(ctx, connect.NewRequest(&pb.ValidateHealthPolicyRequest{Policy: bad}))
+		if err != nil {
+			t.Fatalf("ValidateHealthPolicy failed: %v", err)
+		}
+		if resp.Msg.Valid || resp.Msg.Error == "" {
+			t.Errorf("Expected compile error, got %v", resp.Msg)
+		}
+	})
+
+	t.Run("set_rejects_invalid", func(t *testing.T) {
+		bad := strings.Replace(lenient, `"true"`, `"1 + 1"`, 1)
+		_, err := srv.SetHealthPolicy(ctx, connect.NewRequest(&pb.SetHealthPolicyRequest{Policy: bad}))
+		if connect.CodeOf(err) != connect.CodeInvalidArgument {
+			t.Errorf("Expected InvalidArgument, got %v", err)
+		}
+		_, err = srv.SetHealthPolicy(ctx, connect.NewRequest(&pb.SetHealthPolicyRequest{Rollback: true}))
+		if connect.CodeOf(err) != connect.CodeFailedPrecondition {
+			t.Errorf("Expected FailedPrecondition for rollback without history, got %v", err)
+		}
+	})
+
+	t.Run("set_and_rollback", func(t *testing.T) {
+		resp, err := srv.SetHealthPolicy(ctx, connect.NewRequest(&pb.SetHealthPolicyRequest{Policy: lenient}))
+		if err != nil {
+			t.Fatalf("SetHealthPolicy failed: %v", err)
+		}
+		if len(resp.Msg.Policy.Rules) != 1 || resp.Msg.Previous == nil {
+			t.Errorf("Unexpected response: %v", resp.Msg)
+		}
+
+		report, err := srv.ReportHealth(ctx, connect.NewRequest(xidEvent()))
+		if err != nil {
+			t.Fatalf("ReportHealth failed: %v", err)
+		}
+		if report.Msg.NodeStatus == pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+			t.Error("New policy was not applied to health reports")
+		}
+
+		if _, err := srv.SetHealthPolicy(ctx, connect.NewRequest(&pb.SetHealthPolicyRequest{Rollback: true})); err != nil {
+			t.Fatalf("rollback failed: %v", err)
+		}
+		report, err = srv.ReportHealth(ctx, connect.NewRequest(xidEvent()))
+		if err != nil {
+			t.Fatalf("ReportHealth failed: %v", err)
+		}
+		if report.Msg.NodeStatus != pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+			t.Errorf("Expected default policy after rollback, got node status %v", report.Msg.NodeStatus)
+		}
+	})
+}
+
+func TestPoolHealthPolicies(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewInMemDB()
+	defer database.Close()
+
+	lenientYAML := `
+rules:
+  - name: ignore-everything
+    condition: "true"
+    result: healthy
+`
+	lenient, err := health.ParsePolicy([]byte(lenientYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	strict, err := health.DefaultPolicy().Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.PoolHealthPolicies = map[string]*health.Policy{"inference": lenient}
+	srv := NewServer(database, cfg, nil, nil)
+
+	pm := NewPoolManager(PoolManagerConfig{}, nil, nil, nil)
+	for _, name := range []string{"inference", "training"} {
+		p, _ := pool.NewSimple(pool.Config{Name: name, InstanceType: "h100-8x", MaxNodes: 4}, &mockProvider{}, "mock")
+		pm.AddPool(p, nil)
+	}
+	srv.SetPoolManager(pm)
+
+	for id, pool := range map[string]string{"node-1": "inference", "node-2": "training", "node-3": ""} {
+		req := &pb.RegisterNodeRequest{NodeId: id}
+		if pool != "" {
+			req.Metadata = &pb.NodeMetadata{Labels: map[string]string{"pool": pool}}
+		}
+		srv.RegisterNode(ctx, connect.NewRequest(req))
+	}
+
+	report := func(nodeID string) pb.NodeStatus {
+		t.Helper()
+		resp, err := srv.ReportHealth(ctx, connect.NewRequest(&pb.ReportHealthRequest{
+			NodeId: nodeID,
+			Events: []*pb.HealthEvent{{
+				Timestamp: timestamppb.Now(),
+				EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_XID,
+				Metrics:   map[string]string{"xid_code": "79"},
+			}},
+		}))
+		if err != nil {
+			t.Fatalf("ReportHealth failed: %v", err)
+		}
+		return resp.Msg.NodeStatus
+	}
+
+	t.Run("evaluator_by_pool_label", func(t *testing.T) {
+		if got := report("node-1"); got == pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+			t.Error("node-1 is in pool inference and should use its lenient policy")
+		}
+		if got := report("node-2"); got != pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+			t.Errorf("node-2 status = %v, want UNHEALTHY from the server policy", got)
+		}
+		if got := report("node-3"); got != pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+			t.Errorf("node-3 status = %v, want UNHEALTHY from the server policy", got)
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		resp, err := srv.GetHealthPolicy(ctx, connect.NewRequest(&pb.GetHealthPolicyRequest{Pool: "inference"}))
+		if err != nil {
+			t.Fatalf("GetHealthPolicy failed: %v", err)
+		}
+		if resp.Msg.Inherited || len(resp.Msg.Policy.Rules) != 1 {
+			t.Errorf("inference policy = %v, want its own single-rule policy", resp.Msg)
+		}
+
+		resp, err = srv.GetHealthPolicy(ctx, connect.NewRequest(&pb.GetHealthPolicyRequest{Pool: "training"}))
+		if err != nil {
+			t.Fatalf("GetHealthPolicy failed: %v", err)
+		}
+		if !resp.Msg.Inherited || len(resp.Msg.Policy.Rules) != len(health.DefaultPolicy().Rules) {
+			t.Errorf("training policy = %v, want the inherited server policy", resp.Msg)
+		}
+	})
+
+	t.Run("unknown_pool", func(t *testing.T) {
+		_, err := srv.GetHealthPolicy(ctx, connect.NewRequest(&pb.GetHealthPolicyRequest{Pool: "trainig"}))
+		if connect.CodeOf(err) != connect.CodeNotFound {
+			t.Errorf("GetHealthPolicy: got %v, want NotFound", err)
+		}
+		_, err = srv.SetHealthPolicy(ctx, connect.NewRequest(&pb.SetHealthPolicyRequest{Pool: "trainig", Policy: lenientYAML}))
+		if connect.CodeOf(err) != connect.CodeNotFound {
+			t.Errorf("SetHealthPolicy: got %v, want NotFound", err)
+		}
+		if srv.poolEvaluator("trainig") != nil {
+			t.Error("SetHealthPolicy created an evaluator for an unknown pool")
+		}
+	})
+
+	t.Run("set_and_rollback", func(t *testing.T) {
+		_, err := srv.SetHealthPolicy(ctx, connect.NewRequest(&pb.SetHealthPolicyRequest{Pool: "training", Rollback: true}))
+		if connect.CodeOf(err) != connect.CodeFailedPrecondition {
+			t.Errorf("rollback of an inherited policy: got %v, want FailedPrecondition", err)
+		}
+
+		resp, err := srv.SetHealthPolicy(ctx, connect.NewRequest(&pb.SetHealthPolicyRequest{
+			Pool:   "training",
+			Policy: lenientYAML,
+		}))
+		if err != nil {
+			t.Fatalf("SetHealthPolicy failed: %v", err)
+		}
+		if len(resp.Msg.Policy.Rules) != 1 {
+			t.Errorf("training policy has %d rules, want 1", len(resp.Msg.Policy.Rules))
+		}
+		// node-2 is already unhealthy, so check with a new node in the pool.
+		srv.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{
+			NodeId:   "node-4",
+			Metadata: &pb.NodeMetadata{Labels: map[string]string{"pool": "training"}},
+		}))
+		if got := report("node-4"); got == pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+			t.Error("node-4 should use the policy just set for pool training")
+		}
+
+		if _, err := srv.SetHealthPolicy(ctx, connect.NewRequest(&pb.SetHealthPolicyRequest{
+			Pool:   "inference",
+			Policy: string(strict),
+		})); err != nil {
+			t.Fatalf("SetHealthPolicy failed: %v", err)
+		}
+		if _, err := srv.SetHealthPolicy(ctx, connect.NewRequest(&pb.SetHealthPolicyRequest{Pool: "inference", Rollback: true})); err != nil {
+			t.Fatalf("rollback failed: %v", err)
+		}
+		if got := report("node-1"); got == pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+			t.Error("rollback should restore the lenient inference policy")
+		}
+	})
+
+	t.Run("removed_falls_back", func(t *testing.T) {
+		if err := srv.SetPoolHealthPolicy("inference", nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := report("node-1"); got != pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+			t.Errorf("node-1 status = %v, want UNHEALTHY from the server policy", got)
+		}
+	})
+}
+
+func TestHealthHistoryAcrossReports(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewInMemDB()
+	defer database.Close()
+
+	policy, err := health.ParsePolicy([]byte(`
+rules:
+  - name: repeated-xid-13
+    condition: |
+      event.event_type == "xid" &&
+      gpu_history.within(duration("10m")).filter(h, h.metrics.xid_code == 13).size() >= 3
+    result: unhealthy
+  - name: default
+    condition: "true"
+    result: healthy
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := DefaultConfig()
+	cfg.HealthPolicy = policy
+	srv := NewServer(database, cfg, nil, nil)
+	srv.RegisterNode(ctx, connect.NewRequest(&pb.RegisterNodeRequest{NodeId: "node-1"}))
+
+	start := time.Now()
+	report := func(offset time.Duration, gpuIndex int32) pb.NodeStatus {
+		t.Helper()
+		resp, err := srv.ReportHealth(ctx, connect.NewRequest(&pb.ReportHealthRequest{
+			NodeId: "node-1",
+			Events: []*pb.HealthEvent{{
+				Timestamp: timestamppb.New(start.Add(offset)),
+				GpuIndex:  gpuIndex,
+				EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_XID,
+				Metrics:   map[string]string{"xid_code": "13"},
+			}},
+		}))
+		if err != nil {
+			t.Fatalf("ReportHealth failed: %v", err)
+		}
+		return resp.Msg.NodeStatus
+	}
+
+	if status := report(0, 0); status == pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+		t.Fatal("first XID 13 should not make the node unhealthy")
+	}
+	if status := report(time.Minute, 1); status == pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+		t.Fatal("XID 13 on another GPU should not count")
+	}
+	if status := report(2*time.Minute, 0); status == pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+		t.Fatal("second XID 13 on GPU 0 should not make the node unhealthy")
+	}
+	if status := report(3*time.Minute, 0); status != pb.NodeStatus_NODE_STATUS_UNHEALTHY {
+		t.Errorf("third XID 13 on GPU 0 within 10m: node status = %v, want UNHEALTHY", status)
+	}
+}
@@ -0,0 +1,204 @@
+package gpu
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+// Counters are a GPU's error counters and clock throttle reasons at one
+// sample. Managers that sample counters periodically turn the changes
+// between two samples into health events with CounterEvents.
+type Counters struct {
+	// Temperature is the GPU temperature in Celsius, reported with thermal
+	// throttling events.
+	Temperature int
+
+	// ECC error counts since the driver was loaded (volatile) and over the
+	// GPU's lifetime (aggregate).
+	ECCSBEVolatile  uint64
+	ECCDBEVolatile  uint64
+	ECCSBEAggregate uint64
+	ECCDBEAggregate uint64
+
+	// Memory pages retired for single-bit and double-bit ECC errors, and
+	// whether a retirement is waiting for a GPU reset. GPUs with row
+	// remapping retire no pages.
+	RetiredPagesSBE   uint64
+	RetiredPagesDBE   uint64
+	RetirementPending bool
+
+	// Memory rows remapped for correctable and uncorrectable errors, and
+	// whether a remap is waiting for a GPU reset or failed.
+	RemappedRowsCorrectable   uint64
+	RemappedRowsUncorrectable uint64
+	RemapPending              bool
+	RemapFailed               bool
+
+	// PCIeReplays counts PCIe transactions the GPU had to replay.
+	PCIeReplays uint64
+
+	// NVLink error counts summed over all links.
+	NVLinkCRCErrors      uint64
+	NVLinkReplayErrors   uint64
+	NVLinkRecoveryErrors uint64
+
+	// ThrottleReasons is the bitmask of reasons the GPU clocks are held down,
+	// as reported by NVML and DCGM.
+	ThrottleReasons uint64
+}
+
+// Clock throttle reason bits, as defined by NVML (nvmlClocksThrottleReason*)
+// and DCGM (DCGM_CLOCKS_THROTTLE_REASON_*).
+const (
+	ThrottleReasonGPUIdle          uint64 = 0x1
+	ThrottleReasonAppClocksSetting uint64 = 0x2
+	ThrottleReasonSWPowerCap       uint64 = 0x4
+	ThrottleReasonHWSlowdown       uint64 = 0x8
+	ThrottleReasonSyncBoost        uint64 = 0x10
+	ThrottleReasonSWThermal        uint64 = 0x20
+	ThrottleReasonHWThermal        uint64 = 0x40
+	ThrottleReasonHWPowerBrake     uint64 = 0x80
+	ThrottleReasonDisplayClocks    uint64 = 0x100
+)
+
+// throttleFaults are the throttle reasons that point at a thermal or power
+// problem, and the system each belongs to. Idle, power capping, and the
+// other reasons are normal operation and never produce events.
+var throttleFaults = []struct {
+	reason uint64
+	name   string
+	system pb.HealthWatchSystem
+}{
+	{ThrottleReasonHWSlowdown, "hw_slowdown", pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_THERMAL},
+	{ThrottleReasonSWThermal, "sw_thermal", pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_THERMAL},
+	{ThrottleReasonHWThermal, "hw_thermal", pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_THERMAL},
+	{ThrottleReasonHWPowerBrake, "hw_power_brake", pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_POWER},
+}
+
+// CounterEvents returns the health events for what changed between two
+// samples of a GPU's counters:
+//
+//   - a rise in double-bit ECC errors produces an ecc_dbe event,
+//   - a rise in single-bit ECC errors produces an ecc_sbe event,
+//   - newly retired pages or remapped rows, a pending retirement or remap,
+//     or a failed remap produce a memory event,
+//   - PCIe replays produce a pcie event,
+//   - NVLink CRC, replay, or recovery errors produce an nvlink event, and
+//   - newly set thermal or power brake throttle reasons produce a thermal or
+//     power event.
+//
+// Counters that went down, as volatile counters do when the driver is
+// reloaded or the GPU is reset, produce no events.
+func CounterEvents(timestamp time.Time, gpuIndex int, gpuUUID string, prev, cur Counters) []HealthEvent {
+	var events []HealthEvent
+	event := func(system pb.HealthWatchSystem, eventType pb.HealthEventType, metrics map[string]any, message string) {
+		events = append(events, HealthEvent{
+			Timestamp: timestamp,
+			GPUIndex:  gpuIndex,
+			GPUUUID:   gpuUUID,
+			System:    system,
+			EventType: eventType,
+			Metrics:   metrics,
+			Message:   message,
+		})
+	}
+
+	if d := max(delta(prev.ECCDBEVolatile, cur.ECCDBEVolatile), delta(prev.ECCDBEAggregate, cur.ECCDBEAggregate)); d > 0 {
+		event(pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM, pb.HealthEventType_HEALTH_EVENT_TYPE_ECC_DBE, map[string]any{
+			"ecc_dbe_count":     cur.ECCDBEVolatile,
+			"ecc_dbe_aggregate": cur.ECCDBEAggregate,
+			"ecc_dbe_delta":     d,
+		}, fmt.Sprintf("%d new double-bit ECC errors", d))
+	}
+	if d := max(delta(prev.ECCSBEVolatile, cur.ECCSBEVolatile), delta(prev.ECCSBEAggregate, cur.ECCSBEAggregate)); d > 0 {
+		event(pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM, pb.HealthEventType_HEALTH_EVENT_TYPE_ECC_SBE, map[string]any{
+			"ecc_sbe_count":     cur.ECCSBEVolatile,
+			"ecc_sbe_aggregate": cur.ECCSBEAggregate,
+			"ecc_sbe_delta":     d,
+		}, fmt.Sprintf("%d new single-bit ECC errors", d))
+	}
+	if d := delta(prev.RetiredPagesSBE, cur.RetiredPagesSBE) + delta(prev.RetiredPagesDBE, cur.RetiredPagesDBE); d > 0 ||
+		cur.RetirementPending && !prev.RetirementPending {
+		message := fmt.Sprintf("%d new retired pages", d)
+		if cur.RetirementPending {
+			message += ", retirement pending GPU reset"
+		}
+		event(pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM, pb.HealthEventType_HEALTH_EVENT_TYPE_MEMORY, map[string]any{
+			"retired_pages_sbe":  cur.RetiredPagesSBE,
+			"retired_pages_dbe":  cur.RetiredPagesDBE,
+			"retirement_pending": cur.RetirementPending,
+		}, message)
+	}
+	if d := delta(prev.RemappedRowsCorrectable, cur.RemappedRowsCorrectable) + delta(prev.RemappedRowsUncorrectable, cur.RemappedRowsUncorrectable); d > 0 ||
+		cur.RemapPending && !prev.RemapPending || cur.RemapFailed && !prev.RemapFailed {
+		message := fmt.Sprintf("%d new remapped rows", d)
+		switch {
+		case cur.RemapFailed:
+			message += ", row remapping failed"
+		case cur.RemapPending:
+			message += ", remap pending GPU reset"
+		}
+		event(pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM, pb.HealthEventType_HEALTH_EVENT_TYPE_MEMORY, map[string]any{
+			"remapped_rows":             cur.RemappedRowsUncorrectable,
+			"remapped_rows_correctable": cur.RemappedRowsCorrectable,
+			"remap_pending":             cur.RemapPending,
+			"remap_failed":              cur.RemapFailed,
+		}, message)
+	}
+	if d := delta(prev.PCIeReplays, cur.PCIeReplays); d > 0 {
+		event(pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_PCIE, pb.HealthEventType_HEALTH_EVENT_TYPE_PCIE, map[string]any{
+			"pcie_replay_count": cur.PCIeReplays,
+			"pcie_replay_delta": d,
+		}, fmt.Sprintf("%d new PCIe replays", d))
+	}
+
+	crc := delta(prev.NVLinkCRCErrors, cur.NVLinkCRCErrors)
+	replay := delta(prev.NVLinkReplayErrors, cur.NVLinkReplayErrors)
+	recovery := delta(prev.NVLinkRecoveryErrors, cur.NVLinkRecoveryErrors)
+	if crc+replay+recovery > 0 {
+		event(pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_NVLINK, pb.HealthEventType_HEALTH_EVENT_TYPE_NVLINK, map[string]any{
+			"link_id":                -1,
+			"nvlink_crc_errors":      crc,
+			"nvlink_replay_errors":   replay,
+			"nvlink_recovery_errors": recovery,
+		}, fmt.Sprintf("new NVLink errors: %d CRC, %d replay, %d recovery", crc, replay, recovery))
+	}
+
+	for _, system := range []pb.HealthWatchSystem{
+		pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_THERMAL,
+		pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_POWER,
+	} {
+		var names []string
+		for _, f := range throttleFaults {
+			if f.system == system && cur.ThrottleReasons&f.reason != 0 && prev.ThrottleReasons&f.reason == 0 {
+				names = append(names, f.name)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		metrics := map[string]any{
+			"throttle_reasons": strings.Join(names, ","),
+			"throttle_mask":    cur.ThrottleReasons,
+		}
+		eventType := pb.HealthEventType_HEALTH_EVENT_TYPE_POWER
+		if system == pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_THERMAL {
+			eventType = pb.HealthEventType_HEALTH_EVENT_TYPE_THERMAL
+			metrics["temperature"] = cur.Temperature
+		}
+		event(system, eventType, metrics, "clocks throttled: "+strings.Join(names, ", "))
+	}
+
+	return events
+}
+
+// delta returns how much a counter rose, or 0 if it was reset.
+func delta(prev, cur uint64) uint64 {
+	if cur < prev {
+		return 0
+	}
+	return cur - prev
+}
@@ -0,0 +1,373 @@
+package gpu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+// DCGMField identifies a DCGM field (DCGM_FI_*).
+type DCGMField int
+
+// DCGM fields read by the DCGM manager.
+const (
+	DCGMFieldGPUTemp              DCGMField = 150 // DCGM_FI_DEV_GPU_TEMP
+	DCGMFieldPowerUsage           DCGMField = 155 // DCGM_FI_DEV_POWER_USAGE
+	DCGMFieldGPUUtil              DCGMField = 203 // DCGM_FI_DEV_GPU_UTIL
+	DCGMFieldFBTotal              DCGMField = 250 // DCGM_FI_DEV_FB_TOTAL, MiB
+	DCGMFieldFBUsed               DCGMField = 252 // DCGM_FI_DEV_FB_USED, MiB
+	DCGMFieldClockThrottleReasons DCGMField = 112 // DCGM_FI_DEV_CLOCK_THROTTLE_REASONS
+	DCGMFieldPCIeReplayCounter    DCGMField = 202 // DCGM_FI_DEV_PCIE_REPLAY_COUNTER
+	DCGMFieldECCSBEVolatileTotal  DCGMField = 310 // DCGM_FI_DEV_ECC_SBE_VOL_TOTAL
+	DCGMFieldECCDBEVolatileTotal  DCGMField = 311 // DCGM_FI_DEV_ECC_DBE_VOL_TOTAL
+	DCGMFieldECCSBEAggregateTotal DCGMField = 312 // DCGM_FI_DEV_ECC_SBE_AGG_TOTAL
+	DCGMFieldECCDBEAggregateTotal DCGMField = 313 // DCGM_FI_DEV_ECC_DBE_AGG_TOTAL
+	DCGMFieldNVLinkCRCFlitErrors  DCGMField = 409 // DCGM_FI_DEV_NVLINK_CRC_FLIT_ERROR_COUNT_TOTAL
+	DCGMFieldNVLinkCRCDataErrors  DCGMField = 419 // DCGM_FI_DEV_NVLINK_CRC_DATA_ERROR_COUNT_TOTAL
+	DCGMFieldNVLinkReplayErrors   DCGMField = 429 // DCGM_FI_DEV_NVLINK_REPLAY_ERROR_COUNT_TOTAL
+	DCGMFieldNVLinkRecoveryErrors DCGMField = 439 // DCGM_FI_DEV_NVLINK_RECOVERY_ERROR_COUNT_TOTAL
+)
+
+// DCGM field groups watched by the DCGM manager.
+var (
+	dcgmHealthFields = []DCGMField{
+		DCGMFieldGPUTemp, DCGMFieldPowerUsage, DCGMFieldGPUUtil, DCGMFieldFBTotal, DCGMFieldFBUsed,
+	}
+	dcgmECCFields = []DCGMField{
+		DCGMFieldECCSBEVolatileTotal, DCGMFieldECCDBEVolatileTotal,
+		DCGMFieldECCSBEAggregateTotal, DCGMFieldECCDBEAggregateTotal,
+	}
+	dcgmNVLinkFields = []DCGMField{
+		DCGMFieldNVLinkCRCFlitErrors, DCGMFieldNVLinkCRCDataErrors,
+		DCGMFieldNVLinkReplayErrors, DCGMFieldNVLinkRecoveryErrors,
+	}
+	dcgmPCIeFields     = []DCGMField{DCGMFieldPCIeReplayCounter}
+	dcgmThrottleFields = []DCGMField{DCGMFieldClockThrottleReasons, DCGMFieldGPUTemp}
+)
+
+// dcgmFieldGroups names the field groups the DCGM manager asks the host
+// engine to watch.
+var dcgmFieldGroups = []struct {
+	name   string
+	fields []DCGMField
+}{
+	{"navarch-health", dcgmHealthFields},
+	{"navarch-ecc", dcgmECCFields},
+	{"navarch-nvlink", dcgmNVLinkFields},
+	{"navarch-pcie", dcgmPCIeFields},
+	{"navarch-throttle", dcgmThrottleFields},
+}
+
+// dcgmWatchInterval is how often the host engine updates watched fields.
+const dcgmWatchInterval = 10 * time.Second
+
+// dcgmHealthWatches are the health watch systems the DCGM manager enables.
+var dcgmHealthWatches = []pb.HealthWatchSystem{
+	pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_PCIE,
+	pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_NVLINK,
+	pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM,
+	pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_INFOROM,
+	pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_THERMAL,
+	pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_POWER,
+	pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_DRIVER,
+}
+
+// DCGMIncident is a problem reported by a DCGM health check.
+type DCGMIncident struct {
+	// GPUIndex is the GPU the incident is about, or -1 if it is not about a
+	// single GPU.
+	GPUIndex int
+
+	// System is the health watch system that reported the incident.
+	System pb.HealthWatchSystem
+
+	// Failure is true for failures and false for warnings.
+	Failure bool
+
+	// ErrorCode is the DCGM error code (dcgmError_t), or 0 if unknown.
+	ErrorCode int
+
+	// Message describes the incident.
+	Message string
+}
+
+// DCGMClient is the part of the DCGM API the DCGM manager uses. DCGMI
+// implements it against a host engine; tests use a fake.
+type DCGMClient interface {
+	// Connect connects to the host engine.
+	Connect(ctx context.Context) error
+
+	// Disconnect releases everything Connect and the other calls set up.
+	Disconnect(ctx context.Context) error
+
+	// Devices returns the GPUs managed by the host engine.
+	Devices(ctx context.Context) ([]DeviceInfo, error)
+
+	// SetHealthWatches enables health watches for the given systems on all
+	// GPUs.
+	SetHealthWatches(ctx context.Context, systems []pb.HealthWatchSystem) error
+
+	// HealthCheck returns the incidents the health watches currently see.
+	HealthCheck(ctx context.Context) ([]DCGMIncident, error)
+
+	// WatchFields asks the host engine to sample a group of fields on all
+	// GPUs at the given interval.
+	WatchFields(ctx context.Context, group string, fields []DCGMField, interval time.Duration) error
+
+	// LatestValues returns the latest values of fields on a GPU. Fields
+	// without a value, such as ECC counters on GPUs with ECC disabled, are
+	// left out.
+	LatestValues(ctx context.Context, index int, fields []DCGMField) (map[DCGMField]float64, error)
+}
+
+// DCGM is a GPU manager backed by a DCGM host engine. Health events come
+// from DCGM health watches and from changes in the ECC, NVLink, PCIe replay,
+// and clock throttle fields it watches.
+type DCGM struct {
+	client DCGMClient
+
+	mu          sync.Mutex
+	initialized bool
+	devices     []DeviceInfo
+	counters    []Counters
+	fields      []map[DCGMField]float64 // last value read of each counter field, per GPU
+	incidents   map[DCGMIncident]bool   // incidents seen by the last health check
+}
+
+// NewDCGM creates a GPU manager that uses the given DCGM client.
+func NewDCGM(client DCGMClient) *DCGM {
+	return &DCGM{client: client}
+}
+
+// Initialize connects to the host engine, sets up health watches and field
+// watches, and records the counters that later samples are compared with.
+func (m *DCGM) Initialize(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.initialized {
+		return errors.New("already initialized")
+	}
+
+	if err := m.client.Connect(ctx); err != nil {
+		return fmt.Errorf("connecting to DCGM: %w", err)
+	}
+	err := m.setup(ctx)
+	if err != nil {
+		m.client.Disconnect(ctx)
+		return err
+	}
+	m.initialized = true
+	return nil
+}
+
+func (m *DCGM) setup(ctx context.Context) error {
+	devices, err := m.client.Devices(ctx)
+	if err != nil {
+		return fmt.Errorf("listing DCGM devices: %w", err)
+	}
+	if err := m.client.SetHealthWatches(ctx, dcgmHealthWatches); err != nil {
+		return fmt.Errorf("setting DCGM health watches: %w", err)
+	}
+	for _, g := range dcgmFieldGroups {
+		if err := m.client.WatchFields(ctx, g.name, g.fields, dcgmWatchInterval); err != nil {
+			return fmt.Errorf("watching DCGM field group %s: %w", g.name, err)
+		}
+	}
+
+	m.fields = make([]map[DCGMField]float64, len(devices))
+	for i := range m.fields {
+		m.fields[i] = make(map[DCGMField]float64)
+	}
+	counters := make([]Counters, len(devices))
+	for i := range devices {
+		if counters[i], err = m.sampleCounters(ctx, i); err != nil {
+			return err
+		}
+	}
+	m.devices = devices
+	m.counters = counters
+	m.incidents = make(map[DCGMIncident]bool)
+	return nil
+}
+
+func (m *DCGM) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.initialized {
+		return errors.New("not initialized")
+	}
+	m.initialized = false
+	return m.client.Disconnect(ctx)
+}
+
+func (m *DCGM) GetDeviceCount(ctx context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.initialized {
+		return 0, errors.New("not initialized")
+	}
+	return len(m.devices), nil
+}
+
+func (m *DCGM) GetDeviceInfo(ctx context.Context, index int) (*DeviceInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkIndex(index); err != nil {
+		return nil, err
+	}
+	info := m.devices[index]
+	return &info, nil
+}
+
+func (m *DCGM) GetDeviceHealth(ctx context.Context, index int) (*HealthInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkIndex(index); err != nil {
+		return nil, err
+	}
+	values, err := m.client.LatestValues(ctx, index, dcgmHealthFields)
+	if err != nil {
+		return nil, fmt.Errorf("reading DCGM fields: %w", err)
+	}
+
+	const mib = 1024 * 1024
+	return &HealthInfo{
+		Temperature:    int(values[DCGMFieldGPUTemp]),
+		PowerUsage:     values[DCGMFieldPowerUsage],
+		MemoryUsed:     uint64(values[DCGMFieldFBUsed]) * mib,
+		MemoryTotal:    uint64(values[DCGMFieldFBTotal]) * mib,
+		GPUUtilization: int(values[DCGMFieldGPUUtil]),
+	}, nil
+}
+
+// CollectHealthEvents runs a DCGM health check and samples the watched
+// counters. An incident produces an event the first time a health check
+// reports it; a counter produces an event whenever it changes.
+func (m *DCGM) CollectHealthEvents(ctx context.Context) ([]HealthEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.initialized {
+		return nil, errors.New("not initialized")
+	}
+
+	now := time.Now()
+	incidents, err := m.client.HealthCheck(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("running DCGM health check: %w", err)
+	}
+	var events []HealthEvent
+	seen := make(map[DCGMIncident]bool, len(incidents))
+	for _, inc := range incidents {
+		if !m.incidents[inc] && !seen[inc] {
+			events = append(events, m.incidentEvent(now, inc))
+		}
+		seen[inc] = true
+	}
+	m.incidents = seen
+
+	for i, dev := range m.devices {
+		cur, err := m.sampleCounters(ctx, i)
+		if err != nil {
+			return events, err
+		}
+		events = append(events, CounterEvents(now, i, dev.UUID, m.counters[i], cur)...)
+		m.counters[i] = cur
+	}
+	return events, nil
+}
+
+// incidentEvent converts a health check incident to a health event.
+func (m *DCGM) incidentEvent(timestamp time.Time, inc DCGMIncident) HealthEvent {
+	health := "warning"
+	if inc.Failure {
+		health = "failure"
+	}
+	event := HealthEvent{
+		Timestamp: timestamp,
+		GPUIndex:  inc.GPUIndex,
+		System:    inc.System,
+		EventType: dcgmEventType(inc.System),
+		Metrics: map[string]any{
+			"dcgm_health":     health,
+			"dcgm_error_code": inc.ErrorCode,
+		},
+		Message: inc.Message,
+	}
+	if inc.GPUIndex >= 0 && inc.GPUIndex < len(m.devices) {
+		event.GPUUUID = m.devices[inc.GPUIndex].UUID
+	}
+	return event
+}
+
+// dcgmEventType returns the event type of incidents from a health watch
+// system.
+func dcgmEventType(system pb.HealthWatchSystem) pb.HealthEventType {
+	switch system {
+	case pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_PCIE:
+		return pb.HealthEventType_HEALTH_EVENT_TYPE_PCIE
+	case pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_NVLINK, pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_NVSWITCH:
+		return pb.HealthEventType_HEALTH_EVENT_TYPE_NVLINK
+	case pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM:
+		return pb.HealthEventType_HEALTH_EVENT_TYPE_MEMORY
+	case pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_THERMAL:
+		return pb.HealthEventType_HEALTH_EVENT_TYPE_THERMAL
+	case pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_POWER:
+		return pb.HealthEventType_HEALTH_EVENT_TYPE_POWER
+	default:
+		return pb.HealthEventType_HEALTH_EVENT_TYPE_DRIVER_ERROR
+	}
+}
+
+// sampleCounters reads the ECC, NVLink, PCIe replay, and throttle fields of
+// a GPU. Fields without a value keep the last value read, so a blank read is
+// not mistaken for a counter reset and the next good read does not show the
+// whole count as new.
+func (m *DCGM) sampleCounters(ctx context.Context, index int) (Counters, error) {
+	fields := make([]DCGMField, 0, len(dcgmECCFields)+len(dcgmNVLinkFields)+len(dcgmPCIeFields)+len(dcgmThrottleFields))
+	fields = append(fields, dcgmECCFields...)
+	fields = append(fields, dcgmNVLinkFields...)
+	fields = append(fields, dcgmPCIeFields...)
+	fields = append(fields, dcgmThrottleFields...)
+
+	values, err := m.client.LatestValues(ctx, index, fields)
+	if err != nil {
+		return Counters{}, fmt.Errorf("reading DCGM counters of GPU %d: %w", index, err)
+	}
+	v := m.fields[index]
+	for f, x := range values {
+		v[f] = x
+	}
+	count := func(f DCGMField) uint64 { return uint64(max(v[f], 0)) }
+	return Counters{
+		Temperature:          int(v[DCGMFieldGPUTemp]),
+		ECCSBEVolatile:       count(DCGMFieldECCSBEVolatileTotal),
+		ECCDBEVolatile:       count(DCGMFieldECCDBEVolatileTotal),
+		ECCSBEAggregate:      count(DCGMFieldECCSBEAggregateTotal),
+		ECCDBEAggregate:      count(DCGMFieldECCDBEAggregateTotal),
+		PCIeReplays:          count(DCGMFieldPCIeReplayCounter),
+		NVLinkCRCErrors:      count(DCGMFieldNVLinkCRCFlitErrors) + count(DCGMFieldNVLinkCRCDataErrors),
+		NVLinkReplayErrors:   count(DCGMFieldNVLinkReplayErrors),
+		NVLinkRecoveryErrors: count(DCGMFieldNVLinkRecoveryErrors),
+		ThrottleReasons:      count(DCGMFieldClockThrottleReasons),
+	}, nil
+}
+
+func (m *DCGM) checkIndex(index int) error {
+	if !m.initialized {
+		return errors.New("not initialized")
+	}
+	if index < 0 || index >= len(m.devices) {
+		return fmt.Errorf("invalid device index: %d", index)
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package gpu
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+// fakeDCGM is a DCGMClient backed by values set by the test.
+type fakeDCGM struct {
+	connected  bool
+	devices    []DeviceInfo
+	watches    []pb.HealthWatchSystem
+	groups     []string
+	incidents  []DCGMIncident
+	values     []map[DCGMField]float64 // per GPU
+	connectErr error
+}
+
+func (f *fakeDCGM) Connect(ctx context.Context) error {
+	if f.connectErr != nil {
+		return f.connectErr
+	}
+	f.connected = true
+	return nil
+}
+
+func (f *fakeDCGM) Disconnect(ctx context.Context) error {
+	f.connected = false
+	return nil
+}
+
+func (f *fakeDCGM) Devices(ctx context.Context) ([]DeviceInfo, error) {
+	return f.devices, nil
+}
+
+func (f *fakeDCGM) SetHealthWatches(ctx context.Context, systems []pb.HealthWatchSystem) error {
+	f.watches = systems
+	return nil
+}
+
+func (f *fakeDCGM) HealthCheck(ctx context.Context) ([]DCGMIncident, error) {
+	return f.incidents, nil
+}
+
+func (f *fakeDCGM) WatchFields(ctx context.Context, group string, fields []DCGMField, interval time.Duration) error {
+	f.groups = append(f.groups, group)
+	return nil
+}
+
+func (f *fakeDCGM) LatestValues(ctx context.Context, index int, fields []DCGMField) (map[DCGMField]float64, error) {
+	values := make(map[DCGMField]float64)
+	for _, field := range fields {
+		if v, ok := f.values[index][field]; ok {
+			values[field] = v
+		}
+	}
+	return values, nil
+}
+
+func newFakeDCGM(gpus int) *fakeDCGM {
+	f := &fakeDCGM{}
+	for i := 0; i < gpus; i++ {
+		f.devices = append(f.devices, DeviceInfo{Index: i, UUID: "GPU-" + string(rune('a'+i)), Name: "NVIDIA H100 80GB HBM3"})
+		f.values = append(f.values, map[DCGMField]float64{
+			DCGMFieldGPUTemp:    40,
+			DCGMFieldPowerUsage: 300.5,
+			DCGMFieldGPUUtil:    75,
+			DCGMFieldFBTotal:    81559,
+			DCGMFieldFBUsed:     1024,
+		})
+	}
+	return f
+}
+
+func TestDCGM_ImplementsManager(t *testing.T) {
+	var _ Manager = (*DCGM)(nil)
+	var _ DCGMClient = (*DCGMI)(nil)
+}
+
+func TestDCGM_Initialize(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sets up watches", func(t *testing.T) {
+		client := newFakeDCGM(2)
+		m := NewDCGM(client)
+		if err := m.Initialize(ctx); err != nil {
+			t.Fatalf("Initialize() error = %v", err)
+		}
+		if !client.connected {
+			t.Error("client not connected")
+		}
+		if len(client.watches) == 0 {
+			t.Error("no health watches set")
+		}
+		if len(client.groups) != len(dcgmFieldGroups) {
+			t.Errorf("watched %d field groups, want %d", len(client.groups), len(dcgmFieldGroups))
+		}
+		if err := m.Initialize(ctx); err == nil {
+			t.Error("second Initialize() succeeded, want error")
+		}
+
+		if err := m.Shutdown(ctx); err != nil {
+			t.Fatalf("Shutdown() error = %v", err)
+		}
+		if client.connected {
+			t.Error("client still connected after Shutdown")
+		}
+	})
+
+	t.Run("connect error", func(t *testing.T) {
+		client := newFakeDCGM(1)
+		client.connectErr = errors.New("host engine not running")
+		if err := NewDCGM(client).Initialize(ctx); err == nil {
+			t.Error("Initialize() succeeded, want error")
+		}
+	})
+
+	t.Run("not initialized", func(t *testing.T) {
+		m := NewDCGM(newFakeDCGM(1))
+		if _, err := m.GetDeviceCount(ctx); err == nil {
+			t.Error("GetDeviceCount() succeeded, want error")
+		}
+		if _, err := m.CollectHealthEvents(ctx); err == nil {
+			t.Error("CollectHealthEvents() succeeded, want error")
+		}
+	})
+}
+
+func TestDCGM_Devices(t *testing.T) {
+	ctx := context.Background()
+	m := NewDCGM(newFakeDCGM(2))
+	if err := m.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := m.GetDeviceCount(ctx)
+	if err != nil || count != 2 {
+		t.Fatalf("GetDeviceCount() = %d, %v, want 2", count, err)
+	}
+	info, err := m.GetDeviceInfo(ctx, 1)
+	if err != nil || info.UUID != "GPU-b" {
+		t.Errorf("GetDeviceInfo(1) = %+v, %v, want GPU-b", info, err)
+	}
+	if _, err := m.GetDeviceInfo(ctx, 2); err == nil {
+		t.Error("GetDeviceInfo(2) succeeded, want error")
+	}
+
+	health, err := m.GetDeviceHealth(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := HealthInfo{
+		Temperature:    40,
+		PowerUsage:     300.5,
+		MemoryUsed:     1024 * 1024 * 1024,
+		MemoryTotal:    81559 * 1024 * 1024,
+		GPUUtilization: 75,
+	}
+	if *health != want {
+		t.Errorf("GetDeviceHealth(0) = %+v, want %+v", *health, want)
+	}
+}
+
+func TestDCGM_CollectHealthEvents(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeDCGM(2)
+	client.values[0][DCGMFieldECCSBEVolatileTotal] = 3
+	client.values[0][DCGMFieldPCIeReplayCounter] = 10
+	m := NewDCGM(client)
+	if err := m.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("baseline produces no events", func(t *testing.T) {
+		events, err := m.CollectHealthEvents(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 0 {
+			t.Errorf("got %d events, want 0: %+v", len(events), events)
+		}
+	})
+
+	t.Run("incidents", func(t *testing.T) {
+		client.incidents = []DCGMIncident{
+			{GPUIndex: 1, System: pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM, Failure: true, Message: "volatile DBE"},
+			{GPUIndex: 0, System: pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_PCIE, Message: "replay rate"},
+		}
+		events, err := m.CollectHealthEvents(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 2 {
+			t.Fatalf("got %d events, want 2: %+v", len(events), events)
+		}
+		mem := events[0]
+		if mem.GPUIndex != 1 || mem.GPUUUID != "GPU-b" ||
+			mem.System != pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM ||
+			mem.EventType != pb.HealthEventType_HEALTH_EVENT_TYPE_MEMORY ||
+			mem.Metrics["dcgm_health"] != "failure" || mem.Message != "volatile DBE" {
+			t.Errorf("unexpected memory event %+v", mem)
+		}
+		if pcie := events[1]; pcie.EventType != pb.HealthEventType_HEALTH_EVENT_TYPE_PCIE || pcie.Metrics["dcgm_health"] != "warning" {
+			t.Errorf("unexpected PCIe event %+v", pcie)
+		}
+
+		// Incidents are reported once while they last, and again if they
+		// come back.
+		if events, _ := m.CollectHealthEvents(ctx); len(events) != 0 {
+			t.Errorf("ongoing incidents produced %d events, want 0", len(events))
+		}
+		client.incidents = client.incidents[:1]
+		m.CollectHealthEvents(ctx)
+		client.incidents = append(client.incidents, DCGMIncident{GPUIndex: 0, System: pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_PCIE, Message: "replay rate"})
+		if events, _ := m.CollectHealthEvents(ctx); len(events) != 1 {
+			t.Errorf("returning incident produced %d events, want 1", len(events))
+		}
+		client.incidents = nil
+		m.CollectHealthEvents(ctx)
+	})
+
+	t.Run("counter deltas", func(t *testing.T) {
+		client.values[0][DCGMFieldECCDBEVolatileTotal] = 2
+		client.values[0][DCGMFieldECCSBEVolatileTotal] = 5
+		client.values[0][DCGMFieldPCIeReplayCounter] = 14
+		client.values[1][DCGMFieldNVLinkCRCFlitErrors] = 7
+		client.values[1][DCGMFieldNVLinkRecoveryErrors] = 1
+
+		events, err := m.CollectHealthEvents(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		byType := make(map[pb.HealthEventType]HealthEvent)
+		for _, e := range events {
+			byType[e.EventType] = e
+		}
+		if len(events) != 4 || len(byType) != 4 {
+			t.Fatalf("got events %+v, want ecc_dbe, ecc_sbe, pcie, and nvlink", events)
+		}
+
+		dbe := byType[pb.HealthEventType_HEALTH_EVENT_TYPE_ECC_DBE]
+		if dbe.GPUIndex != 0 || dbe.GPUUUID != "GPU-a" || dbe.System != pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM ||
+			dbe.Metrics["ecc_dbe_count"] != uint64(2) || dbe.Metrics["ecc_dbe_delta"] != uint64(2) {
+			t.Errorf("unexpected ecc_dbe event %+v", dbe)
+		}
+		sbe := byType[pb.HealthEventType_HEALTH_EVENT_TYPE_ECC_SBE]
+		if sbe.Metrics["ecc_sbe_count"] != uint64(5) || sbe.Metrics["ecc_sbe_delta"] != uint64(2) {
+			t.Errorf("unexpected ecc_sbe event %+v", sbe)
+		}
+		if _, ok := sbe.Metrics["ecc_dbe_count"]; ok {
+			t.Error("ecc_sbe event carries ecc_dbe_count")
+		}
+		pcie := byType[pb.HealthEventType_HEALTH_EVENT_TYPE_PCIE]
+		if pcie.System != pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_PCIE || pcie.Metrics["pcie_replay_delta"] != uint64(4) {
+			t.Errorf("unexpected pcie event %+v", pcie)
+		}
+		nvlink := byType[pb.HealthEventType_HEALTH_EVENT_TYPE_NVLINK]
+		if nvlink.GPUIndex != 1 || nvlink.System != pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_NVLINK ||
+			nvlink.Metrics["nvlink_crc_errors"] != uint64(7) || nvlink.Metrics["nvlink_recovery_errors"] != uint64(1) {
+			t.Errorf("unexpected nvlink event %+v", nvlink)
+		}
+
+		if events, _ := m.CollectHealthEvents(ctx); len(events) != 0 {
+			t.Errorf("unchanged counters produced %d events, want 0", len(events))
+		}
+	})
+
+	t.Run("counter reset", func(t *testing.T) {
+		client.values[0][DCGMFieldECCDBEVolatileTotal] = 0
+		if events, _ := m.CollectHealthEvents(ctx); len(events) != 0 {
+			t.Errorf("reset counter produced %d events, want 0", len(events))
+		}
+	})
+
+	t.Run("throttling", func(t *testing.T) {
+		client.values[1][DCGMFieldClockThrottleReasons] = float64(ThrottleReasonSWPowerCap | ThrottleReasonGPUIdle)
+		if events, _ := m.CollectHealthEvents(ctx); len(events) != 0 {
+			t.Errorf("power capping produced %d events, want 0", len(events))
+		}
+
+		client.values[1][DCGMFieldGPUTemp] = 91
+		client.values[1][DCGMFieldClockThrottleReasons] = float64(ThrottleReasonHWThermal | ThrottleReasonHWPowerBrake)
+		events, err := m.CollectHealthEvents(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 2 {
+			t.Fatalf("got %d events, want thermal and power: %+v", len(events), events)
+		}
+		thermal, power := events[0], events[1]
+		if thermal.EventType != pb.HealthEventType_HEALTH_EVENT_TYPE_THERMAL ||
+			thermal.System != pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_THERMAL ||
+			thermal.Metrics["throttle_reasons"] != "hw_thermal" || thermal.Metrics["temperature"] != 91 {
+			t.Errorf("unexpected thermal event %+v", thermal)
+		}
+		if power.EventType != pb.HealthEventType_HEALTH_EVENT_TYPE_POWER ||
+			power.System != pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_POWER ||
+			power.Metrics["throttle_reasons"] != "hw_power_brake" {
+			t.Errorf("unexpected power event %+v", power)
+		}
+
+		if events, _ := m.CollectHealthEvents(ctx); len(events) != 0 {
+			t.Errorf("ongoing throttling produced %d events, want 0", len(events))
+		}
+	})
+}
+
+func TestDCGM_CollectHealthEvents_MissingValues(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeDCGM(1)
+	client.values[0][DCGMFieldECCDBEAggregateTotal] = 12
+	client.values[0][DCGMFieldNVLinkCRCFlitErrors] = 3
+	client.values[0][DCGMFieldNVLinkCRCDataErrors] = 4
+	m := NewDCGM(client)
+	if err := m.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// dcgmi dmon reports N/A for fields the host engine has no sample of
+	// yet. A blank read and the good read after it are not new errors.
+	delete(client.values[0], DCGMFieldECCDBEAggregateTotal)
+	delete(client.values[0], DCGMFieldNVLinkCRCDataErrors)
+	if events, _ := m.CollectHealthEvents(ctx); len(events) != 0 {
+		t.Errorf("missing values produced events %+v, want none", events)
+	}
+	client.values[0][DCGMFieldECCDBEAggregateTotal] = 12
+	client.values[0][DCGMFieldNVLinkCRCDataErrors] = 4
+	if events, _ := m.CollectHealthEvents(ctx); len(events) != 0 {
+		t.Errorf("returning values produced events %+v, want none", events)
+	}
+
+	client.values[0][DCGMFieldECCDBEAggregateTotal] = 13
+	events, err := m.CollectHealthEvents(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Metrics["ecc_dbe_delta"] != uint64(1) {
+		t.Errorf("got events %+v, want one ecc_dbe event with delta 1", events)
+	}
+}
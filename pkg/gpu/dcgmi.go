@@ -0,0 +1,409 @@
+package gpu
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+// DefaultDCGMHost is the address of a host engine running on the node.
+const DefaultDCGMHost = "localhost:5555"
+
+// DCGMI is a DCGMClient that drives a host engine with the dcgmi command
+// line tool, which ships with DCGM.
+type DCGMI struct {
+	host        string
+	group       int // DCGM group holding all GPUs, created by Connect
+	fieldGroups []*dcgmiFieldGroup
+
+	// run runs dcgmi with the given arguments and returns its output.
+	run func(ctx context.Context, args ...string) ([]byte, error)
+}
+
+// dcgmiFieldGroup is a field group created by WatchFields, with the last
+// sample of it.
+type dcgmiFieldGroup struct {
+	id       int
+	fields   []DCGMField
+	interval time.Duration
+	sampled  time.Time
+	samples  map[int]map[DCGMField]float64 // per GPU
+}
+
+func (g *dcgmiFieldGroup) has(f DCGMField) bool {
+	return slices.Contains(g.fields, f)
+}
+
+// NewDCGMI creates a DCGM client for the host engine at host, such as
+// DefaultDCGMHost.
+func NewDCGMI(host string) *DCGMI {
+	c := &DCGMI{host: host}
+	c.run = c.exec
+	return c
+}
+
+func (c *DCGMI) exec(ctx context.Context, args ...string) ([]byte, error) {
+	args = append(args, "--host", c.host)
+	out, err := exec.CommandContext(ctx, "dcgmi", args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		msg := strings.TrimSpace(string(exitErr.Stderr))
+		if msg == "" {
+			msg = strings.TrimSpace(string(out))
+		}
+		return nil, fmt.Errorf("dcgmi %s failed: %w: %s", args[0], err, msg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("running dcgmi: %w", err)
+	}
+	return out, nil
+}
+
+var dcgmiGroupID = regexp.MustCompile(`group ID of (\d+)`)
+
+// Connect creates the DCGM group that health watches and checks apply to.
+// It fails if dcgmi is not installed or the host engine is unreachable.
+func (c *DCGMI) Connect(ctx context.Context) error {
+	out, err := c.run(ctx, "group", "-c", "navarch", "--default")
+	if err != nil {
+		return err
+	}
+	m := dcgmiGroupID.FindSubmatch(out)
+	if m == nil {
+		return fmt.Errorf("unexpected dcgmi group output: %s", strings.TrimSpace(string(out)))
+	}
+	c.group, _ = strconv.Atoi(string(m[1]))
+	return nil
+}
+
+// Disconnect deletes the field groups and the group created by Connect and
+// WatchFields.
+func (c *DCGMI) Disconnect(ctx context.Context) error {
+	var errs []error
+	for _, g := range c.fieldGroups {
+		if _, err := c.run(ctx, "fieldgroup", "-d", "-g", strconv.Itoa(g.id)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	c.fieldGroups = nil
+	if _, err := c.run(ctx, "group", "-d", strconv.Itoa(c.group)); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Devices lists the GPUs with dcgmi discovery and reads their memory size.
+func (c *DCGMI) Devices(ctx context.Context) ([]DeviceInfo, error) {
+	out, err := c.run(ctx, "discovery", "-l")
+	if err != nil {
+		return nil, err
+	}
+	devices := parseDCGMIDiscovery(out)
+	for i := range devices {
+		values, err := c.LatestValues(ctx, devices[i].Index, []DCGMField{DCGMFieldFBTotal})
+		if err != nil {
+			return nil, err
+		}
+		devices[i].Memory = uint64(values[DCGMFieldFBTotal]) * 1024 * 1024
+	}
+	return devices, nil
+}
+
+// dcgmiWatchFlags are the dcgmi health -s letters of the health watch
+// systems. dcgmi watches thermal and power together and cannot set the
+// other systems on their own.
+var dcgmiWatchFlags = map[pb.HealthWatchSystem]string{
+	pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_PCIE:    "p",
+	pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM:     "m",
+	pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_INFOROM: "i",
+	pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_THERMAL: "t",
+	pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_POWER:   "t",
+	pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_NVLINK:  "n",
+}
+
+// SetHealthWatches enables health watches with dcgmi health -s. Systems
+// dcgmi cannot watch on their own are skipped.
+func (c *DCGMI) SetHealthWatches(ctx context.Context, systems []pb.HealthWatchSystem) error {
+	var flags []string
+	for _, s := range systems {
+		if f, ok := dcgmiWatchFlags[s]; ok && !slices.Contains(flags, f) {
+			flags = append(flags, f)
+		}
+	}
+	if len(flags) == 0 {
+		return nil
+	}
+	_, err := c.run(ctx, "health", "-g", strconv.Itoa(c.group), "-s", strings.Join(flags, ""))
+	return err
+}
+
+// HealthCheck runs dcgmi health -c.
+func (c *DCGMI) HealthCheck(ctx context.Context) ([]DCGMIncident, error) {
+	out, err := c.run(ctx, "health", "-g", strconv.Itoa(c.group), "-c", "-j")
+	if err != nil {
+		return nil, err
+	}
+	return parseDCGMIHealth(out)
+}
+
+// WatchFields creates a field group with dcgmi fieldgroup -c. LatestValues
+// reads the fields of a field group for all GPUs with one dcgmi dmon call,
+// and reuses that sample until the interval has passed.
+func (c *DCGMI) WatchFields(ctx context.Context, group string, fields []DCGMField, interval time.Duration) error {
+	out, err := c.run(ctx, "fieldgroup", "-c", group, "-f", dcgmiFieldIDs(fields))
+	if err != nil {
+		return err
+	}
+	m := dcgmiGroupID.FindSubmatch(out)
+	if m == nil {
+		return fmt.Errorf("unexpected dcgmi fieldgroup output: %s", strings.TrimSpace(string(out)))
+	}
+	id, _ := strconv.Atoi(string(m[1]))
+	c.fieldGroups = append(c.fieldGroups, &dcgmiFieldGroup{id: id, fields: fields, interval: interval})
+	return nil
+}
+
+// LatestValues returns fields of a GPU from the samples of the field groups
+// holding them, and samples fields in no field group once with dcgmi dmon.
+func (c *DCGMI) LatestValues(ctx context.Context, index int, fields []DCGMField) (map[DCGMField]float64, error) {
+	values := make(map[DCGMField]float64, len(fields))
+	var unwatched []DCGMField
+	for _, f := range fields {
+		if !slices.ContainsFunc(c.fieldGroups, func(g *dcgmiFieldGroup) bool { return g.has(f) }) {
+			unwatched = append(unwatched, f)
+		}
+	}
+
+	for _, g := range c.fieldGroups {
+		if !slices.ContainsFunc(fields, g.has) {
+			continue
+		}
+		if err := c.sample(ctx, g); err != nil {
+			return nil, err
+		}
+		sample, ok := g.samples[index]
+		if !ok {
+			return nil, fmt.Errorf("no dcgmi dmon sample for GPU %d", index)
+		}
+		for _, f := range fields {
+			if v, ok := sample[f]; ok {
+				values[f] = v
+			}
+		}
+	}
+
+	if len(unwatched) > 0 {
+		out, err := c.run(ctx, "dmon", "-i", strconv.Itoa(index), "-e", dcgmiFieldIDs(unwatched), "-c", "1")
+		if err != nil {
+			return nil, err
+		}
+		sample, err := parseDCGMIDmon(out, index, unwatched)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(values, sample)
+	}
+	return values, nil
+}
+
+// sample reads a field group for all GPUs with dcgmi dmon, unless the last
+// sample is less than the group's interval old.
+func (c *DCGMI) sample(ctx context.Context, g *dcgmiFieldGroup) error {
+	if g.samples != nil && time.Since(g.sampled) < g.interval {
+		return nil
+	}
+	out, err := c.run(ctx, "dmon", "-g", strconv.Itoa(c.group), "-f", strconv.Itoa(g.id), "-c", "1")
+	if err != nil {
+		return err
+	}
+	g.samples = parseDCGMIDmonSamples(out, g.fields)
+	g.sampled = time.Now()
+	return nil
+}
+
+func dcgmiFieldIDs(fields []DCGMField) string {
+	ids := make([]string, len(fields))
+	for i, f := range fields {
+		ids[i] = strconv.Itoa(int(f))
+	}
+	return strings.Join(ids, ",")
+}
+
+// parseDCGMIDiscovery parses the GPU table printed by dcgmi discovery -l:
+//
+//	| 0      | Name: NVIDIA H100 80GB HBM3                    |
+//	|        | PCI Bus ID: 00000000:18:00.0                   |
+//	|        | Device UUID: GPU-5f1c...                       |
+func parseDCGMIDiscovery(out []byte) []DeviceInfo {
+	var devices []DeviceInfo
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		cols := strings.Split(scanner.Text(), "|")
+		if len(cols) < 3 {
+			continue
+		}
+		if index, err := strconv.Atoi(strings.TrimSpace(cols[1])); err == nil {
+			devices = append(devices, DeviceInfo{Index: index})
+		}
+		if len(devices) == 0 {
+			continue
+		}
+		key, value, ok := strings.Cut(cols[2], ":")
+		if !ok {
+			continue
+		}
+		dev := &devices[len(devices)-1]
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "Name":
+			dev.Name = value
+		case "PCI Bus ID":
+			dev.PCIBusID = value
+		case "Device UUID":
+			dev.UUID = value
+		}
+	}
+	return devices
+}
+
+// parseDCGMIDmon parses the sample dcgmi dmon printed for a GPU.
+func parseDCGMIDmon(out []byte, index int, fields []DCGMField) (map[DCGMField]float64, error) {
+	values, ok := parseDCGMIDmonSamples(out, fields)[index]
+	if !ok {
+		return nil, fmt.Errorf("no dcgmi dmon sample for GPU %d", index)
+	}
+	return values, nil
+}
+
+// parseDCGMIDmonSamples parses the samples dcgmi dmon printed, by GPU.
+// Values are in the order of the fields; N/A values are left out.
+//
+//	#Entity   ECCSB  ECCDB
+//	ID
+//	GPU 0     0      0
+//	GPU 1     0      N/A
+func parseDCGMIDmonSamples(out []byte, fields []DCGMField) map[int]map[DCGMField]float64 {
+	samples := make(map[int]map[DCGMField]float64)
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := strings.Fields(scanner.Text())
+		if len(line) < 2 || line[0] != "GPU" {
+			continue
+		}
+		index, err := strconv.Atoi(line[1])
+		if err != nil {
+			continue
+		}
+		values := make(map[DCGMField]float64, len(fields))
+		for i, s := range line[2:] {
+			if i >= len(fields) {
+				break
+			}
+			if v, err := strconv.ParseFloat(s, 64); err == nil {
+				values[fields[i]] = v
+			}
+		}
+		samples[index] = values
+	}
+	return samples
+}
+
+// dcgmiSystems maps the names dcgmi health reports systems under to health
+// watch systems.
+var dcgmiSystems = map[string]pb.HealthWatchSystem{
+	"pcie":     pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_PCIE,
+	"nvlink":   pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_NVLINK,
+	"pmu":      pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_PMU,
+	"mcu":      pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MCU,
+	"memory":   pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM,
+	"sm":       pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_SM,
+	"inforom":  pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_INFOROM,
+	"thermal":  pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_THERMAL,
+	"power":    pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_POWER,
+	"driver":   pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_DRIVER,
+	"nvswitch": pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_NVSWITCH,
+}
+
+var dcgmiGPUKey = regexp.MustCompile(`^(?:GPU(?: ID)?:?\s*)?(\d+)$`)
+
+// parseDCGMIHealth parses the JSON report of dcgmi health -c -j. dcgmi
+// nests the report as objects with a "value" and "children": GPUs are keyed
+// by their ID, systems by name, and each system's value is its health
+// ("Healthy", "Warning", or "Failure") with the messages below it.
+func parseDCGMIHealth(out []byte) ([]DCGMIncident, error) {
+	var report any
+	if err := json.Unmarshal(out, &report); err != nil {
+		return nil, fmt.Errorf("parsing dcgmi health output: %w", err)
+	}
+
+	var incidents []DCGMIncident
+	var walk func(key string, node any, gpu int)
+	walk = func(key string, node any, gpu int) {
+		obj, ok := node.(map[string]any)
+		if !ok {
+			return
+		}
+		if m := dcgmiGPUKey.FindStringSubmatch(strings.TrimSpace(key)); m != nil {
+			gpu, _ = strconv.Atoi(m[1])
+		}
+		if system, ok := dcgmiSystems[strings.ToLower(strings.TrimSpace(key))]; ok {
+			health, _ := obj["value"].(string)
+			failure := strings.EqualFold(health, "Failure")
+			if !failure && !strings.EqualFold(health, "Warning") {
+				return
+			}
+			incidents = append(incidents, DCGMIncident{
+				GPUIndex: gpu,
+				System:   system,
+				Failure:  failure,
+				Message:  strings.Join(dcgmiMessages(obj["children"]), "; "),
+			})
+			return
+		}
+		for _, k := range sortedKeys(obj) {
+			walk(k, obj[k], gpu)
+		}
+	}
+	walk("", report, -1)
+	return incidents, nil
+}
+
+// dcgmiMessages returns the string values below a node of a dcgmi report.
+func dcgmiMessages(node any) []string {
+	var messages []string
+	switch v := node.(type) {
+	case string:
+		if s := strings.TrimSpace(v); s != "" {
+			messages = append(messages, s)
+		}
+	case []any:
+		for _, child := range v {
+			messages = append(messages, dcgmiMessages(child)...)
+		}
+	case map[string]any:
+		for _, k := range sortedKeys(v) {
+			messages = append(messages, dcgmiMessages(v[k])...)
+		}
+	}
+	return messages
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
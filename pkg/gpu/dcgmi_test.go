@@ -0,0 +1,213 @@
+package gpu
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+const dcgmiDiscoveryOutput = `2 GPUs found.
++--------+----------------------------------------------------------------------+
+| GPU ID | Device Information                                                   |
++--------+----------------------------------------------------------------------+
+| 0      | Name: NVIDIA H100 80GB HBM3                                          |
+|        | PCI Bus ID: 00000000:18:00.0                                         |
+|        | Device UUID: GPU-5f1c0e2a-0000-0000-0000-000000000000                |
++--------+----------------------------------------------------------------------+
+| 1      | Name: NVIDIA H100 80GB HBM3                                          |
+|        | PCI Bus ID: 00000000:2A:00.0                                         |
+|        | Device UUID: GPU-5f1c0e2a-0000-0000-0000-000000000001                |
++--------+----------------------------------------------------------------------+
+0 NvSwitches found.
+`
+
+const dcgmiHealthOutput = `{
+  "body": {
+    "Overall Health": {"value": "Failure"},
+    "GPU": {
+      "children": {
+        "0": {
+          "children": {
+            "Memory": {
+              "value": "Failure",
+              "children": {
+                "Error": {"value": "A volatile double-bit ECC error has occurred on GPU 0."}
+              }
+            },
+            "PCIe": {"value": "Healthy"}
+          }
+        },
+        "1": {
+          "children": {
+            "NVLink": {
+              "value": "Warning",
+              "children": {
+                "Warning": {"value": "Detected more than 100 CRC errors on NVLink 3."}
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "header": ["Health Monitor Report"]
+}`
+
+func TestDCGMI(t *testing.T) {
+	ctx := context.Background()
+
+	var calls []string
+	c := NewDCGMI(DefaultDCGMHost)
+	c.run = func(ctx context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		switch args[0] {
+		case "group":
+			if args[1] == "-c" {
+				return []byte(`Successfully created group "navarch" with a group ID of 3`), nil
+			}
+			return nil, nil
+		case "discovery":
+			return []byte(dcgmiDiscoveryOutput), nil
+		case "dmon":
+			return []byte("#Entity   FBTTL\nID\nGPU " + args[2] + "     81559\n"), nil
+		case "health":
+			if args[3] == "-c" {
+				return []byte(dcgmiHealthOutput), nil
+			}
+			return nil, nil
+		}
+		t.Fatalf("unexpected dcgmi %v", args)
+		return nil, nil
+	}
+
+	if err := c.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if c.group != 3 {
+		t.Errorf("group = %d, want 3", c.group)
+	}
+
+	devices, err := c.Devices(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DeviceInfo{
+		{Index: 0, UUID: "GPU-5f1c0e2a-0000-0000-0000-000000000000", Name: "NVIDIA H100 80GB HBM3", PCIBusID: "00000000:18:00.0", Memory: 81559 * 1024 * 1024},
+		{Index: 1, UUID: "GPU-5f1c0e2a-0000-0000-0000-000000000001", Name: "NVIDIA H100 80GB HBM3", PCIBusID: "00000000:2A:00.0", Memory: 81559 * 1024 * 1024},
+	}
+	if len(devices) != len(want) {
+		t.Fatalf("got %d devices, want %d", len(devices), len(want))
+	}
+	for i := range want {
+		if devices[i] != want[i] {
+			t.Errorf("device %d = %+v, want %+v", i, devices[i], want[i])
+		}
+	}
+
+	calls = nil
+	if err := c.SetHealthWatches(ctx, dcgmHealthWatches); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 || calls[0] != "health -g 3 -s pnmit" {
+		t.Errorf("SetHealthWatches ran %q, want health -g 3 -s pnmit", calls)
+	}
+
+	incidents, err := c.HealthCheck(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantIncidents := []DCGMIncident{
+		{GPUIndex: 0, System: pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM, Failure: true, Message: "A volatile double-bit ECC error has occurred on GPU 0."},
+		{GPUIndex: 1, System: pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_NVLINK, Message: "Detected more than 100 CRC errors on NVLink 3."},
+	}
+	if len(incidents) != len(wantIncidents) {
+		t.Fatalf("got incidents %+v, want %+v", incidents, wantIncidents)
+	}
+	for i := range wantIncidents {
+		if incidents[i] != wantIncidents[i] {
+			t.Errorf("incident %d = %+v, want %+v", i, incidents[i], wantIncidents[i])
+		}
+	}
+}
+
+func TestParseDCGMIDmon(t *testing.T) {
+	fields := []DCGMField{DCGMFieldECCSBEVolatileTotal, DCGMFieldECCDBEVolatileTotal, DCGMFieldPCIeReplayCounter}
+	out := []byte(`#Entity   ECCSB  ECCDB  PCIRP
+ID
+GPU 1     4      N/A    12
+`)
+
+	values, err := parseDCGMIDmon(out, 1, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || values[DCGMFieldECCSBEVolatileTotal] != 4 || values[DCGMFieldPCIeReplayCounter] != 12 {
+		t.Errorf("values = %v, want SBE 4 and PCIe replays 12 without DBE", values)
+	}
+
+	if _, err := parseDCGMIDmon(out, 0, fields); err == nil {
+		t.Error("parsing a sample for a missing GPU succeeded, want error")
+	}
+}
+
+func TestDCGMI_WatchFields(t *testing.T) {
+	ctx := context.Background()
+
+	var calls []string
+	c := NewDCGMI(DefaultDCGMHost)
+	c.group = 3
+	c.run = func(ctx context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, strings.Join(args, " "))
+		switch {
+		case args[0] == "fieldgroup" && args[1] == "-c":
+			return []byte(`Successfully created field group "` + args[2] + `" with a field group ID of 7`), nil
+		case args[0] == "dmon" && args[1] == "-g":
+			return []byte("#Entity   ECCSB  ECCDB\nID\nGPU 0     4      0\nGPU 1     N/A    2\n"), nil
+		case args[0] == "dmon" && args[1] == "-i":
+			return []byte("#Entity   TMPTR\nID\nGPU " + args[2] + "     41\n"), nil
+		}
+		return nil, nil
+	}
+
+	fields := []DCGMField{DCGMFieldECCSBEVolatileTotal, DCGMFieldECCDBEVolatileTotal}
+	if err := c.WatchFields(ctx, "navarch-ecc", fields, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 || calls[0] != "fieldgroup -c navarch-ecc -f 310,311" {
+		t.Errorf("WatchFields ran %q, want fieldgroup -c navarch-ecc -f 310,311", calls)
+	}
+
+	// One dmon call samples the field group for every GPU, and the sample is
+	// reused until the watch interval passes.
+	calls = nil
+	gpu0, err := c.LatestValues(ctx, 0, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gpu1, err := c.LatestValues(ctx, 1, []DCGMField{DCGMFieldECCSBEVolatileTotal, DCGMFieldECCDBEVolatileTotal, DCGMFieldGPUTemp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gpu0[DCGMFieldECCSBEVolatileTotal] != 4 || len(gpu0) != 2 {
+		t.Errorf("GPU 0 values = %v, want SBE 4 and DBE 0", gpu0)
+	}
+	if _, ok := gpu1[DCGMFieldECCSBEVolatileTotal]; ok || gpu1[DCGMFieldECCDBEVolatileTotal] != 2 || gpu1[DCGMFieldGPUTemp] != 41 {
+		t.Errorf("GPU 1 values = %v, want DBE 2 and temperature 41 without SBE", gpu1)
+	}
+	want := []string{"dmon -g 3 -f 7 -c 1", "dmon -i 1 -e 150 -c 1"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("LatestValues ran %q, want %q", calls, want)
+	}
+
+	calls = nil
+	if err := c.Disconnect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"fieldgroup -d -g 7", "group -d 3"}; !slices.Equal(calls, want) {
+		t.Errorf("Disconnect ran %q, want %q", calls, want)
+	}
+}
@@ -141,6 +141,8 @@ func EventTypeString(t pb.HealthEventType) string {
 		return "ecc_sbe"
 	case pb.HealthEventType_HEALTH_EVENT_TYPE_ECC_DBE:
 		return "ecc_dbe"
+	case pb.HealthEventType_HEALTH_EVENT_TYPE_DIAGNOSTIC:
+		return "diagnostic"
 	default:
 		return "unknown"
 	}
@@ -175,3 +177,27 @@ func SystemString(s pb.HealthWatchSystem) string {
 		return "DCGM_HEALTH_WATCH_UNKNOWN"
 	}
 }
+
+// ParseEventType returns the event type for a string returned by
+// EventTypeString. It reports false for unrecognized strings.
+func ParseEventType(s string) (pb.HealthEventType, bool) {
+	for v := range pb.HealthEventType_name {
+		t := pb.HealthEventType(v)
+		if t != pb.HealthEventType_HEALTH_EVENT_TYPE_UNKNOWN && EventTypeString(t) == s {
+			return t, true
+		}
+	}
+	return pb.HealthEventType_HEALTH_EVENT_TYPE_UNKNOWN, false
+}
+
+// ParseSystem returns the health watch system for a string returned by
+// SystemString. It reports false for unrecognized strings.
+func ParseSystem(s string) (pb.HealthWatchSystem, bool) {
+	for v := range pb.HealthWatchSystem_name {
+		sys := pb.HealthWatchSystem(v)
+		if sys != pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_UNKNOWN && SystemString(sys) == s {
+			return sys, true
+		}
+	}
+	return pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_UNKNOWN, false
+}
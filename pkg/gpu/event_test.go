@@ -106,6 +106,7 @@ func TestEventTypeString(t *testing.T) {
 		{pb.HealthEventType_HEALTH_EVENT_TYPE_XID, "xid"},
 		{pb.HealthEventType_HEALTH_EVENT_TYPE_THERMAL, "thermal"},
 		{pb.HealthEventType_HEALTH_EVENT_TYPE_ECC_DBE, "ecc_dbe"},
+		{pb.HealthEventType_HEALTH_EVENT_TYPE_DIAGNOSTIC, "diagnostic"},
 		{pb.HealthEventType_HEALTH_EVENT_TYPE_NVLINK, "nvlink"},
 		{pb.HealthEventType_HEALTH_EVENT_TYPE_UNKNOWN, "unknown"},
 	}
@@ -136,3 +137,29 @@ func TestSystemString(t *testing.T) {
 		}
 	}
 }
+
+func TestParseEventType(t *testing.T) {
+	for v := range pb.HealthEventType_name {
+		eventType := pb.HealthEventType(v)
+		if eventType == pb.HealthEventType_HEALTH_EVENT_TYPE_UNKNOWN {
+			continue
+		}
+		got, ok := ParseEventType(EventTypeString(eventType))
+		if !ok || got != eventType {
+			t.Errorf("ParseEventType(%q) = %v, %v; want %v", EventTypeString(eventType), got, ok, eventType)
+		}
+	}
+	if _, ok := ParseEventType("unknown"); ok {
+		t.Error("ParseEventType(\"unknown\") should not be recognized")
+	}
+}
+
+func TestParseSystem(t *testing.T) {
+	got, ok := ParseSystem("DCGM_HEALTH_WATCH_DRIVER")
+	if !ok || got != pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_DRIVER {
+		t.Errorf("ParseSystem(DCGM_HEALTH_WATCH_DRIVER) = %v, %v", got, ok)
+	}
+	if _, ok := ParseSystem("driver"); ok {
+		t.Error("ParseSystem(\"driver\") should not be recognized")
+	}
+}
@@ -44,3 +44,58 @@ type Manager interface {
 	// These events are sent to the control plane for CEL policy evaluation.
 	CollectHealthEvents(ctx context.Context) ([]HealthEvent, error)
 }
+
+// Resetter is implemented by managers that can reset a single GPU, clearing
+// a hung or faulted state without rebooting the node. A reset fails if
+// processes are still using the GPU.
+type Resetter interface {
+	ResetDevice(ctx context.Context, index int) error
+}
+
+// PCIeLink is the state of a GPU's PCIe link. A link running narrower than
+// its maximum width has trained down, usually because of a bad riser or
+// slot. The generation drops at idle to save power, so a lower generation
+// alone is not a fault.
+type PCIeLink struct {
+	Generation    int
+	MaxGeneration int
+	Width         int
+	MaxWidth      int
+}
+
+// NVLink is the state of one of a GPU's NVLink links.
+type NVLink struct {
+	ID     int
+	Active bool
+}
+
+// MemoryState describes whether a GPU's memory is still fully usable.
+type MemoryState struct {
+	Total uint64
+	Free  uint64
+
+	// RemappedRows is how many rows were remapped after uncorrectable errors.
+	RemappedRows int
+
+	// RemapPending means a row remap takes effect at the next GPU reset.
+	// Until then, allocations may hit the bad row.
+	RemapPending bool
+
+	// RemapFailed means a bad row could not be remapped, so the GPU cannot
+	// be trusted with its full memory.
+	RemapFailed bool
+}
+
+// Inspector is implemented by managers that can report the state of a GPU's
+// links and memory, for active diagnostics.
+type Inspector interface {
+	// GetPCIeLink returns the state of a GPU's PCIe link.
+	GetPCIeLink(ctx context.Context, index int) (*PCIeLink, error)
+
+	// GetNVLinks returns the state of a GPU's NVLink links. It returns no
+	// links for GPUs without NVLink.
+	GetNVLinks(ctx context.Context, index int) ([]NVLink, error)
+
+	// GetMemoryState returns whether a GPU's memory is fully usable.
+	GetMemoryState(ctx context.Context, index int) (*MemoryState, error)
+}
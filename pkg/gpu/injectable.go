@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	pb "github.com/NavarchProject/navarch/proto"
 )
@@ -30,8 +31,26 @@ type injectableDevice struct {
 	info             DeviceInfo
 	baseHealth       HealthInfo
 	temperatureSpike int
+
+	// Faults found by active diagnostics
+	pcieWidth    int          // Trained-down PCIe link width, 0 if none
+	nvlinksDown  map[int]bool // NVLink links that are down
+	remapPending bool
+	remapFailed  bool
+
+	// Error counters and throttle reasons, as sampled by CollectHealthEvents,
+	// and the sample it last reported.
+	counters Counters
+	reported Counters
 }
 
+// Injectable devices have a PCIe 5.0 x16 link and this many NVLink links.
+const (
+	injectablePCIeGeneration = 5
+	injectablePCIeWidth      = 16
+	injectableNVLinks        = 18
+)
+
 // NewInjectable creates a new injectable GPU manager with the specified device count.
 func NewInjectable(deviceCount int, gpuType string) *Injectable {
 	if gpuType == "" {
@@ -158,7 +177,8 @@ func (g *Injectable) GetDeviceHealth(ctx context.Context, index int) (*HealthInf
 	return &health, nil
 }
 
-// CollectHealthEvents returns and clears all pending health events.
+// CollectHealthEvents returns and clears all pending health events, followed
+// by events for injected changes in error counters and throttle reasons.
 func (g *Injectable) CollectHealthEvents(ctx context.Context) ([]HealthEvent, error) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
@@ -175,6 +195,19 @@ func (g *Injectable) CollectHealthEvents(ctx context.Context) ([]HealthEvent, er
 	copy(events, g.healthEvents)
 	g.healthEvents = nil
 
+	now := time.Now()
+	for i, d := range g.devices {
+		cur := d.counters
+		cur.Temperature = d.baseHealth.Temperature
+		if d.temperatureSpike > 0 {
+			cur.Temperature = d.temperatureSpike
+		}
+		cur.RemapPending = d.remapPending
+		cur.RemapFailed = d.remapFailed
+		events = append(events, CounterEvents(now, i, d.info.UUID, d.reported, cur)...)
+		d.reported = cur
+	}
+
 	return events, nil
 }
 
@@ -262,6 +295,223 @@ func (g *Injectable) ClearTemperatureSpike(gpuIndex int) {
 	}
 }
 
+// ResetDevice simulates a GPU reset: the device's injected error, temperature
+// spike, pending row remap and page retirement, volatile ECC counts, and
+// pending health events are cleared.
+func (g *Injectable) ResetDevice(ctx context.Context, index int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.initialized {
+		return errors.New("not initialized")
+	}
+	if g.backendError != nil {
+		return g.backendError
+	}
+	if index < 0 || index >= g.deviceCount {
+		return fmt.Errorf("invalid device index: %d", index)
+	}
+
+	delete(g.deviceErrors, index)
+	g.devices[index].temperatureSpike = 0
+	g.devices[index].remapPending = false
+	g.devices[index].counters.RetirementPending = false
+	g.devices[index].counters.ECCSBEVolatile = 0
+	g.devices[index].counters.ECCDBEVolatile = 0
+	var remaining []HealthEvent
+	for _, e := range g.healthEvents {
+		if e.GPUIndex != index {
+			remaining = append(remaining, e)
+		}
+	}
+	g.healthEvents = remaining
+	return nil
+}
+
+// GetPCIeLink returns the state of a device's PCIe link.
+func (g *Injectable) GetPCIeLink(ctx context.Context, index int) (*PCIeLink, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	d, err := g.inspectDeviceLocked(index)
+	if err != nil {
+		return nil, err
+	}
+
+	link := &PCIeLink{
+		Generation:    injectablePCIeGeneration,
+		MaxGeneration: injectablePCIeGeneration,
+		Width:         injectablePCIeWidth,
+		MaxWidth:      injectablePCIeWidth,
+	}
+	if d.pcieWidth > 0 {
+		link.Width = d.pcieWidth
+	}
+	return link, nil
+}
+
+// GetNVLinks returns the state of a device's NVLink links.
+func (g *Injectable) GetNVLinks(ctx context.Context, index int) ([]NVLink, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	d, err := g.inspectDeviceLocked(index)
+	if err != nil {
+		return nil, err
+	}
+
+	links := make([]NVLink, injectableNVLinks)
+	for i := range links {
+		links[i] = NVLink{ID: i, Active: !d.nvlinksDown[i]}
+	}
+	return links, nil
+}
+
+// GetMemoryState returns whether a device's memory is fully usable.
+func (g *Injectable) GetMemoryState(ctx context.Context, index int) (*MemoryState, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	d, err := g.inspectDeviceLocked(index)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &MemoryState{
+		Total:        d.baseHealth.MemoryTotal,
+		Free:         d.baseHealth.MemoryTotal - d.baseHealth.MemoryUsed,
+		RemapPending: d.remapPending,
+		RemapFailed:  d.remapFailed,
+	}
+	if d.remapPending || d.remapFailed {
+		state.RemappedRows = 1
+	}
+	return state, nil
+}
+
+// inspectDeviceLocked returns a device for inspection, or the error injected
+// for it.
+func (g *Injectable) inspectDeviceLocked(index int) (*injectableDevice, error) {
+	if g.backendError != nil {
+		return nil, g.backendError
+	}
+	if !g.initialized {
+		return nil, errors.New("not initialized")
+	}
+	if err := g.deviceErrors[index]; err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= g.deviceCount {
+		return nil, fmt.Errorf("invalid device index: %d", index)
+	}
+	return g.devices[index], nil
+}
+
+// InjectPCIeDegradation makes a GPU's PCIe link train down to width lanes.
+func (g *Injectable) InjectPCIeDegradation(gpuIndex, width int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if gpuIndex >= 0 && gpuIndex < g.deviceCount {
+		g.devices[gpuIndex].pcieWidth = width
+	}
+}
+
+// InjectNVLinkDown takes one of a GPU's NVLink links down.
+func (g *Injectable) InjectNVLinkDown(gpuIndex, linkID int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if gpuIndex >= 0 && gpuIndex < g.deviceCount {
+		d := g.devices[gpuIndex]
+		if d.nvlinksDown == nil {
+			d.nvlinksDown = make(map[int]bool)
+		}
+		d.nvlinksDown[linkID] = true
+	}
+}
+
+// InjectRowRemap marks a row of a GPU's memory as remapped after an
+// uncorrectable error. If failed, the remap failed; otherwise it is pending
+// until the GPU is reset.
+func (g *Injectable) InjectRowRemap(gpuIndex int, failed bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if gpuIndex >= 0 && gpuIndex < g.deviceCount {
+		g.devices[gpuIndex].counters.RemappedRowsUncorrectable++
+		if failed {
+			g.devices[gpuIndex].remapFailed = true
+		} else {
+			g.devices[gpuIndex].remapPending = true
+		}
+	}
+}
+
+// InjectThrottleReasons sets the reasons a GPU's clocks are throttled, a
+// mask of ThrottleReason* bits. Zero ends throttling.
+func (g *Injectable) InjectThrottleReasons(gpuIndex int, reasons uint64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if gpuIndex >= 0 && gpuIndex < g.deviceCount {
+		g.devices[gpuIndex].counters.ThrottleReasons = reasons
+	}
+}
+
+// InjectECCErrors adds single-bit and double-bit ECC errors to a GPU's
+// volatile and aggregate counters.
+func (g *Injectable) InjectECCErrors(gpuIndex, sbe, dbe int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if gpuIndex >= 0 && gpuIndex < g.deviceCount {
+		c := &g.devices[gpuIndex].counters
+		c.ECCSBEVolatile += uint64(sbe)
+		c.ECCSBEAggregate += uint64(sbe)
+		c.ECCDBEVolatile += uint64(dbe)
+		c.ECCDBEAggregate += uint64(dbe)
+	}
+}
+
+// InjectRetiredPages retires pages of a GPU's memory for single-bit and
+// double-bit ECC errors. The retirement is pending until the GPU is reset.
+func (g *Injectable) InjectRetiredPages(gpuIndex, sbe, dbe int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if gpuIndex >= 0 && gpuIndex < g.deviceCount {
+		c := &g.devices[gpuIndex].counters
+		c.RetiredPagesSBE += uint64(sbe)
+		c.RetiredPagesDBE += uint64(dbe)
+		c.RetirementPending = true
+	}
+}
+
+// InjectPCIeReplays adds to a GPU's PCIe replay counter.
+func (g *Injectable) InjectPCIeReplays(gpuIndex, count int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if gpuIndex >= 0 && gpuIndex < g.deviceCount {
+		g.devices[gpuIndex].counters.PCIeReplays += uint64(count)
+	}
+}
+
+// InjectNVLinkErrors adds to a GPU's NVLink CRC, replay, and recovery error
+// counters.
+func (g *Injectable) InjectNVLinkErrors(gpuIndex, crc, replay, recovery int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if gpuIndex >= 0 && gpuIndex < g.deviceCount {
+		c := &g.devices[gpuIndex].counters
+		c.NVLinkCRCErrors += uint64(crc)
+		c.NVLinkReplayErrors += uint64(replay)
+		c.NVLinkRecoveryErrors += uint64(recovery)
+	}
+}
+
 // InjectBackendError makes all backend operations return an error.
 // This simulates DCGM/driver failures.
 func (g *Injectable) InjectBackendError(err error) {
@@ -350,7 +600,14 @@ func (g *Injectable) ClearAllErrors() {
 	g.bootError = nil
 	g.deviceErrors = make(map[int]error)
 	for i := 0; i < g.deviceCount; i++ {
-		g.devices[i].temperatureSpike = 0
+		d := g.devices[i]
+		d.temperatureSpike = 0
+		d.pcieWidth = 0
+		d.nvlinksDown = nil
+		d.remapPending = false
+		d.remapFailed = false
+		d.counters = Counters{}
+		d.reported = Counters{}
 	}
 }
 
@@ -364,7 +621,8 @@ func (g *Injectable) HasActiveFailures() bool {
 	}
 
 	for _, d := range g.devices {
-		if d.temperatureSpike > 0 {
+		if d.temperatureSpike > 0 || d.pcieWidth > 0 || len(d.nvlinksDown) > 0 || d.remapPending || d.remapFailed ||
+			d.counters != (Counters{}) {
 			return true
 		}
 	}
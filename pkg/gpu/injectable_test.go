@@ -523,6 +523,200 @@ func TestInjectable_DeviceError(t *testing.T) {
 	})
 }
 
+func TestInjectable_ResetDevice(t *testing.T) {
+	ctx := context.Background()
+	g := NewInjectable(4, "")
+
+	if err := g.ResetDevice(ctx, 0); err == nil {
+		t.Error("ResetDevice() before Initialize should fail")
+	}
+	if err := g.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	g.InjectDeviceError(1, errors.New("stuck"))
+	g.InjectTemperatureSpike(1, 95)
+	g.InjectXIDHealthEvent(1, 79, "fallen off the bus")
+	g.InjectXIDHealthEvent(2, 13, "graphics exception")
+
+	if err := g.ResetDevice(ctx, 1); err != nil {
+		t.Fatalf("ResetDevice() error = %v", err)
+	}
+	if _, err := g.GetDeviceInfo(ctx, 1); err != nil {
+		t.Errorf("GetDeviceInfo(1) after reset error = %v", err)
+	}
+	if h, _ := g.GetDeviceHealth(ctx, 1); h.Temperature == 95 {
+		t.Error("temperature spike should be cleared by reset")
+	}
+	events, _ := g.CollectHealthEvents(ctx)
+	if len(events) != 1 || events[0].GPUIndex != 2 {
+		t.Errorf("events after reset = %+v, want only GPU 2's event", events)
+	}
+
+	if err := g.ResetDevice(ctx, 4); err == nil {
+		t.Error("ResetDevice() with invalid index should fail")
+	}
+}
+
+func TestInjectable_Inspect(t *testing.T) {
+	ctx := context.Background()
+	g := NewInjectable(2, "")
+	var _ Inspector = g
+
+	if _, err := g.GetPCIeLink(ctx, 0); err == nil {
+		t.Error("GetPCIeLink() before Initialize should fail")
+	}
+	if err := g.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	link, err := g.GetPCIeLink(ctx, 0)
+	if err != nil || link.Width != link.MaxWidth {
+		t.Fatalf("GetPCIeLink(0) = %+v, %v, want a full-width link", link, err)
+	}
+	links, err := g.GetNVLinks(ctx, 0)
+	if err != nil || len(links) == 0 {
+		t.Fatalf("GetNVLinks(0) = %v, %v, want links", links, err)
+	}
+	for _, l := range links {
+		if !l.Active {
+			t.Errorf("link %d down before injection", l.ID)
+		}
+	}
+
+	g.InjectPCIeDegradation(1, 8)
+	g.InjectNVLinkDown(1, 3)
+	g.InjectRowRemap(1, false)
+
+	if link, _ := g.GetPCIeLink(ctx, 1); link.Width != 8 || link.MaxWidth != 16 {
+		t.Errorf("GetPCIeLink(1) = %+v, want x8 of x16", link)
+	}
+	links, _ = g.GetNVLinks(ctx, 1)
+	if links[3].Active || !links[2].Active {
+		t.Errorf("GetNVLinks(1) = %v, want only link 3 down", links)
+	}
+	if mem, _ := g.GetMemoryState(ctx, 1); !mem.RemapPending || mem.RemapFailed || mem.Total == 0 {
+		t.Errorf("GetMemoryState(1) = %+v, want a pending remap", mem)
+	}
+	if !g.HasActiveFailures() {
+		t.Error("HasActiveFailures() = false with link faults injected")
+	}
+
+	if err := g.ResetDevice(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if mem, _ := g.GetMemoryState(ctx, 1); mem.RemapPending {
+		t.Error("pending remap should be cleared by reset")
+	}
+
+	g.ClearAllErrors()
+	if link, _ := g.GetPCIeLink(ctx, 1); link.Width != link.MaxWidth {
+		t.Errorf("GetPCIeLink(1) after ClearAllErrors = %+v", link)
+	}
+	if g.HasActiveFailures() {
+		t.Error("HasActiveFailures() = true after ClearAllErrors")
+	}
+
+	g.InjectDeviceError(0, errors.New("stuck"))
+	if _, err := g.GetMemoryState(ctx, 0); err == nil {
+		t.Error("GetMemoryState() should return the injected device error")
+	}
+}
+
+func TestInjectable_InjectCounters(t *testing.T) {
+	ctx := context.Background()
+	g := NewInjectable(4, "")
+	if err := g.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	collect := func() map[pb.HealthEventType][]HealthEvent {
+		t.Helper()
+		events, err := g.CollectHealthEvents(ctx)
+		if err != nil {
+			t.Fatalf("CollectHealthEvents() error = %v", err)
+		}
+		byType := make(map[pb.HealthEventType][]HealthEvent)
+		for _, e := range events {
+			byType[e.EventType] = append(byType[e.EventType], e)
+		}
+		return byType
+	}
+
+	g.InjectECCErrors(0, 3, 1)
+	g.InjectRetiredPages(1, 0, 2)
+	g.InjectRowRemap(2, true)
+	g.InjectPCIeReplays(3, 5)
+	g.InjectNVLinkErrors(3, 2, 1, 0)
+	g.InjectTemperatureSpike(1, 92)
+	g.InjectThrottleReasons(1, ThrottleReasonHWSlowdown|ThrottleReasonSWPowerCap)
+
+	events := collect()
+	if e := events[pb.HealthEventType_HEALTH_EVENT_TYPE_ECC_DBE]; len(e) != 1 || e[0].GPUIndex != 0 || e[0].Metrics["ecc_dbe_delta"] != uint64(1) {
+		t.Errorf("ecc_dbe events = %+v, want 1 new error on GPU 0", e)
+	}
+	if e := events[pb.HealthEventType_HEALTH_EVENT_TYPE_ECC_SBE]; len(e) != 1 || e[0].Metrics["ecc_sbe_count"] != uint64(3) {
+		t.Errorf("ecc_sbe events = %+v, want 3 errors on GPU 0", e)
+	}
+	memory := events[pb.HealthEventType_HEALTH_EVENT_TYPE_MEMORY]
+	if len(memory) != 2 {
+		t.Fatalf("memory events = %+v, want retired pages and a failed remap", memory)
+	}
+	if e := memory[0]; e.GPUIndex != 1 || e.Metrics["retired_pages_dbe"] != uint64(2) || e.Metrics["retirement_pending"] != true {
+		t.Errorf("retired pages event = %+v", e)
+	}
+	if e := memory[1]; e.GPUIndex != 2 || e.Metrics["remap_failed"] != true || e.Metrics["remapped_rows"] != uint64(1) {
+		t.Errorf("row remap event = %+v", e)
+	}
+	if e := events[pb.HealthEventType_HEALTH_EVENT_TYPE_PCIE]; len(e) != 1 || e[0].Metrics["pcie_replay_delta"] != uint64(5) {
+		t.Errorf("pcie events = %+v, want 5 replays", e)
+	}
+	if e := events[pb.HealthEventType_HEALTH_EVENT_TYPE_NVLINK]; len(e) != 1 || e[0].Metrics["nvlink_crc_errors"] != uint64(2) {
+		t.Errorf("nvlink events = %+v, want 2 CRC errors", e)
+	}
+	if e := events[pb.HealthEventType_HEALTH_EVENT_TYPE_THERMAL]; len(e) != 1 ||
+		e[0].Metrics["throttle_reasons"] != "hw_slowdown" || e[0].Metrics["temperature"] != 92 {
+		t.Errorf("thermal events = %+v, want HW slowdown at 92C", e)
+	}
+
+	t.Run("reported once", func(t *testing.T) {
+		if events := collect(); len(events) != 0 {
+			t.Errorf("unchanged counters produced events %+v", events)
+		}
+		g.InjectECCErrors(0, 1, 0)
+		if events := collect(); len(events) != 1 || len(events[pb.HealthEventType_HEALTH_EVENT_TYPE_ECC_SBE]) != 1 {
+			t.Errorf("events = %+v, want one ecc_sbe event", events)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		if err := g.ResetDevice(ctx, 0); err != nil {
+			t.Fatal(err)
+		}
+		if events := collect(); len(events) != 0 {
+			t.Errorf("cleared volatile counters produced events %+v", events)
+		}
+		g.InjectECCErrors(0, 0, 1)
+		if e := collect()[pb.HealthEventType_HEALTH_EVENT_TYPE_ECC_DBE]; len(e) != 1 ||
+			e[0].Metrics["ecc_dbe_count"] != uint64(1) || e[0].Metrics["ecc_dbe_aggregate"] != uint64(2) {
+			t.Errorf("ecc_dbe events after reset = %+v, want 1 volatile and 2 aggregate", e)
+		}
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		if !g.HasActiveFailures() {
+			t.Error("HasActiveFailures() = false with counters injected")
+		}
+		g.ClearAllErrors()
+		if g.HasActiveFailures() {
+			t.Error("HasActiveFailures() = true after ClearAllErrors")
+		}
+		if events := collect(); len(events) != 0 {
+			t.Errorf("events after ClearAllErrors = %+v", events)
+		}
+	})
+}
+
 func TestInjectable_ClearAllErrors(t *testing.T) {
 	ctx := context.Background()
 	g := NewInjectable(4, "")
@@ -0,0 +1,480 @@
+//go:build linux
+
+package gpu
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// KernelMessage is a message read from the kernel log.
+type KernelMessage struct {
+	// Time is when the message was logged, or zero if the source does not
+	// record it.
+	Time time.Time
+	Text string
+}
+
+// KernelLogSource reads kernel log messages. A source keeps a cursor just
+// past the last message it returned, so a collector that saves the cursor
+// can resume after a restart without missing or repeating messages.
+type KernelLogSource interface {
+	// Name identifies the kind of source, such as "kmsg". Cursors are only
+	// meaningful to sources with the same name.
+	Name() string
+
+	// Read returns the messages logged since the last Read, or since the
+	// cursor passed to Seek, and advances the cursor past them.
+	Read(ctx context.Context) ([]KernelMessage, error)
+
+	// Cursor returns the position after the last message read, or "" if
+	// nothing has been read.
+	Cursor() string
+
+	// Seek makes the next Read start after the message at cursor.
+	Seek(cursor string) error
+
+	Close() error
+}
+
+// DefaultKmsgPath is the kernel's record-oriented log device.
+const DefaultKmsgPath = "/dev/kmsg"
+
+// kernelLogFiles are the syslog files that kernel messages end up in, for
+// hosts where neither /dev/kmsg nor journald can be read.
+var kernelLogFiles = []string{
+	"/var/log/kern.log", // Debian/Ubuntu
+	"/var/log/messages", // RHEL/CentOS
+	"/var/log/syslog",   // Some systems
+}
+
+// DefaultKernelLogSource returns the best kernel log source on this host:
+// /dev/kmsg if it can be read, then journald if journalctl is installed,
+// then the first syslog file that exists. It returns nil if there is none.
+func DefaultKernelLogSource() KernelLogSource {
+	if f, err := os.Open(DefaultKmsgPath); err == nil {
+		f.Close()
+		return NewKmsgSource(DefaultKmsgPath)
+	}
+	if _, err := exec.LookPath("journalctl"); err == nil {
+		return NewJournalSource()
+	}
+	for _, path := range kernelLogFiles {
+		if _, err := os.Stat(path); err == nil {
+			return NewFileSource(path)
+		}
+	}
+	return nil
+}
+
+// KmsgSource follows the kernel ring buffer through /dev/kmsg. Every record
+// carries a sequence number, and the cursor is the boot ID together with the
+// sequence number of the last record read, so records are skipped exactly
+// when they were read before in the same boot.
+//
+// Records the kernel overwrote before they were read are lost; the ring
+// buffer only holds the most recent messages.
+type KmsgSource struct {
+	path   string
+	bootID string // boot ID of the running kernel
+	fd     int    // -1 until the first Read
+
+	// The cursor: the boot and sequence number of the last record read.
+	cursorBoot string
+	cursorSeq  uint64
+	hasCursor  bool
+}
+
+// NewKmsgSource creates a source reading the ring buffer at path, normally
+// DefaultKmsgPath. The first Read returns every record still in the buffer.
+func NewKmsgSource(path string) *KmsgSource {
+	bootID, _ := os.ReadFile("/proc/sys/kernel/random/boot_id")
+	return &KmsgSource{
+		path:   path,
+		bootID: strings.TrimSpace(string(bootID)),
+		fd:     -1,
+	}
+}
+
+func (s *KmsgSource) Name() string { return "kmsg" }
+
+func (s *KmsgSource) Read(ctx context.Context) ([]KernelMessage, error) {
+	if s.fd < 0 {
+		// Reads must not block once the buffer is drained, so the device is
+		// read with raw non-blocking syscalls rather than through os.File,
+		// which would wait in the runtime poller.
+		fd, err := syscall.Open(s.path, syscall.O_RDONLY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", s.path, err)
+		}
+		s.fd = fd
+	}
+
+	// Each read of /dev/kmsg returns one record, and a record never exceeds
+	// this size.
+	buf := make([]byte, 16*1024)
+	var messages []KernelMessage
+	for ctx.Err() == nil {
+		n, err := syscall.Read(s.fd, buf)
+		switch {
+		case errors.Is(err, syscall.EAGAIN):
+			return messages, nil
+		case errors.Is(err, syscall.EPIPE), errors.Is(err, syscall.EINTR):
+			// EPIPE means records were overwritten before they were read; the
+			// next read returns the oldest record left.
+			continue
+		case err != nil:
+			return messages, fmt.Errorf("read %s: %w", s.path, err)
+		case n == 0:
+			return messages, nil
+		}
+		messages = append(messages, s.parse(buf[:n])...)
+	}
+	return messages, ctx.Err()
+}
+
+// parse parses kmsg records, returning the messages not read before and
+// advancing the cursor. Records have the form
+//
+//	priority,sequence,timestamp,flags[,...];message
+//
+// followed by continuation lines starting with a space, which are skipped.
+func (s *KmsgSource) parse(data []byte) []KernelMessage {
+	var messages []KernelMessage
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" || line[0] == ' ' {
+			continue
+		}
+		header, text, ok := strings.Cut(line, ";")
+		if !ok {
+			continue
+		}
+		fields := strings.Split(header, ",")
+		if len(fields) < 2 {
+			continue
+		}
+		seq, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		if s.hasCursor && s.cursorBoot == s.bootID && seq <= s.cursorSeq {
+			continue
+		}
+		s.cursorBoot, s.cursorSeq, s.hasCursor = s.bootID, seq, true
+		messages = append(messages, KernelMessage{Text: text})
+	}
+	return messages
+}
+
+func (s *KmsgSource) Cursor() string {
+	if !s.hasCursor {
+		return ""
+	}
+	return s.cursorBoot + ":" + strconv.FormatUint(s.cursorSeq, 10)
+}
+
+// Seek takes a cursor of the form "bootID:sequence". A cursor from an
+// earlier boot skips nothing, since every record in the buffer is newer.
+func (s *KmsgSource) Seek(cursor string) error {
+	boot, seqStr, ok := strings.Cut(cursor, ":")
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if !ok || err != nil {
+		return fmt.Errorf("invalid kmsg cursor %q", cursor)
+	}
+	s.cursorBoot, s.cursorSeq, s.hasCursor = boot, seq, true
+	return nil
+}
+
+func (s *KmsgSource) Close() error {
+	if s.fd < 0 {
+		return nil
+	}
+	err := syscall.Close(s.fd)
+	s.fd = -1
+	return err
+}
+
+// JournalSource reads kernel messages from the systemd journal in its
+// export format, using journald's own cursors. Unlike the ring buffer, the
+// journal keeps messages from earlier boots, so messages logged just before
+// a reboot are still read after it.
+type JournalSource struct {
+	cursor string
+
+	// run runs journalctl with the given arguments and returns its output.
+	run func(ctx context.Context, args ...string) ([]byte, error)
+}
+
+// NewJournalSource creates a source reading the journal with journalctl.
+// The first Read returns the kernel messages of the current boot.
+func NewJournalSource() *JournalSource {
+	return &JournalSource{run: runJournalctl}
+}
+
+func runJournalctl(ctx context.Context, args ...string) ([]byte, error) {
+	out, err := exec.CommandContext(ctx, "journalctl", args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("journalctl failed: %w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("running journalctl: %w", err)
+	}
+	return out, nil
+}
+
+func (s *JournalSource) Name() string { return "journald" }
+
+func (s *JournalSource) Read(ctx context.Context) ([]KernelMessage, error) {
+	args := []string{"--output=export", "--no-pager", "--quiet"}
+	if s.cursor != "" {
+		args = append(args, "--after-cursor="+s.cursor)
+	} else {
+		args = append(args, "--boot")
+	}
+	// Match on the transport rather than using --dmesg, which implies --boot
+	// and would drop messages from before a reboot.
+	args = append(args, "_TRANSPORT=kernel")
+
+	out, err := s.run(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := parseJournalExport(out)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []KernelMessage
+	for _, entry := range entries {
+		if cursor := entry["__CURSOR"]; cursor != "" {
+			s.cursor = cursor
+		}
+		msg := KernelMessage{Text: entry["MESSAGE"]}
+		if usec, err := strconv.ParseInt(entry["__REALTIME_TIMESTAMP"], 10, 64); err == nil {
+			msg.Time = time.UnixMicro(usec)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
+
+func (s *JournalSource) Cursor() string { return s.cursor }
+
+func (s *JournalSource) Seek(cursor string) error {
+	s.cursor = cursor
+	return nil
+}
+
+func (s *JournalSource) Close() error { return nil }
+
+// parseJournalExport parses journal entries in the export format: fields of
+// the form KEY=value, one per line, with entries separated by blank lines.
+// Fields whose values are binary or span lines are written as the key on its
+// own line, a little-endian 64-bit length, the value, and a newline.
+func parseJournalExport(data []byte) ([]map[string]string, error) {
+	var entries []map[string]string
+	entry := map[string]string{}
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			return nil, errors.New("journal export: truncated field")
+		}
+		line := data[:i]
+		data = data[i+1:]
+
+		if len(line) == 0 {
+			if len(entry) > 0 {
+				entries = append(entries, entry)
+				entry = map[string]string{}
+			}
+			continue
+		}
+		if key, value, ok := bytes.Cut(line, []byte("=")); ok {
+			entry[string(key)] = string(value)
+			continue
+		}
+		if len(data) < 8 {
+			return nil, fmt.Errorf("journal export: truncated field %s", line)
+		}
+		size := binary.LittleEndian.Uint64(data)
+		data = data[8:]
+		if uint64(len(data)) < size+1 {
+			return nil, fmt.Errorf("journal export: truncated field %s", line)
+		}
+		entry[string(line)] = string(data[:size])
+		data = data[size+1:]
+	}
+	if len(entry) > 0 {
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// FileSource tails a syslog file such as /var/log/kern.log. The cursor is
+// the file's inode and the offset read up to.
+//
+// A file truncated in place is read again from the start. A file rotated by
+// renaming is read to the end before the new file at the path is opened, so
+// nothing logged just before rotation is lost; if the rotation happened
+// while the agent was down, the rest of the old file is read from path.1
+// when its inode matches the cursor.
+type FileSource struct {
+	path   string
+	file   *os.File // open file being read, nil until the first Read
+	inode  uint64
+	offset int64
+}
+
+// NewFileSource creates a source tailing the file at path. The first Read
+// returns the whole file.
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+func (s *FileSource) Name() string { return "file" }
+
+func (s *FileSource) Read(ctx context.Context) ([]KernelMessage, error) {
+	if s.file == nil {
+		if opened, err := s.open(); err != nil || !opened {
+			return nil, err
+		}
+	}
+
+	var messages []KernelMessage
+	for {
+		read, err := s.readLines()
+		messages = append(messages, read...)
+		if err != nil {
+			return messages, err
+		}
+
+		// The open file is drained. If it was rotated away, move on to the
+		// new file at the path.
+		info, err := os.Stat(s.path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return messages, nil
+			}
+			return messages, fmt.Errorf("stat %s: %w", s.path, err)
+		}
+		if inode(info) == s.inode {
+			return messages, nil
+		}
+		s.Close()
+		s.inode, s.offset = 0, 0
+		if opened, err := s.open(); err != nil || !opened {
+			return messages, err
+		}
+	}
+}
+
+// open opens the file at the path, or reports false if there is none. If the
+// cursor points at a file that has since been rotated to path.1, it opens
+// that instead so its rest is read first.
+func (s *FileSource) open() (bool, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("open %s: %w", s.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return false, fmt.Errorf("stat %s: %w", s.path, err)
+	}
+
+	if s.inode != 0 && inode(info) != s.inode {
+		if rotated, err := os.Open(s.path + ".1"); err == nil {
+			if rinfo, err := rotated.Stat(); err == nil && inode(rinfo) == s.inode {
+				f.Close()
+				s.file = rotated
+				return true, nil
+			}
+			rotated.Close()
+		}
+		s.offset = 0
+	}
+	s.file = f
+	s.inode = inode(info)
+	return true, nil
+}
+
+// readLines reads the complete lines after the offset and advances it. A
+// partial last line is left to be read once it is finished.
+func (s *FileSource) readLines() ([]KernelMessage, error) {
+	info, err := s.file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat %s: %w", s.file.Name(), err)
+	}
+	if info.Size() < s.offset {
+		// Truncated in place.
+		s.offset = 0
+	}
+	if _, err := s.file.Seek(s.offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("seek %s: %w", s.file.Name(), err)
+	}
+
+	var messages []KernelMessage
+	r := bufio.NewReader(s.file)
+	for {
+		line, err := r.ReadString('\n')
+		if err == io.EOF {
+			return messages, nil
+		}
+		if err != nil {
+			return messages, fmt.Errorf("read %s: %w", s.file.Name(), err)
+		}
+		s.offset += int64(len(line))
+		messages = append(messages, KernelMessage{Text: strings.TrimSuffix(line, "\n")})
+	}
+}
+
+func (s *FileSource) Cursor() string {
+	if s.inode == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", s.inode, s.offset)
+}
+
+// Seek takes a cursor of the form "inode:offset".
+func (s *FileSource) Seek(cursor string) error {
+	inodeStr, offsetStr, ok := strings.Cut(cursor, ":")
+	ino, err1 := strconv.ParseUint(inodeStr, 10, 64)
+	offset, err2 := strconv.ParseInt(offsetStr, 10, 64)
+	if !ok || err1 != nil || err2 != nil {
+		return fmt.Errorf("invalid file cursor %q", cursor)
+	}
+	s.Close()
+	s.inode, s.offset = ino, offset
+	return nil
+}
+
+func (s *FileSource) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func inode(info os.FileInfo) uint64 {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return st.Ino
+	}
+	return 0
+}
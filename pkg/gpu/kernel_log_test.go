@@ -0,0 +1,229 @@
+//go:build linux
+
+package gpu
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func texts(messages []KernelMessage) []string {
+	var out []string
+	for _, m := range messages {
+		out = append(out, m.Text)
+	}
+	return out
+}
+
+func readTexts(t *testing.T, s KernelLogSource) string {
+	t.Helper()
+	messages, err := s.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	return strings.Join(texts(messages), "|")
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKmsgSource(t *testing.T) {
+	// A regular file stands in for /dev/kmsg; reads return several records
+	// at once instead of one, which parse handles the same way.
+	path := filepath.Join(t.TempDir(), "kmsg")
+	appendFile(t, path, "6,100,5000,-;eth0: link up\n"+
+		"4,101,6000,-;NVRM: Xid (PCI:0000:41:00): 79, pid=123, GPU has fallen off the bus\n"+
+		" SUBSYSTEM=pci\n"+
+		" DEVICE=+pci:0000:41:00.0\n")
+
+	s := NewKmsgSource(path)
+	s.bootID = "boot-a"
+	defer s.Close()
+
+	if got, want := readTexts(t, s), "eth0: link up|NVRM: Xid (PCI:0000:41:00): 79, pid=123, GPU has fallen off the bus"; got != want {
+		t.Errorf("first Read() = %q, want %q", got, want)
+	}
+	if got := s.Cursor(); got != "boot-a:101" {
+		t.Errorf("Cursor() = %q, want boot-a:101", got)
+	}
+
+	appendFile(t, path, "4,102,7000,-;NVRM: Xid (PCI:0000:41:00): 48, DBE\n")
+	if got := readTexts(t, s); got != "NVRM: Xid (PCI:0000:41:00): 48, DBE" {
+		t.Errorf("second Read() = %q, want only the new record", got)
+	}
+
+	t.Run("restart resumes after cursor", func(t *testing.T) {
+		restarted := NewKmsgSource(path)
+		restarted.bootID = "boot-a"
+		defer restarted.Close()
+		if err := restarted.Seek("boot-a:101"); err != nil {
+			t.Fatal(err)
+		}
+		if got := readTexts(t, restarted); got != "NVRM: Xid (PCI:0000:41:00): 48, DBE" {
+			t.Errorf("Read() = %q, want only records after sequence 101", got)
+		}
+	})
+
+	t.Run("cursor from earlier boot", func(t *testing.T) {
+		rebooted := NewKmsgSource(path)
+		rebooted.bootID = "boot-b"
+		defer rebooted.Close()
+		if err := rebooted.Seek("boot-a:200"); err != nil {
+			t.Fatal(err)
+		}
+		messages, err := rebooted.Read(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(messages) != 3 {
+			t.Errorf("Read() returned %d messages, want all 3 from the new boot", len(messages))
+		}
+	})
+
+	if err := s.Seek("not-a-cursor"); err == nil {
+		t.Error("Seek() with an invalid cursor succeeded, want error")
+	}
+}
+
+func exportField(key, value string) string {
+	if !strings.Contains(value, "\n") {
+		return key + "=" + value + "\n"
+	}
+	size := make([]byte, 8)
+	binary.LittleEndian.PutUint64(size, uint64(len(value)))
+	return key + "\n" + string(size) + value + "\n"
+}
+
+func TestJournalSource(t *testing.T) {
+	export := exportField("__CURSOR", "s=abc;i=1") +
+		exportField("__REALTIME_TIMESTAMP", "1700000000000000") +
+		exportField("_TRANSPORT", "kernel") +
+		exportField("MESSAGE", "NVRM: Xid (PCI:0000:41:00): 79, pid=123, GPU has fallen off the bus") +
+		"\n" +
+		exportField("__CURSOR", "s=abc;i=2") +
+		exportField("MESSAGE", "line one\nline two") +
+		"\n"
+
+	var calls [][]string
+	outputs := []string{export, ""}
+	s := NewJournalSource()
+	s.run = func(ctx context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		out := outputs[0]
+		outputs = outputs[1:]
+		return []byte(out), nil
+	}
+
+	messages, err := s.Read(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("Read() returned %d messages, want 2", len(messages))
+	}
+	if !messages[0].Time.Equal(time.UnixMicro(1700000000000000)) {
+		t.Errorf("messages[0].Time = %v, want the realtime timestamp", messages[0].Time)
+	}
+	if messages[1].Text != "line one\nline two" {
+		t.Errorf("messages[1].Text = %q, want the binary-encoded message", messages[1].Text)
+	}
+	if got := s.Cursor(); got != "s=abc;i=2" {
+		t.Errorf("Cursor() = %q, want s=abc;i=2", got)
+	}
+
+	if _, err := s.Read(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	first, second := strings.Join(calls[0], " "), strings.Join(calls[1], " ")
+	if !strings.Contains(first, "--boot") || !strings.Contains(first, "_TRANSPORT=kernel") {
+		t.Errorf("first journalctl call %q, want the current boot's kernel messages", first)
+	}
+	if !strings.Contains(second, "--after-cursor=s=abc;i=2") {
+		t.Errorf("second journalctl call %q, want it to resume after the cursor", second)
+	}
+}
+
+func TestParseJournalExport_Truncated(t *testing.T) {
+	if _, err := parseJournalExport([]byte("MESSAGE\n\x10\x00\x00\x00\x00\x00\x00\x00short")); err == nil {
+		t.Error("parseJournalExport() of a truncated binary field succeeded, want error")
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kern.log")
+	appendFile(t, path, "one\ntwo\nthr")
+
+	s := NewFileSource(path)
+	defer s.Close()
+	if got := readTexts(t, s); got != "one|two" {
+		t.Errorf("first Read() = %q, want the complete lines", got)
+	}
+
+	// The partial line is finished, then the file is rotated by renaming
+	// and more is logged to both files before the next read.
+	appendFile(t, path, "ee\nfour\n")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, "five\n")
+	if got := readTexts(t, s); got != "three|four|five" {
+		t.Errorf("Read() across rotation = %q, want three|four|five", got)
+	}
+	cursor := s.Cursor()
+
+	t.Run("restart resumes after cursor", func(t *testing.T) {
+		appendFile(t, path, "six\n")
+		restarted := NewFileSource(path)
+		defer restarted.Close()
+		if err := restarted.Seek(cursor); err != nil {
+			t.Fatal(err)
+		}
+		if got := readTexts(t, restarted); got != "six" {
+			t.Errorf("Read() = %q, want six", got)
+		}
+	})
+
+	t.Run("rotated while down", func(t *testing.T) {
+		appendFile(t, path, "seven\n")
+		if err := os.Rename(path, path+".1"); err != nil {
+			t.Fatal(err)
+		}
+		appendFile(t, path, "eight\n")
+
+		restarted := NewFileSource(path)
+		defer restarted.Close()
+		if err := restarted.Seek(cursor); err != nil {
+			t.Fatal(err)
+		}
+		if got := readTexts(t, restarted); got != "six|seven|eight" {
+			t.Errorf("Read() = %q, want the rest of the rotated file and the new file", got)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		s := NewFileSource(path)
+		defer s.Close()
+		readTexts(t, s)
+		if err := os.WriteFile(path, []byte("nine\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := readTexts(t, s); got != "nine" {
+			t.Errorf("Read() = %q, want the file read from the start", got)
+		}
+	})
+}
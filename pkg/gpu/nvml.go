@@ -7,7 +7,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"os/exec"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/NVIDIA/go-nvml/pkg/nvml"
 )
@@ -17,8 +21,13 @@ type NVML struct {
 	mu          sync.RWMutex
 	initialized bool
 	devices     []nvml.Device
+	uuids       []string
 
-	// xidCollector monitors for XID errors via NVML events or dmesg fallback.
+	// counters holds each device's error counters as of the last
+	// collection, to find what changed since.
+	counters []Counters
+
+	// xidCollector monitors for XID errors via NVML events or the kernel log.
 	xidCollector *XIDCollector
 
 	// healthEvents stores events collected since the last call to CollectHealthEvents.
@@ -59,12 +68,22 @@ func (m *NVML) Initialize(ctx context.Context) error {
 		m.devices[i] = device
 	}
 
+	// Sample counters now so that errors from before the agent started are
+	// not reported as new.
+	m.uuids = make([]string, count)
+	m.counters = make([]Counters, count)
+	for i, device := range m.devices {
+		m.uuids[i], _ = device.GetUUID()
+		m.counters[i] = sampleCounters(device, Counters{})
+	}
+
 	m.initialized = true
 
 	// Initialize XID collector
 	m.xidCollector = NewXIDCollector()
+	m.xidCollector.SetCursorFile(DefaultXIDCursorFile)
 	if err := m.xidCollector.Initialize(ctx, m.devices); err != nil {
-		slog.Warn("failed to initialize XID collector, falling back to dmesg", "error", err)
+		slog.Warn("failed to initialize XID collector, falling back to the kernel log", "error", err)
 	}
 
 	return nil
@@ -89,6 +108,8 @@ func (m *NVML) Shutdown(ctx context.Context) error {
 	}
 
 	m.devices = nil
+	m.uuids = nil
+	m.counters = nil
 	m.initialized = false
 	return nil
 }
@@ -191,7 +212,9 @@ func (m *NVML) GetDeviceHealth(ctx context.Context, index int) (*HealthInfo, err
 	}, nil
 }
 
-// CollectHealthEvents returns health events since the last collection.
+// CollectHealthEvents returns health events since the last collection: XID
+// errors, events added with AddHealthEvent, and events for changes in each
+// device's error counters and clock throttle reasons.
 func (m *NVML) CollectHealthEvents(ctx context.Context) ([]HealthEvent, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -216,6 +239,13 @@ func (m *NVML) CollectHealthEvents(ctx context.Context) ([]HealthEvent, error) {
 	events = append(events, xidEvents...)
 	m.healthEvents = nil
 
+	now := time.Now()
+	for i, device := range m.devices {
+		cur := sampleCounters(device, m.counters[i])
+		events = append(events, CounterEvents(now, i, m.uuids[i], m.counters[i], cur)...)
+		m.counters[i] = cur
+	}
+
 	return events, nil
 }
 
@@ -227,6 +257,209 @@ func (m *NVML) AddHealthEvent(event HealthEvent) {
 	m.healthEvents = append(m.healthEvents, event)
 }
 
+// ResetDevice resets a GPU with nvidia-smi, since NVML does not expose a
+// full GPU reset. The reset fails if any process is using the GPU.
+func (m *NVML) ResetDevice(ctx context.Context, index int) error {
+	m.mu.RLock()
+	initialized, count := m.initialized, len(m.devices)
+	m.mu.RUnlock()
+
+	if !initialized {
+		return errors.New("not initialized")
+	}
+	if index < 0 || index >= count {
+		return fmt.Errorf("invalid device index: %d", index)
+	}
+
+	out, err := exec.CommandContext(ctx, "nvidia-smi", "--gpu-reset", "-i", strconv.Itoa(index)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("nvidia-smi --gpu-reset failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// GetPCIeLink returns the state of a device's PCIe link.
+func (m *NVML) GetPCIeLink(ctx context.Context, index int) (*PCIeLink, error) {
+	device, err := m.device(index)
+	if err != nil {
+		return nil, err
+	}
+
+	var link PCIeLink
+	for _, q := range []struct {
+		name string
+		get  func() (int, nvml.Return)
+		dst  *int
+	}{
+		{"GetCurrPcieLinkGeneration", device.GetCurrPcieLinkGeneration, &link.Generation},
+		{"GetMaxPcieLinkGeneration", device.GetMaxPcieLinkGeneration, &link.MaxGeneration},
+		{"GetCurrPcieLinkWidth", device.GetCurrPcieLinkWidth, &link.Width},
+		{"GetMaxPcieLinkWidth", device.GetMaxPcieLinkWidth, &link.MaxWidth},
+	} {
+		v, ret := q.get()
+		if ret != nvml.SUCCESS {
+			return nil, fmt.Errorf("%s failed: %v", q.name, nvmlError(ret))
+		}
+		*q.dst = v
+	}
+	return &link, nil
+}
+
+// GetNVLinks returns the state of a device's NVLink links. Links the device
+// does not have are skipped.
+func (m *NVML) GetNVLinks(ctx context.Context, index int) ([]NVLink, error) {
+	device, err := m.device(index)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []NVLink
+	for id := 0; id < nvml.NVLINK_MAX_LINKS; id++ {
+		state, ret := device.GetNvLinkState(id)
+		if ret == nvml.ERROR_NOT_SUPPORTED || ret == nvml.ERROR_INVALID_ARGUMENT {
+			continue
+		}
+		if ret != nvml.SUCCESS {
+			return nil, fmt.Errorf("GetNvLinkState(%d) failed: %v", id, nvmlError(ret))
+		}
+		links = append(links, NVLink{ID: id, Active: state == nvml.FEATURE_ENABLED})
+	}
+	return links, nil
+}
+
+// GetMemoryState returns whether a device's memory is fully usable. GPUs
+// without row remapping report no remapped rows.
+func (m *NVML) GetMemoryState(ctx context.Context, index int) (*MemoryState, error) {
+	device, err := m.device(index)
+	if err != nil {
+		return nil, err
+	}
+
+	memInfo, ret := device.GetMemoryInfo()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("GetMemoryInfo failed: %v", nvmlError(ret))
+	}
+	state := &MemoryState{Total: memInfo.Total, Free: memInfo.Free}
+
+	_, uncorrectable, pending, failed, ret := device.GetRemappedRows()
+	switch ret {
+	case nvml.SUCCESS:
+		state.RemappedRows = uncorrectable
+		state.RemapPending = pending
+		state.RemapFailed = failed
+	case nvml.ERROR_NOT_SUPPORTED:
+	default:
+		return nil, fmt.Errorf("GetRemappedRows failed: %v", nvmlError(ret))
+	}
+	return state, nil
+}
+
+// sampleCounters reads a device's error counters and clock throttle
+// reasons. Counters the device does not support are left as they were in
+// prev, and so are counters that cannot be read, so that a failed read does
+// not look like a reset followed by new errors.
+func sampleCounters(device nvml.Device, prev Counters) Counters {
+	c := prev
+
+	if temp, ret := device.GetTemperature(nvml.TEMPERATURE_GPU); ret == nvml.SUCCESS {
+		c.Temperature = int(temp)
+	}
+	if reasons, ret := device.GetCurrentClocksThrottleReasons(); ret == nvml.SUCCESS {
+		c.ThrottleReasons = reasons
+	}
+
+	for _, q := range []struct {
+		errorType nvml.MemoryErrorType
+		counter   nvml.EccCounterType
+		dst       *uint64
+	}{
+		{nvml.MEMORY_ERROR_TYPE_CORRECTED, nvml.VOLATILE_ECC, &c.ECCSBEVolatile},
+		{nvml.MEMORY_ERROR_TYPE_UNCORRECTED, nvml.VOLATILE_ECC, &c.ECCDBEVolatile},
+		{nvml.MEMORY_ERROR_TYPE_CORRECTED, nvml.AGGREGATE_ECC, &c.ECCSBEAggregate},
+		{nvml.MEMORY_ERROR_TYPE_UNCORRECTED, nvml.AGGREGATE_ECC, &c.ECCDBEAggregate},
+	} {
+		if v, ret := device.GetTotalEccErrors(q.errorType, q.counter); ret == nvml.SUCCESS {
+			*q.dst = v
+		}
+	}
+
+	for _, q := range []struct {
+		cause nvml.PageRetirementCause
+		dst   *uint64
+	}{
+		{nvml.PAGE_RETIREMENT_CAUSE_MULTIPLE_SINGLE_BIT_ECC_ERRORS, &c.RetiredPagesSBE},
+		{nvml.PAGE_RETIREMENT_CAUSE_DOUBLE_BIT_ECC_ERROR, &c.RetiredPagesDBE},
+	} {
+		if pages, ret := device.GetRetiredPages(q.cause); ret == nvml.SUCCESS {
+			*q.dst = uint64(len(pages))
+		}
+	}
+	if pending, ret := device.GetRetiredPagesPendingStatus(); ret == nvml.SUCCESS {
+		c.RetirementPending = pending == nvml.FEATURE_ENABLED
+	}
+
+	if correctable, uncorrectable, pending, failed, ret := device.GetRemappedRows(); ret == nvml.SUCCESS {
+		c.RemappedRowsCorrectable = uint64(correctable)
+		c.RemappedRowsUncorrectable = uint64(uncorrectable)
+		c.RemapPending = pending
+		c.RemapFailed = failed
+	}
+
+	if replays, ret := device.GetPcieReplayCounter(); ret == nvml.SUCCESS {
+		c.PCIeReplays = uint64(replays)
+	}
+
+	// NVLink counters are summed over the device's links, so they are only
+	// updated if every link could be read.
+	var crc, replay, recovery uint64
+	complete := true
+	for link := 0; link < nvml.NVLINK_MAX_LINKS && complete; link++ {
+		if _, ret := device.GetNvLinkState(link); ret != nvml.SUCCESS {
+			continue
+		}
+		for _, q := range []struct {
+			counter nvml.NvLinkErrorCounter
+			dst     *uint64
+		}{
+			{nvml.NVLINK_ERROR_DL_CRC_FLIT, &crc},
+			{nvml.NVLINK_ERROR_DL_CRC_DATA, &crc},
+			{nvml.NVLINK_ERROR_DL_REPLAY, &replay},
+			{nvml.NVLINK_ERROR_DL_RECOVERY, &recovery},
+		} {
+			v, ret := device.GetNvLinkErrorCounter(link, q.counter)
+			if ret == nvml.ERROR_NOT_SUPPORTED {
+				continue
+			}
+			if ret != nvml.SUCCESS {
+				complete = false
+				break
+			}
+			*q.dst += v
+		}
+	}
+	if complete {
+		c.NVLinkCRCErrors = crc
+		c.NVLinkReplayErrors = replay
+		c.NVLinkRecoveryErrors = recovery
+	}
+
+	return c
+}
+
+// device returns the handle of an initialized device.
+func (m *NVML) device(index int) (nvml.Device, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.initialized {
+		return nil, errors.New("not initialized")
+	}
+	if index < 0 || index >= len(m.devices) {
+		return nil, fmt.Errorf("invalid device index: %d", index)
+	}
+	return m.devices[index], nil
+}
+
 // nvmlError converts an NVML return code to an error string.
 func nvmlError(ret nvml.Return) string {
 	return ret.Error()
@@ -295,6 +295,22 @@ func TestNVML_AddHealthEvent(t *testing.T) {
 func TestNVML_ImplementsManager(t *testing.T) {
 	// Compile-time check that NVML implements Manager
 	var _ Manager = (*NVML)(nil)
+	var _ Inspector = (*NVML)(nil)
+}
+
+func TestNVML_InspectNotInitialized(t *testing.T) {
+	ctx := context.Background()
+	m := NewNVML()
+
+	if _, err := m.GetPCIeLink(ctx, 0); err == nil {
+		t.Error("GetPCIeLink() should fail when not initialized")
+	}
+	if _, err := m.GetNVLinks(ctx, 0); err == nil {
+		t.Error("GetNVLinks() should fail when not initialized")
+	}
+	if _, err := m.GetMemoryState(ctx, 0); err == nil {
+		t.Error("GetMemoryState() should fail when not initialized")
+	}
 }
 
 func TestIsNVMLAvailable(t *testing.T) {
@@ -5,9 +5,11 @@ package gpu
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
-	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -18,7 +20,7 @@ import (
 )
 
 // XIDCollector collects NVIDIA XID errors using NVML events.
-// Falls back to dmesg parsing when NVML events are unavailable.
+// Falls back to parsing the kernel log when NVML events are unavailable.
 type XIDCollector struct {
 	mu sync.Mutex
 
@@ -27,29 +29,40 @@ type XIDCollector struct {
 	devices     []nvml.Device
 	deviceUUIDs []string
 
-	// Dmesg fallback
-	logPath      string
-	lastPosition int64
-	pciToIndex   map[string]int
-	pciToUUID    map[string]string
+	// Kernel log fallback
+	source     KernelLogSource
+	pciToIndex map[string]int
+	pciToUUID  map[string]string
+
+	// Kernel log cursor persistence. resumed is set when a saved cursor was
+	// restored.
+	cursorFile   string
+	cursorLoaded bool
+	resumed      bool
 
 	// Collected events buffer
 	events []HealthEvent
 
-	// State
-	running bool
-	cancel  context.CancelFunc
+	// State. nvmlEvents is set when XIDs come from NVML events rather than
+	// the kernel log.
+	running    bool
+	nvmlEvents bool
+	cancel     context.CancelFunc
 }
 
 // XID error pattern in kernel logs:
 // NVRM: Xid (PCI:0000:41:00): 79, pid=12345, GPU has fallen off the bus
 var xidPattern = regexp.MustCompile(`NVRM: Xid \(PCI:([^)]+)\): (\d+)(?:, (.*))?`)
 
+// DefaultXIDCursorFile is where the NVML manager saves the kernel log cursor.
+const DefaultXIDCursorFile = "/var/lib/navarch/xid-cursor.json"
+
 // NewXIDCollector creates a new XID collector.
-// It attempts to use NVML events and falls back to dmesg parsing.
+// It attempts to use NVML events and falls back to the kernel log source
+// returned by DefaultKernelLogSource.
 func NewXIDCollector() *XIDCollector {
 	return &XIDCollector{
-		logPath: findKernelLogPath(),
+		source: DefaultKernelLogSource(),
 	}
 }
 
@@ -91,21 +104,24 @@ func (c *XIDCollector) Initialize(ctx context.Context, devices []nvml.Device) er
 		return nil
 	}
 
-	registered := false
-	for _, device := range devices {
-		// Check if device supports XID events
-		supportedEvents, ret := device.GetSupportedEventTypes()
-		if ret != nvml.SUCCESS {
-			continue
-		}
+	registered := c.listen(func() bool {
+		registered := false
+		for _, device := range devices {
+			// Check if device supports XID events
+			supportedEvents, ret := device.GetSupportedEventTypes()
+			if ret != nvml.SUCCESS {
+				continue
+			}
 
-		if supportedEvents&nvml.EventTypeXidCriticalError != 0 {
-			ret = device.RegisterEvents(nvml.EventTypeXidCriticalError, eventSet)
-			if ret == nvml.SUCCESS {
-				registered = true
+			if supportedEvents&nvml.EventTypeXidCriticalError != 0 {
+				ret = device.RegisterEvents(nvml.EventTypeXidCriticalError, eventSet)
+				if ret == nvml.SUCCESS {
+					registered = true
+				}
 			}
 		}
-	}
+		return registered
+	})
 
 	if !registered {
 		eventSet.Free()
@@ -123,6 +139,31 @@ func (c *XIDCollector) Initialize(ctx context.Context, devices []nvml.Device) er
 	return nil
 }
 
+// listen reads the kernel log up to now and then calls register to start
+// NVML XID events, reporting whether it did. Each XID is reported by exactly
+// one of the two: the log up to registration, after a restart, covers what
+// was logged while the agent was down, and NVML covers everything after.
+// Once NVML events are on, the log is only read to keep the cursor current.
+//
+// Without a saved cursor, the log read here is dropped when NVML events
+// start, as nothing before the agent started is reported. If NVML events
+// cannot start, the log is the only source and everything read is reported.
+func (c *XIDCollector) listen(register func() bool) bool {
+	logEvents, err := c.collectFromLog()
+	if err != nil {
+		slog.Warn("failed to read kernel log", "error", err)
+	}
+	if !register() {
+		c.events = append(c.events, logEvents...)
+		return false
+	}
+	if c.resumed {
+		c.events = append(c.events, logEvents...)
+	}
+	c.nvmlEvents = true
+	return true
+}
+
 // collectEvents runs in the background collecting NVML XID events.
 func (c *XIDCollector) collectEvents(ctx context.Context) {
 	for {
@@ -188,87 +229,137 @@ func (c *XIDCollector) Shutdown() {
 		c.eventSet = nil
 	}
 
+	if c.source != nil {
+		c.source.Close()
+	}
+
 	c.running = false
+	c.nvmlEvents = false
 }
 
 // Collect returns XID events since the last collection.
-// Uses NVML events if available, otherwise falls back to dmesg parsing.
+// Uses NVML events if available, otherwise falls back to the kernel log.
 func (c *XIDCollector) Collect() ([]HealthEvent, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	events := c.events
+	c.events = nil
+
 	// If NVML events are working, return buffered events
-	if c.running && c.eventSet != nil {
-		events := c.events
-		c.events = nil
+	if c.running && c.nvmlEvents {
+		// Keep reading the kernel log so its cursor stays current; NVML
+		// already reported the XIDs in it.
+		if _, err := c.collectFromLog(); err != nil {
+			slog.Warn("failed to read kernel log", "error", err)
+		}
 		return events, nil
 	}
 
-	// Fall back to dmesg parsing
-	return c.collectFromDmesg()
+	logEvents, err := c.collectFromLog()
+	return append(events, logEvents...), err
 }
 
-// collectFromDmesg parses kernel logs for XID errors.
-func (c *XIDCollector) collectFromDmesg() ([]HealthEvent, error) {
-	if c.logPath == "" {
+// collectFromLog parses the kernel log messages since the last collection
+// for XID errors, and saves the source's cursor.
+func (c *XIDCollector) collectFromLog() ([]HealthEvent, error) {
+	if c.source == nil {
 		return nil, nil
 	}
-
-	f, err := os.Open(c.logPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("open kernel log: %w", err)
+	if !c.cursorLoaded {
+		c.cursorLoaded = true
+		c.loadCursor()
 	}
-	defer f.Close()
 
-	info, err := f.Stat()
-	if err != nil {
-		return nil, fmt.Errorf("stat kernel log: %w", err)
-	}
+	before := c.source.Cursor()
+	messages, err := c.source.Read(context.Background())
 
-	// Handle log rotation
-	if info.Size() < c.lastPosition {
-		c.lastPosition = 0
+	var events []HealthEvent
+	for _, msg := range messages {
+		if event := c.parseLine(msg.Text); event != nil {
+			if !msg.Time.IsZero() {
+				event.Timestamp = msg.Time
+			}
+			events = append(events, *event)
+		}
 	}
 
-	if c.lastPosition > 0 {
-		if _, err := f.Seek(c.lastPosition, io.SeekStart); err != nil {
-			return nil, fmt.Errorf("seek kernel log: %w", err)
+	if cursor := c.source.Cursor(); cursor != before {
+		if err := c.saveCursor(cursor); err != nil {
+			slog.Warn("failed to save kernel log cursor", "path", c.cursorFile, "error", err)
 		}
 	}
-
-	events, newPos, err := c.parseLogEntries(f)
 	if err != nil {
-		return nil, err
+		return events, fmt.Errorf("read kernel log: %w", err)
 	}
-
-	c.lastPosition = newPos
 	return events, nil
 }
 
-// parseLogEntries reads log lines and extracts XID events.
-func (c *XIDCollector) parseLogEntries(r io.Reader) ([]HealthEvent, int64, error) {
-	var events []HealthEvent
-	var bytesRead int64
-
-	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
-		line := scanner.Text()
-		bytesRead += int64(len(line)) + 1
+// xidCursor is the cursor file's contents.
+type xidCursor struct {
+	Source string `json:"source"`
+	Cursor string `json:"cursor"`
+}
 
-		event := c.parseLine(line)
-		if event != nil {
-			events = append(events, *event)
+// loadCursor restores the cursor saved in the cursor file, if it belongs to
+// the same kind of source.
+func (c *XIDCollector) loadCursor() {
+	if c.cursorFile == "" {
+		return
+	}
+	data, err := os.ReadFile(c.cursorFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("failed to read kernel log cursor", "path", c.cursorFile, "error", err)
 		}
+		return
+	}
+	var saved xidCursor
+	if err := json.Unmarshal(data, &saved); err != nil {
+		slog.Warn("ignoring invalid kernel log cursor", "path", c.cursorFile, "error", err)
+		return
 	}
+	if saved.Source != c.source.Name() || saved.Cursor == "" {
+		return
+	}
+	if err := c.source.Seek(saved.Cursor); err != nil {
+		slog.Warn("ignoring invalid kernel log cursor", "path", c.cursorFile, "error", err)
+		return
+	}
+	c.resumed = true
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, 0, fmt.Errorf("scan kernel log: %w", err)
+// saveCursor replaces the cursor file. The file is renamed into place so a
+// crash never leaves a partial cursor.
+func (c *XIDCollector) saveCursor(cursor string) error {
+	if c.cursorFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(xidCursor{Source: c.source.Name(), Cursor: cursor})
+	if err != nil {
+		return err
 	}
 
-	return events, c.lastPosition + bytesRead, nil
+	dir := filepath.Dir(c.cursorFile)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".xid-cursor-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.cursorFile)
 }
 
 // parseLine parses a single log line for XID errors.
@@ -341,33 +432,40 @@ func normalizePCIID(pciID string) string {
 	return strings.ToLower(pciID)
 }
 
-// findKernelLogPath returns the path to the kernel log file.
-// Prefers /dev/kmsg for real-time kernel messages (like gpud does),
-// falls back to log files.
-func findKernelLogPath() string {
-	paths := []string{
-		"/dev/kmsg",         // Real-time kernel ring buffer (preferred)
-		"/var/log/kern.log", // Debian/Ubuntu
-		"/var/log/messages", // RHEL/CentOS
-		"/var/log/syslog",   // Some systems
-	}
-	for _, p := range paths {
-		if _, err := os.Stat(p); err == nil {
-			return p
-		}
+// SetLogPath makes the collector tail the kernel log file at path, or read
+// no kernel log if path is empty.
+func (c *XIDCollector) SetLogPath(path string) {
+	if path == "" {
+		c.SetLogSource(nil)
+		return
 	}
-	return ""
+	c.SetLogSource(NewFileSource(path))
 }
 
-// SetLogPath sets the log path for testing.
-func (c *XIDCollector) SetLogPath(path string) {
+// SetLogSource sets the kernel log source read when NVML events are
+// unavailable, closing the previous one.
+func (c *XIDCollector) SetLogSource(source KernelLogSource) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.source != nil {
+		c.source.Close()
+	}
+	c.source = source
+	c.cursorLoaded = false
+}
+
+// SetCursorFile sets the file the kernel log cursor is saved to, so that a
+// restarted collector resumes where the last one stopped: messages logged
+// while it was down are reported, and messages already reported are not.
+// The cursor is not saved if path is empty, the default.
+func (c *XIDCollector) SetCursorFile(path string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.logPath = path
-	c.lastPosition = 0
+	c.cursorFile = path
+	c.cursorLoaded = false
 }
 
-// SetPCIMappings sets the PCI to GPU mappings for dmesg fallback.
+// SetPCIMappings sets the PCI to GPU mappings for the kernel log fallback.
 func (c *XIDCollector) SetPCIMappings(pciToIndex map[string]int, pciToUUID map[string]string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+
 	pb "github.com/NavarchProject/navarch/proto"
 )
 
@@ -513,3 +515,108 @@ func TestRecentXIDFilter(t *testing.T) {
 		t.Errorf("second filtered event xid_code = %v, want 3", filtered[1].Metrics["xid_code"])
 	}
 }
+
+func TestXIDCollector_CursorFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	logPath := filepath.Join(tmpDir, "kern.log")
+	cursorFile := filepath.Join(tmpDir, "state", "xid-cursor.json")
+
+	if err := os.WriteFile(logPath, []byte("kernel: NVRM: Xid (PCI:0000:41:00): 79, pid=123, GPU has fallen off the bus\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	collector := NewXIDCollector()
+	collector.SetLogPath(logPath)
+	collector.SetCursorFile(cursorFile)
+	events, err := collector.Collect()
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Collect() returned %d events, want 1", len(events))
+	}
+	collector.Shutdown()
+
+	// An XID logged while the agent is down is reported after the restart,
+	// and the one already reported is not.
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("kernel: NVRM: Xid (PCI:0000:41:00): 48, DBE\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	restarted := NewXIDCollector()
+	restarted.SetLogPath(logPath)
+	restarted.SetCursorFile(cursorFile)
+	events, err = restarted.Collect()
+	if err != nil {
+		t.Fatalf("Collect() after restart error = %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Collect() after restart returned %d events, want 1", len(events))
+	}
+	if xid, ok := events[0].Metrics["xid_code"].(int); !ok || xid != 48 {
+		t.Errorf("events[0].Metrics[xid_code] = %v, want 48", events[0].Metrics["xid_code"])
+	}
+}
+
+func TestXIDCollector_CatchUpBeforeNVMLEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+	logPath := filepath.Join(tmpDir, "kern.log")
+	cursorFile := filepath.Join(tmpDir, "xid-cursor.json")
+	appendLog := func(line string) {
+		t.Helper()
+		f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(line + "\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A previous run read the log up to here.
+	appendLog("kernel: NVRM: Xid (PCI:0000:41:00): 13, pid=1, Graphics Engine Exception")
+	previous := NewXIDCollector()
+	previous.SetLogPath(logPath)
+	previous.SetCursorFile(cursorFile)
+	if _, err := previous.Collect(); err != nil {
+		t.Fatal(err)
+	}
+
+	// XID 48 is logged while the agent is down, and XID 79 once NVML is
+	// listening again. NVML reports 79, so the log must report only 48.
+	appendLog("kernel: NVRM: Xid (PCI:0000:41:00): 48, DBE")
+	collector := NewXIDCollector()
+	collector.SetLogPath(logPath)
+	collector.SetCursorFile(cursorFile)
+	collector.mu.Lock()
+	registered := collector.listen(func() bool {
+		appendLog("kernel: NVRM: Xid (PCI:0000:41:00): 79, pid=123, GPU has fallen off the bus")
+		return true
+	})
+	collector.running = true
+	collector.mu.Unlock()
+	if !registered {
+		t.Fatal("listen() = false, want true")
+	}
+	collector.handleXIDEvent(nvml.EventData{EventType: nvml.EventTypeXidCriticalError, EventData: 79})
+
+	var codes []int
+	for i := 0; i < 2; i++ {
+		events, err := collector.Collect()
+		if err != nil {
+			t.Fatalf("Collect() error = %v", err)
+		}
+		for _, e := range events {
+			codes = append(codes, e.Metrics["xid_code"].(int))
+		}
+	}
+	if len(codes) != 2 || codes[0] != 48 || codes[1] != 79 {
+		t.Errorf("reported XIDs %v, want [48 79]", codes)
+	}
+}
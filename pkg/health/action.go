@@ -0,0 +1,115 @@
+package health
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ActionType names something the control plane does when a rule matches, in
+// addition to classifying the node.
+type ActionType string
+
+const (
+	// ActionCordon stops new workloads from being scheduled on the node.
+	ActionCordon ActionType = "cordon"
+
+	// ActionDrain cordons the node and drains its workloads.
+	ActionDrain ActionType = "drain"
+
+	// ActionRunDiagnostic asks the node agent to run a diagnostic. The
+	// action's parameters are passed to the command, e.g. test: memory.
+	ActionRunDiagnostic ActionType = "run_diagnostic"
+
+	// ActionResetGPU asks the node agent to reset the GPU that raised the
+	// matching event.
+	ActionResetGPU ActionType = "reset_gpu"
+
+	// ActionNotifyOnly reports the match to the notifier without changing
+	// the node.
+	ActionNotifyOnly ActionType = "notify_only"
+)
+
+// ActionTypes lists every supported action type.
+var ActionTypes = []ActionType{ActionCordon, ActionDrain, ActionRunDiagnostic, ActionResetGPU, ActionNotifyOnly}
+
+// Action is an action declared by a rule. In YAML an action is either its type
+// alone or a mapping with parameters:
+//
+//	actions:
+//	  - cordon
+//	  - type: run_diagnostic
+//	    parameters:
+//	      test: memory
+type Action struct {
+	Type ActionType `yaml:"type"`
+
+	// Parameters are passed to the node command the action issues.
+	Parameters map[string]string `yaml:"parameters,omitempty"`
+}
+
+// UnmarshalYAML accepts the short form of an action, its type as a string.
+func (a *Action) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		a.Type = ActionType(node.Value)
+		return nil
+	}
+	type plain Action
+	return node.Decode((*plain)(a))
+}
+
+// ActionLimit caps how often an action runs across the whole fleet: at most
+// Max times in any Window. The same action also runs at most once per node
+// (per GPU for reset_gpu) in that window.
+type ActionLimit struct {
+	Max    int           `yaml:"max"`
+	Window time.Duration `yaml:"window"`
+}
+
+// DefaultActionLimits are the limits used for actions the policy does not set
+// a limit for. They are deliberately low for actions that take capacity away.
+var DefaultActionLimits = map[ActionType]ActionLimit{
+	ActionCordon:        {Max: 5, Window: time.Hour},
+	ActionDrain:         {Max: 3, Window: time.Hour},
+	ActionRunDiagnostic: {Max: 20, Window: time.Hour},
+	ActionResetGPU:      {Max: 10, Window: time.Hour},
+	ActionNotifyOnly:    {Max: 100, Window: time.Hour},
+}
+
+// ActionLimit returns the limit for an action type: the policy's own limit if
+// it sets one, otherwise the default.
+func (p *Policy) ActionLimit(t ActionType) ActionLimit {
+	if limit, ok := p.ActionLimits[t]; ok {
+		return limit
+	}
+	return DefaultActionLimits[t]
+}
+
+func validActionType(t ActionType) bool {
+	for _, known := range ActionTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+func validateActions(p *Policy) error {
+	for _, rule := range p.Rules {
+		for _, action := range rule.Actions {
+			if !validActionType(action.Type) {
+				return fmt.Errorf("rule %q: unknown action %q (must be one of %v)", rule.Name, action.Type, ActionTypes)
+			}
+		}
+	}
+	for t, limit := range p.ActionLimits {
+		if !validActionType(t) {
+			return fmt.Errorf("action_limits: unknown action %q", t)
+		}
+		if limit.Max <= 0 || limit.Window <= 0 {
+			return fmt.Errorf("action_limits.%s: max and window must be positive", t)
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package health
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -13,12 +14,20 @@ import (
 	"github.com/NavarchProject/navarch/pkg/gpu"
 )
 
+// ErrNoPreviousPolicy is returned by Rollback when the policy has never been
+// updated.
+var ErrNoPreviousPolicy = errors.New("no previous policy to roll back to")
+
 // Evaluator evaluates health events against a policy using CEL.
 type Evaluator struct {
 	policy   *Policy
 	env      *cel.Env
 	programs map[string]cel.Program
 	mu       sync.RWMutex
+
+	// The policy replaced by the last UpdatePolicy, kept for Rollback.
+	previous         *Policy
+	previousPrograms map[string]cel.Program
 }
 
 // EvaluationResult contains the outcome of evaluating events against a policy.
@@ -42,32 +51,37 @@ type EvaluationResult struct {
 type RuleMatch struct {
 	Rule  string
 	Event gpu.HealthEvent
+
+	// EventIndex is the index of Event in the evaluated events.
+	EventIndex int
+
+	// Result is the matching rule's result.
+	Result Result
+
+	// GPUScoped is true if Result applies to the event's GPU rather than
+	// the node: the rule has ScopeGPU and the event names a GPU.
+	GPUScoped bool
+
+	// Actions are the matching rule's actions.
+	Actions []Action
 }
 
 // NewEvaluator creates a new health policy evaluator.
 func NewEvaluator(policy *Policy) (*Evaluator, error) {
-	// Create CEL environment with the event variable
+	// Create CEL environment with the event variable and the event history
 	env, err := cel.NewEnv(
 		cel.Variable("event", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("history", cel.ListType(cel.DynType)),
+		cel.Variable("gpu_history", cel.ListType(cel.DynType)),
+		historyFunctions(),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("create CEL environment: %w", err)
 	}
 
-	// Compile all rule conditions
-	programs := make(map[string]cel.Program)
-	for _, rule := range policy.Rules {
-		ast, issues := env.Compile(rule.Condition)
-		if issues != nil && issues.Err() != nil {
-			return nil, fmt.Errorf("compile rule %q: %w", rule.Name, issues.Err())
-		}
-
-		program, err := env.Program(ast)
-		if err != nil {
-			return nil, fmt.Errorf("create program for rule %q: %w", rule.Name, err)
-		}
-
-		programs[rule.Name] = program
+	programs, err := compilePolicy(env, policy)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Evaluator{
@@ -78,8 +92,16 @@ func NewEvaluator(policy *Policy) (*Evaluator, error) {
 }
 
 // Evaluate evaluates a set of health events against the policy.
-// Returns the worst health status among all events.
+// Returns the worst health status among all events. Rules that use history
+// see only the earlier events of the same set.
 func (e *Evaluator) Evaluate(ctx context.Context, events []gpu.HealthEvent) (*EvaluationResult, error) {
+	return e.EvaluateWithHistory(ctx, events, nil)
+}
+
+// EvaluateWithHistory evaluates a set of health events like Evaluate, with
+// past as the node's earlier events. Each event is evaluated with a history
+// of the past events and the events up to it in the set.
+func (e *Evaluator) EvaluateWithHistory(ctx context.Context, events []gpu.HealthEvent, past []gpu.HealthEvent) (*EvaluationResult, error) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -100,32 +122,46 @@ func (e *Evaluator) Evaluate(ctx context.Context, events []gpu.HealthEvent) (*Ev
 	var worstRule string
 	var worstEvent *gpu.HealthEvent
 
+	seen := make([]gpu.HealthEvent, 0, len(past)+len(events))
+	seen = append(seen, past...)
+
 	// Evaluate each event against all rules
 	for i := range events {
 		event := &events[i]
-		eventMap := eventToMap(event)
+		seen = append(seen, *event)
+		vars := evalVars(event, seen)
 
 		// Find the first matching rule for this event
 		for _, rule := range sortedRules {
 			program := e.programs[rule.Name]
 
-			out, _, err := program.Eval(map[string]any{
-				"event": eventMap,
-			})
+			out, _, err := program.Eval(vars)
 			if err != nil {
 				// Log but continue - don't fail evaluation on single rule error
 				continue
 			}
 
 			if out.Type() == types.BoolType && out.Value().(bool) {
+				gpuScoped := rule.Scope == ScopeGPU && event.GPUIndex >= 0
 				result.AllMatches = append(result.AllMatches, RuleMatch{
-					Rule:  rule.Name,
-					Event: *event,
+					Rule:       rule.Name,
+					Event:      *event,
+					EventIndex: i,
+					Result:     rule.Result,
+					GPUScoped:  gpuScoped,
+					Actions:    rule.Actions,
 				})
 
+				// A failed GPU only degrades the node; whether enough
+				// healthy GPUs remain is up to the control plane.
+				nodeResult := rule.Result
+				if gpuScoped && nodeResult == ResultUnhealthy {
+					nodeResult = ResultDegraded
+				}
+
 				// Update worst status if this is worse
-				if isWorse(rule.Result, worstStatus) {
-					worstStatus = rule.Result
+				if isWorse(nodeResult, worstStatus) {
+					worstStatus = nodeResult
 					worstRule = rule.Name
 					worstEvent = event
 				}
@@ -148,15 +184,13 @@ func (e *Evaluator) EvaluateSingle(ctx context.Context, event gpu.HealthEvent) (
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	eventMap := eventToMap(&event)
+	vars := evalVars(&event, []gpu.HealthEvent{event})
 	sortedRules := e.policy.SortedRules()
 
 	for _, rule := range sortedRules {
 		program := e.programs[rule.Name]
 
-		out, _, err := program.Eval(map[string]any{
-			"event": eventMap,
-		})
+		out, _, err := program.Eval(vars)
 		if err != nil {
 			continue
 		}
@@ -170,26 +204,55 @@ func (e *Evaluator) EvaluateSingle(ctx context.Context, event gpu.HealthEvent) (
 	return ResultHealthy, "", nil
 }
 
-// UpdatePolicy replaces the current policy with a new one.
-func (e *Evaluator) UpdatePolicy(policy *Policy) error {
-	// Compile the new policy first
+// compilePolicy compiles every rule condition into a CEL program.
+func compilePolicy(env *cel.Env, policy *Policy) (map[string]cel.Program, error) {
 	programs := make(map[string]cel.Program)
 	for _, rule := range policy.Rules {
-		ast, issues := e.env.Compile(rule.Condition)
+		ast, issues := env.Compile(rule.Condition)
 		if issues != nil && issues.Err() != nil {
-			return fmt.Errorf("compile rule %q: %w", rule.Name, issues.Err())
+			return nil, fmt.Errorf("compile rule %q: %w", rule.Name, issues.Err())
+		}
+		// Conditions on dynamic fields are only known to be boolean at
+		// evaluation time; anything else can never match.
+		if out := ast.OutputType(); !out.IsExactType(cel.BoolType) && !out.IsExactType(cel.DynType) {
+			return nil, fmt.Errorf("compile rule %q: condition must be a boolean expression, got %s", rule.Name, out)
 		}
 
-		program, err := e.env.Program(ast)
+		program, err := env.Program(ast)
 		if err != nil {
-			return fmt.Errorf("create program for rule %q: %w", rule.Name, err)
+			return nil, fmt.Errorf("create program for rule %q: %w", rule.Name, err)
 		}
 
 		programs[rule.Name] = program
 	}
+	return programs, nil
+}
+
+// Validate checks that a policy is well-formed and that every rule condition
+// compiles, without changing the current policy.
+func (e *Evaluator) Validate(policy *Policy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	_, err := compilePolicy(e.env, policy)
+	return err
+}
+
+// UpdatePolicy replaces the current policy with a new one. The new policy is
+// validated and compiled first; on error the current policy stays in effect.
+// The replaced policy is kept for Rollback.
+func (e *Evaluator) UpdatePolicy(policy *Policy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	programs, err := compilePolicy(e.env, policy)
+	if err != nil {
+		return err
+	}
 
 	// Swap atomically
 	e.mu.Lock()
+	e.previous, e.previousPrograms = e.policy, e.programs
 	e.policy = policy
 	e.programs = programs
 	e.mu.Unlock()
@@ -197,6 +260,29 @@ func (e *Evaluator) UpdatePolicy(policy *Policy) error {
 	return nil
 }
 
+// Rollback restores the policy that was in effect before the last
+// UpdatePolicy. The policy it replaces becomes the new rollback target, so a
+// second Rollback undoes the first.
+func (e *Evaluator) Rollback() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.previous == nil {
+		return ErrNoPreviousPolicy
+	}
+	e.policy, e.previous = e.previous, e.policy
+	e.programs, e.previousPrograms = e.previousPrograms, e.programs
+	return nil
+}
+
+// PreviousPolicy returns the policy Rollback would restore, or nil if the
+// policy has never been updated.
+func (e *Evaluator) PreviousPolicy() *Policy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.previous
+}
+
 // Policy returns the current policy.
 func (e *Evaluator) Policy() *Policy {
 	e.mu.RLock()
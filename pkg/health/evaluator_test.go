@@ -2,7 +2,9 @@ package health
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/NavarchProject/navarch/pkg/gpu"
 	pb "github.com/NavarchProject/navarch/proto"
@@ -120,6 +122,125 @@ func TestEvaluator_Evaluate_ThermalCritical(t *testing.T) {
 	}
 }
 
+func TestEvaluator_Evaluate_DiagnosticEvents(t *testing.T) {
+	eval, _ := NewEvaluator(DefaultPolicy())
+	ctx := context.Background()
+
+	memory := func(metrics map[string]any) gpu.HealthEvent {
+		return gpu.HealthEvent{
+			EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_MEMORY,
+			System:    pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM,
+			Metrics:   metrics,
+		}
+	}
+	tests := []struct {
+		name       string
+		event      gpu.HealthEvent
+		wantStatus Result
+		wantRule   string
+	}{
+		{
+			name:       "remap failed",
+			event:      memory(map[string]any{"remap_failed": true, "remap_pending": false}),
+			wantStatus: ResultUnhealthy,
+			wantRule:   "memory-remap-failure",
+		},
+		{
+			name:       "remap pending",
+			event:      memory(map[string]any{"remap_failed": false, "remap_pending": true}),
+			wantStatus: ResultDegraded,
+			wantRule:   "memory-warning",
+		},
+		{
+			name: "diagnostic failed",
+			event: gpu.HealthEvent{
+				GPUIndex:  -1,
+				EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_DIAGNOSTIC,
+				Metrics:   map[string]any{"diagnostic": "dcgm", "exit_code": 226},
+			},
+			wantStatus: ResultUnhealthy,
+			wantRule:   "diagnostic-failure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval.Evaluate(ctx, []gpu.HealthEvent{tt.event})
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if result.Status != tt.wantStatus || result.MatchedRule != tt.wantRule {
+				t.Errorf("got %v (rule %q), want %v (rule %q)", result.Status, result.MatchedRule, tt.wantStatus, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestEvaluator_Evaluate_DCGMEvents(t *testing.T) {
+	eval, _ := NewEvaluator(DefaultPolicy())
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		event      gpu.HealthEvent
+		wantStatus Result
+		wantRule   string
+	}{
+		{
+			name: "health failure",
+			event: gpu.HealthEvent{
+				EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_THERMAL,
+				System:    pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_THERMAL,
+				Metrics:   map[string]any{"dcgm_health": "failure", "dcgm_error_code": 0},
+			},
+			wantStatus: ResultUnhealthy,
+			wantRule:   "dcgm-health-failure",
+		},
+		{
+			name: "health warning",
+			event: gpu.HealthEvent{
+				EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_MEMORY,
+				System:    pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM,
+				Metrics:   map[string]any{"dcgm_health": "warning", "dcgm_error_code": 0},
+			},
+			wantStatus: ResultDegraded,
+			wantRule:   "memory-warning",
+		},
+		{
+			name: "new double-bit ECC errors",
+			event: gpu.CounterEvents(time.Now(), 0, "GPU-0",
+				gpu.Counters{}, gpu.Counters{ECCDBEVolatile: 1, ECCDBEAggregate: 1})[0],
+			wantStatus: ResultUnhealthy,
+			wantRule:   "ecc-dbe",
+		},
+		{
+			name: "new single-bit ECC errors",
+			event: gpu.CounterEvents(time.Now(), 0, "GPU-0",
+				gpu.Counters{}, gpu.Counters{ECCSBEVolatile: 3})[0],
+			wantStatus: ResultHealthy,
+		},
+		{
+			name: "power brake",
+			event: gpu.CounterEvents(time.Now(), 0, "GPU-0",
+				gpu.Counters{}, gpu.Counters{ThrottleReasons: gpu.ThrottleReasonHWPowerBrake})[0],
+			wantStatus: ResultDegraded,
+			wantRule:   "power-warning",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval.Evaluate(ctx, []gpu.HealthEvent{tt.event})
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if result.Status != tt.wantStatus || result.MatchedRule != tt.wantRule {
+				t.Errorf("got %v (rule %q), want %v (rule %q)", result.Status, result.MatchedRule, tt.wantStatus, tt.wantRule)
+			}
+		})
+	}
+}
+
 func TestEvaluator_Evaluate_ThermalWarning(t *testing.T) {
 	eval, _ := NewEvaluator(DefaultPolicy())
 	ctx := context.Background()
@@ -361,3 +482,111 @@ func TestIsWorse(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluator_Validate(t *testing.T) {
+	eval, _ := NewEvaluator(DefaultPolicy())
+
+	tests := []struct {
+		name    string
+		rules   []Rule
+		wantErr bool
+	}{
+		{"valid", []Rule{{Name: "xid", Condition: `event.event_type == "xid"`, Result: ResultUnhealthy}}, false},
+		{"dynamic_field", []Rule{{Name: "flag", Condition: `event.metrics.fatal`, Result: ResultUnhealthy}}, false},
+		{"syntax_error", []Rule{{Name: "bad", Condition: "event.[invalid", Result: ResultHealthy}}, true},
+		{"not_boolean", []Rule{{Name: "sum", Condition: "1 + 1", Result: ResultHealthy}}, true},
+		{"invalid_result", []Rule{{Name: "r", Condition: "true", Result: "broken"}}, true},
+		{"no_rules", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := eval.Validate(&Policy{Rules: tt.rules})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if eval.PreviousPolicy() != nil {
+		t.Error("Validate() should not change the policy")
+	}
+}
+
+func TestEvaluator_Rollback(t *testing.T) {
+	original := DefaultPolicy()
+	eval, _ := NewEvaluator(original)
+	ctx := context.Background()
+	event := gpu.NewXIDEvent(0, "GPU-0", 79, "Fatal XID")
+
+	if err := eval.Rollback(); !errors.Is(err, ErrNoPreviousPolicy) {
+		t.Errorf("Rollback() error = %v, want ErrNoPreviousPolicy", err)
+	}
+
+	lenient := &Policy{Rules: []Rule{{Name: "default", Condition: "true", Result: ResultHealthy}}}
+	if err := eval.UpdatePolicy(lenient); err != nil {
+		t.Fatalf("UpdatePolicy() error = %v", err)
+	}
+	if eval.PreviousPolicy() != original {
+		t.Error("PreviousPolicy() should return the replaced policy")
+	}
+
+	if err := eval.Rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if eval.Policy() != original {
+		t.Error("Rollback() should restore the original policy")
+	}
+	if result, _, _ := eval.EvaluateSingle(ctx, event); result != ResultUnhealthy {
+		t.Errorf("after rollback: result = %v, want unhealthy", result)
+	}
+
+	// A second rollback undoes the first.
+	if err := eval.Rollback(); err != nil {
+		t.Fatalf("second Rollback() error = %v", err)
+	}
+	if result, _, _ := eval.EvaluateSingle(ctx, event); result != ResultHealthy {
+		t.Errorf("after second rollback: result = %v, want healthy", result)
+	}
+}
+
+func TestEvaluator_Evaluate_GPUScope(t *testing.T) {
+	policy, err := ParsePolicy([]byte(`
+rules:
+  - name: fatal-xid
+    condition: event.event_type == "xid" && event.metrics.xid_code == 79
+    result: unhealthy
+    scope: gpu
+  - name: default
+    condition: "true"
+    result: healthy
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eval, err := NewEvaluator(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	result, err := eval.Evaluate(ctx, []gpu.HealthEvent{gpu.NewXIDEvent(3, "GPU-3", 79, "GPU has fallen off the bus")})
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if result.Status != ResultDegraded || result.MatchedRule != "fatal-xid" {
+		t.Errorf("Status = %v (%s), want degraded (fatal-xid)", result.Status, result.MatchedRule)
+	}
+	if m := result.AllMatches[0]; !m.GPUScoped || m.Result != ResultUnhealthy {
+		t.Errorf("match = %+v, want a GPU-scoped unhealthy match", m)
+	}
+
+	// An event that names no GPU fails the node as a node-scoped rule would.
+	nodeEvent := gpu.NewXIDEvent(-1, "", 79, "GPU has fallen off the bus")
+	result, err = eval.Evaluate(ctx, []gpu.HealthEvent{nodeEvent})
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if result.Status != ResultUnhealthy || result.AllMatches[0].GPUScoped {
+		t.Errorf("Status = %v, GPUScoped = %v, want unhealthy node-scoped match", result.Status, result.AllMatches[0].GPUScoped)
+	}
+}
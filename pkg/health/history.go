@@ -0,0 +1,271 @@
+package health
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"github.com/google/cel-go/common/types/traits"
+
+	"github.com/NavarchProject/navarch/pkg/gpu"
+)
+
+const (
+	// DefaultHistoryWindow is how far back History keeps events by default.
+	DefaultHistoryWindow = time.Hour
+
+	// DefaultHistoryMaxEvents is how many events History keeps per node by
+	// default.
+	DefaultHistoryMaxEvents = 1000
+)
+
+// History keeps a sliding window of recent health events per node, so that
+// rules can look at more than the event being evaluated. Each node's window
+// is bounded by age and by event count.
+type History struct {
+	window    time.Duration
+	maxEvents int
+
+	mu        sync.Mutex
+	nodes     map[string][]gpu.HealthEvent
+	lastPrune time.Time
+}
+
+// NewHistory creates a History that keeps events no older than window and at
+// most maxEvents per node. Zero values use DefaultHistoryWindow and
+// DefaultHistoryMaxEvents.
+func NewHistory(window time.Duration, maxEvents int) *History {
+	if window <= 0 {
+		window = DefaultHistoryWindow
+	}
+	if maxEvents <= 0 {
+		maxEvents = DefaultHistoryMaxEvents
+	}
+	return &History{
+		window:    window,
+		maxEvents: maxEvents,
+		nodes:     make(map[string][]gpu.HealthEvent),
+	}
+}
+
+// Window returns how far back events are kept.
+func (h *History) Window() time.Duration {
+	return h.window
+}
+
+// Events returns a node's events within the window ending at now, oldest
+// first.
+func (h *History) Events(nodeID string, now time.Time) []gpu.HealthEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	events := h.nodes[nodeID]
+	cutoff := now.Add(-h.window)
+	start := sort.Search(len(events), func(i int) bool {
+		return !events[i].Timestamp.Before(cutoff)
+	})
+	return append([]gpu.HealthEvent(nil), events[start:]...)
+}
+
+// Record adds events for a node. Events that have aged out of the window, and
+// the oldest events beyond the per-node limit, are dropped. Nodes with no
+// events left in the window are forgotten, as by Prune.
+func (h *History) Record(nodeID string, events []gpu.HealthEvent, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cutoff := now.Add(-h.window)
+	if len(events) > 0 {
+		merged := append(h.nodes[nodeID], events...)
+		sort.SliceStable(merged, func(i, j int) bool {
+			return merged[i].Timestamp.Before(merged[j].Timestamp)
+		})
+		h.nodes[nodeID] = trimEvents(merged, cutoff, h.maxEvents)
+	}
+	h.pruneLocked(now)
+}
+
+// Prune forgets nodes with no events left in the window ending at now, such
+// as nodes that were replaced. It sweeps at most once per window, so it is
+// cheap to call often.
+func (h *History) Prune(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pruneLocked(now)
+}
+
+func (h *History) pruneLocked(now time.Time) {
+	if now.Sub(h.lastPrune) < h.window {
+		return
+	}
+	h.lastPrune = now
+	cutoff := now.Add(-h.window)
+	for id, nodeEvents := range h.nodes {
+		if trimmed := trimEvents(nodeEvents, cutoff, h.maxEvents); len(trimmed) > 0 {
+			h.nodes[id] = trimmed
+		} else {
+			delete(h.nodes, id)
+		}
+	}
+}
+
+// Forget drops a node's events.
+func (h *History) Forget(nodeID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.nodes, nodeID)
+}
+
+// trimEvents drops events before cutoff and keeps at most maxEvents of the
+// newest. events must be sorted oldest first.
+func trimEvents(events []gpu.HealthEvent, cutoff time.Time, maxEvents int) []gpu.HealthEvent {
+	start := sort.Search(len(events), func(i int) bool {
+		return !events[i].Timestamp.Before(cutoff)
+	})
+	if len(events)-start > maxEvents {
+		start = len(events) - maxEvents
+	}
+	if start == 0 {
+		return events
+	}
+	return append([]gpu.HealthEvent(nil), events[start:]...)
+}
+
+// evalVars returns the CEL variables for evaluating event. seen holds the
+// node's events up to and including event. The history lists are built, in
+// timestamp order, only if a rule refers to them.
+func evalVars(event *gpu.HealthEvent, seen []gpu.HealthEvent) map[string]any {
+	var history, gpuHistory []map[string]any
+	build := func() {
+		if history != nil {
+			return
+		}
+		ordered := make([]*gpu.HealthEvent, len(seen))
+		for i := range seen {
+			ordered[i] = &seen[i]
+		}
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return ordered[i].Timestamp.Before(ordered[j].Timestamp)
+		})
+
+		history = make([]map[string]any, 0, len(seen))
+		gpuHistory = make([]map[string]any, 0, len(seen))
+		for _, past := range ordered {
+			age := event.Timestamp.Sub(past.Timestamp)
+			if age < 0 {
+				continue
+			}
+			m := eventToMap(past)
+			m["age"] = age
+			history = append(history, m)
+			if sameGPU(event, past) {
+				gpuHistory = append(gpuHistory, m)
+			}
+		}
+	}
+
+	return map[string]any{
+		"event": eventToMap(event),
+		"history": func() any {
+			build()
+			return history
+		},
+		"gpu_history": func() any {
+			build()
+			return gpuHistory
+		},
+	}
+}
+
+// sameGPU reports whether two events concern the same GPU, by UUID when both
+// have one and by index otherwise.
+func sameGPU(a, b *gpu.HealthEvent) bool {
+	if a.GPUUUID != "" && b.GPUUUID != "" {
+		return a.GPUUUID == b.GPUUUID
+	}
+	return a.GPUIndex == b.GPUIndex
+}
+
+// historyFunctions declares the CEL functions for working with history
+// lists: within(duration) keeps events no older than the duration, and
+// oldest() and newest() return the first and last event. oldest and newest
+// fail on an empty list, which makes the rule not match.
+func historyFunctions() cel.EnvOption {
+	listType := cel.ListType(cel.DynType)
+	return cel.Lib(historyLib{
+		options: []cel.EnvOption{
+			cel.Function("within",
+				cel.MemberOverload("list_within_duration", []*cel.Type{listType, cel.DurationType}, listType,
+					cel.BinaryBinding(historyWithin),
+				),
+			),
+			cel.Function("oldest",
+				cel.MemberOverload("list_oldest", []*cel.Type{listType}, cel.DynType,
+					cel.UnaryBinding(func(list ref.Val) ref.Val { return historyAt(list, false) }),
+				),
+			),
+			cel.Function("newest",
+				cel.MemberOverload("list_newest", []*cel.Type{listType}, cel.DynType,
+					cel.UnaryBinding(func(list ref.Val) ref.Val { return historyAt(list, true) }),
+				),
+			),
+		},
+	})
+}
+
+type historyLib struct {
+	options []cel.EnvOption
+}
+
+func (l historyLib) CompileOptions() []cel.EnvOption { return l.options }
+
+func (l historyLib) ProgramOptions() []cel.ProgramOption { return nil }
+
+func historyWithin(list, window ref.Val) ref.Val {
+	lister, ok := list.(traits.Lister)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(list)
+	}
+	d, ok := window.(types.Duration)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(window)
+	}
+
+	var kept []ref.Val
+	for it := lister.Iterator(); it.HasNext() == types.True; {
+		item := it.Next()
+		m, ok := item.(traits.Mapper)
+		if !ok {
+			return types.NewErr("within: list element is not an event")
+		}
+		age, found := m.Find(types.String("age"))
+		if !found {
+			return types.NewErr("within: event has no age; use it on history or gpu_history")
+		}
+		if a, ok := age.(types.Duration); ok && a.Duration <= d.Duration {
+			kept = append(kept, item)
+		}
+	}
+	return types.NewRefValList(types.DefaultTypeAdapter, kept)
+}
+
+func historyAt(list ref.Val, newest bool) ref.Val {
+	lister, ok := list.(traits.Lister)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(list)
+	}
+	size, ok := lister.Size().(types.Int)
+	if !ok {
+		return types.MaybeNoSuchOverloadErr(list)
+	}
+	if size == 0 {
+		return types.NewErr("no events in list")
+	}
+	if newest {
+		return lister.Get(size - 1)
+	}
+	return lister.Get(types.Int(0))
+}
@@ -0,0 +1,202 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NavarchProject/navarch/pkg/gpu"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+func TestHistory(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	xid := func(minute int) gpu.HealthEvent {
+		return gpu.NewXIDEventAt(start.Add(time.Duration(minute)*time.Minute), 0, "GPU-0", 13, "")
+	}
+
+	t.Run("window", func(t *testing.T) {
+		h := NewHistory(10*time.Minute, 0)
+		h.Record("node-1", []gpu.HealthEvent{xid(0), xid(5)}, start.Add(5*time.Minute))
+		h.Record("node-1", []gpu.HealthEvent{xid(12)}, start.Add(12*time.Minute))
+
+		got := h.Events("node-1", start.Add(12*time.Minute))
+		if len(got) != 2 || !got[0].Timestamp.Equal(xid(5).Timestamp) {
+			t.Errorf("Events() = %v, want events at minutes 5 and 12", got)
+		}
+		if got := h.Events("node-1", start.Add(20*time.Minute)); len(got) != 1 {
+			t.Errorf("Events() later = %d events, want 1", len(got))
+		}
+		if got := h.Events("node-2", start); len(got) != 0 {
+			t.Errorf("Events() for unknown node = %v", got)
+		}
+	})
+
+	t.Run("max_events", func(t *testing.T) {
+		h := NewHistory(time.Hour, 3)
+		h.Record("node-1", []gpu.HealthEvent{xid(4), xid(1), xid(3), xid(2), xid(0)}, start.Add(5*time.Minute))
+
+		got := h.Events("node-1", start.Add(5*time.Minute))
+		if len(got) != 3 {
+			t.Fatalf("Events() = %d events, want 3", len(got))
+		}
+		for i, minute := range []int{2, 3, 4} {
+			if !got[i].Timestamp.Equal(xid(minute).Timestamp) {
+				t.Errorf("event %d at %v, want minute %d", i, got[i].Timestamp, minute)
+			}
+		}
+	})
+
+	t.Run("forget_and_prune", func(t *testing.T) {
+		h := NewHistory(10*time.Minute, 0)
+		h.Record("node-1", []gpu.HealthEvent{xid(0)}, start)
+		h.Record("node-2", []gpu.HealthEvent{xid(0)}, start)
+		h.Forget("node-1")
+		if got := h.Events("node-1", start); len(got) != 0 {
+			t.Errorf("Events() after Forget = %v", got)
+		}
+
+		h.Record("node-3", []gpu.HealthEvent{xid(30)}, start.Add(30*time.Minute))
+		h.mu.Lock()
+		_, stale := h.nodes["node-2"]
+		h.mu.Unlock()
+		if stale {
+			t.Error("node-2 should have been pruned after its events aged out")
+		}
+
+		// Without further reports, Prune alone drops node-3.
+		h.Prune(start.Add(45 * time.Minute))
+		h.mu.Lock()
+		remaining := len(h.nodes)
+		h.mu.Unlock()
+		if remaining != 0 {
+			t.Errorf("%d nodes left after Prune, want none", remaining)
+		}
+	})
+}
+
+func TestEvaluator_TemporalRules(t *testing.T) {
+	policy, err := ParsePolicy([]byte(`
+rules:
+  - name: repeated-xid-13
+    condition: |
+      event.event_type == "xid" &&
+      gpu_history.within(duration("10m")).filter(h, h.event_type == "xid" && h.metrics.xid_code == 13).size() > 3
+    result: unhealthy
+  - name: sbe-rising
+    condition: |
+      event.event_type == "ecc_sbe" &&
+      event.metrics.ecc_sbe_count - gpu_history.within(duration("1h")).filter(h, h.event_type == "ecc_sbe").oldest().metrics.ecc_sbe_count >= 100
+    result: degraded
+  - name: busy-node
+    condition: history.within(duration("1m")).size() >= 10
+    result: degraded
+  - name: default
+    condition: "true"
+    result: healthy
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eval, err := NewEvaluator(policy)
+	if err != nil {
+		t.Fatalf("NewEvaluator() error = %v", err)
+	}
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	xid13 := func(d time.Duration, gpuIndex int) gpu.HealthEvent {
+		return gpu.NewXIDEventAt(at(d), gpuIndex, "", 13, "")
+	}
+
+	t.Run("count_within_window", func(t *testing.T) {
+		past := []gpu.HealthEvent{xid13(0, 0), xid13(2*time.Minute, 0), xid13(4*time.Minute, 0)}
+
+		result, err := eval.EvaluateWithHistory(ctx, []gpu.HealthEvent{xid13(6*time.Minute, 0)}, past)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != ResultUnhealthy || result.MatchedRule != "repeated-xid-13" {
+			t.Errorf("fourth XID 13 in 10m: got %s (%s), want unhealthy (repeated-xid-13)", result.Status, result.MatchedRule)
+		}
+
+		result, _ = eval.EvaluateWithHistory(ctx, []gpu.HealthEvent{xid13(11*time.Minute, 0)}, past)
+		if result.Status != ResultHealthy {
+			t.Errorf("first XID has aged out: got %s (%s), want healthy", result.Status, result.MatchedRule)
+		}
+
+		result, _ = eval.EvaluateWithHistory(ctx, []gpu.HealthEvent{xid13(6*time.Minute, 1)}, past)
+		if result.Status != ResultHealthy {
+			t.Errorf("XID on another GPU: got %s (%s), want healthy", result.Status, result.MatchedRule)
+		}
+	})
+
+	t.Run("history_within_batch", func(t *testing.T) {
+		batch := []gpu.HealthEvent{xid13(0, 0), xid13(time.Minute, 0), xid13(2*time.Minute, 0), xid13(3*time.Minute, 0)}
+		result, err := eval.Evaluate(ctx, batch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != ResultUnhealthy {
+			t.Errorf("four XID 13 in one report: got %s, want unhealthy", result.Status)
+		}
+	})
+
+	t.Run("metric_rising", func(t *testing.T) {
+		sbe := func(d time.Duration, count int) gpu.HealthEvent {
+			return gpu.NewMemoryEventAt(at(d), 0, "GPU-0", pb.HealthEventType_HEALTH_EVENT_TYPE_ECC_SBE, count, 0, "")
+		}
+		past := []gpu.HealthEvent{sbe(0, 10), sbe(20*time.Minute, 50)}
+
+		result, _ := eval.EvaluateWithHistory(ctx, []gpu.HealthEvent{sbe(40*time.Minute, 150)}, past)
+		if result.Status != ResultDegraded || result.MatchedRule != "sbe-rising" {
+			t.Errorf("SBE +140 in 1h: got %s (%s), want degraded (sbe-rising)", result.Status, result.MatchedRule)
+		}
+		result, _ = eval.EvaluateWithHistory(ctx, []gpu.HealthEvent{sbe(40*time.Minute, 60)}, past)
+		if result.Status != ResultHealthy {
+			t.Errorf("SBE +50 in 1h: got %s (%s), want healthy", result.Status, result.MatchedRule)
+		}
+	})
+
+	t.Run("node_history", func(t *testing.T) {
+		var past []gpu.HealthEvent
+		for i := range 9 {
+			past = append(past, gpu.NewThermalEventAt(at(time.Duration(i)*time.Second), i%8, "", 70, ""))
+		}
+		result, _ := eval.EvaluateWithHistory(ctx, []gpu.HealthEvent{gpu.NewThermalEventAt(at(10*time.Second), 0, "", 70, "")}, past)
+		if result.Status != ResultDegraded || result.MatchedRule != "busy-node" {
+			t.Errorf("10 events in 1m across GPUs: got %s (%s), want degraded (busy-node)", result.Status, result.MatchedRule)
+		}
+	})
+}
+
+func TestEvaluator_HistoryFunctionErrors(t *testing.T) {
+	policy, err := ParsePolicy([]byte(`
+rules:
+  - name: newest-of-empty
+    condition: gpu_history.filter(h, h.event_type == "nvlink").newest().gpu_index == 0
+    result: unhealthy
+  - name: default
+    condition: "true"
+    result: healthy
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eval, err := NewEvaluator(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A rule that fails to evaluate does not match.
+	result, _, err := eval.EvaluateSingle(context.Background(), gpu.NewXIDEvent(0, "GPU-0", 13, ""))
+	if err != nil || result != ResultHealthy {
+		t.Errorf("EvaluateSingle() = %s, %v; want healthy", result, err)
+	}
+
+	if err := eval.Validate(&Policy{Rules: []Rule{
+		{Name: "bad", Condition: `history.within("10m").size() > 0`, Result: ResultUnhealthy},
+	}}); err == nil {
+		t.Error("Validate() should reject within() with a string argument")
+	}
+}
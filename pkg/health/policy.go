@@ -20,6 +20,20 @@ const (
 	ResultUnhealthy Result = "unhealthy"
 )
 
+// Scope is what a rule's result applies to.
+type Scope string
+
+const (
+	// ScopeNode applies the result to the node. It is the default.
+	ScopeNode Scope = "node"
+
+	// ScopeGPU applies the result to the GPU that raised the event. An
+	// unhealthy GPU is quarantined instead of failing the node; the node is
+	// degraded, and is replaced only when too few healthy GPUs remain.
+	// Events that do not name a GPU are classified as with ScopeNode.
+	ScopeGPU Scope = "gpu"
+)
+
 // PolicyFile represents the on-disk format for a health policy configuration.
 type PolicyFile struct {
 	// Version of the policy file format.
@@ -31,6 +45,9 @@ type PolicyFile struct {
 	// Rules define health classification logic. Rules are evaluated in order;
 	// the first matching rule determines the result.
 	Rules []Rule `yaml:"rules"`
+
+	// ActionLimits overrides DefaultActionLimits for rule actions.
+	ActionLimits map[ActionType]ActionLimit `yaml:"action_limits,omitempty"`
 }
 
 // PolicyMetadata contains optional policy metadata.
@@ -43,6 +60,9 @@ type PolicyMetadata struct {
 type Policy struct {
 	// Rules are evaluated in definition order (first match wins).
 	Rules []Rule `yaml:"rules"`
+
+	// ActionLimits overrides DefaultActionLimits for rule actions.
+	ActionLimits map[ActionType]ActionLimit `yaml:"action_limits,omitempty"`
 }
 
 // Rule defines a single health evaluation rule.
@@ -64,6 +84,13 @@ type Rule struct {
 
 	// Result is the health status when this rule matches.
 	Result Result `yaml:"result"`
+
+	// Scope is what Result applies to. Empty means ScopeNode.
+	Scope Scope `yaml:"scope,omitempty"`
+
+	// Actions are carried out by the control plane when this rule matches,
+	// subject to the policy's action limits.
+	Actions []Action `yaml:"actions,omitempty"`
 }
 
 // LoadPolicy loads a health policy from a YAML file.
@@ -88,7 +115,7 @@ func ParsePolicy(data []byte) (*Policy, error) {
 		return nil, fmt.Errorf("parse policy YAML: %w", err)
 	}
 
-	policy := &Policy{Rules: pf.Rules}
+	policy := &Policy{Rules: pf.Rules, ActionLimits: pf.ActionLimits}
 
 	if err := policy.Validate(); err != nil {
 		return nil, fmt.Errorf("validate policy: %w", err)
@@ -97,6 +124,12 @@ func ParsePolicy(data []byte) (*Policy, error) {
 	return policy, nil
 }
 
+// Marshal encodes the policy in the policy file format, so that it can be
+// read back with ParsePolicy.
+func (p *Policy) Marshal() ([]byte, error) {
+	return yaml.Marshal(PolicyFile{Version: "v1", Rules: p.Rules, ActionLimits: p.ActionLimits})
+}
+
 // Validate checks that the policy is well-formed.
 func (p *Policy) Validate() error {
 	if len(p.Rules) == 0 {
@@ -121,9 +154,14 @@ func (p *Policy) Validate() error {
 		default:
 			return fmt.Errorf("rule %q: invalid result %q (must be healthy, degraded, or unhealthy)", rule.Name, rule.Result)
 		}
+		switch rule.Scope {
+		case "", ScopeNode, ScopeGPU:
+		default:
+			return fmt.Errorf("rule %q: invalid scope %q (must be node or gpu)", rule.Name, rule.Scope)
+		}
 	}
 
-	return nil
+	return validateActions(p)
 }
 
 // SortedRules returns rules in evaluation order (definition order).
@@ -3,7 +3,9 @@ package health
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestParsePolicy(t *testing.T) {
@@ -230,6 +232,24 @@ func TestPolicy_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "gpu scope",
+			policy: Policy{
+				Rules: []Rule{
+					{Name: "test", Condition: "true", Result: ResultUnhealthy, Scope: ScopeGPU},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid scope",
+			policy: Policy{
+				Rules: []Rule{
+					{Name: "test", Condition: "true", Result: ResultHealthy, Scope: "pool"},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -317,3 +337,97 @@ func TestFatalXIDCodes(t *testing.T) {
 		}
 	}
 }
+
+func TestPolicy_Marshal(t *testing.T) {
+	policy := DefaultPolicy()
+	data, err := policy.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	parsed, err := ParsePolicy(data)
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+	if !reflect.DeepEqual(parsed, policy) {
+		t.Error("marshaled policy does not round-trip")
+	}
+}
+
+func TestParsePolicy_Actions(t *testing.T) {
+	policy, err := ParsePolicy([]byte(`
+action_limits:
+  cordon: {max: 2, window: 30m}
+rules:
+  - name: fatal-xid
+    condition: 'event.event_type == "xid"'
+    result: unhealthy
+    actions:
+      - cordon
+      - type: run_diagnostic
+        parameters:
+          test: memory
+  - name: default
+    condition: 'true'
+    result: healthy
+`))
+	if err != nil {
+		t.Fatalf("ParsePolicy() error = %v", err)
+	}
+
+	want := []Action{
+		{Type: ActionCordon},
+		{Type: ActionRunDiagnostic, Parameters: map[string]string{"test": "memory"}},
+	}
+	if got := policy.Rules[0].Actions; !reflect.DeepEqual(got, want) {
+		t.Errorf("Actions = %+v, want %+v", got, want)
+	}
+	if got := policy.ActionLimit(ActionCordon); got != (ActionLimit{Max: 2, Window: 30 * time.Minute}) {
+		t.Errorf("ActionLimit(cordon) = %+v, want policy override", got)
+	}
+	if got := policy.ActionLimit(ActionDrain); got != DefaultActionLimits[ActionDrain] {
+		t.Errorf("ActionLimit(drain) = %+v, want default", got)
+	}
+
+	data, err := policy.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParsePolicy(data)
+	if err != nil {
+		t.Fatalf("ParsePolicy(Marshal()) error = %v", err)
+	}
+	if !reflect.DeepEqual(parsed, policy) {
+		t.Errorf("marshaled policy does not round-trip:\n%s", data)
+	}
+
+	invalid := map[string]string{
+		"unknown action": `
+rules:
+  - name: r
+    condition: 'true'
+    result: healthy
+    actions: [reboot]
+`,
+		"unknown limit": `
+action_limits:
+  reboot: {max: 1, window: 1h}
+rules:
+  - name: r
+    condition: 'true'
+    result: healthy
+`,
+		"zero limit": `
+action_limits:
+  cordon: {max: 0, window: 1h}
+rules:
+  - name: r
+    condition: 'true'
+    result: healthy
+`,
+	}
+	for name, yaml := range invalid {
+		if _, err := ParsePolicy([]byte(yaml)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
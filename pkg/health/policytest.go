@@ -0,0 +1,211 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/NavarchProject/navarch/pkg/gpu"
+)
+
+// TestFile is the on-disk format for policy test fixtures. JSON fixtures use
+// the same field names.
+//
+//	cases:
+//	  - name: bus error is fatal
+//	    event:
+//	      event_type: xid
+//	      metrics:
+//	        xid_code: 79
+//	    expect:
+//	      result: unhealthy
+//	      matched_rule: fatal-xid
+type TestFile struct {
+	Cases []TestCase `yaml:"cases"`
+}
+
+// TestCase is one policy test: the events to evaluate and the expected
+// outcome. Multiple events are evaluated together, so the worst result wins
+// as it does for a health report.
+type TestCase struct {
+	Name string `yaml:"name"`
+
+	// Event is shorthand for a single-event case. Set Event or Events, not
+	// both.
+	Event  *TestEvent  `yaml:"event,omitempty"`
+	Events []TestEvent `yaml:"events,omitempty"`
+
+	// History lists earlier events from the same node, for rules that use
+	// history or gpu_history. They are not evaluated themselves.
+	History []TestEvent `yaml:"history,omitempty"`
+
+	Expect TestExpectation `yaml:"expect"`
+}
+
+// TestEvent describes a gpu.HealthEvent using the same names and values that
+// rule conditions see.
+type TestEvent struct {
+	// Timestamp defaults to the time the tests run.
+	Timestamp time.Time      `yaml:"timestamp,omitempty"`
+	GPUIndex  int            `yaml:"gpu_index"`
+	GPUUUID   string         `yaml:"gpu_uuid,omitempty"`
+	System    string         `yaml:"system,omitempty"`
+	EventType string         `yaml:"event_type"`
+	Metrics   map[string]any `yaml:"metrics,omitempty"`
+	Message   string         `yaml:"message,omitempty"`
+}
+
+// TestExpectation is the expected outcome of a test case. An empty
+// MatchedRule is not checked.
+type TestExpectation struct {
+	Result      Result `yaml:"result" json:"result"`
+	MatchedRule string `yaml:"matched_rule,omitempty" json:"matched_rule,omitempty"`
+}
+
+// TestResult is the outcome of running one test case.
+type TestResult struct {
+	Name     string          `json:"name"`
+	Passed   bool            `json:"passed"`
+	Expected TestExpectation `json:"expected"`
+
+	// Result and MatchedRule are what the policy produced.
+	Result      Result `json:"result,omitempty"`
+	MatchedRule string `json:"matched_rule,omitempty"`
+
+	// Error is set if the case could not be evaluated.
+	Error string `json:"error,omitempty"`
+}
+
+// LoadTestFile reads policy test fixtures from a YAML or JSON file.
+func LoadTestFile(path string) (*TestFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read test file: %w", err)
+	}
+	return ParseTestFile(data)
+}
+
+// ParseTestFile parses policy test fixtures from YAML or JSON data.
+func ParseTestFile(data []byte) (*TestFile, error) {
+	var tf TestFile
+	if err := yaml.Unmarshal(data, &tf); err != nil {
+		return nil, fmt.Errorf("parse test file: %w", err)
+	}
+	if len(tf.Cases) == 0 {
+		return nil, fmt.Errorf("test file must have at least one case")
+	}
+
+	for i, tc := range tf.Cases {
+		name := tc.Name
+		if name == "" {
+			name = fmt.Sprintf("case %d", i)
+		}
+		if tc.Event != nil && len(tc.Events) > 0 {
+			return nil, fmt.Errorf("%s: set event or events, not both", name)
+		}
+		if tc.Event == nil && len(tc.Events) == 0 {
+			return nil, fmt.Errorf("%s: event or events is required", name)
+		}
+		switch tc.Expect.Result {
+		case ResultHealthy, ResultDegraded, ResultUnhealthy:
+		default:
+			return nil, fmt.Errorf("%s: invalid expected result %q (must be healthy, degraded, or unhealthy)", name, tc.Expect.Result)
+		}
+		for _, ev := range append(tc.events(), tc.History...) {
+			if _, err := ev.toHealthEvent(time.Time{}); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+
+	return &tf, nil
+}
+
+func (tc TestCase) events() []TestEvent {
+	if tc.Event != nil {
+		return []TestEvent{*tc.Event}
+	}
+	return tc.Events
+}
+
+func (ev TestEvent) toHealthEvent(now time.Time) (gpu.HealthEvent, error) {
+	eventType, ok := gpu.ParseEventType(ev.EventType)
+	if !ok {
+		return gpu.HealthEvent{}, fmt.Errorf("unknown event_type %q", ev.EventType)
+	}
+	event := gpu.HealthEvent{
+		Timestamp: ev.Timestamp,
+		GPUIndex:  ev.GPUIndex,
+		GPUUUID:   ev.GPUUUID,
+		EventType: eventType,
+		Metrics:   ev.Metrics,
+		Message:   ev.Message,
+	}
+	if ev.System != "" {
+		system, ok := gpu.ParseSystem(ev.System)
+		if !ok {
+			return gpu.HealthEvent{}, fmt.Errorf("unknown system %q", ev.System)
+		}
+		event.System = system
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = now
+	}
+	return event, nil
+}
+
+func toHealthEvents(evs []TestEvent, now time.Time) ([]gpu.HealthEvent, error) {
+	events := make([]gpu.HealthEvent, 0, len(evs))
+	for _, ev := range evs {
+		event, err := ev.toHealthEvent(now)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// RunTests evaluates each test case against the current policy.
+func (e *Evaluator) RunTests(ctx context.Context, tf *TestFile) []TestResult {
+	now := time.Now()
+	results := make([]TestResult, 0, len(tf.Cases))
+
+	for i, tc := range tf.Cases {
+		r := TestResult{Name: tc.Name, Expected: tc.Expect}
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("case %d", i)
+		}
+
+		events, err := toHealthEvents(tc.events(), now)
+		if err != nil {
+			r.Error = err.Error()
+		}
+		history, err := toHealthEvents(tc.History, now)
+		if err != nil {
+			r.Error = err.Error()
+		}
+		if r.Error == "" {
+			eval, err := e.EvaluateWithHistory(ctx, events, history)
+			if err != nil {
+				r.Error = err.Error()
+			} else {
+				r.Result = eval.Status
+				r.MatchedRule = eval.MatchedRule
+				// Evaluate only names a rule that made the status worse than
+				// healthy; for all-healthy cases report the first match.
+				if r.MatchedRule == "" && len(eval.AllMatches) > 0 {
+					r.MatchedRule = eval.AllMatches[0].Rule
+				}
+				r.Passed = r.Result == tc.Expect.Result &&
+					(tc.Expect.MatchedRule == "" || r.MatchedRule == tc.Expect.MatchedRule)
+			}
+		}
+		results = append(results, r)
+	}
+
+	return results
+}
@@ -0,0 +1,211 @@
+package health
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+const testPolicyYAML = `
+rules:
+  - name: fatal-xid
+    condition: event.event_type == "xid" && event.metrics.xid_code == 79
+    result: unhealthy
+  - name: hot
+    condition: event.event_type == "thermal" && event.metrics.temperature >= 85
+    result: degraded
+  - name: default
+    condition: "true"
+    result: healthy
+`
+
+func TestParseTestFile(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		tf, err := ParseTestFile([]byte(`
+cases:
+  - name: bus error
+    event:
+      event_type: xid
+      system: DCGM_HEALTH_WATCH_DRIVER
+      metrics:
+        xid_code: 79
+    expect:
+      result: unhealthy
+      matched_rule: fatal-xid
+  - name: several
+    events:
+      - event_type: thermal
+      - event_type: nvlink
+    expect:
+      result: healthy
+`))
+		if err != nil {
+			t.Fatalf("ParseTestFile() error = %v", err)
+		}
+		if len(tf.Cases) != 2 {
+			t.Fatalf("len(Cases) = %d, want 2", len(tf.Cases))
+		}
+		if got := tf.Cases[0].events(); len(got) != 1 || got[0].Metrics["xid_code"] != 79 {
+			t.Errorf("case 0 events = %+v", got)
+		}
+		if got := tf.Cases[1].events(); len(got) != 2 {
+			t.Errorf("case 1 has %d events, want 2", len(got))
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		tf, err := ParseTestFile([]byte(`{"cases": [{
+			"name": "json case",
+			"event": {"event_type": "xid", "timestamp": "2024-05-01T12:00:00Z", "metrics": {"xid_code": 79}},
+			"expect": {"result": "unhealthy"}
+		}]}`))
+		if err != nil {
+			t.Fatalf("ParseTestFile() error = %v", err)
+		}
+		want := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		if got := tf.Cases[0].Event.Timestamp; !got.Equal(want) {
+			t.Errorf("Timestamp = %v, want %v", got, want)
+		}
+	})
+
+	errCases := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{"no_cases", "cases: []", "at least one case"},
+		{"no_events", "cases:\n  - name: c\n    expect: {result: healthy}", "event or events is required"},
+		{"both", "cases:\n  - name: c\n    event: {event_type: xid}\n    events: [{event_type: xid}]\n    expect: {result: healthy}", "not both"},
+		{"bad_result", "cases:\n  - name: c\n    event: {event_type: xid}\n    expect: {result: broken}", "invalid expected result"},
+		{"bad_event_type", "cases:\n  - name: c\n    event: {event_type: melted}\n    expect: {result: healthy}", "unknown event_type"},
+		{"bad_system", "cases:\n  - name: c\n    event: {event_type: xid, system: driver}\n    expect: {result: healthy}", "unknown system"},
+	}
+	for _, tt := range errCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTestFile([]byte(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want mention of %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEvaluator_RunTests(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicyYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eval, err := NewEvaluator(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tf, err := ParseTestFile([]byte(`
+cases:
+  - name: bus error
+    event: {event_type: xid, metrics: {xid_code: 79}}
+    expect: {result: unhealthy, matched_rule: fatal-xid}
+  - name: warm but fine
+    event: {event_type: thermal, metrics: {temperature: 70}}
+    expect: {result: healthy, matched_rule: default}
+  - name: worst wins
+    events:
+      - {event_type: thermal, metrics: {temperature: 90}}
+      - {event_type: xid, metrics: {xid_code: 79}}
+    expect: {result: unhealthy, matched_rule: fatal-xid}
+  - name: wrong rule
+    event: {event_type: thermal, metrics: {temperature: 90}}
+    expect: {result: degraded, matched_rule: thermal-critical}
+  - name: wrong result
+    event: {event_type: xid, metrics: {xid_code: 13}}
+    expect: {result: unhealthy}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := eval.RunTests(context.Background(), tf)
+	if len(results) != len(tf.Cases) {
+		t.Fatalf("len(results) = %d, want %d", len(results), len(tf.Cases))
+	}
+
+	want := []struct {
+		passed bool
+		result Result
+		rule   string
+	}{
+		{true, ResultUnhealthy, "fatal-xid"},
+		{true, ResultHealthy, "default"},
+		{true, ResultUnhealthy, "fatal-xid"},
+		{false, ResultDegraded, "hot"},
+		{false, ResultHealthy, "default"},
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Passed != w.passed || r.Result != w.result || r.MatchedRule != w.rule {
+			t.Errorf("%s: got passed=%v result=%s rule=%s, want passed=%v result=%s rule=%s",
+				r.Name, r.Passed, r.Result, r.MatchedRule, w.passed, w.result, w.rule)
+		}
+	}
+}
+
+func TestTestEvent_toHealthEvent(t *testing.T) {
+	now := time.Now()
+	ev := TestEvent{GPUIndex: 3, EventType: "ecc_dbe", System: "DCGM_HEALTH_WATCH_MEM"}
+	event, err := ev.toHealthEvent(now)
+	if err != nil {
+		t.Fatalf("toHealthEvent() error = %v", err)
+	}
+	if event.EventType != pb.HealthEventType_HEALTH_EVENT_TYPE_ECC_DBE ||
+		event.System != pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM ||
+		event.GPUIndex != 3 || !event.Timestamp.Equal(now) {
+		t.Errorf("toHealthEvent() = %+v", event)
+	}
+}
+
+func TestEvaluator_RunTestsWithHistory(t *testing.T) {
+	policy, err := ParsePolicy([]byte(`
+rules:
+  - name: repeated-xid
+    condition: event.event_type == "xid" && gpu_history.within(duration("10m")).size() >= 3
+    result: unhealthy
+  - name: default
+    condition: "true"
+    result: healthy
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eval, err := NewEvaluator(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tf, err := ParseTestFile([]byte(`
+cases:
+  - name: third xid in window
+    history:
+      - {event_type: xid, timestamp: 2024-01-01T00:00:00Z, metrics: {xid_code: 13}}
+      - {event_type: xid, timestamp: 2024-01-01T00:05:00Z, metrics: {xid_code: 13}}
+    event: {event_type: xid, timestamp: 2024-01-01T00:08:00Z, metrics: {xid_code: 13}}
+    expect: {result: unhealthy, matched_rule: repeated-xid}
+  - name: first xid aged out
+    history:
+      - {event_type: xid, timestamp: 2024-01-01T00:00:00Z, metrics: {xid_code: 13}}
+      - {event_type: xid, timestamp: 2024-01-01T00:05:00Z, metrics: {xid_code: 13}}
+    event: {event_type: xid, timestamp: 2024-01-01T00:12:00Z, metrics: {xid_code: 13}}
+    expect: {result: healthy}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range eval.RunTests(context.Background(), tf) {
+		if !r.Passed {
+			t.Errorf("%s: got %s (%s), error %q", r.Name, r.Result, r.MatchedRule, r.Error)
+		}
+	}
+}
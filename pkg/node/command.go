@@ -2,12 +2,19 @@ package node
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/NavarchProject/navarch/pkg/gpu"
 	pb "github.com/NavarchProject/navarch/proto"
 )
 
@@ -17,6 +24,13 @@ type CommandHandler interface {
 	Handle(ctx context.Context, cmd *pb.NodeCommand) error
 }
 
+// CommandOutputHandler is implemented by handlers whose commands produce
+// output worth reporting to the control plane, such as diagnostic results.
+// The dispatcher prefers HandleWithOutput over Handle when it is available.
+type CommandOutputHandler interface {
+	HandleWithOutput(ctx context.Context, cmd *pb.NodeCommand) (string, error)
+}
+
 // ShutdownFunc is called to initiate node shutdown.
 type ShutdownFunc func(ctx context.Context, force bool) error
 
@@ -24,6 +38,13 @@ type ShutdownFunc func(ctx context.Context, force bool) error
 // It should return when all workloads have completed or been terminated.
 type WorkloadDrainFunc func(ctx context.Context, timeout time.Duration, force bool) error
 
+// GPUResetFunc is called to reset the GPU at an index.
+type GPUResetFunc func(ctx context.Context, gpuIndex int) error
+
+// HealthEventFunc is called with the problems found by diagnostics, to
+// report them to the control plane.
+type HealthEventFunc func(ctx context.Context, events []gpu.HealthEvent)
+
 // CommandDispatcher routes commands to their appropriate handlers.
 type CommandDispatcher struct {
 	handlers map[pb.NodeCommandType]CommandHandler
@@ -34,16 +55,28 @@ type CommandDispatcher struct {
 	cordoned bool
 	draining bool
 
+	// quarantined GPUs by index, written to gpuExclusionFile if set.
+	quarantined      map[int]QuarantinedGPU
+	gpuExclusionFile string
+
+	// GPUs and the diagnostics run against them
+	gpuManager  gpu.Manager
+	diagnostics []Diagnostic
+
 	// Callbacks for node lifecycle operations
 	shutdownFunc      ShutdownFunc
 	workloadDrainFunc WorkloadDrainFunc
+	gpuResetFunc      GPUResetFunc
+	healthEventFunc   HealthEventFunc
 }
 
 // NewCommandDispatcher creates a new command dispatcher with default handlers.
 func NewCommandDispatcher(logger *slog.Logger) *CommandDispatcher {
 	d := &CommandDispatcher{
-		handlers: make(map[pb.NodeCommandType]CommandHandler),
-		logger:   logger,
+		handlers:    make(map[pb.NodeCommandType]CommandHandler),
+		logger:      logger,
+		quarantined: make(map[int]QuarantinedGPU),
+		diagnostics: DefaultDiagnostics(),
 	}
 
 	// Register default handlers
@@ -51,6 +84,8 @@ func NewCommandDispatcher(logger *slog.Logger) *CommandDispatcher {
 	d.RegisterHandler(pb.NodeCommandType_NODE_COMMAND_TYPE_DRAIN, &DrainHandler{dispatcher: d})
 	d.RegisterHandler(pb.NodeCommandType_NODE_COMMAND_TYPE_TERMINATE, &TerminateHandler{dispatcher: d})
 	d.RegisterHandler(pb.NodeCommandType_NODE_COMMAND_TYPE_RUN_DIAGNOSTIC, &DiagnosticHandler{dispatcher: d})
+	d.RegisterHandler(pb.NodeCommandType_NODE_COMMAND_TYPE_RESET_GPU, &ResetGPUHandler{dispatcher: d})
+	d.RegisterHandler(pb.NodeCommandType_NODE_COMMAND_TYPE_QUARANTINE_GPU, &QuarantineGPUHandler{dispatcher: d})
 
 	return d
 }
@@ -69,6 +104,67 @@ func (d *CommandDispatcher) SetWorkloadDrainFunc(fn WorkloadDrainFunc) {
 	d.workloadDrainFunc = fn
 }
 
+// SetGPUResetFunc sets the callback for resetting a GPU.
+func (d *CommandDispatcher) SetGPUResetFunc(fn GPUResetFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.gpuResetFunc = fn
+}
+
+// SetHealthEventFunc sets the callback for reporting problems found by
+// diagnostics.
+func (d *CommandDispatcher) SetHealthEventFunc(fn HealthEventFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.healthEventFunc = fn
+}
+
+// SetGPUManager sets the GPUs that diagnostics run against. Without one,
+// diagnostic commands fail.
+func (d *CommandDispatcher) SetGPUManager(m gpu.Manager) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.gpuManager = m
+}
+
+// RegisterDiagnostic adds a diagnostic, replacing any with the same name.
+func (d *CommandDispatcher) RegisterDiagnostic(diag Diagnostic) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, existing := range d.diagnostics {
+		if existing.Name() == diag.Name() {
+			d.diagnostics[i] = diag
+			return
+		}
+	}
+	d.diagnostics = append(d.diagnostics, diag)
+}
+
+// SetGPUExclusionFile sets the file that quarantined GPUs are published to,
+// for the workload system to read. GPUs already listed in an existing file
+// stay quarantined.
+func (d *CommandDispatcher) SetGPUExclusionFile(path string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read GPU exclusion file: %w", err)
+	}
+	if err == nil {
+		var list GPUExclusionList
+		if err := json.Unmarshal(data, &list); err != nil {
+			return fmt.Errorf("parse GPU exclusion file: %w", err)
+		}
+		for _, g := range list.GPUs {
+			d.quarantined[g.Index] = g
+		}
+	}
+	d.gpuExclusionFile = path
+	return nil
+}
+
 // RegisterHandler registers a handler for a command type.
 func (d *CommandDispatcher) RegisterHandler(cmdType pb.NodeCommandType, handler CommandHandler) {
 	d.mu.Lock()
@@ -76,14 +172,14 @@ func (d *CommandDispatcher) RegisterHandler(cmdType pb.NodeCommandType, handler
 	d.handlers[cmdType] = handler
 }
 
-// Dispatch routes a command to its handler and returns the result.
-func (d *CommandDispatcher) Dispatch(ctx context.Context, cmd *pb.NodeCommand) error {
+// Dispatch routes a command to its handler and returns its output, if any.
+func (d *CommandDispatcher) Dispatch(ctx context.Context, cmd *pb.NodeCommand) (string, error) {
 	d.mu.RLock()
 	handler, ok := d.handlers[cmd.Type]
 	d.mu.RUnlock()
 
 	if !ok {
-		return fmt.Errorf("no handler registered for command type: %s", cmd.Type.String())
+		return "", fmt.Errorf("no handler registered for command type: %s", cmd.Type.String())
 	}
 
 	d.logger.InfoContext(ctx, "executing command",
@@ -91,13 +187,22 @@ func (d *CommandDispatcher) Dispatch(ctx context.Context, cmd *pb.NodeCommand) e
 		slog.String("type", cmd.Type.String()),
 	)
 
-	if err := handler.Handle(ctx, cmd); err != nil {
+	var (
+		output string
+		err    error
+	)
+	if h, ok := handler.(CommandOutputHandler); ok {
+		output, err = h.HandleWithOutput(ctx, cmd)
+	} else {
+		err = handler.Handle(ctx, cmd)
+	}
+	if err != nil {
 		d.logger.ErrorContext(ctx, "command failed",
 			slog.String("command_id", cmd.CommandId),
 			slog.String("type", cmd.Type.String()),
 			slog.String("error", err.Error()),
 		)
-		return err
+		return output, err
 	}
 
 	d.logger.InfoContext(ctx, "command completed",
@@ -105,7 +210,7 @@ func (d *CommandDispatcher) Dispatch(ctx context.Context, cmd *pb.NodeCommand) e
 		slog.String("type", cmd.Type.String()),
 	)
 
-	return nil
+	return output, nil
 }
 
 // IsCordoned returns whether the node is currently cordoned.
@@ -122,6 +227,18 @@ func (d *CommandDispatcher) IsDraining() bool {
 	return d.draining
 }
 
+// QuarantinedGPUs returns the GPUs quarantined on this node, by index.
+func (d *CommandDispatcher) QuarantinedGPUs() []QuarantinedGPU {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	gpus := make([]QuarantinedGPU, 0, len(d.quarantined))
+	for _, index := range slices.Sorted(maps.Keys(d.quarantined)) {
+		gpus = append(gpus, d.quarantined[index])
+	}
+	return gpus
+}
+
 // CordonHandler handles cordon commands.
 type CordonHandler struct {
 	dispatcher *CommandDispatcher
@@ -272,25 +389,193 @@ func (h *TerminateHandler) Handle(ctx context.Context, cmd *pb.NodeCommand) erro
 	return nil
 }
 
-// DiagnosticHandler handles diagnostic commands.
+// DiagnosticHandler handles diagnostic commands. It returns a
+// DiagnosticReport as JSON and reports the problems found as health events.
+// Parameters:
+//   - test: comma-separated diagnostics to run (default: "all")
+//   - timeout: how long the diagnostics may run, in seconds (default: 600)
 type DiagnosticHandler struct {
 	dispatcher *CommandDispatcher
 }
 
 func (h *DiagnosticHandler) Handle(ctx context.Context, cmd *pb.NodeCommand) error {
-	testName := "all"
-	if cmd.Parameters != nil {
-		if t, ok := cmd.Parameters["test"]; ok {
-			testName = t
+	_, err := h.HandleWithOutput(ctx, cmd)
+	return err
+}
+
+func (h *DiagnosticHandler) HandleWithOutput(ctx context.Context, cmd *pb.NodeCommand) (string, error) {
+	h.dispatcher.mu.RLock()
+	gpus := h.dispatcher.gpuManager
+	diagnostics := slices.Clone(h.dispatcher.diagnostics)
+	eventFunc := h.dispatcher.healthEventFunc
+	h.dispatcher.mu.RUnlock()
+
+	if gpus == nil {
+		return "", fmt.Errorf("diagnostics are not supported on this node")
+	}
+	selected, err := selectDiagnostics(diagnostics, cmd.Parameters["test"])
+	if err != nil {
+		return "", err
+	}
+
+	timeout := 10 * time.Minute
+	if t, ok := cmd.Parameters["timeout"]; ok {
+		if d, err := time.ParseDuration(t + "s"); err == nil {
+			timeout = d
 		}
 	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
 	h.dispatcher.logger.InfoContext(ctx, "running diagnostics",
-		slog.String("test", testName),
+		slog.Int("count", len(selected)),
+		slog.Duration("timeout", timeout),
 	)
 
-	// In a real implementation, this would run actual diagnostic tests
-	// For now, we just log the request
+	report := RunDiagnostics(runCtx, gpus, selected)
+	for _, res := range report.Results {
+		level := slog.LevelInfo
+		if res.Status == DiagnosticFailed || res.Status == DiagnosticError {
+			level = slog.LevelWarn
+		}
+		h.dispatcher.logger.Log(ctx, level, "diagnostic finished",
+			slog.String("diagnostic", res.Name),
+			slog.String("status", res.Status),
+			slog.String("message", res.Message),
+		)
+	}
+
+	if events := report.HealthEvents(); len(events) > 0 && eventFunc != nil {
+		eventFunc(ctx, events)
+	}
+
+	out, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("encoding diagnostic report: %w", err)
+	}
+	return string(out), nil
+}
+
+// ResetGPUHandler handles GPU reset commands.
+// Parameters:
+//   - gpu_index: index of the GPU to reset (required)
+type ResetGPUHandler struct {
+	dispatcher *CommandDispatcher
+}
+
+func (h *ResetGPUHandler) Handle(ctx context.Context, cmd *pb.NodeCommand) error {
+	index, err := strconv.Atoi(cmd.Parameters["gpu_index"])
+	if err != nil || index < 0 {
+		return fmt.Errorf("invalid gpu_index parameter %q", cmd.Parameters["gpu_index"])
+	}
+
+	h.dispatcher.mu.RLock()
+	resetFunc := h.dispatcher.gpuResetFunc
+	h.dispatcher.mu.RUnlock()
+
+	if resetFunc == nil {
+		return fmt.Errorf("GPU reset is not supported on this node")
+	}
+
+	h.dispatcher.logger.InfoContext(ctx, "resetting GPU",
+		slog.Int("gpu_index", index),
+		slog.String("gpu_uuid", cmd.Parameters["gpu_uuid"]),
+	)
+
+	if err := resetFunc(ctx, index); err != nil {
+		return fmt.Errorf("resetting GPU %d: %w", index, err)
+	}
+	return nil
+}
+
+// QuarantinedGPU is a GPU hidden from workloads after the control plane found
+// it unhealthy.
+type QuarantinedGPU struct {
+	Index  int       `json:"index"`
+	UUID   string    `json:"uuid,omitempty"`
+	Reason string    `json:"reason,omitempty"`
+	Since  time.Time `json:"since"`
+}
+
+// GPUExclusionList is the format of the GPU exclusion file. Workload systems
+// read it to keep quarantined GPUs out of their device lists.
+type GPUExclusionList struct {
+	GPUs []QuarantinedGPU `json:"gpus"`
+}
+
+// QuarantineGPUHandler handles GPU quarantine commands.
+// Parameters:
+//   - gpu_index: index of the GPU to quarantine (required)
+//   - gpu_uuid: UUID of the GPU
+//   - reason: why the GPU was quarantined
+type QuarantineGPUHandler struct {
+	dispatcher *CommandDispatcher
+}
+
+func (h *QuarantineGPUHandler) Handle(ctx context.Context, cmd *pb.NodeCommand) error {
+	index, err := strconv.Atoi(cmd.Parameters["gpu_index"])
+	if err != nil || index < 0 {
+		return fmt.Errorf("invalid gpu_index parameter %q", cmd.Parameters["gpu_index"])
+	}
+
+	d := h.dispatcher
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.quarantined[index]; ok {
+		return nil
+	}
+	d.quarantined[index] = QuarantinedGPU{
+		Index:  index,
+		UUID:   cmd.Parameters["gpu_uuid"],
+		Reason: cmd.Parameters["reason"],
+		Since:  time.Now(),
+	}
 
+	// The GPU only counts as quarantined once the file lists it, so a
+	// redelivered command retries the write.
+	if d.gpuExclusionFile != "" {
+		if err := d.writeGPUExclusionFileLocked(); err != nil {
+			delete(d.quarantined, index)
+			return fmt.Errorf("writing GPU exclusion file: %w", err)
+		}
+	}
+
+	d.logger.WarnContext(ctx, "GPU quarantined",
+		slog.Int("gpu_index", index),
+		slog.String("gpu_uuid", cmd.Parameters["gpu_uuid"]),
+		slog.String("reason", cmd.Parameters["reason"]),
+	)
 	return nil
 }
+
+// writeGPUExclusionFileLocked replaces the exclusion file with the current
+// quarantined GPUs. The file is renamed into place so readers never see a
+// partial list.
+func (d *CommandDispatcher) writeGPUExclusionFileLocked() error {
+	list := GPUExclusionList{GPUs: make([]QuarantinedGPU, 0, len(d.quarantined))}
+	for _, index := range slices.Sorted(maps.Keys(d.quarantined)) {
+		list.GPUs = append(list.GPUs, d.quarantined[index])
+	}
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(d.gpuExclusionFile), ".gpu-exclusion-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), d.gpuExclusionFile)
+}
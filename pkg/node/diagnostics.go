@@ -0,0 +1,414 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/NavarchProject/navarch/pkg/gpu"
+	pb "github.com/NavarchProject/navarch/proto"
+)
+
+// ErrDiagnosticSkipped is returned, wrapped, by diagnostics that cannot run
+// on this node, such as those needing a tool that is not installed.
+var ErrDiagnosticSkipped = errors.New("diagnostic skipped")
+
+// Diagnostic is an active GPU test run by a RUN_DIAGNOSTIC command. Each
+// problem it finds is returned as a health event, which is reported to the
+// control plane for policy evaluation.
+type Diagnostic interface {
+	// Name identifies the diagnostic in the command's "test" parameter.
+	Name() string
+
+	// Run tests the node's GPUs and returns the problems found.
+	Run(ctx context.Context, gpus gpu.Manager) ([]gpu.HealthEvent, error)
+}
+
+// DefaultDiagnostics returns the built-in diagnostics: an NVML sweep,
+// memory, PCIe, and NVLink checks, and the dcgmi and nvbandwidth tools,
+// which are skipped where they are not installed.
+func DefaultDiagnostics() []Diagnostic {
+	return []Diagnostic{
+		nvmlDiagnostic{},
+		memoryDiagnostic{},
+		pcieDiagnostic{},
+		nvlinkDiagnostic{},
+		&ExternalDiagnostic{
+			DiagnosticName: "dcgm",
+			Command:        "dcgmi",
+			Args:           []string{"diag", "-r", "1"},
+			// Failed tests show as "| Memory | Fail - GPU: 0 |" in the results.
+			FailurePattern: regexp.MustCompile(`(?m)^.*\|\s*Fail\b.*$`),
+			SkipPattern:    regexp.MustCompile(`(?i)host engine|unable to (establish a )?connect|not supported|unsupported`),
+		},
+		&ExternalDiagnostic{
+			DiagnosticName: "nvbandwidth",
+			Command:        "nvbandwidth",
+			Args:           []string{"-t", "host_to_device_memcpy_ce", "device_to_host_memcpy_ce"},
+			FailurePattern: regexp.MustCompile(`(?m)^.*CUDA_ERROR_(ECC_UNCORRECTABLE|HARDWARE_STACK_ERROR|ILLEGAL_INSTRUCTION|LAUNCH_FAILED|NVLINK_UNCORRECTABLE)\b.*$`),
+			SkipPattern:    regexp.MustCompile(`(?i)not supported|waived|CUDA_ERROR_NO_DEVICE`),
+		},
+	}
+}
+
+// Diagnostic statuses.
+const (
+	DiagnosticPassed  = "passed"
+	DiagnosticFailed  = "failed"
+	DiagnosticSkipped = "skipped"
+	DiagnosticError   = "error"
+)
+
+// DiagnosticReport is the output of a RUN_DIAGNOSTIC command, returned as
+// JSON in the command acknowledgement.
+type DiagnosticReport struct {
+	// Passed is false if any diagnostic failed or could not complete.
+	Passed  bool               `json:"passed"`
+	Results []DiagnosticResult `json:"results"`
+}
+
+// DiagnosticResult is the outcome of one diagnostic.
+type DiagnosticResult struct {
+	Name       string              `json:"name"`
+	Status     string              `json:"status"`
+	Message    string              `json:"message,omitempty"`
+	DurationMS int64               `json:"duration_ms"`
+	Findings   []DiagnosticFinding `json:"findings,omitempty"`
+
+	events []gpu.HealthEvent
+}
+
+// DiagnosticFinding is a problem a diagnostic found.
+type DiagnosticFinding struct {
+	// GPUIndex is the GPU with the problem, or -1 for the node.
+	GPUIndex  int            `json:"gpu_index"`
+	GPUUUID   string         `json:"gpu_uuid,omitempty"`
+	EventType string         `json:"event_type"`
+	Message   string         `json:"message"`
+	Metrics   map[string]any `json:"metrics,omitempty"`
+}
+
+// HealthEvents returns the problems found by every diagnostic, for the
+// control plane to evaluate. Each event's "diagnostic" metric names the
+// diagnostic that found it.
+func (r *DiagnosticReport) HealthEvents() []gpu.HealthEvent {
+	var events []gpu.HealthEvent
+	for _, res := range r.Results {
+		events = append(events, res.events...)
+	}
+	return events
+}
+
+// RunDiagnostics runs diagnostics in order and reports their results.
+func RunDiagnostics(ctx context.Context, gpus gpu.Manager, diagnostics []Diagnostic) *DiagnosticReport {
+	report := &DiagnosticReport{Passed: true}
+	for _, d := range diagnostics {
+		res := runDiagnostic(ctx, gpus, d)
+		if res.Status == DiagnosticFailed || res.Status == DiagnosticError {
+			report.Passed = false
+		}
+		report.Results = append(report.Results, res)
+	}
+	return report
+}
+
+func runDiagnostic(ctx context.Context, gpus gpu.Manager, d Diagnostic) DiagnosticResult {
+	start := time.Now()
+	events, err := d.Run(ctx, gpus)
+	res := DiagnosticResult{
+		Name:       d.Name(),
+		Status:     DiagnosticPassed,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+
+	switch {
+	case errors.Is(err, ErrDiagnosticSkipped):
+		res.Status = DiagnosticSkipped
+		res.Message = err.Error()
+		return res
+	case err != nil:
+		res.Status = DiagnosticError
+		res.Message = err.Error()
+	case len(events) > 0:
+		res.Status = DiagnosticFailed
+		res.Message = fmt.Sprintf("%d problem(s) found", len(events))
+	}
+
+	for _, e := range events {
+		if e.Timestamp.IsZero() {
+			e.Timestamp = start
+		}
+		metrics := make(map[string]any, len(e.Metrics)+1)
+		for k, v := range e.Metrics {
+			metrics[k] = v
+		}
+		metrics["diagnostic"] = res.Name
+		e.Metrics = metrics
+
+		res.events = append(res.events, e)
+		res.Findings = append(res.Findings, DiagnosticFinding{
+			GPUIndex:  e.GPUIndex,
+			GPUUUID:   e.GPUUUID,
+			EventType: gpu.EventTypeString(e.EventType),
+			Message:   e.Message,
+			Metrics:   e.Metrics,
+		})
+	}
+	return res
+}
+
+// selectDiagnostics returns the diagnostics named by a comma-separated test
+// parameter, in the order given. An empty parameter or "all" selects all of
+// them.
+func selectDiagnostics(diagnostics []Diagnostic, test string) ([]Diagnostic, error) {
+	if test == "" || test == "all" {
+		return diagnostics, nil
+	}
+
+	var selected []Diagnostic
+	for _, name := range strings.Split(test, ",") {
+		name = strings.TrimSpace(name)
+		i := -1
+		for j, d := range diagnostics {
+			if d.Name() == name {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			names := make([]string, len(diagnostics))
+			for j, d := range diagnostics {
+				names[j] = d.Name()
+			}
+			return nil, fmt.Errorf("unknown diagnostic %q (available: %s)", name, strings.Join(names, ", "))
+		}
+		selected = append(selected, diagnostics[i])
+	}
+	return selected, nil
+}
+
+// diagnosticEvent returns a health event for a problem that has no more
+// specific event type.
+func diagnosticEvent(gpuIndex int, gpuUUID, message string) gpu.HealthEvent {
+	return gpu.HealthEvent{
+		GPUIndex:  gpuIndex,
+		GPUUUID:   gpuUUID,
+		System:    pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_DRIVER,
+		EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_DIAGNOSTIC,
+		Message:   message,
+	}
+}
+
+// nvmlDiagnostic checks that every GPU answers device and health queries.
+type nvmlDiagnostic struct{}
+
+func (nvmlDiagnostic) Name() string { return "nvml" }
+
+func (nvmlDiagnostic) Run(ctx context.Context, gpus gpu.Manager) ([]gpu.HealthEvent, error) {
+	count, err := gpus.GetDeviceCount(ctx)
+	if err != nil {
+		return []gpu.HealthEvent{diagnosticEvent(-1, "", fmt.Sprintf("failed to get device count: %v", err))}, nil
+	}
+
+	var events []gpu.HealthEvent
+	for i := 0; i < count; i++ {
+		info, err := gpus.GetDeviceInfo(ctx, i)
+		if err != nil {
+			events = append(events, diagnosticEvent(i, "", fmt.Sprintf("GPU %d did not answer a device query: %v", i, err)))
+			continue
+		}
+		if _, err := gpus.GetDeviceHealth(ctx, i); err != nil {
+			events = append(events, diagnosticEvent(i, info.UUID, fmt.Sprintf("GPU %d did not answer a health query: %v", i, err)))
+		}
+	}
+	return events, nil
+}
+
+// inspectDiagnostic runs check against every GPU of a manager that
+// implements gpu.Inspector.
+func inspectDiagnostic(ctx context.Context, gpus gpu.Manager, check func(in gpu.Inspector, index int, uuid string) ([]gpu.HealthEvent, error)) ([]gpu.HealthEvent, error) {
+	in, ok := gpus.(gpu.Inspector)
+	if !ok {
+		return nil, fmt.Errorf("%w: GPU manager cannot inspect devices", ErrDiagnosticSkipped)
+	}
+	count, err := gpus.GetDeviceCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device count: %w", err)
+	}
+
+	var events []gpu.HealthEvent
+	for i := 0; i < count; i++ {
+		var uuid string
+		if info, err := gpus.GetDeviceInfo(ctx, i); err == nil {
+			uuid = info.UUID
+		}
+		found, err := check(in, i, uuid)
+		if err != nil {
+			return events, fmt.Errorf("GPU %d: %w", i, err)
+		}
+		events = append(events, found...)
+	}
+	return events, nil
+}
+
+// memoryDiagnostic checks that no GPU has memory rows it failed to remap or
+// that wait for a reset to be remapped, either of which keeps allocations
+// from using the GPU's full memory safely.
+type memoryDiagnostic struct{}
+
+func (memoryDiagnostic) Name() string { return "memory" }
+
+func (memoryDiagnostic) Run(ctx context.Context, gpus gpu.Manager) ([]gpu.HealthEvent, error) {
+	return inspectDiagnostic(ctx, gpus, func(in gpu.Inspector, index int, uuid string) ([]gpu.HealthEvent, error) {
+		mem, err := in.GetMemoryState(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		var msg string
+		switch {
+		case mem.RemapFailed:
+			msg = fmt.Sprintf("GPU %d failed to remap a memory row", index)
+		case mem.RemapPending:
+			msg = fmt.Sprintf("GPU %d has a memory row remap pending a GPU reset", index)
+		case mem.Total == 0:
+			msg = fmt.Sprintf("GPU %d reports no memory", index)
+		default:
+			return nil, nil
+		}
+		return []gpu.HealthEvent{{
+			GPUIndex:  index,
+			GPUUUID:   uuid,
+			System:    pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_MEM,
+			EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_MEMORY,
+			Metrics: map[string]any{
+				"remapped_rows": mem.RemappedRows,
+				"remap_pending": mem.RemapPending,
+				"remap_failed":  mem.RemapFailed,
+				"memory_total":  mem.Total,
+			},
+			Message: msg,
+		}}, nil
+	})
+}
+
+// pcieDiagnostic checks that every GPU's PCIe link runs at its full width.
+type pcieDiagnostic struct{}
+
+func (pcieDiagnostic) Name() string { return "pcie" }
+
+func (pcieDiagnostic) Run(ctx context.Context, gpus gpu.Manager) ([]gpu.HealthEvent, error) {
+	return inspectDiagnostic(ctx, gpus, func(in gpu.Inspector, index int, uuid string) ([]gpu.HealthEvent, error) {
+		link, err := in.GetPCIeLink(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		if link.Width >= link.MaxWidth {
+			return nil, nil
+		}
+		return []gpu.HealthEvent{{
+			GPUIndex:  index,
+			GPUUUID:   uuid,
+			System:    pb.HealthWatchSystem_HEALTH_WATCH_SYSTEM_PCIE,
+			EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_PCIE,
+			Metrics: map[string]any{
+				"link_width":          link.Width,
+				"max_link_width":      link.MaxWidth,
+				"link_generation":     link.Generation,
+				"max_link_generation": link.MaxGeneration,
+			},
+			Message: fmt.Sprintf("GPU %d PCIe link trained down to x%d of x%d", index, link.Width, link.MaxWidth),
+		}}, nil
+	})
+}
+
+// nvlinkDiagnostic checks that every NVLink link of every GPU is up.
+type nvlinkDiagnostic struct{}
+
+func (nvlinkDiagnostic) Name() string { return "nvlink" }
+
+func (nvlinkDiagnostic) Run(ctx context.Context, gpus gpu.Manager) ([]gpu.HealthEvent, error) {
+	return inspectDiagnostic(ctx, gpus, func(in gpu.Inspector, index int, uuid string) ([]gpu.HealthEvent, error) {
+		links, err := in.GetNVLinks(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		var events []gpu.HealthEvent
+		for _, l := range links {
+			if !l.Active {
+				events = append(events, gpu.NewNVLinkEvent(index, uuid, l.ID,
+					fmt.Sprintf("GPU %d NVLink %d is down", index, l.ID)))
+			}
+		}
+		return events, nil
+	})
+}
+
+// ExternalDiagnostic runs a diagnostic tool, such as dcgmi or nvbandwidth,
+// and reports a problem if it exits with an error that its output shows is a
+// test failure. Other errors, such as the tool failing to reach a service it
+// needs, are not reported as problems with the node. It is skipped if the
+// tool is not installed.
+type ExternalDiagnostic struct {
+	// DiagnosticName identifies the diagnostic.
+	DiagnosticName string
+
+	// Command is the tool to run, looked up in PATH.
+	Command string
+
+	// Args are passed to the tool.
+	Args []string
+
+	// FailurePattern matches the output of a tool that exited with an error
+	// because a test failed; the first match is the problem's message. If
+	// nil, every error exit is a test failure.
+	FailurePattern *regexp.Regexp
+
+	// SkipPattern matches the output of a tool that exited with an error
+	// because it cannot run its tests on this node, such as an unsupported
+	// GPU. It is checked only if FailurePattern does not match.
+	SkipPattern *regexp.Regexp
+}
+
+func (d *ExternalDiagnostic) Name() string { return d.DiagnosticName }
+
+func (d *ExternalDiagnostic) Run(ctx context.Context, gpus gpu.Manager) ([]gpu.HealthEvent, error) {
+	path, err := exec.LookPath(d.Command)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s not found", ErrDiagnosticSkipped, d.Command)
+	}
+
+	out, err := exec.CommandContext(ctx, path, d.Args...).CombinedOutput()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil {
+		verdict := lastLine(string(out))
+		if d.FailurePattern != nil {
+			match := d.FailurePattern.Find(out)
+			if match == nil {
+				if d.SkipPattern != nil && d.SkipPattern.Match(out) {
+					return nil, fmt.Errorf("%w: %s: %s", ErrDiagnosticSkipped, d.Command, verdict)
+				}
+				return nil, fmt.Errorf("%s exited with status %d: %s", d.Command, exitErr.ExitCode(), verdict)
+			}
+			verdict = strings.TrimSpace(string(match))
+		}
+		event := diagnosticEvent(-1, "", fmt.Sprintf("%s exited with status %d: %s",
+			d.Command, exitErr.ExitCode(), verdict))
+		event.Metrics = map[string]any{"exit_code": exitErr.ExitCode()}
+		return []gpu.HealthEvent{event}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("running %s: %w", d.Command, err)
+	}
+	return nil, nil
+}
+
+// lastLine returns the last non-empty line of a tool's output, which
+// usually holds its verdict.
+func lastLine(out string) string {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	"connectrpc.com/connect"
@@ -53,6 +54,15 @@ type Config struct {
 
 	// AuthToken is the authentication token for control plane communication.
 	AuthToken string
+
+	// DisableCommandStream makes the node poll for commands instead of
+	// receiving them over a WatchCommands stream.
+	DisableCommandStream bool
+
+	// GPUExclusionFile is where GPUs quarantined by the control plane are
+	// listed, as JSON, for the workload system to read. If empty,
+	// quarantined GPUs are only logged.
+	GPUExclusionFile string
 }
 
 // Node represents the node daemon that communicates with the control plane.
@@ -71,6 +81,12 @@ type Node struct {
 
 	// Command handling
 	commandDispatcher *CommandDispatcher
+
+	// Health events found by diagnostics, sent with the next health check.
+	// healthCheckNow asks the health check loop to run one right away.
+	pendingMu      sync.Mutex
+	pendingEvents  []gpu.HealthEvent
+	healthCheckNow chan struct{}
 }
 
 // New creates a new Node. If logger is nil, slog.Default() is used.
@@ -97,7 +113,18 @@ func New(cfg Config, logger *slog.Logger) (*Node, error) {
 
 	metricsCollector := metrics.NewCollector(gpuManager, nil)
 
-	return &Node{
+	dispatcher := NewCommandDispatcher(logger)
+	dispatcher.SetGPUManager(gpuManager)
+	if r, ok := gpuManager.(gpu.Resetter); ok {
+		dispatcher.SetGPUResetFunc(r.ResetDevice)
+	}
+	if cfg.GPUExclusionFile != "" {
+		if err := dispatcher.SetGPUExclusionFile(cfg.GPUExclusionFile); err != nil {
+			return nil, err
+		}
+	}
+
+	n := &Node{
 		config:              cfg,
 		logger:              logger,
 		gpu:                 gpuManager,
@@ -106,12 +133,16 @@ func New(cfg Config, logger *slog.Logger) (*Node, error) {
 		healthCheckInterval: 60 * time.Second,
 		heartbeatInterval:   30 * time.Second,
 		commandPollInterval: 10 * time.Second,
-		commandDispatcher:   NewCommandDispatcher(logger),
-	}, nil
+		commandDispatcher:   dispatcher,
+		healthCheckNow:      make(chan struct{}, 1),
+	}
+	dispatcher.SetHealthEventFunc(n.reportHealthEvents)
+	return n, nil
 }
 
 // createGPUManager creates a GPU manager.
-// It attempts to use NVML for real GPU access, falling back to a fake manager
+// It uses a DCGM host engine if NAVARCH_DCGM_HOST is set, and otherwise
+// attempts to use NVML for real GPU access, falling back to a fake manager
 // for development or when NVML is unavailable.
 func createGPUManager(logger *slog.Logger) gpu.Manager {
 	// Force fake mode if explicitly requested
@@ -119,6 +150,11 @@ func createGPUManager(logger *slog.Logger) gpu.Manager {
 		return createFakeGPUManager(logger)
 	}
 
+	if host := os.Getenv("NAVARCH_DCGM_HOST"); host != "" {
+		logger.Info("using DCGM GPU manager", slog.String("host", host))
+		return gpu.NewDCGM(gpu.NewDCGMI(host))
+	}
+
 	// Try to use real NVML
 	if gpu.IsNVMLAvailable() {
 		logger.Info("using NVML GPU manager")
@@ -182,7 +218,7 @@ func (n *Node) Start(ctx context.Context) error {
 
 	go n.heartbeatLoop(ctx)
 	go n.healthCheckLoop(ctx)
-	go n.commandPollLoop(ctx)
+	go n.commandLoop(ctx)
 
 	return nil
 }
@@ -393,11 +429,12 @@ func (n *Node) healthCheckLoop(ctx context.Context) {
 			n.logger.InfoContext(ctx, "health check loop stopped")
 			return
 		case <-ticker.C():
-			if err := n.runHealthChecks(ctx); err != nil {
-				n.logger.ErrorContext(ctx, "failed to run health checks",
-					slog.String("error", err.Error()),
-				)
-			}
+		case <-n.healthCheckNow:
+		}
+		if err := n.runHealthChecks(ctx); err != nil {
+			n.logger.ErrorContext(ctx, "failed to run health checks",
+				slog.String("error", err.Error()),
+			)
 		}
 	}
 }
@@ -416,6 +453,8 @@ func (n *Node) runHealthChecks(ctx context.Context) error {
 	// Collect health events and generate check result
 	healthEventCheck, rawEvents := n.runHealthEventCheck(ctx)
 	results = append(results, healthEventCheck)
+	pending := n.takePendingEvents()
+	rawEvents = append(rawEvents, pending...)
 
 	// Log health events if any were detected
 	for _, event := range rawEvents {
@@ -441,6 +480,7 @@ func (n *Node) runHealthChecks(ctx context.Context) error {
 
 	resp, err := n.client.ReportHealth(ctx, req)
 	if err != nil {
+		n.queueHealthEvents(pending)
 		return err
 	}
 
@@ -558,6 +598,97 @@ func (n *Node) runHealthEventCheck(ctx context.Context) (*pb.HealthCheckResult,
 	}, events
 }
 
+// reportHealthEvents queues the problems found by diagnostics and runs a
+// health check right away, so the control plane evaluates them without
+// waiting for the next interval. They are sent with the check's results
+// because the control plane works out the node's health from a full report.
+func (n *Node) reportHealthEvents(ctx context.Context, events []gpu.HealthEvent) {
+	n.queueHealthEvents(events)
+	select {
+	case n.healthCheckNow <- struct{}{}:
+	default:
+	}
+}
+
+// queueHealthEvents adds events to send with the next health check.
+func (n *Node) queueHealthEvents(events []gpu.HealthEvent) {
+	n.pendingMu.Lock()
+	defer n.pendingMu.Unlock()
+	n.pendingEvents = append(n.pendingEvents, events...)
+}
+
+// takePendingEvents returns and clears the health events awaiting the next
+// health check.
+func (n *Node) takePendingEvents() []gpu.HealthEvent {
+	n.pendingMu.Lock()
+	defer n.pendingMu.Unlock()
+	events := n.pendingEvents
+	n.pendingEvents = nil
+	return events
+}
+
+// commandLoop receives commands from the control plane. It holds a
+// WatchCommands stream open and falls back to polling while the stream is
+// down, retrying the stream after each poll.
+func (n *Node) commandLoop(ctx context.Context) {
+	if n.config.DisableCommandStream {
+		n.commandPollLoop(ctx)
+		return
+	}
+
+	n.logger.InfoContext(ctx, "starting command stream")
+
+	for {
+		err := n.watchCommands(ctx)
+		if ctx.Err() != nil {
+			n.logger.InfoContext(ctx, "command stream stopped")
+			return
+		}
+		if connect.CodeOf(err) == connect.CodeUnimplemented {
+			n.logger.WarnContext(ctx, "control plane does not support command streaming, polling instead")
+			n.commandPollLoop(ctx)
+			return
+		}
+		n.logger.WarnContext(ctx, "command stream broken, falling back to polling",
+			slog.String("error", err.Error()),
+			slog.Duration("interval", n.commandPollInterval),
+		)
+
+		select {
+		case <-ctx.Done():
+			n.logger.InfoContext(ctx, "command stream stopped")
+			return
+		case <-n.clock.After(n.commandPollInterval):
+		}
+
+		if err := n.pollCommands(ctx); err != nil {
+			n.logger.ErrorContext(ctx, "failed to poll commands",
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+// watchCommands executes commands from a WatchCommands stream until the
+// stream ends.
+func (n *Node) watchCommands(ctx context.Context) error {
+	stream, err := n.client.WatchCommands(ctx, connect.NewRequest(&pb.WatchCommandsRequest{
+		NodeId: n.config.NodeID,
+	}))
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	for stream.Receive() {
+		n.executeCommand(ctx, stream.Msg())
+	}
+	if err := stream.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("stream closed by control plane")
+}
+
 // commandPollLoop polls for commands from the control plane.
 func (n *Node) commandPollLoop(ctx context.Context) {
 	n.logger.InfoContext(ctx, "starting command poll loop",
@@ -600,42 +731,68 @@ func (n *Node) pollCommands(ctx context.Context) error {
 	}
 
 	for _, cmd := range resp.Msg.Commands {
-		n.logger.InfoContext(ctx, "executing command",
-			slog.String("command_id", cmd.CommandId),
-			slog.String("command_type", cmd.Type.String()),
-		)
+		n.executeCommand(ctx, cmd)
+	}
 
-		// Execute the command
-		if err := n.commandDispatcher.Dispatch(ctx, cmd); err != nil {
-			n.logger.ErrorContext(ctx, "command execution failed",
-				slog.String("command_id", cmd.CommandId),
-				slog.String("command_type", cmd.Type.String()),
-				slog.String("error", err.Error()),
-			)
-			// Acknowledge the command as failed
-			n.acknowledgeCommand(ctx, cmd.CommandId, "failed", err.Error())
-			continue
-		}
+	return nil
+}
 
-		n.logger.InfoContext(ctx, "command executed successfully",
+// executeCommand runs a command and reports its progress to the control plane.
+func (n *Node) executeCommand(ctx context.Context, cmd *pb.NodeCommand) {
+	n.logger.InfoContext(ctx, "executing command",
+		slog.String("command_id", cmd.CommandId),
+		slog.String("command_type", cmd.Type.String()),
+	)
+
+	// Acknowledge receipt so the control plane does not redeliver the command
+	n.acknowledgeCommand(ctx, cmd.CommandId, pb.CommandStatus_COMMAND_STATUS_RUNNING, "", "")
+
+	// Execute the command
+	output, err := n.commandDispatcher.Dispatch(ctx, cmd)
+	if err != nil {
+		n.logger.ErrorContext(ctx, "command execution failed",
 			slog.String("command_id", cmd.CommandId),
 			slog.String("command_type", cmd.Type.String()),
+			slog.String("error", err.Error()),
 		)
-
-		// Acknowledge successful completion
-		n.acknowledgeCommand(ctx, cmd.CommandId, "completed", "")
+		// Acknowledge the command as failed
+		n.acknowledgeCommand(ctx, cmd.CommandId, pb.CommandStatus_COMMAND_STATUS_FAILED, err.Error(), output)
+		return
 	}
 
-	return nil
+	n.logger.InfoContext(ctx, "command executed successfully",
+		slog.String("command_id", cmd.CommandId),
+		slog.String("command_type", cmd.Type.String()),
+	)
+
+	// Acknowledge successful completion
+	n.acknowledgeCommand(ctx, cmd.CommandId, pb.CommandStatus_COMMAND_STATUS_COMPLETED, "", output)
 }
 
-// acknowledgeCommand logs command completion status.
-// TODO: Add AcknowledgeCommand RPC to proto for proper status updates.
-func (n *Node) acknowledgeCommand(ctx context.Context, commandID, status, message string) {
-	n.logger.InfoContext(ctx, "command status update",
+// acknowledgeCommand reports command status to the control plane. Failures are
+// logged but not returned; a command whose acknowledgement is lost is
+// redelivered by the control plane.
+func (n *Node) acknowledgeCommand(ctx context.Context, commandID string, status pb.CommandStatus, message, output string) {
+	req := connect.NewRequest(&pb.AcknowledgeCommandRequest{
+		NodeId:    n.config.NodeID,
+		CommandId: commandID,
+		Status:    status,
+		Message:   message,
+		Output:    output,
+	})
+
+	if _, err := n.client.AcknowledgeCommand(ctx, req); err != nil {
+		n.logger.WarnContext(ctx, "failed to acknowledge command",
+			slog.String("command_id", commandID),
+			slog.String("status", status.String()),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	n.logger.DebugContext(ctx, "command status reported",
 		slog.String("command_id", commandID),
-		slog.String("status", status),
-		slog.String("message", message),
+		slog.String("status", status.String()),
 	)
 }
 
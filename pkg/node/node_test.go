@@ -2,10 +2,18 @@ package node
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/NavarchProject/navarch/pkg/gpu"
 	pb "github.com/NavarchProject/navarch/proto"
+	"github.com/NavarchProject/navarch/proto/protoconnect"
 )
 
 func TestNew(t *testing.T) {
@@ -183,4 +191,322 @@ func TestHealthChecks(t *testing.T) {
 
 		injectableGPU.ClearHealthEvents()
 	})
+
+	t.Run("diagnostic_events_wait_for_health_check", func(t *testing.T) {
+		// Diagnostic events go out with a full health check, run right
+		// away, and are kept for the next one if it cannot be reported.
+		n.reportHealthEvents(ctx, []gpu.HealthEvent{{GPUIndex: 1, EventType: pb.HealthEventType_HEALTH_EVENT_TYPE_PCIE}})
+		select {
+		case <-n.healthCheckNow:
+		default:
+			t.Error("Expected a health check to be requested")
+		}
+
+		n.client = protoconnect.NewControlPlaneServiceClient(http.DefaultClient, "http://127.0.0.1:1")
+		if err := n.runHealthChecks(ctx); err == nil {
+			t.Fatal("Expected the health report to fail")
+		}
+		if pending := n.takePendingEvents(); len(pending) != 1 || pending[0].GPUIndex != 1 {
+			t.Errorf("Expected the diagnostic event to stay queued, got %+v", pending)
+		}
+	})
+}
+
+func TestResetGPUCommand(t *testing.T) {
+	ctx := context.Background()
+	injectableGPU := gpu.NewInjectable(2, "")
+
+	n, err := New(Config{
+		ControlPlaneAddr: "http://localhost:50051",
+		NodeID:           "test-node",
+		GPU:              injectableGPU,
+	}, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := injectableGPU.Initialize(ctx); err != nil {
+		t.Fatalf("GPU Initialize failed: %v", err)
+	}
+	injectableGPU.InjectXIDHealthEvent(1, 79, "fallen off the bus")
+
+	reset := func(params map[string]string) error {
+		_, err := n.commandDispatcher.Dispatch(ctx, &pb.NodeCommand{
+			CommandId:  "cmd-1",
+			Type:       pb.NodeCommandType_NODE_COMMAND_TYPE_RESET_GPU,
+			Parameters: params,
+		})
+		return err
+	}
+
+	if err := reset(map[string]string{"gpu_index": "1"}); err != nil {
+		t.Fatalf("reset failed: %v", err)
+	}
+	if events, _ := injectableGPU.CollectHealthEvents(ctx); len(events) != 0 {
+		t.Errorf("expected reset to clear GPU 1's events, got %v", events)
+	}
+
+	if err := reset(nil); err == nil {
+		t.Error("expected error without gpu_index")
+	}
+	if err := reset(map[string]string{"gpu_index": "7"}); err == nil {
+		t.Error("expected error for a GPU that does not exist")
+	}
+}
+
+func TestQuarantineGPUCommand(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "gpu-exclusion.json")
+
+	newNode := func() *Node {
+		t.Helper()
+		n, err := New(Config{
+			ControlPlaneAddr: "http://localhost:50051",
+			NodeID:           "test-node",
+			GPUExclusionFile: path,
+		}, nil)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		return n
+	}
+	n := newNode()
+
+	quarantine := func(params map[string]string) error {
+		_, err := n.commandDispatcher.Dispatch(ctx, &pb.NodeCommand{
+			CommandId:  "cmd-1",
+			Type:       pb.NodeCommandType_NODE_COMMAND_TYPE_QUARANTINE_GPU,
+			Parameters: params,
+		})
+		return err
+	}
+
+	if err := quarantine(map[string]string{"gpu_index": "3", "gpu_uuid": "GPU-3", "reason": "xid 79"}); err != nil {
+		t.Fatalf("quarantine failed: %v", err)
+	}
+	if err := quarantine(map[string]string{"gpu_index": "1"}); err != nil {
+		t.Fatalf("quarantine failed: %v", err)
+	}
+	if err := quarantine(nil); err == nil {
+		t.Error("expected error without gpu_index")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading exclusion file: %v", err)
+	}
+	var list GPUExclusionList
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatalf("parsing exclusion file: %v", err)
+	}
+	if len(list.GPUs) != 2 || list.GPUs[0].Index != 1 || list.GPUs[1].Index != 3 || list.GPUs[1].UUID != "GPU-3" {
+		t.Errorf("exclusion list = %+v, want GPUs 1 and 3", list.GPUs)
+	}
+
+	// A restarted agent keeps the GPUs quarantined.
+	n = newNode()
+	if got := n.commandDispatcher.QuarantinedGPUs(); len(got) != 2 || got[1].Reason != "xid 79" {
+		t.Errorf("QuarantinedGPUs() after restart = %+v, want GPUs 1 and 3", got)
+	}
+
+	t.Run("write failure is retried", func(t *testing.T) {
+		d := n.commandDispatcher
+		d.gpuExclusionFile = filepath.Join(t.TempDir(), "missing", "gpu-exclusion.json")
+		if err := quarantine(map[string]string{"gpu_index": "5"}); err == nil {
+			t.Fatal("expected error when the exclusion file cannot be written")
+		}
+		if got := d.QuarantinedGPUs(); len(got) != 2 {
+			t.Errorf("QuarantinedGPUs() after failed write = %+v, want GPU 5 left out", got)
+		}
+
+		// The redelivered command writes the file once it can.
+		d.gpuExclusionFile = path
+		if err := quarantine(map[string]string{"gpu_index": "5"}); err != nil {
+			t.Fatalf("redelivered quarantine failed: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading exclusion file: %v", err)
+		}
+		var list GPUExclusionList
+		if err := json.Unmarshal(data, &list); err != nil {
+			t.Fatalf("parsing exclusion file: %v", err)
+		}
+		if len(list.GPUs) != 3 || list.GPUs[2].Index != 5 {
+			t.Errorf("exclusion list = %+v, want GPU 5 added", list.GPUs)
+		}
+	})
+}
+
+func TestDiagnosticCommand(t *testing.T) {
+	ctx := context.Background()
+	injectableGPU := gpu.NewInjectable(4, "")
+
+	n, err := New(Config{
+		ControlPlaneAddr: "http://localhost:50051",
+		NodeID:           "test-node",
+		GPU:              injectableGPU,
+	}, nil)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := injectableGPU.Initialize(ctx); err != nil {
+		t.Fatalf("GPU Initialize failed: %v", err)
+	}
+	var reported []gpu.HealthEvent
+	n.commandDispatcher.SetHealthEventFunc(func(ctx context.Context, events []gpu.HealthEvent) {
+		reported = append(reported, events...)
+	})
+
+	diagnose := func(params map[string]string) (*DiagnosticReport, error) {
+		t.Helper()
+		output, err := n.commandDispatcher.Dispatch(ctx, &pb.NodeCommand{
+			CommandId:  "cmd-1",
+			Type:       pb.NodeCommandType_NODE_COMMAND_TYPE_RUN_DIAGNOSTIC,
+			Parameters: params,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var report DiagnosticReport
+		if err := json.Unmarshal([]byte(output), &report); err != nil {
+			t.Fatalf("parsing output %q: %v", output, err)
+		}
+		return &report, nil
+	}
+	statuses := func(report *DiagnosticReport) map[string]string {
+		m := make(map[string]string)
+		for _, r := range report.Results {
+			m[r.Name] = r.Status
+		}
+		return m
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		report, err := diagnose(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := statuses(report)
+		for _, name := range []string{"nvml", "memory", "pcie", "nvlink"} {
+			if got[name] != DiagnosticPassed {
+				t.Errorf("%s: %s, want passed", name, got[name])
+			}
+		}
+		if !report.Passed || len(reported) != 0 {
+			t.Errorf("passed = %v with %d events reported, want a clean pass", report.Passed, len(reported))
+		}
+	})
+
+	t.Run("faults_become_health_events", func(t *testing.T) {
+		injectableGPU.InjectPCIeDegradation(1, 8)
+		injectableGPU.InjectNVLinkDown(2, 5)
+		injectableGPU.InjectRowRemap(3, true)
+		defer injectableGPU.ClearAllErrors()
+		reported = nil
+
+		report, err := diagnose(map[string]string{"test": "pcie, nvlink,memory"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Passed || len(report.Results) != 3 || report.Results[0].Name != "pcie" {
+			t.Fatalf("report = %+v, want pcie, nvlink, and memory to fail", report)
+		}
+		if f := report.Results[0].Findings; len(f) != 1 || f[0].GPUIndex != 1 || f[0].EventType != "pcie" {
+			t.Errorf("pcie findings = %+v, want GPU 1", f)
+		}
+
+		want := map[pb.HealthEventType]int{
+			pb.HealthEventType_HEALTH_EVENT_TYPE_PCIE:   1,
+			pb.HealthEventType_HEALTH_EVENT_TYPE_NVLINK: 2,
+			pb.HealthEventType_HEALTH_EVENT_TYPE_MEMORY: 3,
+		}
+		if len(reported) != len(want) {
+			t.Fatalf("reported %d events, want %d", len(reported), len(want))
+		}
+		for _, e := range reported {
+			if want[e.EventType] != e.GPUIndex || e.GPUUUID == "" || e.Metrics["diagnostic"] == nil {
+				t.Errorf("event %+v does not match an injected fault", e)
+			}
+		}
+	})
+
+	t.Run("unresponsive_gpu", func(t *testing.T) {
+		injectableGPU.InjectDeviceError(2, errors.New("GPU is lost"))
+		defer injectableGPU.ClearAllErrors()
+		reported = nil
+
+		report, err := diagnose(map[string]string{"test": "nvml"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.Passed || len(reported) != 1 || reported[0].GPUIndex != 2 ||
+			reported[0].EventType != pb.HealthEventType_HEALTH_EVENT_TYPE_DIAGNOSTIC {
+			t.Errorf("reported %+v, want a diagnostic event for GPU 2", reported)
+		}
+	})
+
+	t.Run("external", func(t *testing.T) {
+		n.commandDispatcher.RegisterDiagnostic(&ExternalDiagnostic{
+			DiagnosticName: "failing-tool",
+			Command:        "sh",
+			Args:           []string{"-c", "echo checking; echo 'bandwidth too low'; exit 3"},
+		})
+		n.commandDispatcher.RegisterDiagnostic(&ExternalDiagnostic{
+			DiagnosticName: "missing-tool",
+			Command:        "navarch-no-such-tool",
+		})
+		reported = nil
+
+		report, err := diagnose(map[string]string{"test": "failing-tool,missing-tool"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := statuses(report); got["failing-tool"] != DiagnosticFailed || got["missing-tool"] != DiagnosticSkipped {
+			t.Errorf("statuses = %v, want failing-tool failed and missing-tool skipped", got)
+		}
+		if len(reported) != 1 || reported[0].GPUIndex != -1 || reported[0].Metrics["exit_code"] != 3 ||
+			!strings.Contains(reported[0].Message, "bandwidth too low") {
+			t.Errorf("reported %+v, want the tool's exit status and verdict", reported)
+		}
+	})
+
+	t.Run("external_tool_errors", func(t *testing.T) {
+		dcgm := func(name, output string) *ExternalDiagnostic {
+			for _, d := range DefaultDiagnostics() {
+				if d.Name() == "dcgm" {
+					ext := *d.(*ExternalDiagnostic)
+					ext.DiagnosticName = name
+					ext.Command = "sh"
+					ext.Args = []string{"-c", "printf '" + output + "'; exit 1"}
+					return &ext
+				}
+			}
+			t.Fatal("no dcgm diagnostic")
+			return nil
+		}
+		n.commandDispatcher.RegisterDiagnostic(dcgm("dcgm-failed",
+			`| Deployment | Pass |\n| GPU Memory | Fail - GPU: 2 |\n+------+\n`))
+		n.commandDispatcher.RegisterDiagnostic(dcgm("dcgm-no-engine",
+			`Error: unable to establish a connection to the specified host: localhost\n`))
+		n.commandDispatcher.RegisterDiagnostic(dcgm("dcgm-crashed", `Segmentation fault\n`))
+		reported = nil
+
+		report, err := diagnose(map[string]string{"test": "dcgm-failed,dcgm-no-engine,dcgm-crashed"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]string{"dcgm-failed": DiagnosticFailed, "dcgm-no-engine": DiagnosticSkipped, "dcgm-crashed": DiagnosticError}
+		if got := statuses(report); !maps.Equal(got, want) {
+			t.Errorf("statuses = %v, want %v", got, want)
+		}
+		if len(reported) != 1 || !strings.HasSuffix(reported[0].Message, "| GPU Memory | Fail - GPU: 2 |") {
+			t.Errorf("reported %+v, want only the failed test", reported)
+		}
+	})
+
+	t.Run("unknown_test", func(t *testing.T) {
+		if _, err := diagnose(map[string]string{"test": "nvml,bogus"}); err == nil {
+			t.Error("expected error for an unknown diagnostic")
+		}
+	})
 }
@@ -50,6 +50,15 @@ func (n *Noop) Drain(ctx context.Context, nodeID string, reason string) error {
 	return nil
 }
 
+// NotifyHealth logs the health notification but takes no action.
+func (n *Noop) NotifyHealth(ctx context.Context, nodeID string, reason string) error {
+	n.logger.Info("node health notification (no notifier configured)",
+		slog.String("node_id", nodeID),
+		slog.String("reason", reason),
+	)
+	return nil
+}
+
 // IsDrained always returns true since there's no external system to check.
 func (n *Noop) IsDrained(ctx context.Context, nodeID string) (bool, error) {
 	return true, nil
@@ -57,3 +66,4 @@ func (n *Noop) IsDrained(ctx context.Context, nodeID string) (bool, error) {
 
 // Ensure Noop implements Notifier.
 var _ Notifier = (*Noop)(nil)
+var _ HealthNotifier = (*Noop)(nil)
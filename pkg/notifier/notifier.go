@@ -33,6 +33,14 @@ type Notifier interface {
 	Name() string
 }
 
+// HealthNotifier is implemented by notifiers that can report health policy
+// matches that do not change the node, such as rules with a notify_only
+// action.
+type HealthNotifier interface {
+	// NotifyHealth reports a health problem on a node.
+	NotifyHealth(ctx context.Context, nodeID string, reason string) error
+}
+
 // DrainWaiter provides a helper to wait for a node to be drained.
 type DrainWaiter interface {
 	// WaitForDrain blocks until the node is drained or context is canceled.
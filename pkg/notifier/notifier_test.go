@@ -100,6 +100,30 @@ func TestWebhook(t *testing.T) {
 		}
 	})
 
+	t.Run("sends health webhook", func(t *testing.T) {
+		var receivedEvent WebhookEvent
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&receivedEvent)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		webhook := NewWebhook(WebhookConfig{
+			HealthURL: server.URL,
+		}, nil)
+
+		if err := webhook.NotifyHealth(ctx, "node-3", "rule ecc-sbe-rising matched"); err != nil {
+			t.Errorf("NotifyHealth failed: %v", err)
+		}
+
+		if receivedEvent.Event != "health" {
+			t.Errorf("expected event 'health', got %q", receivedEvent.Event)
+		}
+		if receivedEvent.Reason != "rule ecc-sbe-rising matched" {
+			t.Errorf("expected reason to be passed through, got %q", receivedEvent.Reason)
+		}
+	})
+
 	t.Run("checks drain status", func(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if r.Method != http.MethodGet {
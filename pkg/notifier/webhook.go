@@ -27,6 +27,10 @@ type WebhookConfig struct {
 	// Should return {"drained": true/false}.
 	DrainStatusURL string `yaml:"drain_status_url"`
 
+	// HealthURL is called when a health policy rule with a notify_only
+	// action matches.
+	HealthURL string `yaml:"health_url"`
+
 	// Timeout for webhook requests. Defaults to 30s.
 	Timeout time.Duration `yaml:"timeout"`
 
@@ -127,6 +131,23 @@ func (w *Webhook) Drain(ctx context.Context, nodeID string, reason string) error
 	return w.sendWebhook(ctx, w.config.DrainURL, event)
 }
 
+// NotifyHealth calls the health webhook endpoint.
+func (w *Webhook) NotifyHealth(ctx context.Context, nodeID string, reason string) error {
+	if w.config.HealthURL == "" {
+		w.logger.Debug("no health webhook configured, skipping")
+		return nil
+	}
+
+	event := WebhookEvent{
+		Event:     "health",
+		NodeID:    nodeID,
+		Reason:    reason,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	return w.sendWebhook(ctx, w.config.HealthURL, event)
+}
+
 // IsDrained checks the drain status webhook endpoint.
 func (w *Webhook) IsDrained(ctx context.Context, nodeID string) (bool, error) {
 	if w.config.DrainStatusURL == "" {
@@ -219,3 +240,4 @@ func (w *Webhook) sendWebhook(ctx context.Context, url string, event WebhookEven
 
 // Ensure Webhook implements Notifier.
 var _ Notifier = (*Webhook)(nil)
+var _ HealthNotifier = (*Webhook)(nil)
@@ -32,6 +32,12 @@ type Config struct {
 
 	UnhealthyThreshold int  // Consecutive health check failures before node is unhealthy
 	AutoReplace        bool // Automatically replace unhealthy nodes
+	MinHealthyGPUs     int  // Healthy GPUs below which a node with quarantined GPUs is unhealthy
+	DisableReaper      bool // Exclude this pool's instances from the orphaned instance reaper
+
+	BurnIn        bool          // Hold new nodes back from workloads until they pass diagnostics
+	BurnInTests   []string      // Diagnostics new nodes run; empty runs all
+	BurnInTimeout time.Duration // Max time a node may take to pass (default: 30m)
 
 	Labels map[string]string // Key-value labels for workload routing
 
@@ -51,6 +57,25 @@ const (
 	DefaultIPWaitTimeout = 15 * time.Minute
 )
 
+// NameLabel is the instance label that records which pool an instance
+// belongs to. It is applied at provision time and used to re-adopt
+// instances after a control plane restart.
+const NameLabel = "pool"
+
+// OwnerLabel and ClusterLabel mark an instance as provisioned by a control
+// plane: OwnerLabel holds the pool name and ClusterLabel the control plane's
+// cluster ID. Unlike NameLabel, which other tooling may also use, they let a
+// control plane tell its own instances apart from those of other deployments
+// in the same account. The keys avoid "." and "/" so that they are valid GCP
+// labels.
+const (
+	OwnerLabel   = "navarch-pool"
+	ClusterLabel = "navarch-cluster"
+)
+
+// DefaultClusterID is the cluster ID of a control plane that does not set one.
+const DefaultClusterID = "default"
+
 // ProviderConfig holds configuration for a single provider within a pool.
 type ProviderConfig struct {
 	Name         string           // Provider name
@@ -205,9 +230,38 @@ func NewSimple(cfg Config, prov provider.Provider, providerName string) (*Pool,
 
 // Config returns the pool configuration.
 func (p *Pool) Config() Config {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	return p.config
 }
 
+// UpdateConfig replaces the pool configuration. Existing nodes are kept; new
+// limits apply to the next scaling action. The name cannot change, and
+// providers and instance type are fixed when the pool is created.
+func (p *Pool) UpdateConfig(cfg Config) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cfg.Name != p.config.Name {
+		return fmt.Errorf("cannot rename pool %q to %q", p.config.Name, cfg.Name)
+	}
+	if cfg.InstanceType != p.config.InstanceType {
+		return fmt.Errorf("cannot change instance type of pool %q", p.config.Name)
+	}
+	if cfg.MinNodes < 0 {
+		return fmt.Errorf("min_nodes must be >= 0")
+	}
+	if cfg.MaxNodes == 0 {
+		return fmt.Errorf("max_nodes must be > 0")
+	}
+	if cfg.MaxNodes < cfg.MinNodes {
+		return fmt.Errorf("max_nodes must be >= min_nodes")
+	}
+
+	p.config = cfg
+	return nil
+}
+
 // SetBootstrapCallback sets a callback to be invoked when bootstrap completes.
 func (p *Pool) SetBootstrapCallback(cb BootstrapCallback) {
 	p.mu.Lock()
@@ -301,6 +355,7 @@ func (p *Pool) ScaleUp(ctx context.Context, count int) ([]*provider.Node, error)
 
 func (p *Pool) bootstrapNode(ctx context.Context, node *ManagedNode) {
 	p.setBootstrapStatus(node.Node.ID, BootstrapRunning, "")
+	cfg := p.Config() // UpdateConfig may replace p.config while this runs
 
 	prov := p.getProvider(node.ProviderName)
 	if prov == nil {
@@ -333,18 +388,18 @@ func (p *Pool) bootstrapNode(ctx context.Context, node *ManagedNode) {
 	}
 
 	bootstrapper := bootstrap.New(bootstrap.Config{
-		SetupCommands:     p.config.SetupCommands,
-		SSHUser:           p.config.SSHUser,
-		SSHPrivateKeyPath: p.config.SSHPrivateKeyPath,
+		SetupCommands:     cfg.SetupCommands,
+		SSHUser:           cfg.SSHUser,
+		SSHPrivateKeyPath: cfg.SSHPrivateKeyPath,
 		SSHPort:           node.Node.SSHPort,
-		SSHTimeout:        p.config.SSHTimeout,
-		SSHConnectTimeout: p.config.SSHConnectTimeout,
-		CommandTimeout:    p.config.CommandTimeout,
+		SSHTimeout:        cfg.SSHTimeout,
+		SSHConnectTimeout: cfg.SSHConnectTimeout,
+		CommandTimeout:    cfg.CommandTimeout,
 	}, p.logger)
 
 	vars := bootstrap.TemplateVars{
-		ControlPlane: p.config.ControlPlaneAddr,
-		Pool:         p.config.Name,
+		ControlPlane: cfg.ControlPlaneAddr,
+		Pool:         cfg.Name,
 		NodeID:       node.Node.ID,
 		Provider:     node.ProviderName,
 		Region:       node.Node.Region,
@@ -402,7 +457,7 @@ func (p *Pool) waitForIP(ctx context.Context, prov provider.Provider, nodeID str
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
 
-	ipTimeout := p.config.IPWaitTimeout
+	ipTimeout := p.Config().IPWaitTimeout
 	if ipTimeout == 0 {
 		ipTimeout = DefaultIPWaitTimeout
 	}
@@ -629,6 +684,37 @@ func (p *Pool) Uncordon(nodeID string) error {
 	return nil
 }
 
+// TerminateNode terminates a node and removes it from the pool without
+// provisioning a replacement. Unlike ScaleDown it ignores min_nodes and the
+// cooldown period; the autoscaler restores capacity on its next evaluation.
+func (p *Pool) TerminateNode(ctx context.Context, nodeID string) error {
+	p.mu.Lock()
+	mn, ok := p.nodes[nodeID]
+	if !ok {
+		p.mu.Unlock()
+		return fmt.Errorf("node %s not found in pool", nodeID)
+	}
+	prov := p.getProvider(mn.ProviderName)
+	if prov == nil {
+		p.mu.Unlock()
+		return fmt.Errorf("provider %s not found for node %s", mn.ProviderName, nodeID)
+	}
+	// The provider call can be slow, so the pool is not held locked while it
+	// runs. The node leaves the pool first and returns if termination fails.
+	delete(p.nodes, nodeID)
+	p.mu.Unlock()
+
+	if err := prov.Terminate(ctx, nodeID); err != nil {
+		p.mu.Lock()
+		if _, ok := p.nodes[nodeID]; !ok {
+			p.nodes[nodeID] = mn
+		}
+		p.mu.Unlock()
+		return fmt.Errorf("failed to terminate node: %w", err)
+	}
+	return nil
+}
+
 // ReplaceNode terminates an unhealthy node and provisions a replacement.
 // Currently uses fallback behavior (tries all providers). Future enhancement:
 // add ReplacementStrategy config to prefer same provider for stateful workloads
@@ -725,3 +811,36 @@ func (p *Pool) HasNode(nodeID string) bool {
 	_, ok := p.nodes[nodeID]
 	return ok
 }
+
+// Providers returns the provider configurations backing the pool.
+func (p *Pool) Providers() []ProviderConfig {
+	providers := make([]ProviderConfig, len(p.providers))
+	copy(providers, p.providers)
+	return providers
+}
+
+// Adopt adds an already-running instance to the pool without provisioning it.
+// It is used to recover pool membership from provider inventory after a
+// control plane restart. Adopted nodes are assumed to be bootstrapped.
+// Returns false if the node is already in the pool or providerName is not
+// one of the pool's providers.
+func (p *Pool) Adopt(node *provider.Node, providerName string) bool {
+	if p.getProvider(providerName) == nil {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.nodes[node.ID]; ok {
+		return false
+	}
+	p.nodes[node.ID] = &ManagedNode{
+		Node:          node,
+		Pool:          p.config.Name,
+		ProviderName:  providerName,
+		ProvisionedAt: p.clock.Now(),
+		Bootstrap:     BootstrapSkipped,
+	}
+	return true
+}
@@ -219,6 +219,46 @@ func TestPool_ScaleDown(t *testing.T) {
 	}
 }
 
+func TestPool_Adopt(t *testing.T) {
+	prov := newMockProvider()
+	pool, _ := NewSimple(Config{
+		Name:          "test-pool",
+		MinNodes:      0,
+		MaxNodes:      10,
+		SetupCommands: []string{"echo hi"},
+	}, prov, "mock")
+
+	node := &provider.Node{ID: "existing-1", Provider: "mock", Status: "running"}
+	if !pool.Adopt(node, "mock") {
+		t.Fatal("Adopt() = false, want true")
+	}
+	if pool.Adopt(node, "mock") {
+		t.Error("Adopt() of existing node = true, want false")
+	}
+	if pool.Adopt(&provider.Node{ID: "existing-2"}, "other") {
+		t.Error("Adopt() with unknown provider = true, want false")
+	}
+
+	if !pool.HasNode("existing-1") {
+		t.Fatal("HasNode() = false after Adopt")
+	}
+	mn := pool.Nodes()[0]
+	if mn.Pool != "test-pool" || mn.ProviderName != "mock" {
+		t.Errorf("adopted node pool/provider = %s/%s, want test-pool/mock", mn.Pool, mn.ProviderName)
+	}
+	if mn.Bootstrap != BootstrapSkipped {
+		t.Errorf("adopted node Bootstrap = %s, want %s", mn.Bootstrap, BootstrapSkipped)
+	}
+
+	// Adopted nodes are subject to normal scale down.
+	if err := pool.ScaleDown(context.Background(), 1); err != nil {
+		t.Fatalf("ScaleDown() error = %v", err)
+	}
+	if pool.Status().TotalNodes != 0 {
+		t.Errorf("TotalNodes = %d, want 0", pool.Status().TotalNodes)
+	}
+}
+
 func TestPool_Cordon(t *testing.T) {
 	prov := newMockProvider()
 	pool, _ := NewSimple(Config{
@@ -315,6 +355,86 @@ func TestPool_ReplaceNode(t *testing.T) {
 	}
 }
 
+func TestPool_TerminateNode(t *testing.T) {
+	prov := newMockProvider()
+	pool, _ := NewSimple(Config{
+		Name:           "test-pool",
+		MinNodes:       1,
+		MaxNodes:       10,
+		CooldownPeriod: time.Hour,
+	}, prov, "mock")
+
+	ctx := context.Background()
+	nodes, _ := pool.ScaleUp(ctx, 1)
+
+	// Ignores min_nodes and cooldown.
+	if err := pool.TerminateNode(ctx, nodes[0].ID); err != nil {
+		t.Fatalf("TerminateNode() error = %v", err)
+	}
+	if pool.HasNode(nodes[0].ID) {
+		t.Error("Terminated node should have been removed")
+	}
+	if len(prov.nodes) != 0 {
+		t.Errorf("provider nodes = %d, want 0", len(prov.nodes))
+	}
+
+	if err := pool.TerminateNode(ctx, "missing"); err == nil {
+		t.Error("TerminateNode() should error for unknown node")
+	}
+
+	t.Run("failure keeps the node", func(t *testing.T) {
+		prov := newMockProvider()
+		pool, _ := NewSimple(Config{Name: "fail-pool", MaxNodes: 10}, prov, "mock")
+		nodes, _ := pool.ScaleUp(ctx, 1)
+		prov.failOn = "terminate"
+		if err := pool.TerminateNode(ctx, nodes[0].ID); err == nil {
+			t.Fatal("TerminateNode() should error when the provider fails")
+		}
+		if !pool.HasNode(nodes[0].ID) {
+			t.Error("Node should stay in the pool when termination fails")
+		}
+	})
+
+	t.Run("pool usable during slow terminate", func(t *testing.T) {
+		slow := &slowTerminator{mockProvider: newMockProvider(), started: make(chan struct{}), release: make(chan struct{})}
+		pool, _ := NewSimple(Config{Name: "slow-pool", MaxNodes: 10}, slow, "mock")
+		nodes, _ := pool.ScaleUp(ctx, 2)
+
+		done := make(chan error, 1)
+		go func() { done <- pool.TerminateNode(ctx, nodes[0].ID) }()
+		<-slow.started
+
+		status := make(chan Status, 1)
+		go func() { status <- pool.Status() }()
+		select {
+		case s := <-status:
+			if s.TotalNodes != 1 {
+				t.Errorf("TotalNodes = %d during termination, want 1", s.TotalNodes)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Status() blocked while the provider was terminating a node")
+		}
+
+		close(slow.release)
+		if err := <-done; err != nil {
+			t.Fatalf("TerminateNode() error = %v", err)
+		}
+	})
+}
+
+// slowTerminator blocks Terminate until release is closed.
+type slowTerminator struct {
+	*mockProvider
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *slowTerminator) Terminate(ctx context.Context, nodeID string) error {
+	close(s.started)
+	<-s.release
+	return s.mockProvider.Terminate(ctx, nodeID)
+}
+
 func TestPool_HealthTracking(t *testing.T) {
 	prov := newMockProvider()
 	pool, _ := NewSimple(Config{
@@ -81,6 +81,8 @@ func (n *SimulatedNode) Start(ctx context.Context) error {
 		Labels:           n.spec.Labels,
 		GPU:              n.gpu,
 		Clock:            n.clock,
+
+		DisableCommandStream: n.spec.CommandDelivery == "poll",
 	}
 
 	realNode, err := node.New(cfg, n.logger)
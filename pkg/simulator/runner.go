@@ -35,6 +35,7 @@ type Runner struct {
 
 	httpServer    *http.Server
 	serverDone    chan struct{}
+	serverCancel  context.CancelFunc // Ends open streams so shutdown does not wait on them
 	database      *db.InMemDB
 	cpServer      *controlplane.Server
 
@@ -47,6 +48,10 @@ type Runner struct {
 	startupConfig StartupConfig
 	mu            sync.Mutex
 	nodeSpecs     map[string]NodeSpec // nodeID -> spec for replacement
+
+	// Command delivery tracking
+	commandWG        sync.WaitGroup
+	commandLatencies map[string][]time.Duration // delivery mode -> issue-to-acknowledge latencies
 }
 
 // simHealthObserver implements NodeHealthObserver for the simulator.
@@ -109,6 +114,7 @@ func NewRunner(scenario *Scenario, opts ...RunnerOption) *Runner {
 		nodes:            make(map[string]*SimulatedNode),
 		logger:           slog.Default(),
 		clock:            clock.Real(),
+		commandLatencies: make(map[string][]time.Duration),
 	}
 	for _, opt := range opts {
 		opt(r)
@@ -186,6 +192,9 @@ func (r *Runner) runRegularScenario(ctx context.Context) error {
 		}
 	}
 
+	r.commandWG.Wait()
+	r.logCommandLatencies()
+
 	for _, assertion := range r.scenario.Assertions {
 		if err := r.checkAssertion(ctx, assertion); err != nil {
 			return fmt.Errorf("assertion failed: %w", err)
@@ -498,8 +507,11 @@ func (r *Runner) startControlPlane(ctx context.Context) error {
 	addr := listener.Addr().(*net.TCPAddr)
 	r.controlPlaneAddr = fmt.Sprintf("http://localhost:%d", addr.Port)
 
+	serverCtx, serverCancel := context.WithCancel(context.Background())
+	r.serverCancel = serverCancel
 	r.httpServer = &http.Server{
-		Handler: h2c.NewHandler(mux, &http2.Server{}),
+		Handler:     h2c.NewHandler(mux, &http2.Server{}),
+		BaseContext: func(net.Listener) context.Context { return serverCtx },
 	}
 	r.serverDone = make(chan struct{})
 
@@ -519,6 +531,7 @@ func (r *Runner) stopControlPlane() {
 		// Use real time for HTTP shutdown since it's not part of simulation
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
+		r.serverCancel()
 		r.httpServer.Shutdown(ctx)
 		<-r.serverDone
 	}
@@ -700,6 +713,10 @@ func (r *Runner) issueCommand(ctx context.Context, event Event) error {
 		cmdType = pb.NodeCommandType_NODE_COMMAND_TYPE_TERMINATE
 	case "run_diagnostic":
 		cmdType = pb.NodeCommandType_NODE_COMMAND_TYPE_RUN_DIAGNOSTIC
+	case "reset_gpu":
+		cmdType = pb.NodeCommandType_NODE_COMMAND_TYPE_RESET_GPU
+	case "quarantine_gpu":
+		cmdType = pb.NodeCommandType_NODE_COMMAND_TYPE_QUARANTINE_GPU
 	default:
 		return fmt.Errorf("unknown command type: %s", event.Params.CommandType)
 	}
@@ -721,9 +738,102 @@ func (r *Runner) issueCommand(ctx context.Context, event Event) error {
 		slog.String("type", event.Params.CommandType),
 	)
 
+	// Cordon and drain complete on the control plane; only commands that are
+	// delivered to the node agent have a delivery latency to measure.
+	if cmdType == pb.NodeCommandType_NODE_COMMAND_TYPE_TERMINATE ||
+		cmdType == pb.NodeCommandType_NODE_COMMAND_TYPE_RUN_DIAGNOSTIC ||
+		cmdType == pb.NodeCommandType_NODE_COMMAND_TYPE_RESET_GPU ||
+		cmdType == pb.NodeCommandType_NODE_COMMAND_TYPE_QUARANTINE_GPU {
+		mode := "stream"
+		if node, ok := r.nodes[event.Target]; ok && node.Spec().CommandDelivery == "poll" {
+			mode = "poll"
+		}
+		r.commandWG.Add(1)
+		go func() {
+			defer r.commandWG.Done()
+			r.trackCommandDelivery(ctx, resp.Msg.CommandId, event.Target, mode, r.clock.Now())
+		}()
+	}
+
 	return nil
 }
 
+// trackCommandDelivery waits until the node acknowledges a command and records
+// the time from issue to acknowledgement.
+func (r *Runner) trackCommandDelivery(ctx context.Context, commandID, nodeID, mode string, issuedAt time.Time) {
+	deadline := issuedAt.Add(30 * time.Second)
+	ticker := r.clock.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			if r.clock.Now().After(deadline) {
+				r.logger.Warn("command was not acknowledged",
+					slog.String("command_id", commandID),
+					slog.String("node_id", nodeID),
+				)
+				return
+			}
+
+			resp, err := r.client.GetCommand(ctx, connect.NewRequest(&pb.GetCommandRequest{CommandId: commandID}))
+			if err != nil {
+				continue
+			}
+			switch resp.Msg.Command.Status {
+			case pb.CommandStatus_COMMAND_STATUS_PENDING, pb.CommandStatus_COMMAND_STATUS_DELIVERED:
+				continue
+			}
+
+			latency := r.clock.Since(issuedAt)
+			r.mu.Lock()
+			r.commandLatencies[mode] = append(r.commandLatencies[mode], latency)
+			r.mu.Unlock()
+
+			r.logger.Info("command acknowledged",
+				slog.String("command_id", commandID),
+				slog.String("node_id", nodeID),
+				slog.String("delivery", mode),
+				slog.Duration("latency", latency),
+			)
+			return
+		}
+	}
+}
+
+// CommandLatencies returns the issue-to-acknowledge latency of every tracked
+// command, keyed by delivery mode ("stream" or "poll").
+func (r *Runner) CommandLatencies() map[string][]time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	latencies := make(map[string][]time.Duration, len(r.commandLatencies))
+	for mode, l := range r.commandLatencies {
+		latencies[mode] = append([]time.Duration(nil), l...)
+	}
+	return latencies
+}
+
+func (r *Runner) logCommandLatencies() {
+	for mode, latencies := range r.CommandLatencies() {
+		var sum, max time.Duration
+		for _, l := range latencies {
+			sum += l
+			if l > max {
+				max = l
+			}
+		}
+		r.logger.Info("command delivery latency",
+			slog.String("delivery", mode),
+			slog.Int("commands", len(latencies)),
+			slog.Duration("avg", sum/time.Duration(len(latencies))),
+			slog.Duration("max", max),
+		)
+	}
+}
+
 func (r *Runner) waitForStatus(ctx context.Context, event Event) error {
 	timeout := event.Params.Timeout.Duration()
 	if timeout == 0 {
@@ -851,6 +961,8 @@ func parseNodeStatus(s string) pb.NodeStatus {
 		return pb.NodeStatus_NODE_STATUS_UNHEALTHY
 	case "terminated":
 		return pb.NodeStatus_NODE_STATUS_TERMINATED
+	case "qualifying":
+		return pb.NodeStatus_NODE_STATUS_QUALIFYING
 	default:
 		return pb.NodeStatus_NODE_STATUS_UNKNOWN
 	}
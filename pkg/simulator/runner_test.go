@@ -276,6 +276,52 @@ func TestRunner_Run_WithCommand(t *testing.T) {
 	}
 }
 
+func TestRunner_Run_CommandDeliveryLatency(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	scenario := &Scenario{
+		Name: "command-latency-test",
+		Fleet: []NodeSpec{
+			{ID: "stream-node", Provider: "gcp", GPUCount: 1, GPUType: "NVIDIA H100"},
+			{ID: "poll-node", Provider: "gcp", GPUCount: 1, GPUType: "NVIDIA H100", CommandDelivery: "poll"},
+		},
+		Events: []Event{
+			{At: Duration(0), Action: "start_fleet"},
+			{At: Duration(time.Second), Action: "issue_command", Target: "stream-node", Params: EventParams{
+				CommandType: "run_diagnostic",
+			}},
+			{At: Duration(time.Second), Action: "issue_command", Target: "poll-node", Params: EventParams{
+				CommandType: "run_diagnostic",
+			}},
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	runner := NewRunner(scenario, WithLogger(logger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := runner.Run(ctx); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	latencies := runner.CommandLatencies()
+	if len(latencies["stream"]) != 1 || len(latencies["poll"]) != 1 {
+		t.Fatalf("Expected one latency per delivery mode, got %v", latencies)
+	}
+	stream, poll := latencies["stream"][0], latencies["poll"][0]
+	if stream > time.Second {
+		t.Errorf("Expected streamed command to be acknowledged within 1s, took %v", stream)
+	}
+	if stream >= poll {
+		t.Errorf("Expected streaming (%v) to beat polling (%v)", stream, poll)
+	}
+	t.Logf("command delivery latency: stream=%v poll=%v", stream, poll)
+}
+
 func TestRunner_Run_MultipleNodes(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test in short mode")
@@ -174,7 +174,8 @@ type NodeSpec struct {
 	GPUCount         int               `yaml:"gpu_count"`
 	GPUType          string            `yaml:"gpu_type"`
 	Labels           map[string]string `yaml:"labels,omitempty"`
-	ControlPlaneAddr string            `yaml:"-"` // Set at runtime, not from YAML
+	CommandDelivery  string            `yaml:"command_delivery,omitempty"` // "stream" (default) or "poll"
+	ControlPlaneAddr string            `yaml:"-"`                          // Set at runtime, not from YAML
 	Generation       int               `yaml:"-"` // Replacement generation (0 = original)
 }
 
@@ -302,6 +303,9 @@ func (s *Scenario) Validate() error {
 				return fmt.Errorf("duplicate node ID: %s", node.ID)
 			}
 			nodeIDs[node.ID] = true
+			if d := node.CommandDelivery; d != "" && d != "stream" && d != "poll" {
+				return fmt.Errorf("node %s: invalid command_delivery %q (valid: stream, poll)", node.ID, d)
+			}
 		}
 	}
 